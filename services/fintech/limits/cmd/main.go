@@ -2,19 +2,28 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"fintech/limits-service/internal/config"
 	"fintech/limits-service/internal/handlers"
+	"fintech/limits-service/internal/infrastructure"
+	"fintech/limits-service/pkg/accounts"
 	"fintech/limits-service/pkg/database"
+	"fintech/limits-service/pkg/fx"
 	"fintech/limits-service/pkg/kafka"
+	"fintech/limits-service/pkg/lock"
+	"fintech/limits-service/pkg/metrics"
+	"fintech/limits-service/pkg/middleware"
 	"fintech/limits-service/pkg/otel"
+	"fintech/limits-service/pkg/respond"
 
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -44,47 +53,238 @@ func main() {
 		}
 	}()
 
+	// OTLP metrics are optional; the service runs without them if they can't be set up
+	if cfg.OTLPMetricsEnabled {
+		mp, err := otel.InitMeterProvider(cfg.ServiceName, cfg.OTLPEndpoint)
+		if err != nil {
+			logrus.WithError(err).Warn("Failed to initialize OpenTelemetry metrics, continuing without them")
+		} else {
+			defer func() {
+				if err := mp.Shutdown(context.Background()); err != nil {
+					logrus.WithError(err).Error("Failed to shutdown meter provider")
+				}
+			}()
+		}
+	}
+
 	// Initialize database
 	db, err := database.NewConnection(cfg.DatabaseURL)
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to connect to database")
 	}
 	defer db.Close()
+	if err := db.ConnectReplica(cfg.DatabaseReplicaURL); err != nil {
+		logrus.WithError(err).Fatal("Failed to connect to database")
+	}
 
 	// Run migrations
 	if err := database.RunMigrations(db); err != nil {
 		logrus.WithError(err).Fatal("Failed to run migrations")
 	}
+	if cfg.PerCurrencyLimits {
+		if err := database.MigratePerCurrencyLimits(db); err != nil {
+			logrus.WithError(err).Fatal("Failed to run migrations")
+		}
+	}
 
 	// Initialize handlers
-	limitsHandler := handlers.NewLimitsHandler(db)
+	auditWriter := infrastructure.NewAuditWriter(infrastructure.NewAuditRepository(db), cfg.AuditBatchSize, cfg.AuditFlushInterval)
+	limitsHandler := handlers.NewLimitsHandler(db, fx.NewConverter(newRateProvider(cfg)), auditWriter)
+	limitsHandler.SetConfig(cfg)
+	if cfg.AccountsServiceURL != "" {
+		limitsHandler.SetAccountsClient(accounts.NewClient(cfg.AccountsServiceURL, cfg.AccountsServiceTimeout))
+	}
+
+	if cfg.RedisURL == "" {
+		logrus.Warn("REDIS_URL is empty: limit spends are not locked across replicas")
+	}
+	locker, err := lock.NewRedisLocker(cfg.RedisURL)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to connect to Redis")
+	}
+	defer locker.Close()
+	limitsHandler.SetLocker(locker, cfg.LimitLockTTL)
+	limitsHandler.Warmup(context.Background())
 
 	// Initialize Kafka consumer
-	consumer, err := kafka.NewConsumer(cfg.KafkaBrokers, "limits-service", "payments")
+	consumer, err := kafka.NewConsumer(cfg.KafkaBrokers, "limits-service", cfg.KafkaTopic, cfg.KafkaSecurity())
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to create Kafka consumer")
 	}
 	defer consumer.Close()
+	consumer.WithConcurrency(cfg.KafkaConcurrency).
+		WithMaxEventAge(cfg.KafkaMaxEventAge).
+		WithDeadLetterTopic(cfg.KafkaBrokers, cfg.KafkaDLQTopic)
+
+	reversalConsumer, err := kafka.NewConsumer(cfg.KafkaBrokers, "limits-service-reversals", cfg.KafkaReversalsTopic, cfg.KafkaSecurity())
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to create Kafka reversal consumer")
+	}
+	defer reversalConsumer.Close()
+	reversalConsumer.WithConcurrency(cfg.KafkaConcurrency).
+		WithMaxEventAge(cfg.KafkaMaxEventAge).
+		WithDeadLetterTopic(cfg.KafkaBrokers, cfg.KafkaDLQTopic)
+
+	accountConsumer, err := kafka.NewConsumer(cfg.KafkaBrokers, "limits-service-accounts", cfg.KafkaAccountsTopic, cfg.KafkaSecurity())
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to create Kafka account consumer")
+	}
+	defer accountConsumer.Close()
+	accountConsumer.WithConcurrency(cfg.KafkaConcurrency).
+		WithMaxEventAge(cfg.KafkaMaxEventAge).
+		WithDeadLetterTopic(cfg.KafkaBrokers, cfg.KafkaDLQTopic)
+
+	// Publish payment event limit check results for downstream services
+	if cfg.KafkaDecisionsTopic != "" {
+		producer, err := kafka.NewProducer(cfg.KafkaBrokers, cfg.KafkaSecurity())
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to create Kafka producer")
+		}
+		defer producer.Close()
+		limitsHandler.SetPublisher(producer)
+	}
+
+	// Every instance reads the whole control topic, outside any consumer group
+	var controlConsumer *kafka.Consumer
+	if cfg.KafkaControlTopic != "" {
+		controlConsumer, err = kafka.NewBroadcastConsumer(cfg.KafkaBrokers, cfg.KafkaControlTopic, cfg.KafkaSecurity())
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to create Kafka control consumer")
+		}
+		defer controlConsumer.Close()
+	}
 
 	// Start Kafka consumer in background
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var consumers sync.WaitGroup
+	consumers.Add(3)
 	go func() {
+		defer consumers.Done()
 		logrus.Info("Starting Kafka consumer")
-		if err := consumer.Start(context.Background(), limitsHandler.HandlePaymentEvent); err != nil {
+		if err := consumer.Start(workerCtx, limitsHandler.HandlePaymentEvent); err != nil && !errors.Is(err, context.Canceled) {
 			logrus.WithError(err).Fatal("Kafka consumer failed")
 		}
 	}()
+	go func() {
+		defer consumers.Done()
+		logrus.Info("Starting Kafka reversal consumer")
+		if err := reversalConsumer.StartReversals(workerCtx, limitsHandler.HandlePaymentReversedEvent); err != nil && !errors.Is(err, context.Canceled) {
+			logrus.WithError(err).Fatal("Kafka reversal consumer failed")
+		}
+	}()
+	go func() {
+		defer consumers.Done()
+		logrus.Info("Starting Kafka account consumer")
+		if err := accountConsumer.StartAccountEvents(workerCtx, limitsHandler.HandleAccountCreatedEvent); err != nil && !errors.Is(err, context.Canceled) {
+			logrus.WithError(err).Fatal("Kafka account consumer failed")
+		}
+	}()
+
+	if controlConsumer != nil {
+		consumers.Add(1)
+		go func() {
+			defer consumers.Done()
+			logrus.Info("Starting Kafka control consumer")
+			if err := controlConsumer.StartControl(workerCtx, limitsHandler.HandleControlCommand); err != nil && !errors.Is(err, context.Canceled) {
+				logrus.WithError(err).Fatal("Kafka control consumer failed")
+			}
+		}()
+	}
+
+	// Retry dead-lettered events through the same handlers
+	reprocessor, err := kafka.NewReprocessor(cfg.KafkaBrokers, "limits-service-dlq", cfg.KafkaDLQTopic, cfg.KafkaParkingTopic,
+		cfg.KafkaSecurity(), cfg.KafkaDLQMaxAttempts, cfg.KafkaDLQBackoff)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to create dead letter reprocessor")
+	}
+	defer reprocessor.Close()
+	reprocessor.HandlePayments(cfg.KafkaTopic, limitsHandler.HandlePaymentEvent)
+	reprocessor.HandleReversals(cfg.KafkaReversalsTopic, limitsHandler.HandlePaymentReversedEvent)
+	reprocessor.HandleAccountEvents(cfg.KafkaAccountsTopic, limitsHandler.HandleAccountCreatedEvent)
+
+	consumers.Add(1)
+	go func() {
+		defer consumers.Done()
+		if err := reprocessor.Start(workerCtx); err != nil && !errors.Is(err, context.Canceled) {
+			logrus.WithError(err).Fatal("Dead letter reprocessor failed")
+		}
+	}()
+
+	consumerDone := make(chan struct{})
+	go func() {
+		consumers.Wait()
+		close(consumerDone)
+	}()
+
+	// Flush batched audit entries in background
+	auditDone := make(chan struct{})
+	go func() {
+		defer close(auditDone)
+		auditWriter.Run(workerCtx)
+	}()
+
+	// Background jobs push their run metrics when a Pushgateway is configured
+	pusher := metrics.NewJobPusher(cfg.PushgatewayURL, cfg.PushgatewayTimeout)
+
+	// Release expired limit holds in background
+	var jobs sync.WaitGroup
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		runPeriodically(workerCtx, cfg.HoldSweepInterval, "hold sweeper", pusher, limitsHandler.SweepExpiredHolds)
+	}()
+
+	// Prune limits past the history horizon in background
+	if cfg.ResetInterval > 0 {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			runPeriodically(workerCtx, cfg.ResetInterval, "limit pruning", pusher, limitsHandler.PruneExpiredLimits)
+		}()
+	}
 
 	// Setup HTTP server
 	router := mux.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(middleware.TraceContext)
+	router.Use(middleware.REDMetrics)
+	router.Use(limitsHandler.RejectWritesWhenReadOnly)
+	respond.SetEnvelope(cfg.ResponseEnvelope)
 
 	// Health check endpoint
 	router.HandleFunc("/health", handlers.HealthCheck).Methods("GET")
 
 	// Limits evaluation endpoint
 	router.HandleFunc("/limits/evaluate", limitsHandler.EvaluateLimit).Methods("POST")
+	router.HandleFunc("/limits/evaluate/batch", limitsHandler.EvaluateLimitBatch).Methods("POST")
+	router.HandleFunc("/limits/evaluate/all", limitsHandler.EvaluateAllLimits).Methods("POST")
+
+	// Releases, temporary limit increases and per-transaction maximums are for support tooling, protected by ADMIN_TOKEN
+	router.Handle("/limits/release", middleware.AdminAuth(cfg.AdminToken)(http.HandlerFunc(limitsHandler.ReleaseLimit))).Methods("POST")
+	router.Handle("/limits/override", middleware.AdminAuth(cfg.AdminToken)(http.HandlerFunc(limitsHandler.OverrideLimit))).Methods("POST")
+	router.Handle("/limits/{accountId}/transaction-max", middleware.AdminAuth(cfg.AdminToken)(http.HandlerFunc(limitsHandler.SetTransactionLimit))).Methods("PUT")
+
+	// Limit usage, summary, history and effective limit endpoints
+	router.HandleFunc("/limits/{accountId}", limitsHandler.GetLimitUsage).Methods("GET")
+	router.HandleFunc("/limits/{accountId}/summary", limitsHandler.GetLimitSummary).Methods("GET")
+	router.HandleFunc("/limits/{accountId}/history", limitsHandler.GetLimitHistory).Methods("GET")
+	router.HandleFunc("/limits/{accountId}/trend", limitsHandler.GetLimitTrend).Methods("GET")
+	router.HandleFunc("/limits/{accountId}/effective", limitsHandler.GetEffectiveLimit).Methods("GET")
+
+	// Limit hold endpoints
+	router.HandleFunc("/limits/hold", limitsHandler.CreateHold).Methods("POST")
+	router.HandleFunc("/limits/hold/{token}/commit", limitsHandler.CommitHold).Methods("POST")
+	router.HandleFunc("/limits/hold/{token}", limitsHandler.CancelHold).Methods("DELETE")
 
 	// Loan application endpoint
 	router.HandleFunc("/loans/apply", limitsHandler.ApplyForLoan).Methods("POST")
+	router.HandleFunc("/loans/{applicationId}/recompute", limitsHandler.RecomputeLoan).Methods("POST")
+
+	// Admin endpoints, protected by ADMIN_TOKEN
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.AdminAuth(cfg.AdminToken))
+	admin.HandleFunc("/loglevel", handlers.GetLogLevel).Methods("GET")
+	admin.HandleFunc("/loglevel", handlers.SetLogLevel).Methods("PUT")
 
 	// Metrics endpoint
 	router.Handle("/metrics", promhttp.Handler()).Methods("GET")
@@ -112,17 +312,70 @@ func main() {
 
 	logrus.Info("Shutting down server...")
 
-	// Give outstanding requests a deadline for completion
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Give outstanding requests and the consumer a deadline for completion
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
 		logrus.WithError(err).Error("Server forced to shutdown")
 	}
 
+	stopWorkers()
+	select {
+	case <-consumerDone:
+	case <-ctx.Done():
+		logrus.Warn("Kafka consumers did not drain before shutdown timeout")
+	}
+
+	// Let a running background job finish before the database pool closes
+	jobs.Wait()
+
+	// Persist any audit entries still buffered
+	<-auditDone
+	if err := auditWriter.Close(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to flush audit entries on shutdown")
+	}
+
 	logrus.Info("Server exited")
 }
 
+// newRateProvider builds the FX rate source: the live service when configured, behind a
+// circuit breaker serving last-known rates while it is down, falling back to the fixed
+// configured rates when no last-known rate exists, cached for FX_RATES_CACHE_TTL
+func newRateProvider(cfg *config.Config) fx.RateProvider {
+	var provider fx.RateProvider = fx.NewStaticRateProvider(cfg.FXBaseCurrency, cfg.FXRates)
+	if cfg.FXRatesURL != "" {
+		live := fx.NewCircuitBreakerRateProvider(fx.NewHTTPRateProvider(cfg.FXRatesURL, cfg.FXRatesTimeout), cfg.FXBreakerFailures, cfg.FXBreakerOpenTimeout)
+		provider = fx.NewFallbackRateProvider(live, provider)
+	}
+	return fx.NewCachingRateProvider(provider, cfg.FXRatesCacheTTL)
+}
+
+// runPeriodically invokes job every interval until ctx is cancelled, pushing each run's metrics
+// to pusher on completion
+func runPeriodically(ctx context.Context, interval time.Duration, name string, pusher *metrics.JobPusher, job func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			start := time.Now()
+			err := job(ctx)
+			if err != nil {
+				logrus.WithError(err).WithField("job", name).Error("Background job failed")
+			}
+
+			// Push even if shutdown cancelled the run, so its outcome still reaches the gateway
+			if err := pusher.Push(context.WithoutCancel(ctx), name, time.Since(start), err); err != nil {
+				logrus.WithError(err).WithField("job", name).Warn("Failed to push background job metrics")
+			}
+		}
+	}
+}
+
 func setupLogging(cfg *config.Config) {
 	logrus.SetFormatter(&logrus.JSONFormatter{
 		TimestampFormat: time.RFC3339,
@@ -1,34 +1,150 @@
 package config
 
 import (
+	"fmt"
+	"sort"
 	"time"
 
+	"fintech/limits-service/pkg/flags"
+	"fintech/limits-service/pkg/kafka"
+
 	"github.com/kelseyhightower/envconfig"
 )
 
 // Config holds all configuration for the limits service
 type Config struct {
 	// Service configuration
-	ServiceName  string `envconfig:"SERVICE_NAME" default:"limits-service"`
-	Port         int    `envconfig:"PORT" default:"8080"`
-	Environment  string `envconfig:"ENVIRONMENT" default:"development"`
+	ServiceName     string        `envconfig:"SERVICE_NAME" default:"limits-service"`
+	Port            int           `envconfig:"PORT" default:"8080"`
+	Environment     string        `envconfig:"ENVIRONMENT" default:"development"`
+	ShutdownTimeout time.Duration `envconfig:"SHUTDOWN_TIMEOUT" default:"30s"`
+	// Wrap JSON responses as {"data": ..., "meta": {"requestId", "timestamp"}}
+	ResponseEnvelope bool `envconfig:"RESPONSE_ENVELOPE" default:"false"`
+	// Bearer token required by /admin endpoints; they reject every request when unset
+	AdminToken string `envconfig:"ADMIN_TOKEN"`
+
+	// Feature flags, comma separated, e.g. "spend_rollback"
+	FeatureFlags string     `envconfig:"FEATURE_FLAGS" default:"spend_rollback"`
+	Flags        *flags.Set `ignored:"true"` // Parsed from FeatureFlags by Load
 
 	// Database configuration
-	DatabaseURL string `envconfig:"DATABASE_URL" required:"true"`
+	DatabaseURL        string `envconfig:"DATABASE_URL" required:"true"`
+	DatabaseReplicaURL string `envconfig:"DATABASE_URL_REPLICA"` // Optional read replica for read-only queries
 
 	// Kafka configuration
-	KafkaBrokers string `envconfig:"KAFKA_BROKERS" default:"localhost:9092"`
+	KafkaBrokers        string        `envconfig:"KAFKA_BROKERS" default:"localhost:9092"`
+	KafkaTopic          string        `envconfig:"KAFKA_TOPIC" default:"payments"`
+	KafkaReversalsTopic string        `envconfig:"KAFKA_REVERSALS_TOPIC" default:"payment-reversals"`
+	KafkaAccountsTopic  string        `envconfig:"KAFKA_ACCOUNTS_TOPIC" default:"account-events"`
+	KafkaControlTopic   string        `envconfig:"KAFKA_CONTROL_TOPIC" default:""`        // Runtime toggles broadcast to every instance; disabled when unset
+	KafkaConcurrency    int           `envconfig:"KAFKA_HANDLER_CONCURRENCY" default:"1"` // Per consumer; partitions stay ordered
+	KafkaMaxEventAge    time.Duration `envconfig:"KAFKA_MAX_EVENT_AGE" default:"0"`       // Older events are skipped; 0 disables
+	KafkaDLQTopic       string        `envconfig:"KAFKA_DLQ_TOPIC" default:""`            // Skipped, invalid and failed events are sent here; <KAFKA_TOPIC>.DLQ when unset
+	KafkaParkingTopic   string        `envconfig:"KAFKA_PARKING_TOPIC" default:""`        // Events that can't be reprocessed; dropped when unset
+	KafkaDLQMaxAttempts int           `envconfig:"KAFKA_DLQ_MAX_ATTEMPTS" default:"5"`    // Reprocess attempts before parking
+	KafkaDLQBackoff     time.Duration `envconfig:"KAFKA_DLQ_BACKOFF" default:"30s"`       // Wait before the first reprocess; doubles per attempt
+	KafkaDecisionsTopic string        `envconfig:"KAFKA_DECISIONS_TOPIC" default:""`      // Payment event limit check results are published here when set
+
+	// Kafka security; plaintext without auth by default
+	KafkaTLSEnabled    bool   `envconfig:"KAFKA_TLS_ENABLED" default:"false"`
+	KafkaTLSCAFile     string `envconfig:"KAFKA_TLS_CA_FILE"`
+	KafkaSASLMechanism string `envconfig:"KAFKA_SASL_MECHANISM"` // PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512
+	KafkaSASLUsername  string `envconfig:"KAFKA_SASL_USERNAME"`
+	KafkaSASLPassword  string `envconfig:"KAFKA_SASL_PASSWORD"`
 
 	// OpenTelemetry configuration
-	OTLPEndpoint string `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT" default:"http://otel-collector:4318"`
+	OTLPEndpoint       string `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT" default:"http://otel-collector:4318"`
+	OTLPMetricsEnabled bool   `envconfig:"OTEL_METRICS_ENABLED" default:"false"` // Also export RED metrics over OTLP
 
 	// Limits configuration
 	DefaultDailyLimit   float64       `envconfig:"DEFAULT_DAILY_LIMIT" default:"10000"`
 	DefaultMonthlyLimit float64       `envconfig:"DEFAULT_MONTHLY_LIMIT" default:"50000"`
 	LimitCheckTimeout   time.Duration `envconfig:"LIMIT_CHECK_TIMEOUT" default:"5s"`
+	DailyTxCountLimit   int           `envconfig:"DAILY_TX_COUNT_LIMIT" default:"0"`    // Payments per account per day; 0 disables
+	MonthlyTxCountLimit int           `envconfig:"MONTHLY_TX_COUNT_LIMIT" default:"0"`  // Payments per account per month; 0 disables
+	DefaultLoanCurrency string        `envconfig:"DEFAULT_LOAN_CURRENCY" default:"USD"` // For loan applications without a currency
+	PerCurrencyLimits   bool          `envconfig:"PER_CURRENCY_LIMITS" default:"false"` // One limit per currency instead of converting
+	MaxLimitCurrencies  int           `envconfig:"MAX_LIMIT_CURRENCIES" default:"0"`    // Distinct currencies per account with per-currency limits; 0 disables
+	MaxSingleTxn        float64       `envconfig:"MAX_SINGLE_TXN" default:"0"`          // Default per-transaction maximum in FX_BASE_CURRENCY; 0 disables
+
+	// Loan scoring configuration
+	LoanGradeMultipliers map[string]float64 `envconfig:"LOAN_GRADE_MULTIPLIERS" default:"A:1.5,B:1.2,C:1,D:0.5"` // Approved amount per requested amount
+	LoanAmountBuckets    []float64          `envconfig:"LOAN_AMOUNT_BUCKETS" default:"1000,5000,10000"`          // Ascending bounds for loan_decisions_total
+	ScoringOnMissingData string             `envconfig:"SCORING_ON_MISSING_DATA" default:"conservative"`         // decline or conservative when account data can't be fetched
+
+	// Credit scoring weights; see domain.ScoringConfig
+	ScoringBaseScore               int                `envconfig:"SCORING_BASE_SCORE" default:"500"`
+	ScoringMinScore                int                `envconfig:"SCORING_MIN_SCORE" default:"300"`
+	ScoringMaxScore                int                `envconfig:"SCORING_MAX_SCORE" default:"850"`
+	ScoringNewAccountDays          int                `envconfig:"SCORING_NEW_ACCOUNT_DAYS" default:"30"`
+	ScoringNewAccountPenalty       int                `envconfig:"SCORING_NEW_ACCOUNT_PENALTY" default:"100"`
+	ScoringEstablishedAccountDays  int                `envconfig:"SCORING_ESTABLISHED_ACCOUNT_DAYS" default:"365"`
+	ScoringEstablishedAccountBonus int                `envconfig:"SCORING_ESTABLISHED_ACCOUNT_BONUS" default:"50"`
+	ScoringManyPayments            int                `envconfig:"SCORING_MANY_PAYMENTS" default:"10"`
+	ScoringManyPaymentsBonus       int                `envconfig:"SCORING_MANY_PAYMENTS_BONUS" default:"100"`
+	ScoringSomePayments            int                `envconfig:"SCORING_SOME_PAYMENTS" default:"5"`
+	ScoringSomePaymentsBonus       int                `envconfig:"SCORING_SOME_PAYMENTS_BONUS" default:"50"`
+	ScoringNoPaymentsPenalty       int                `envconfig:"SCORING_NO_PAYMENTS_PENALTY" default:"50"`
+	ScoringLargeAmount             float64            `envconfig:"SCORING_LARGE_AMOUNT" default:"10000"`
+	ScoringLargeAmountPenalty      int                `envconfig:"SCORING_LARGE_AMOUNT_PENALTY" default:"50"`
+	ScoringSmallAmount             float64            `envconfig:"SCORING_SMALL_AMOUNT" default:"1000"`
+	ScoringSmallAmountBonus        int                `envconfig:"SCORING_SMALL_AMOUNT_BONUS" default:"25"`
+	ScoringGradeThresholds         map[string]int     `envconfig:"SCORING_GRADE_THRESHOLDS" default:"A:750,B:650,C:550,D:450"` // Minimum score per grade
+	ScoringApprovalCaps            map[string]float64 `envconfig:"SCORING_APPROVAL_CAPS" default:"C:5000,D:1000"`              // Largest approved request per grade
+
+	// Startup warmup of hot accounts' current-period limits
+	WarmupAccountIDs  []string      `envconfig:"WARMUP_ACCOUNT_IDS"`
+	WarmupMaxAccounts int           `envconfig:"WARMUP_MAX_ACCOUNTS" default:"1000"`
+	WarmupTimeout     time.Duration `envconfig:"WARMUP_TIMEOUT" default:"10s"`
+
+	// Limit hold configuration
+	HoldTTL           time.Duration `envconfig:"HOLD_TTL" default:"15m"`
+	MaxHoldTTL        time.Duration `envconfig:"MAX_HOLD_TTL" default:"24h"`
+	HoldSweepInterval time.Duration `envconfig:"HOLD_SWEEP_INTERVAL" default:"1m"`
+
+	// How often limits past the history horizon are pruned; 0 disables. The horizon is the number of
+	// periods of each limit type kept, matching the most the history and trend endpoints return.
+	ResetInterval         time.Duration `envconfig:"RESET_INTERVAL" default:"1h"`
+	LimitRetentionPeriods int           `envconfig:"LIMIT_RETENTION_PERIODS" default:"366"`
+
+	// Prometheus Pushgateway that background jobs push their run metrics to; pushing is disabled when empty
+	PushgatewayURL     string        `envconfig:"PUSHGATEWAY_URL"`
+	PushgatewayTimeout time.Duration `envconfig:"PUSHGATEWAY_TIMEOUT" default:"5s"`
+
+	// Accounts service that account age and payment history for loan scoring come from; without it
+	// SCORING_ON_MISSING_DATA decides every loan application
+	AccountsServiceURL     string        `envconfig:"ACCOUNTS_SERVICE_URL"`
+	AccountsServiceTimeout time.Duration `envconfig:"ACCOUNTS_SERVICE_TIMEOUT" default:"2s"`
 
-	// Redis configuration (for future distributed locking)
-	RedisURL string `envconfig:"REDIS_URL" default:"redis://localhost:6379"`
+	// Currency conversion configuration
+	FXBaseCurrency       string             `envconfig:"FX_BASE_CURRENCY" default:"USD"`
+	FXRates              map[string]float64 `envconfig:"FX_RATES" default:"EUR:0.92,GBP:0.79,SEK:10.5"` // Units per 1 base currency
+	FXRatesURL           string             `envconfig:"FX_RATES_URL"`                                  // Optional live rate source
+	FXRatesTimeout       time.Duration      `envconfig:"FX_RATES_TIMEOUT" default:"2s"`
+	FXRatesCacheTTL      time.Duration      `envconfig:"FX_RATES_CACHE_TTL" default:"5m"`
+	FXBreakerFailures    int                `envconfig:"FX_BREAKER_FAILURES" default:"5"` // Consecutive failures before the breaker opens
+	FXBreakerOpenTimeout time.Duration      `envconfig:"FX_BREAKER_OPEN_TIMEOUT" default:"30s"`
+	FXToleranceBps       float64            `envconfig:"FX_TOLERANCE_BPS" default:"0"` // Allowed overshoot for converted spends
+
+	// Audit log configuration
+	AuditBatchSize     int           `envconfig:"AUDIT_BATCH_SIZE" default:"100"`
+	AuditFlushInterval time.Duration `envconfig:"AUDIT_FLUSH_INTERVAL" default:"2s"`
+	AuditSpends        bool          `envconfig:"AUDIT_SPENDS" default:"true"` // Audit every successful limit spend
+
+	// Redis configuration for distributed limit locking; locking is disabled when set to empty
+	RedisURL     string        `envconfig:"REDIS_URL" default:"redis://localhost:6379"`
+	LimitLockTTL time.Duration `envconfig:"LIMIT_LOCK_TTL" default:"5s"` // Longest a spend holds its account's lock
+}
+
+// KafkaSecurity returns the TLS and SASL settings for Kafka connections
+func (c *Config) KafkaSecurity() kafka.SecurityConfig {
+	return kafka.SecurityConfig{
+		TLS:           c.KafkaTLSEnabled,
+		CAFile:        c.KafkaTLSCAFile,
+		SASLMechanism: c.KafkaSASLMechanism,
+		Username:      c.KafkaSASLUsername,
+		Password:      c.KafkaSASLPassword,
+	}
 }
 
 // Load loads configuration from environment variables
@@ -38,5 +154,20 @@ func Load() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	if cfg.ScoringOnMissingData != "decline" && cfg.ScoringOnMissingData != "conservative" {
+		return nil, fmt.Errorf("invalid SCORING_ON_MISSING_DATA %q: must be decline or conservative", cfg.ScoringOnMissingData)
+	}
+	if cfg.ScoringMinScore > cfg.ScoringMaxScore {
+		return nil, fmt.Errorf("invalid SCORING_MIN_SCORE %d: must not exceed SCORING_MAX_SCORE %d", cfg.ScoringMinScore, cfg.ScoringMaxScore)
+	}
+	if cfg.MaxSingleTxn < 0 {
+		return nil, fmt.Errorf("invalid MAX_SINGLE_TXN %.2f: must not be negative", cfg.MaxSingleTxn)
+	}
+	if cfg.KafkaDLQTopic == "" {
+		cfg.KafkaDLQTopic = cfg.KafkaTopic + ".DLQ"
+	}
+	cfg.Flags = flags.Parse(cfg.FeatureFlags)
+	sort.Float64s(cfg.LoanAmountBuckets)
+
 	return &cfg, nil
 }
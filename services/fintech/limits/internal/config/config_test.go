@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadShutdownTimeoutDefault(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/test")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.ShutdownTimeout != 30*time.Second {
+		t.Errorf("ShutdownTimeout = %v, want 30s", cfg.ShutdownTimeout)
+	}
+}
+
+func TestLoadShutdownTimeoutConfigured(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/test")
+	t.Setenv("SHUTDOWN_TIMEOUT", "90s")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.ShutdownTimeout != 90*time.Second {
+		t.Errorf("ShutdownTimeout = %v, want 90s", cfg.ShutdownTimeout)
+	}
+}
+
+func TestLoadDLQTopicDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"default topic", nil, "payments.DLQ"},
+		{"configured topic", map[string]string{"KAFKA_TOPIC": "payments-v2"}, "payments-v2.DLQ"},
+		{"configured dead letter topic", map[string]string{"KAFKA_DLQ_TOPIC": "limits-dlq"}, "limits-dlq"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DATABASE_URL", "postgres://localhost/test")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.KafkaDLQTopic != tt.want {
+				t.Errorf("KafkaDLQTopic = %q, want %q", cfg.KafkaDLQTopic, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // LimitType represents different types of limits
@@ -16,16 +18,18 @@ const (
 
 // Limit represents a spending limit for an account
 type Limit struct {
-	ID          string    `json:"id"`
-	AccountID   string    `json:"account_id"`
-	Type        LimitType `json:"type"`
-	Amount      float64   `json:"amount"`
-	Used        float64   `json:"used"`
-	Currency    string    `json:"currency"`
-	PeriodStart time.Time `json:"period_start"`
-	PeriodEnd   time.Time `json:"period_end"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                string     `json:"id"`
+	AccountID         string     `json:"account_id"`
+	Type              LimitType  `json:"type"`
+	Amount            float64    `json:"amount"`
+	Used              float64    `json:"used"`
+	Currency          string     `json:"currency"`
+	PeriodStart       time.Time  `json:"period_start"`
+	PeriodEnd         time.Time  `json:"period_end"`
+	OverrideAmount    *float64   `json:"override_amount,omitempty"` // Temporary increase replacing Amount until OverrideExpiresAt
+	OverrideExpiresAt *time.Time `json:"override_expires_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
 }
 
 // NewLimit creates a new limit with default values
@@ -41,17 +45,9 @@ func NewLimit(accountID string, limitType LimitType, amount float64, currency st
 	}
 
 	now := time.Now().UTC()
-	var periodStart, periodEnd time.Time
-
-	switch limitType {
-	case DailyLimit:
-		periodStart = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-		periodEnd = periodStart.AddDate(0, 0, 1).Add(-time.Nanosecond)
-	case MonthlyLimit:
-		periodStart = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
-		periodEnd = periodStart.AddDate(0, 1, 0).Add(-time.Nanosecond)
-	default:
-		return nil, errors.New("invalid limit type")
+	periodStart, periodEnd, err := periodBounds(limitType, now)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Limit{
@@ -67,17 +63,75 @@ func NewLimit(accountID string, limitType LimitType, amount float64, currency st
 	}, nil
 }
 
+// periodBounds returns the first and last instant of the limitType period containing now
+func periodBounds(limitType LimitType, now time.Time) (time.Time, time.Time, error) {
+	var periodStart, periodEnd time.Time
+
+	switch limitType {
+	case DailyLimit:
+		periodStart = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		periodEnd = periodStart.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	case MonthlyLimit:
+		periodStart = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		periodEnd = periodStart.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	default:
+		return time.Time{}, time.Time{}, errors.New("invalid limit type")
+	}
+
+	return periodStart, periodEnd, nil
+}
+
+// ErrInvalidOverride is returned for a temporary increase that doesn't raise the limit or has already expired
+var ErrInvalidOverride = errors.New("invalid temporary limit increase")
+
+// OverrideActive reports whether a temporary increase currently replaces the limit amount
+func (l *Limit) OverrideActive() bool {
+	return l.OverrideAmount != nil && l.OverrideExpiresAt != nil && time.Now().UTC().Before(*l.OverrideExpiresAt)
+}
+
+// EffectiveAmount returns the amount that can be spent this period: the temporary increase while
+// it is active, the base amount otherwise
+func (l *Limit) EffectiveAmount() float64 {
+	if l.OverrideActive() {
+		return *l.OverrideAmount
+	}
+	return l.Amount
+}
+
+// ValidateOverride checks that a temporary increase to amount until expiresAt raises the limit and
+// hasn't already expired
+func (l *Limit) ValidateOverride(amount float64, expiresAt time.Time) error {
+	if amount <= l.Amount {
+		return fmt.Errorf("%w: amount must exceed the base limit of %.2f %s", ErrInvalidOverride, l.Amount, l.Currency)
+	}
+	if !expiresAt.After(time.Now().UTC()) {
+		return fmt.Errorf("%w: expiry must be in the future", ErrInvalidOverride)
+	}
+	return nil
+}
+
 // CanSpend checks if a transaction amount can be spent within the limit
 func (l *Limit) CanSpend(amount float64) bool {
-	return l.Used+amount <= l.Amount
+	return l.Used+amount <= l.EffectiveAmount()
+}
+
+// CanSpendWithTolerance checks if a transaction amount fits the limit when it may
+// overshoot by up to toleranceBps basis points of the limit amount
+func (l *Limit) CanSpendWithTolerance(amount float64, toleranceBps float64) bool {
+	return l.Used+amount <= l.EffectiveAmount()*(1+toleranceBps/10000)
 }
 
 // Spend deducts amount from the available limit
 func (l *Limit) Spend(amount float64) error {
+	return l.SpendWithTolerance(amount, 0)
+}
+
+// SpendWithTolerance deducts amount, allowing the limit to be overshot by up to toleranceBps basis points
+func (l *Limit) SpendWithTolerance(amount float64, toleranceBps float64) error {
 	if amount <= 0 {
 		return errors.New("spend amount must be positive")
 	}
-	if !l.CanSpend(amount) {
+	if !l.CanSpendWithTolerance(amount, toleranceBps) {
 		return errors.New("insufficient limit")
 	}
 
@@ -86,12 +140,27 @@ func (l *Limit) Spend(amount float64) error {
 	return nil
 }
 
+// Release returns a previously spent amount to the available limit
+func (l *Limit) Release(amount float64) error {
+	if amount <= 0 {
+		return errors.New("release amount must be positive")
+	}
+
+	l.Used -= amount
+	if l.Used < 0 {
+		l.Used = 0
+	}
+	l.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
 // GetRemaining returns the remaining available limit
 func (l *Limit) GetRemaining() float64 {
-	if l.Used > l.Amount {
+	amount := l.EffectiveAmount()
+	if l.Used > amount {
 		return 0
 	}
-	return l.Amount - l.Used
+	return amount - l.Used
 }
 
 // IsExpired checks if the limit period has expired
@@ -99,12 +168,299 @@ func (l *Limit) IsExpired() bool {
 	return time.Now().UTC().After(l.PeriodEnd)
 }
 
+// PeriodLabel returns a human-readable label for the limit's period,
+// e.g. "2024-12-15 (Daily)" or "December 2024 (Monthly)"
+func (l *Limit) PeriodLabel() string {
+	switch l.Type {
+	case DailyLimit:
+		return l.PeriodStart.Format("2006-01-02") + " (Daily)"
+	case MonthlyLimit:
+		return l.PeriodStart.Format("January 2006") + " (Monthly)"
+	default:
+		return l.PeriodStart.Format("2006-01-02")
+	}
+}
+
 // Reset resets the used amount to zero (for new periods)
 func (l *Limit) Reset() {
 	l.Used = 0
 	l.UpdatedAt = time.Now().UTC()
 }
 
+// VelocityLimit caps the number of transactions an account may make in a period, alongside the
+// amount its Limit allows
+type VelocityLimit struct {
+	ID          string    `json:"id"`
+	AccountID   string    `json:"account_id"`
+	Type        LimitType `json:"type"`
+	MaxCount    int       `json:"max_count"`
+	TxCount     int       `json:"tx_count"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewVelocityLimit creates a transaction count limit for the current period
+func NewVelocityLimit(accountID string, limitType LimitType, maxCount int) (*VelocityLimit, error) {
+	if accountID == "" {
+		return nil, errors.New("account ID cannot be empty")
+	}
+	if maxCount <= 0 {
+		return nil, errors.New("max transaction count must be positive")
+	}
+
+	now := time.Now().UTC()
+	periodStart, periodEnd, err := periodBounds(limitType, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VelocityLimit{
+		AccountID:   accountID,
+		Type:        limitType,
+		MaxCount:    maxCount,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// CanTransact checks if one more transaction fits the limit
+func (v *VelocityLimit) CanTransact() bool {
+	return v.TxCount < v.MaxCount
+}
+
+// IncrementCount records one transaction against the limit
+func (v *VelocityLimit) IncrementCount() error {
+	if !v.CanTransact() {
+		return errors.New("transaction count limit reached")
+	}
+
+	v.TxCount++
+	v.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// GetRemaining returns how many more transactions the limit allows this period
+func (v *VelocityLimit) GetRemaining() int {
+	if v.TxCount > v.MaxCount {
+		return 0
+	}
+	return v.MaxCount - v.TxCount
+}
+
+// PerTransactionLimit is the limit type reported for a payment rejected by its account's
+// per-transaction maximum
+const PerTransactionLimit = "PER_TRANSACTION"
+
+// ErrMsgExceedsPerTransactionMax is the error message of a result denied by a per-transaction maximum
+const ErrMsgExceedsPerTransactionMax = "Exceeds per-transaction maximum"
+
+// TransactionLimit caps the amount of any single payment from an account, however much of its
+// daily and monthly limits remains
+type TransactionLimit struct {
+	AccountID string    `json:"account_id"`
+	MaxAmount float64   `json:"max_amount"`
+	Currency  string    `json:"currency"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewTransactionLimit creates a per-transaction maximum for an account
+func NewTransactionLimit(accountID string, maxAmount float64, currency string) (*TransactionLimit, error) {
+	if accountID == "" {
+		return nil, errors.New("account ID cannot be empty")
+	}
+	if maxAmount <= 0 {
+		return nil, errors.New("max transaction amount must be positive")
+	}
+
+	return &TransactionLimit{
+		AccountID: accountID,
+		MaxAmount: maxAmount,
+		Currency:  currency,
+		UpdatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// Allows checks if a single payment of amount, in the limit's currency, is within the maximum
+func (t *TransactionLimit) Allows(amount float64) bool {
+	return amount <= t.MaxAmount
+}
+
+// DeniedResult returns the check result of a payment rejected for exceeding the maximum
+func (t *TransactionLimit) DeniedResult() *LimitCheckResult {
+	return &LimitCheckResult{
+		Allowed:      false,
+		LimitType:    PerTransactionLimit,
+		AccountID:    t.AccountID,
+		LimitAmount:  t.MaxAmount,
+		Remaining:    t.MaxAmount,
+		ErrorMessage: ErrMsgExceedsPerTransactionMax,
+		ErrorCode:    ErrCodeLimitExceeded,
+	}
+}
+
+// LimitSummary reports usage of one limit period. Summaries aggregated across
+// currencies are expressed in the base currency.
+type LimitSummary struct {
+	Type        LimitType `json:"type"`
+	Currency    string    `json:"currency"`
+	Amount      float64   `json:"amount"`
+	Used        float64   `json:"used"`
+	Remaining   float64   `json:"remaining"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Aggregated  bool      `json:"aggregated"`
+}
+
+// UsagePoint is one day of a limit usage trend
+type UsagePoint struct {
+	Date        string  `json:"date"` // YYYY-MM-DD, UTC
+	Used        float64 `json:"used"`
+	Amount      float64 `json:"amount"`
+	Utilization float64 `json:"utilization"` // used / amount; 0 when there is no limit that day
+}
+
+// BuildDailyTrend lays daily summaries out as a series of days consecutive days starting at from,
+// with zero points for days that have no limit row
+func BuildDailyTrend(summaries []*LimitSummary, from time.Time, days int) []UsagePoint {
+	byDate := make(map[string]*LimitSummary, len(summaries))
+	for _, summary := range summaries {
+		byDate[summary.PeriodStart.UTC().Format("2006-01-02")] = summary
+	}
+
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	points := make([]UsagePoint, 0, days)
+	for i := 0; i < days; i++ {
+		point := UsagePoint{Date: start.AddDate(0, 0, i).Format("2006-01-02")}
+		if summary, ok := byDate[point.Date]; ok {
+			point.Used = summary.Used
+			point.Amount = summary.Amount
+			if summary.Amount > 0 {
+				point.Utilization = summary.Used / summary.Amount
+			}
+		}
+		points = append(points, point)
+	}
+
+	return points
+}
+
+// NewLimitSummary summarizes a single limit row in its own currency
+func NewLimitSummary(limit *Limit) *LimitSummary {
+	return &LimitSummary{
+		Type:        limit.Type,
+		Currency:    limit.Currency,
+		Amount:      limit.EffectiveAmount(),
+		Used:        limit.Used,
+		Remaining:   limit.GetRemaining(),
+		PeriodStart: limit.PeriodStart,
+		PeriodEnd:   limit.PeriodEnd,
+	}
+}
+
+// Effective limit rules, in the order they are applied
+const (
+	RuleBaseDefault       = "base_default"       // Configured default for the limit type
+	RulePeriodLimit       = "period_limit"       // Amount stored for the current period, e.g. an approved loan limit
+	RuleTemporaryIncrease = "temporary_increase" // Support-granted increase until it expires
+)
+
+// EffectiveLimitRule is one step in resolving an effective limit
+type EffectiveLimitRule struct {
+	Rule    string  `json:"rule"`
+	Amount  float64 `json:"amount"`
+	Applied bool    `json:"applied"`
+	Detail  string  `json:"detail,omitempty"`
+}
+
+// EffectiveLimit is the limit that applies to an account, with the rules that produced it
+type EffectiveLimit struct {
+	AccountID string               `json:"account_id"`
+	Type      LimitType            `json:"type"`
+	Currency  string               `json:"currency"`
+	Amount    float64              `json:"amount"`
+	Used      float64              `json:"used"`
+	Remaining float64              `json:"remaining"`
+	Rules     []EffectiveLimitRule `json:"rules"`
+}
+
+// ResolveEffectiveLimit starts from the configured default and lets the current period's stored
+// limit, when there is one, take precedence. current may be nil if nothing was spent this period.
+func ResolveEffectiveLimit(accountID string, limitType LimitType, defaultAmount float64, defaultCurrency string, current *Limit) *EffectiveLimit {
+	effective := &EffectiveLimit{
+		AccountID: accountID,
+		Type:      limitType,
+		Currency:  defaultCurrency,
+		Amount:    defaultAmount,
+		Remaining: defaultAmount,
+		Rules: []EffectiveLimitRule{{
+			Rule:    RuleBaseDefault,
+			Amount:  defaultAmount,
+			Applied: true,
+		}},
+	}
+
+	if current == nil {
+		return effective
+	}
+
+	overrides := current.Amount != defaultAmount || current.Currency != defaultCurrency
+	effective.Rules = append(effective.Rules, EffectiveLimitRule{
+		Rule:    RulePeriodLimit,
+		Amount:  current.Amount,
+		Applied: overrides,
+		Detail:  fmt.Sprintf("%s limit of %.2f %s", current.PeriodLabel(), current.Amount, current.Currency),
+	})
+
+	if current.OverrideAmount != nil && current.OverrideExpiresAt != nil {
+		effective.Rules = append(effective.Rules, EffectiveLimitRule{
+			Rule:    RuleTemporaryIncrease,
+			Amount:  *current.OverrideAmount,
+			Applied: current.OverrideActive(),
+			Detail:  fmt.Sprintf("Increased to %.2f %s until %s", *current.OverrideAmount, current.Currency, current.OverrideExpiresAt.Format(time.RFC3339)),
+		})
+	}
+
+	effective.Currency = current.Currency
+	effective.Amount = current.EffectiveAmount()
+	effective.Used = current.Used
+	effective.Remaining = current.GetRemaining()
+	return effective
+}
+
+// HoldStatus represents the lifecycle state of a limit hold
+type HoldStatus string
+
+const (
+	HoldActive    HoldStatus = "HELD"
+	HoldCommitted HoldStatus = "COMMITTED"
+	HoldReleased  HoldStatus = "RELEASED"
+	HoldExpired   HoldStatus = "EXPIRED"
+)
+
+// ErrTooManyCurrencies is returned when a spend would give an account limits in more currencies than allowed
+var ErrTooManyCurrencies = errors.New("account has limits in the maximum number of currencies")
+
+// ErrHoldNotActive is returned when a hold does not exist or was already committed, released or expired
+var ErrHoldNotActive = errors.New("hold not found or no longer active")
+
+// LimitHold represents an amount reserved against a limit until it is committed or released
+type LimitHold struct {
+	Token     string     `json:"token"`
+	LimitID   string     `json:"limit_id"`
+	AccountID string     `json:"account_id"`
+	Type      LimitType  `json:"type"`
+	Amount    float64    `json:"amount"`
+	Currency  string     `json:"currency"`
+	Status    HoldStatus `json:"status"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
 // ScoringResult represents the result of a credit scoring evaluation
 type ScoringResult struct {
 	Score       int     `json:"score"`        // Score from 0-1000
@@ -116,12 +472,160 @@ type ScoringResult struct {
 	CalculatedAt time.Time `json:"calculated_at"`
 }
 
+// LoanDecision is a persisted, versioned scoring outcome for a loan application.
+// Recomputed decisions get a new version linked to the application's original decision.
+type LoanDecision struct {
+	ID                 string        `json:"id"`
+	ApplicationID      string        `json:"application_id"`
+	Version            int           `json:"version"`
+	OriginalDecisionID string        `json:"original_decision_id,omitempty"`
+	AccountID          string        `json:"account_id"`
+	RequestedAmount    float64       `json:"requested_amount"`
+	Currency           string        `json:"currency"`
+	AccountAgeDays     int           `json:"account_age_days"`
+	PreviousPayments   int           `json:"previous_payments"`
+	Result             ScoringResult `json:"result"`
+	OverriddenBy       string        `json:"overridden_by,omitempty"`
+	CreatedAt          time.Time     `json:"created_at"`
+}
+
+// ErrDecisionNotFound is returned when no decision exists for a loan application
+var ErrDecisionNotFound = errors.New("loan decision not found")
+
+// Recompute derives the next version of this decision from overridden inputs
+func (d *LoanDecision) Recompute(result *ScoringResult, accountAgeDays, previousPayments int, overriddenBy string) *LoanDecision {
+	originalID := d.OriginalDecisionID
+	if originalID == "" {
+		originalID = d.ID
+	}
+
+	return &LoanDecision{
+		ApplicationID:      d.ApplicationID,
+		Version:            d.Version + 1,
+		OriginalDecisionID: originalID,
+		AccountID:          d.AccountID,
+		RequestedAmount:    d.RequestedAmount,
+		Currency:           d.Currency,
+		AccountAgeDays:     accountAgeDays,
+		PreviousPayments:   previousPayments,
+		Result:             *result,
+		OverriddenBy:       overriddenBy,
+		CreatedAt:          time.Now().UTC(),
+	}
+}
+
+// MissingDataPolicy selects how a loan application is scored when its account data can't be fetched
+type MissingDataPolicy string
+
+const (
+	// MissingDataDecline declines the application outright
+	MissingDataDecline MissingDataPolicy = "decline"
+	// MissingDataConservative scores it as a brand-new account with no payment history
+	MissingDataConservative MissingDataPolicy = "conservative"
+)
+
+// ScoringConfig holds the tunable parameters of credit scoring
+type ScoringConfig struct {
+	// Score before adjustments, and the bounds the adjusted score is clamped to
+	BaseScore int
+	MinScore  int
+	MaxScore  int
+
+	// Accounts younger than NewAccountDays lose NewAccountPenalty points; accounts older than
+	// EstablishedAccountDays gain EstablishedAccountBonus
+	NewAccountDays          int
+	NewAccountPenalty       int
+	EstablishedAccountDays  int
+	EstablishedAccountBonus int
+
+	// More than ManyPayments previous payments gain ManyPaymentsBonus points, more than SomePayments
+	// gain SomePaymentsBonus, and none lose NoPaymentsPenalty
+	ManyPayments      int
+	ManyPaymentsBonus int
+	SomePayments      int
+	SomePaymentsBonus int
+	NoPaymentsPenalty int
+
+	// Requests above LargeAmount lose LargeAmountPenalty points; requests below SmallAmount gain
+	// SmallAmountBonus
+	LargeAmount        float64
+	LargeAmountPenalty int
+	SmallAmount        float64
+	SmallAmountBonus   int
+
+	// Minimum score for grades A to D; lower scores are graded F
+	GradeThresholds map[string]int
+	// Largest requested amount approved, by grade; grades without a cap approve any amount
+	ApprovalCaps map[string]float64
+	// Approved MaxAmount as a multiple of the requested amount, by grade
+	GradeMultipliers map[string]float64
+}
+
+// DefaultScoringConfig returns the standard scoring parameters
+func DefaultScoringConfig() ScoringConfig {
+	return ScoringConfig{
+		BaseScore:               500,
+		MinScore:                300,
+		MaxScore:                850,
+		NewAccountDays:          30,
+		NewAccountPenalty:       100,
+		EstablishedAccountDays:  365,
+		EstablishedAccountBonus: 50,
+		ManyPayments:            10,
+		ManyPaymentsBonus:       100,
+		SomePayments:            5,
+		SomePaymentsBonus:       50,
+		NoPaymentsPenalty:       50,
+		LargeAmount:             10000,
+		LargeAmountPenalty:      50,
+		SmallAmount:             1000,
+		SmallAmountBonus:        25,
+		GradeThresholds:         map[string]int{"A": 750, "B": 650, "C": 550, "D": 450},
+		ApprovalCaps:            map[string]float64{"C": 5000, "D": 1000},
+		GradeMultipliers:        map[string]float64{"A": 1.5, "B": 1.2, "C": 1.0, "D": 0.5},
+	}
+}
+
+// scoringGrades lists the approvable grades from best to worst, with the outcome of each
+var scoringGrades = []struct {
+	grade            string
+	riskLevel        string
+	reason           string
+	exceedsCapReason string
+}{
+	{"A", "Low", "Excellent credit profile", "Amount exceeds approved limit for credit score"},
+	{"B", "Low", "Good credit profile", "Amount exceeds approved limit for credit score"},
+	{"C", "Medium", "Moderate credit profile", "Amount exceeds approved limit for credit score"},
+	{"D", "High", "Below average credit profile", "Insufficient credit score for requested amount"},
+}
+
 // ScoringService provides credit scoring functionality
-type ScoringService struct{}
+type ScoringService struct {
+	config ScoringConfig
+}
 
-// NewScoringService creates a new scoring service
+// NewScoringService creates a new scoring service with the default configuration
 func NewScoringService() *ScoringService {
-	return &ScoringService{}
+	return &ScoringService{config: DefaultScoringConfig()}
+}
+
+// SetConfig replaces the scoring parameters. Grades missing from GradeThresholds or
+// GradeMultipliers keep their default threshold or multiplier; ApprovalCaps replaces the default
+// caps when set.
+func (s *ScoringService) SetConfig(config ScoringConfig) {
+	defaults := DefaultScoringConfig()
+	for grade, threshold := range config.GradeThresholds {
+		defaults.GradeThresholds[grade] = threshold
+	}
+	for grade, multiplier := range config.GradeMultipliers {
+		defaults.GradeMultipliers[grade] = multiplier
+	}
+	config.GradeThresholds = defaults.GradeThresholds
+	config.GradeMultipliers = defaults.GradeMultipliers
+	if config.ApprovalCaps == nil {
+		config.ApprovalCaps = defaults.ApprovalCaps
+	}
+	s.config = config
 }
 
 // AuditEntry represents an audit log entry
@@ -164,9 +668,31 @@ func (a *AuditService) LogAction(eventType, accountID, userID, action, resource,
 	}
 }
 
-// generateID generates a simple ID for audit entries
+// generateID generates a unique ID for audit entries, which loan responses also use as the
+// application ID
 func generateID() string {
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+	return uuid.New().String()
+}
+
+// DeclineForMissingData returns the declined result of an application whose account data was
+// unavailable under MissingDataDecline
+func (s *ScoringService) DeclineForMissingData() *ScoringResult {
+	return &ScoringResult{
+		Score:        s.config.MinScore,
+		Grade:        "F",
+		RiskLevel:    "Very High",
+		Approved:     false,
+		Reason:       "Account data unavailable - application declined",
+		CalculatedAt: time.Now().UTC(),
+	}
+}
+
+// EvaluateConservative scores an application whose account data was unavailable under
+// MissingDataConservative, assuming the worst inputs: a brand-new account with no payments.
+func (s *ScoringService) EvaluateConservative(accountID string, requestedAmount float64) *ScoringResult {
+	result := s.EvaluateScore(accountID, requestedAmount, 0, 0)
+	result.Reason += " (account data unavailable, conservative assumptions applied)"
+	return result
 }
 
 // EvaluateScore performs credit scoring evaluation (stub implementation)
@@ -174,82 +700,59 @@ func (s *ScoringService) EvaluateScore(accountID string, requestedAmount float64
 	now := time.Now().UTC()
 
 	// Simple scoring algorithm (stub - in production, this would integrate with credit bureaus, ML models, etc.)
-	baseScore := 500 // Starting score
+	cfg := s.config
+	baseScore := cfg.BaseScore // Starting score
 
 	// Account age factor (newer accounts = higher risk)
-	if accountAgeDays < 30 {
-		baseScore -= 100
-	} else if accountAgeDays > 365 {
-		baseScore += 50
+	if accountAgeDays < cfg.NewAccountDays {
+		baseScore -= cfg.NewAccountPenalty
+	} else if accountAgeDays > cfg.EstablishedAccountDays {
+		baseScore += cfg.EstablishedAccountBonus
 	}
 
 	// Previous payments factor (more payments = lower risk)
-	if previousPayments > 10 {
-		baseScore += 100
-	} else if previousPayments > 5 {
-		baseScore += 50
+	if previousPayments > cfg.ManyPayments {
+		baseScore += cfg.ManyPaymentsBonus
+	} else if previousPayments > cfg.SomePayments {
+		baseScore += cfg.SomePaymentsBonus
 	} else if previousPayments == 0 {
-		baseScore -= 50
+		baseScore -= cfg.NoPaymentsPenalty
 	}
 
 	// Amount factor (higher amounts = higher risk)
-	if requestedAmount > 10000 {
-		baseScore -= 50
-	} else if requestedAmount < 1000 {
-		baseScore += 25
+	if requestedAmount > cfg.LargeAmount {
+		baseScore -= cfg.LargeAmountPenalty
+	} else if requestedAmount < cfg.SmallAmount {
+		baseScore += cfg.SmallAmountBonus
 	}
 
 	// Ensure score is within bounds
-	if baseScore > 850 {
-		baseScore = 850
-	} else if baseScore < 300 {
-		baseScore = 300
+	if baseScore > cfg.MaxScore {
+		baseScore = cfg.MaxScore
+	} else if baseScore < cfg.MinScore {
+		baseScore = cfg.MinScore
 	}
 
-	// Determine grade and risk level
-	var grade, riskLevel string
-	var approved bool
+	// Determine grade and risk level from the best grade whose threshold the score reaches
+	grade, riskLevel := "F", "Very High"
+	approved := false
 	var maxAmount float64
-	var reason string
-
-	switch {
-	case baseScore >= 750:
-		grade = "A"
-		riskLevel = "Low"
-		approved = true
-		maxAmount = requestedAmount * 1.5
-		reason = "Excellent credit profile"
-	case baseScore >= 650:
-		grade = "B"
-		riskLevel = "Low"
-		approved = true
-		maxAmount = requestedAmount * 1.2
-		reason = "Good credit profile"
-	case baseScore >= 550:
-		grade = "C"
-		riskLevel = "Medium"
-		approved = requestedAmount <= 5000
-		if approved {
-			maxAmount = requestedAmount
-			reason = "Moderate credit profile"
-		} else {
-			reason = "Amount exceeds approved limit for credit score"
+	reason := "Poor credit profile - application declined"
+
+	for _, g := range scoringGrades {
+		if baseScore < cfg.GradeThresholds[g.grade] {
+			continue
 		}
-	case baseScore >= 450:
-		grade = "D"
-		riskLevel = "High"
-		approved = requestedAmount <= 1000
+		grade, riskLevel = g.grade, g.riskLevel
+		limit, capped := cfg.ApprovalCaps[g.grade]
+		approved = !capped || requestedAmount <= limit
 		if approved {
-			maxAmount = requestedAmount * 0.5
-			reason = "Below average credit profile"
+			maxAmount = requestedAmount * cfg.GradeMultipliers[g.grade]
+			reason = g.reason
 		} else {
-			reason = "Insufficient credit score for requested amount"
+			reason = g.exceedsCapReason
 		}
-	default:
-		grade = "F"
-		riskLevel = "Very High"
-		approved = false
-		reason = "Poor credit profile - application declined"
+		break
 	}
 
 	return &ScoringResult{
@@ -265,13 +768,66 @@ func (s *ScoringService) EvaluateScore(accountID string, requestedAmount float64
 
 // LimitCheckResult represents the result of a limit check
 type LimitCheckResult struct {
-	Allowed       bool    `json:"allowed"`
-	Remaining     float64 `json:"remaining"`
-	LimitAmount   float64 `json:"limit_amount"`
-	UsedAmount    float64 `json:"used_amount"`
-	LimitType     string  `json:"limit_type"`
-	AccountID     string  `json:"account_id"`
-	ErrorMessage  string  `json:"error_message,omitempty"`
+	Allowed          bool    `json:"allowed"`
+	Remaining        float64 `json:"remaining"`
+	LimitAmount      float64 `json:"limit_amount"`
+	UsedAmount       float64 `json:"used_amount"`
+	LimitType        string  `json:"limit_type"`
+	AccountID        string  `json:"account_id"`
+	PeriodLabel      string  `json:"period_label"`
+	ErrorMessage     string  `json:"error_message,omitempty"`
+	ErrorCode        string  `json:"error_code,omitempty"`        // Stable reason for a denial, e.g. ErrCodeLimitExceeded
+	ToleranceApplied bool    `json:"tolerance_applied,omitempty"` // Allowed only thanks to the FX conversion tolerance
+}
+
+// Error codes of denied limit check results
+const (
+	ErrCodeLimitExceeded     = "LIMIT_EXCEEDED"
+	ErrCodeTooManyCurrencies = "TOO_MANY_CURRENCIES"
+)
+
+// AggregateLimitResult is the outcome of checking one payment against several limits together.
+// The payment is allowed, and spent from every limit, only if each limit allows it.
+type AggregateLimitResult struct {
+	Allowed    bool                `json:"allowed"`
+	FailedType string              `json:"failed_type,omitempty"` // First limit type, in evaluation order, that denied
+	Results    []*LimitCheckResult `json:"results"`
+}
+
+// Reason codes reported by LimitExplanation
+const (
+	ReasonWithinLimit       = "WITHIN_LIMIT"
+	ReasonWithinTolerance   = "WITHIN_FX_TOLERANCE" // Over the limit, but within the FX tolerance for converted spends
+	ReasonLimitExceeded     = "LIMIT_EXCEEDED"
+	ReasonTooManyCurrencies = "TOO_MANY_CURRENCIES" // Would create a limit beyond the per-account currency cap
+)
+
+// Sources of the limit a LimitExplanation was evaluated against
+const (
+	LimitSourceStored  = "STORED"  // The current period's limit row
+	LimitSourceDefault = "DEFAULT" // No row yet; the configured default that a spend would create
+)
+
+// LimitExplanation breaks down how a limit decision is reached for a spend, without spending it
+type LimitExplanation struct {
+	Allowed           bool      `json:"allowed"`
+	ReasonCode        string    `json:"reason_code"`
+	AccountID         string    `json:"account_id"`
+	LimitType         string    `json:"limit_type"`
+	LimitSource       string    `json:"limit_source"`
+	LimitID           string    `json:"limit_id,omitempty"` // Empty for a default limit
+	PeriodLabel       string    `json:"period_label"`
+	PeriodEnd         time.Time `json:"period_end"`
+	LimitAmount       float64   `json:"limit_amount"`
+	UsedAmount        float64   `json:"used_amount"`
+	Remaining         float64   `json:"remaining"`
+	LimitCurrency     string    `json:"limit_currency"`
+	RequestedAmount   float64   `json:"requested_amount"`
+	RequestedCurrency string    `json:"requested_currency,omitempty"`
+	ConvertedAmount   float64   `json:"converted_amount"`          // The requested amount in the limit's currency
+	ConversionRate    float64   `json:"conversion_rate,omitempty"` // Set only when the amount was converted
+	ToleranceBps      float64   `json:"tolerance_bps"`
+	ToleranceApplied  bool      `json:"tolerance_applied"`
 }
 
 // NewLimitCheckResult creates a new limit check result
@@ -280,13 +836,17 @@ func NewLimitCheckResult(allowed bool, limit *Limit, errorMessage string) *Limit
 		Allowed:     allowed,
 		LimitType:   string(limit.Type),
 		AccountID:   limit.AccountID,
-		LimitAmount: limit.Amount,
+		LimitAmount: limit.EffectiveAmount(),
 		UsedAmount:  limit.Used,
 		Remaining:   limit.GetRemaining(),
+		PeriodLabel: limit.PeriodLabel(),
 	}
 
-	if !allowed && errorMessage != "" {
-		result.ErrorMessage = errorMessage
+	if !allowed {
+		result.ErrorCode = ErrCodeLimitExceeded
+		if errorMessage != "" {
+			result.ErrorMessage = errorMessage
+		}
 	}
 
 	return result
@@ -0,0 +1,246 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestLoanDecisionRecomputeLinksToOriginal(t *testing.T) {
+	original := &LoanDecision{ID: "decision-1", ApplicationID: "app-1", Version: 1, AccountID: "acc-1", RequestedAmount: 5000, Currency: "USD"}
+
+	first := original.Recompute(&ScoringResult{Score: 700}, 800, 60, "underwriter-7")
+	first.ID = "decision-2"
+	if first.OriginalDecisionID != "decision-1" || first.Version != 2 {
+		t.Errorf("first recompute = v%d linked to %q, want v2 linked to decision-1", first.Version, first.OriginalDecisionID)
+	}
+	if first.ApplicationID != "app-1" || first.OverriddenBy != "underwriter-7" || first.AccountAgeDays != 800 || first.PreviousPayments != 60 {
+		t.Errorf("first recompute = %+v", first)
+	}
+
+	second := first.Recompute(&ScoringResult{Score: 720}, 800, 61, "underwriter-8")
+	if second.OriginalDecisionID != "decision-1" || second.Version != 3 {
+		t.Errorf("second recompute = v%d linked to %q, want v3 linked to decision-1", second.Version, second.OriginalDecisionID)
+	}
+}
+
+func TestCanSpendWithTolerance(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    float64
+		tolerance float64
+		want      bool
+	}{
+		{"within the limit", 1000, 0, true},
+		{"just over without tolerance", 1008, 0, false},
+		{"just over within tolerance", 1008, 100, true},
+		{"at the tolerance", 1010, 100, true},
+		{"beyond tolerance", 1020, 100, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit := &Limit{Amount: 1000, Currency: "USD"}
+			if got := limit.CanSpendWithTolerance(tt.amount, tt.tolerance); got != tt.want {
+				t.Errorf("CanSpendWithTolerance(%.2f, %.0f bps) = %v, want %v", tt.amount, tt.tolerance, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriodLabel(t *testing.T) {
+	start := time.Date(2024, time.December, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		limitType   LimitType
+		periodStart time.Time
+		want        string
+	}{
+		{DailyLimit, start, "2024-12-15 (Daily)"},
+		{MonthlyLimit, time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC), "December 2024 (Monthly)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.limitType), func(t *testing.T) {
+			limit := &Limit{Type: tt.limitType, PeriodStart: tt.periodStart}
+			if got := limit.PeriodLabel(); got != tt.want {
+				t.Errorf("PeriodLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitCheckResultPeriodLabel(t *testing.T) {
+	limit := &Limit{
+		AccountID:   "acc-1",
+		Type:        MonthlyLimit,
+		Amount:      5000,
+		Currency:    "USD",
+		PeriodStart: time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	if got := NewLimitCheckResult(true, limit, "").PeriodLabel; got != "December 2024 (Monthly)" {
+		t.Errorf("PeriodLabel = %q, want %q", got, "December 2024 (Monthly)")
+	}
+}
+
+func TestResolveEffectiveLimit(t *testing.T) {
+	future := time.Now().UTC().Add(time.Hour)
+	past := time.Now().UTC().Add(-time.Hour)
+	increase := 2500.0
+	periodStart := time.Date(2024, time.December, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		current       *Limit
+		wantAmount    float64
+		wantRemaining float64
+		wantCurrency  string
+		wantApplied   map[string]bool
+	}{
+		{
+			name:          "base default only",
+			wantAmount:    1000,
+			wantRemaining: 1000,
+			wantCurrency:  "USD",
+			wantApplied:   map[string]bool{RuleBaseDefault: true},
+		},
+		{
+			name:          "period limit matching the default",
+			current:       &Limit{Amount: 1000, Used: 200, Currency: "USD"},
+			wantAmount:    1000,
+			wantRemaining: 800,
+			wantCurrency:  "USD",
+			wantApplied:   map[string]bool{RuleBaseDefault: true, RulePeriodLimit: false},
+		},
+		{
+			name:          "period limit overriding the default",
+			current:       &Limit{Amount: 1500, Used: 200, Currency: "EUR"},
+			wantAmount:    1500,
+			wantRemaining: 1300,
+			wantCurrency:  "EUR",
+			wantApplied:   map[string]bool{RuleBaseDefault: true, RulePeriodLimit: true},
+		},
+		{
+			name:          "active temporary increase",
+			current:       &Limit{Amount: 1000, Used: 1200, Currency: "USD", OverrideAmount: &increase, OverrideExpiresAt: &future},
+			wantAmount:    2500,
+			wantRemaining: 1300,
+			wantCurrency:  "USD",
+			wantApplied:   map[string]bool{RuleBaseDefault: true, RulePeriodLimit: false, RuleTemporaryIncrease: true},
+		},
+		{
+			name:          "expired temporary increase",
+			current:       &Limit{Amount: 1500, Used: 1200, Currency: "USD", OverrideAmount: &increase, OverrideExpiresAt: &past},
+			wantAmount:    1500,
+			wantRemaining: 300,
+			wantCurrency:  "USD",
+			wantApplied:   map[string]bool{RuleBaseDefault: true, RulePeriodLimit: true, RuleTemporaryIncrease: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.current != nil {
+				tt.current.Type = DailyLimit
+				tt.current.PeriodStart = periodStart
+			}
+			effective := ResolveEffectiveLimit("acc-1", DailyLimit, 1000, "USD", tt.current)
+
+			if effective.Amount != tt.wantAmount || effective.Remaining != tt.wantRemaining || effective.Currency != tt.wantCurrency {
+				t.Errorf("effective = %.2f %s (%.2f remaining), want %.2f %s (%.2f remaining)",
+					effective.Amount, effective.Currency, effective.Remaining, tt.wantAmount, tt.wantCurrency, tt.wantRemaining)
+			}
+
+			applied := make(map[string]bool)
+			for _, rule := range effective.Rules {
+				applied[rule.Rule] = rule.Applied
+			}
+			if len(applied) != len(tt.wantApplied) {
+				t.Errorf("rules = %+v, want %v", effective.Rules, tt.wantApplied)
+			}
+			for rule, want := range tt.wantApplied {
+				if got, ok := applied[rule]; !ok || got != want {
+					t.Errorf("rule %s applied = %v (present %v), want %v", rule, got, ok, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateScoreGradeMultipliers(t *testing.T) {
+	tests := []struct {
+		name        string
+		multipliers map[string]float64
+		baseScore   int
+		wantGrade   string
+		wantMax     float64
+	}{
+		{"default grade A multiplier", nil, 700, "A", 750},
+		{"overridden grade A multiplier", map[string]float64{"A": 2}, 700, "A", 1000},
+		{"other grades keep their default", map[string]float64{"A": 2}, 500, "B", 600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultScoringConfig()
+			config.BaseScore = tt.baseScore
+			config.GradeMultipliers = tt.multipliers
+
+			s := NewScoringService()
+			s.SetConfig(config)
+
+			// An established account with many payments, requesting a small amount
+			result := s.EvaluateScore("acc-1", 500, 400, 20)
+			if result.Grade != tt.wantGrade || result.MaxAmount != tt.wantMax {
+				t.Errorf("EvaluateScore = grade %s max %.2f, want grade %s max %.2f", result.Grade, result.MaxAmount, tt.wantGrade, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestBuildDailyTrendFillsGaps(t *testing.T) {
+	from := time.Date(2024, time.December, 10, 15, 30, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2024, time.December, d, 0, 0, 0, 0, time.UTC) }
+	summaries := []*LimitSummary{
+		{Type: DailyLimit, Amount: 1000, Used: 250, PeriodStart: day(11)},
+		{Type: DailyLimit, Amount: 1000, Used: 1000, PeriodStart: day(13)},
+		{Type: DailyLimit, Amount: 2000, Used: 500, PeriodStart: day(20)}, // Outside the series
+	}
+
+	points := BuildDailyTrend(summaries, from, 5)
+
+	want := []UsagePoint{
+		{Date: "2024-12-10"},
+		{Date: "2024-12-11", Used: 250, Amount: 1000, Utilization: 0.25},
+		{Date: "2024-12-12"},
+		{Date: "2024-12-13", Used: 1000, Amount: 1000, Utilization: 1},
+		{Date: "2024-12-14"},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d: %+v", len(points), len(want), points)
+	}
+	for i := range want {
+		if points[i] != want[i] {
+			t.Errorf("point %d = %+v, want %+v", i, points[i], want[i])
+		}
+	}
+}
+
+func TestLogActionIDsAreUniqueUUIDs(t *testing.T) {
+	audit := NewAuditService()
+
+	// Entries created back to back, well within one tick of a coarse clock, still get distinct IDs
+	const entries = 10000
+	seen := make(map[string]bool, entries)
+	for i := 0; i < entries; i++ {
+		id := audit.LogAction("LoanApplication", "acc-1", "", "APPLY", "loan", "", "", "", "INFO").ID
+		if _, err := uuid.Parse(id); err != nil {
+			t.Fatalf("audit ID %q is not a UUID: %v", id, err)
+		}
+		if seen[id] {
+			t.Fatalf("audit ID %s generated twice", id)
+		}
+		seen[id] = true
+	}
+}
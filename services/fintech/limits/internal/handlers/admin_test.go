@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// restoreLogLevel puts the global log level back after the test
+func restoreLogLevel(t *testing.T) {
+	t.Helper()
+
+	level := logrus.GetLevel()
+	t.Cleanup(func() { logrus.SetLevel(level) })
+}
+
+func getLogLevel(t *testing.T) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	GetLogLevel(rec, httptest.NewRequest(http.MethodGet, "/admin/loglevel", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var resp LogLevelResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return resp.Level
+}
+
+func TestSetLogLevel(t *testing.T) {
+	restoreLogLevel(t)
+	logrus.SetLevel(logrus.InfoLevel)
+
+	rec := httptest.NewRecorder()
+	SetLogLevel(rec, httptest.NewRequest(http.MethodPut, "/admin/loglevel", strings.NewReader(`{"level": "debug"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if logrus.GetLevel() != logrus.DebugLevel {
+		t.Errorf("log level = %s, want debug", logrus.GetLevel())
+	}
+	if got := getLogLevel(t); got != "debug" {
+		t.Errorf("GET level = %q, want %q", got, "debug")
+	}
+}
+
+func TestSetLogLevelRejectsInvalidRequests(t *testing.T) {
+	restoreLogLevel(t)
+	logrus.SetLevel(logrus.InfoLevel)
+
+	for _, body := range []string{`{"level": "verbose"}`, `{"level": ""}`, "not json"} {
+		rec := httptest.NewRecorder()
+		SetLogLevel(rec, httptest.NewRequest(http.MethodPut, "/admin/loglevel", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+	if got := getLogLevel(t); got != "info" {
+		t.Errorf("GET level = %q, want the unchanged %q", got, "info")
+	}
+}
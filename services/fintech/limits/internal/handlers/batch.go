@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/internal/infrastructure"
+	"fintech/limits-service/pkg/otel"
+	"fintech/limits-service/pkg/respond"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxBatchItems caps the number of evaluations accepted in one batch request
+const maxBatchItems = 100
+
+// Batch item statuses
+const (
+	BatchItemAllowed    = "allowed"
+	BatchItemDenied     = "denied"
+	BatchItemError      = "error"
+	BatchItemRolledBack = "rolled_back" // Transactional batch aborted; this item was not applied
+)
+
+// BatchEvaluateRequest represents a request to evaluate several limit spends at once
+type BatchEvaluateRequest struct {
+	Items []EvaluateLimitRequest `json:"items"`
+	// Transactional applies every item or none: a denied or errored item aborts the batch
+	Transactional bool `json:"transactional,omitempty"`
+}
+
+// BatchItemResult is the outcome of one item in a batch evaluation
+type BatchItemResult struct {
+	Index  int                      `json:"index"`
+	Status string                   `json:"status"`
+	Result *domain.LimitCheckResult `json:"result,omitempty"`
+	Error  string                   `json:"error,omitempty"`
+}
+
+// BatchSummary counts batch item outcomes
+type BatchSummary struct {
+	Total      int  `json:"total"`
+	Allowed    int  `json:"allowed"`
+	Denied     int  `json:"denied"`
+	Errored    int  `json:"errored"`
+	RolledBack bool `json:"rolledBack,omitempty"`
+}
+
+// BatchEvaluateResponse represents the response for a batch evaluation
+type BatchEvaluateResponse struct {
+	Items   []BatchItemResult `json:"items"`
+	Summary BatchSummary      `json:"summary"`
+}
+
+// EvaluateLimitBatch handles POST /limits/evaluate/batch. Items are evaluated in order and reported
+// individually with a 207 Multi-Status response; an errored item does not stop the remaining items
+// unless the batch is transactional.
+func (h *LimitsHandler) EvaluateLimitBatch(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "EvaluateLimitBatch")
+	defer span.End()
+
+	req, err := decodeBatchRequest(r)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to decode batch request")
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	for i := range req.Items {
+		req.Items[i].Currency = normalizeCurrency(req.Items[i].Currency)
+	}
+
+	if len(req.Items) == 0 || len(req.Items) > maxBatchItems {
+		var errs fieldErrors
+		errs.add("items", fmt.Sprintf("must contain between 1 and %d items", maxBatchItems))
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("items", len(req.Items)),
+		otel.Attribute("transactional", req.Transactional),
+	)
+
+	var items []BatchItemResult
+	if req.Transactional {
+		items = h.evaluateTransactional(ctx, req.Items)
+	} else {
+		items = h.evaluateEach(ctx, req.Items)
+	}
+
+	response := BatchEvaluateResponse{Items: items, Summary: BatchSummary{Total: len(items)}}
+	for _, item := range items {
+		switch item.Status {
+		case BatchItemAllowed:
+			response.Summary.Allowed++
+		case BatchItemDenied:
+			response.Summary.Denied++
+		case BatchItemError:
+			response.Summary.Errored++
+		case BatchItemRolledBack:
+			response.Summary.RolledBack = true
+		}
+	}
+
+	if err := respond.JSON(ctx, w, http.StatusMultiStatus, response); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// evaluateEach spends each item independently. Items that pass validation and the per-transaction
+// maximum are spent together by CheckAndSpendBatch, which resolves each limit once and reports an
+// item that fails without undoing the others.
+func (h *LimitsHandler) evaluateEach(ctx context.Context, items []EvaluateLimitRequest) []BatchItemResult {
+	results := make([]BatchItemResult, len(items))
+
+	checkCtx, cancel := context.WithTimeout(ctx, h.config.LimitCheckTimeout)
+	defer cancel()
+
+	requests := make([]infrastructure.SpendRequest, 0, len(items))
+	indexes := make([]int, 0, len(items)) // Item index of each request
+	for i, item := range items {
+		results[i] = BatchItemResult{Index: i}
+
+		limitType, err := h.parseEvaluateItem(item)
+		if err != nil {
+			results[i].Status, results[i].Error = BatchItemError, err.Error()
+			continue
+		}
+
+		denied, err := h.checkPerTransactionLimit(checkCtx, item.AccountID, item.Amount, item.Currency)
+		if err != nil {
+			logrus.WithError(err).WithField("account", item.AccountID).Error("Failed to check per-transaction maximum in batch")
+			results[i].Status, results[i].Error = BatchItemError, "Internal server error"
+			continue
+		}
+		if denied != nil {
+			results[i].Status, results[i].Result = BatchItemDenied, denied
+			continue
+		}
+
+		requests = append(requests, infrastructure.SpendRequest{
+			AccountID:    item.AccountID,
+			Type:         limitType,
+			Amount:       item.Amount,
+			DefaultLimit: h.getDefaultLimit(limitType),
+			Currency:     item.Currency,
+		})
+		indexes = append(indexes, i)
+	}
+	if len(requests) == 0 {
+		return results
+	}
+
+	spent, errs := h.repo.CheckAndSpendBatch(checkCtx, requests)
+	for j, i := range indexes {
+		if errs[j] != nil {
+			logrus.WithError(errs[j]).WithField("account", items[i].AccountID).Error("Failed to check limit in batch")
+			results[i].Status, results[i].Error = BatchItemError, "Internal server error"
+			continue
+		}
+
+		h.auditSpend("LimitEvaluation", items[i].Amount, items[i].Currency, spent[j])
+		results[i].Result = spent[j]
+		if spent[j].Allowed {
+			results[i].Status = BatchItemAllowed
+		} else {
+			results[i].Status = BatchItemDenied
+		}
+	}
+	return results
+}
+
+// evaluateTransactional spends every item or none. The item that aborted the batch is reported as
+// denied or errored and every other item as rolled back.
+func (h *LimitsHandler) evaluateTransactional(ctx context.Context, items []EvaluateLimitRequest) []BatchItemResult {
+	results := make([]BatchItemResult, len(items))
+	for i := range results {
+		results[i] = BatchItemResult{Index: i, Status: BatchItemRolledBack}
+	}
+
+	// Reject the whole batch up front if any item is malformed
+	requests := make([]infrastructure.SpendRequest, len(items))
+	for i, item := range items {
+		limitType, err := h.parseEvaluateItem(item)
+		if err != nil {
+			results[i].Status, results[i].Error = BatchItemError, err.Error()
+			return results
+		}
+		requests[i] = infrastructure.SpendRequest{
+			AccountID:    item.AccountID,
+			Type:         limitType,
+			Amount:       item.Amount,
+			DefaultLimit: h.getDefaultLimit(limitType),
+			Currency:     item.Currency,
+		}
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, h.config.LimitCheckTimeout)
+	defer cancel()
+
+	// An item over its per-transaction maximum aborts the batch before anything is spent
+	for i, item := range items {
+		denied, err := h.checkPerTransactionLimit(checkCtx, item.AccountID, item.Amount, item.Currency)
+		if err != nil {
+			logrus.WithError(err).WithField("account", item.AccountID).Error("Failed to check per-transaction maximum in batch")
+			results[i].Status, results[i].Error = BatchItemError, "Internal server error"
+			return results
+		}
+		if denied != nil {
+			results[i].Status, results[i].Result = BatchItemDenied, denied
+			return results
+		}
+	}
+
+	spent, err := h.repo.CheckAndSpendAll(checkCtx, requests)
+	if err != nil {
+		failed := len(spent)
+		if failed >= len(results) {
+			failed = len(results) - 1 // Commit failed after every item was applied
+		}
+		logrus.WithError(err).WithField("account", items[failed].AccountID).Error("Failed to apply transactional batch")
+		results[failed].Status, results[failed].Error = BatchItemError, "Internal server error"
+		return results
+	}
+
+	if last := spent[len(spent)-1]; !last.Allowed {
+		results[len(spent)-1].Status = BatchItemDenied
+		results[len(spent)-1].Result = last
+		return results
+	}
+
+	for i, result := range spent {
+		h.auditSpend("LimitEvaluation", items[i].Amount, items[i].Currency, result)
+		results[i].Status = BatchItemAllowed
+		results[i].Result = result
+	}
+	return results
+}
+
+// decodeBatchRequest decodes a batch request body, which is either a BatchEvaluateRequest or a bare
+// array of items evaluated independently
+func decodeBatchRequest(r *http.Request) (BatchEvaluateRequest, error) {
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return BatchEvaluateRequest{}, err
+	}
+
+	var req BatchEvaluateRequest
+	if trimmed := bytes.TrimLeft(body, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '[' {
+		return req, json.Unmarshal(body, &req.Items)
+	}
+	return req, json.Unmarshal(body, &req)
+}
+
+// parseEvaluateItem validates a batch item and returns its limit type, which must not be disabled
+func (h *LimitsHandler) parseEvaluateItem(item EvaluateLimitRequest) (domain.LimitType, error) {
+	if errs := item.validate(); len(errs) > 0 {
+		return "", errors.New(errs.String())
+	}
+
+	limitType := domain.LimitType(item.LimitType)
+	if h.runtime.LimitTypeDisabled(limitType) {
+		return "", fmt.Errorf("Limit type %s is disabled", limitType)
+	}
+	return limitType, nil
+}
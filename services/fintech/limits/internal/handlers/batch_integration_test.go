@@ -0,0 +1,91 @@
+//go:build integration
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func evaluateBatch(t *testing.T, h *LimitsHandler, body string) BatchEvaluateResponse {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.EvaluateLimitBatch(rec, httptest.NewRequest(http.MethodPost, "/limits/evaluate/batch", strings.NewReader(body)))
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("status = %d, want 207: %s", rec.Code, rec.Body)
+	}
+
+	var response BatchEvaluateResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode batch response: %v", err)
+	}
+	return response
+}
+
+func batchStatuses(response BatchEvaluateResponse) []string {
+	statuses := make([]string, len(response.Items))
+	for i, item := range response.Items {
+		statuses[i] = item.Status
+	}
+	return statuses
+}
+
+func TestEvaluateLimitBatchMixedItems(t *testing.T) {
+	h, _, db := newTestHandler(t, nil)
+	first, second := newID("acc"), newID("acc")
+
+	response := evaluateBatch(t, h, `{"items": [
+		{"accountId": "`+first+`", "limitType": "DAILY", "amount": 100, "currency": "USD"},
+		{"accountId": "`+first+`", "limitType": "DAILY", "amount": 20000, "currency": "USD"},
+		{"accountId": "`+second+`", "limitType": "WEEKLY", "amount": 100, "currency": "USD"},
+		{"accountId": "`+second+`", "limitType": "DAILY", "amount": 250, "currency": "USD"}
+	]}`)
+
+	want := []string{BatchItemAllowed, BatchItemDenied, BatchItemError, BatchItemAllowed}
+	if got := batchStatuses(response); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if s := response.Summary; s.Total != 4 || s.Allowed != 2 || s.Denied != 1 || s.Errored != 1 || s.RolledBack {
+		t.Errorf("summary = %+v, want 4 total, 2 allowed, 1 denied, 1 errored", s)
+	}
+	if response.Items[2].Error == "" {
+		t.Error("errored item has no error message")
+	}
+
+	// The failed items don't undo the allowed ones
+	if used := limitUsed(t, db, first, "DAILY"); used != 100 {
+		t.Errorf("first account used = %.2f, want 100", used)
+	}
+	if used := limitUsed(t, db, second, "DAILY"); used != 250 {
+		t.Errorf("second account used = %.2f, want 250", used)
+	}
+}
+
+func TestEvaluateLimitBatchTransactionalRollsBack(t *testing.T) {
+	h, _, db := newTestHandler(t, nil)
+	accountID := newID("acc")
+
+	// Create the limit so its usage can be read back
+	evaluateBatch(t, h, `[{"accountId": "`+accountID+`", "limitType": "DAILY", "amount": 10, "currency": "USD"}]`)
+
+	response := evaluateBatch(t, h, `{"transactional": true, "items": [
+		{"accountId": "`+accountID+`", "limitType": "DAILY", "amount": 100, "currency": "USD"},
+		{"accountId": "`+accountID+`", "limitType": "DAILY", "amount": 20000, "currency": "USD"},
+		{"accountId": "`+accountID+`", "limitType": "DAILY", "amount": 50, "currency": "USD"}
+	]}`)
+
+	want := []string{BatchItemRolledBack, BatchItemDenied, BatchItemRolledBack}
+	if got := batchStatuses(response); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if !response.Summary.RolledBack {
+		t.Errorf("summary = %+v, want rolled back", response.Summary)
+	}
+	if used := limitUsed(t, db, accountID, "DAILY"); used != 10 {
+		t.Errorf("used = %.2f after a rolled back batch, want 10", used)
+	}
+}
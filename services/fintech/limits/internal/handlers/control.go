@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/kafka"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrReadOnly is returned for payment events received while the service is in read-only mode
+var ErrReadOnly = errors.New("limits service is in read-only mode")
+
+// RuntimeState is behaviour toggled at runtime by control commands, on every instance at once
+type RuntimeState struct {
+	mu            sync.RWMutex
+	readOnly      bool
+	disabledTypes map[domain.LimitType]bool
+}
+
+// NewRuntimeState creates a runtime state with writes allowed and every limit type enabled
+func NewRuntimeState() *RuntimeState {
+	return &RuntimeState{disabledTypes: make(map[domain.LimitType]bool)}
+}
+
+// ReadOnly reports whether spends and other writes are currently rejected
+func (s *RuntimeState) ReadOnly() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.readOnly
+}
+
+// LimitTypeDisabled reports whether limitType is currently not enforced
+func (s *RuntimeState) LimitTypeDisabled(limitType domain.LimitType) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.disabledTypes[limitType]
+}
+
+// HandleControlCommand applies a command from the control topic. Unknown commands and commands with
+// invalid arguments are logged and ignored rather than failed, since no retry would fix them.
+func (h *LimitsHandler) HandleControlCommand(command *kafka.ControlCommand) error {
+	state := h.runtime
+	logger := logrus.WithField("command", command.Command)
+
+	switch command.Command {
+	case kafka.ControlSetReadOnly:
+		if command.Enabled == nil {
+			logger.Warn("Ignoring control command without enabled")
+			return nil
+		}
+		state.mu.Lock()
+		changed := state.readOnly != *command.Enabled
+		state.readOnly = *command.Enabled
+		state.mu.Unlock()
+		if changed {
+			logger.WithField("read_only", *command.Enabled).Warn("Read-only mode changed")
+		}
+
+	case kafka.ControlSetLimitType:
+		limitType := domain.LimitType(strings.ToUpper(command.LimitType))
+		if command.Enabled == nil || (limitType != domain.DailyLimit && limitType != domain.MonthlyLimit) {
+			logger.WithField("limit_type", command.LimitType).Warn("Ignoring control command without a valid limit type and enabled")
+			return nil
+		}
+		state.mu.Lock()
+		changed := state.disabledTypes[limitType] == *command.Enabled
+		state.disabledTypes[limitType] = !*command.Enabled
+		state.mu.Unlock()
+		if changed {
+			logger.WithFields(logrus.Fields{
+				"limit_type": limitType,
+				"enabled":    *command.Enabled,
+			}).Warn("Limit type enforcement changed")
+		}
+
+	case kafka.ControlSetLogLevel:
+		level, err := logrus.ParseLevel(command.Level)
+		if err != nil {
+			logger.WithField("level", command.Level).Warn("Ignoring control command with an invalid log level")
+			return nil
+		}
+		if previous := logrus.GetLevel(); previous != level {
+			logrus.SetLevel(level)
+			logger.WithFields(logrus.Fields{
+				"previous": previous.String(),
+				"level":    level.String(),
+			}).Warn("Log level changed")
+		}
+
+	default:
+		logger.Warn("Ignoring unknown control command")
+	}
+
+	return nil
+}
+
+// RejectWritesWhenReadOnly is middleware answering 503 to every request that could write while the
+// service is in read-only mode. Reads and /admin endpoints are always served.
+func (h *LimitsHandler) RejectWritesWhenReadOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if h.runtime.ReadOnly() && !strings.HasPrefix(r.URL.Path, "/admin/") {
+				writeError(w, http.StatusServiceUnavailable, ErrCodeReadOnly, "Service is in read-only mode")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
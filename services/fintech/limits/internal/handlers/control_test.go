@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/kafka"
+
+	"github.com/sirupsen/logrus"
+)
+
+// applyControl decodes a control message as the control consumer does and applies it
+func applyControl(t *testing.T, h *LimitsHandler, message string) {
+	t.Helper()
+
+	var command kafka.ControlCommand
+	if err := json.Unmarshal([]byte(message), &command); err != nil {
+		t.Fatalf("invalid control message %s: %v", message, err)
+	}
+	if err := h.HandleControlCommand(&command); err != nil {
+		t.Fatalf("HandleControlCommand(%s): %v", message, err)
+	}
+}
+
+func TestHandleControlCommandSetsReadOnly(t *testing.T) {
+	h := &LimitsHandler{runtime: NewRuntimeState()}
+
+	// Commands set state rather than toggle it, so a redelivered command changes nothing
+	for i := 0; i < 2; i++ {
+		applyControl(t, h, `{"command": "set_read_only", "enabled": true}`)
+		if !h.runtime.ReadOnly() {
+			t.Fatalf("read-only = false after set_read_only enabled (delivery %d)", i+1)
+		}
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodPost, "/limits/check", http.StatusServiceUnavailable},
+		{http.MethodGet, "/limits/acc-1", http.StatusOK},
+		{http.MethodPost, "/admin/log-level", http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.RejectWritesWhenReadOnly(next).ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s %s in read-only mode = %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+		}
+	}
+
+	applyControl(t, h, `{"command": "set_read_only", "enabled": false}`)
+	if h.runtime.ReadOnly() {
+		t.Error("read-only = true after set_read_only disabled")
+	}
+}
+
+func TestHandleControlCommandSetsLimitType(t *testing.T) {
+	h := &LimitsHandler{runtime: NewRuntimeState()}
+
+	applyControl(t, h, `{"command": "set_limit_type", "limitType": "monthly", "enabled": false}`)
+	if !h.runtime.LimitTypeDisabled(domain.MonthlyLimit) || h.runtime.LimitTypeDisabled(domain.DailyLimit) {
+		t.Fatal("want only MONTHLY disabled")
+	}
+
+	applyControl(t, h, `{"command": "set_limit_type", "limitType": "MONTHLY", "enabled": true}`)
+	if h.runtime.LimitTypeDisabled(domain.MonthlyLimit) {
+		t.Error("MONTHLY still disabled after set_limit_type enabled")
+	}
+}
+
+func TestHandleControlCommandIgnoresInvalidCommands(t *testing.T) {
+	restoreLogLevel(t)
+	logrus.SetLevel(logrus.InfoLevel)
+	h := &LimitsHandler{runtime: NewRuntimeState()}
+
+	for _, message := range []string{
+		`{"command": "drop_all_limits"}`,
+		`{"command": "set_read_only"}`,
+		`{"command": "set_limit_type", "limitType": "WEEKLY", "enabled": false}`,
+		`{"command": "set_log_level", "level": "loud"}`,
+	} {
+		applyControl(t, h, message)
+	}
+
+	if h.runtime.ReadOnly() || h.runtime.LimitTypeDisabled(domain.DailyLimit) || h.runtime.LimitTypeDisabled(domain.MonthlyLimit) {
+		t.Error("an ignored command changed the runtime state")
+	}
+	if level := logrus.GetLevel(); level != logrus.InfoLevel {
+		t.Errorf("log level = %s, want info unchanged", level)
+	}
+
+	applyControl(t, h, `{"command": "set_log_level", "level": "debug"}`)
+	if level := logrus.GetLevel(); level != logrus.DebugLevel {
+		t.Errorf("log level = %s after set_log_level debug", level)
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/kafka"
+
+	"github.com/sirupsen/logrus"
+)
+
+// publishDecision publishes the outcome of a payment event's limit check, keyed by account ID, when a
+// publisher is set. deniedBy is the limit type that denied the payment, empty if it was allowed, and
+// velocity reports a denial by transaction count. Nil results, for disabled limit types, are left out.
+// Failures are logged rather than returned: the limits are already spent, and a redelivered event
+// would spend them again.
+func (h *LimitsHandler) publishDecision(ctx context.Context, event *kafka.PaymentInitiatedEvent, deniedBy string, velocity bool, results ...*domain.LimitCheckResult) {
+	if h.publisher == nil {
+		return
+	}
+
+	decision := kafka.LimitCheckCompletedEvent{
+		EventType: kafka.LimitCheckCompleted,
+		PaymentID: event.PaymentID,
+		AccountID: event.FromAccountID,
+		Amount:    event.Amount,
+		Currency:  event.Currency,
+		Allowed:   deniedBy == "",
+		DeniedBy:  deniedBy,
+		Velocity:  velocity,
+		Results:   make([]kafka.LimitDecision, 0, len(results)),
+		CheckedAt: time.Now().UTC(),
+	}
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		decision.Results = append(decision.Results, kafka.LimitDecision{
+			LimitType:   result.LimitType,
+			Allowed:     result.Allowed,
+			Remaining:   result.Remaining,
+			LimitAmount: result.LimitAmount,
+			UsedAmount:  result.UsedAmount,
+			PeriodLabel: result.PeriodLabel,
+		})
+	}
+
+	value, err := json.Marshal(decision)
+	if err != nil {
+		logrus.WithError(err).WithField("payment_id", event.PaymentID).Error("Failed to encode limit check result")
+		return
+	}
+	if err := h.publisher.Publish(ctx, h.config.KafkaDecisionsTopic, event.FromAccountID, value); err != nil {
+		logrus.WithError(err).WithField("payment_id", event.PaymentID).Error("Failed to publish limit check result")
+	}
+}
+
+// deniedBy returns the limit type of the first result that denied the spend, or "" if none did
+func deniedBy(results ...*domain.LimitCheckResult) string {
+	for _, result := range results {
+		if !spendAllowed(result) {
+			return result.LimitType
+		}
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/otel"
+	"fintech/limits-service/pkg/respond"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// GetEffectiveLimit handles GET /limits/{accountId}/effective?type=DAILY, returning the limit that
+// currently applies and a breakdown of the rules that produced it
+func (h *LimitsHandler) GetEffectiveLimit(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "GetEffectiveLimit")
+	defer span.End()
+
+	accountID := mux.Vars(r)["accountId"]
+	limitType := domain.LimitType(r.URL.Query().Get("type"))
+	var errs fieldErrors
+	if errs.limitType("type", string(limitType), domain.DailyLimit, domain.MonthlyLimit); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("account_id", accountID),
+		otel.Attribute("limit_type", string(limitType)),
+	)
+
+	current, err := h.repo.GetCurrentLimit(ctx, accountID, limitType)
+	if err != nil {
+		logrus.WithError(err).WithField("account_id", accountID).Error("Failed to load current limit")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	effective := domain.ResolveEffectiveLimit(accountID, limitType, h.getDefaultLimit(limitType), h.config.FXBaseCurrency, current)
+	if err := respond.JSON(ctx, w, http.StatusOK, effective); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/middleware"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Error codes returned in ErrorResponse. Codes are stable for clients to match on; messages are
+// for humans and may change.
+const (
+	ErrCodeInvalidLimitType  = "INVALID_LIMIT_TYPE"  // Limit type isn't one the endpoint accepts
+	ErrCodeAmountNotPositive = "AMOUNT_NOT_POSITIVE" // An amount is zero or negative
+	ErrCodeLimitExceeded     = domain.ErrCodeLimitExceeded
+	ErrCodeInternal          = "INTERNAL" // Unexpected failure; the cause is logged, not returned
+
+	// Failures the codes above don't describe
+	ErrCodeInvalidRequest    = "INVALID_REQUEST"     // Malformed body, or a missing or invalid parameter
+	ErrCodeInvalidCurrency   = "INVALID_CURRENCY"    // Currency isn't a 3-letter ISO code
+	ErrCodeValidationFailed  = "VALIDATION_FAILED"   // Details list each invalid field
+	ErrCodeLimitTypeDisabled = "LIMIT_TYPE_DISABLED" // Disabled by a control command
+	ErrCodeNotFound          = "NOT_FOUND"           // No such limit, hold or loan application
+	ErrCodeReadOnly          = "READ_ONLY"           // Writes are paused by a control command
+	ErrCodeUnavailable       = "UNAVAILABLE"         // A dependency, e.g. FX rates, can't be reached
+)
+
+// ErrorResponse is the JSON body of every error response
+type ErrorResponse struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"requestId,omitempty"`
+}
+
+// writeError writes an ErrorResponse with the given status. The request ID is the one the RequestID
+// middleware set on the response, if any.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeErrorDetails(w, status, code, message, nil)
+}
+
+// writeErrorDetails writes an ErrorResponse carrying details, e.g. the fields that failed validation
+func writeErrorDetails(w http.ResponseWriter, status int, code, message string, details interface{}) {
+	response := ErrorResponse{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: w.Header().Get(middleware.RequestIDHeader),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logrus.WithError(err).Error("Failed to encode error response")
+	}
+}
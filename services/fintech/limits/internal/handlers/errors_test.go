@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// validationResponse is an ErrorResponse with its details decoded as field errors
+type validationResponse struct {
+	Code    string       `json:"code"`
+	Details []FieldError `json:"details"`
+}
+
+// requestValidation calls handler with body and accountId as the path's account, decoding the
+// validation errors it answers with
+func requestValidation(t *testing.T, handler http.HandlerFunc, target, body string) (int, validationResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"accountId": "acc-1"})
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	var response validationResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode error: %v", err)
+	}
+	return rec.Code, response
+}
+
+func TestNonPositiveAmountsReturnAmountNotPositive(t *testing.T) {
+	h := &LimitsHandler{runtime: NewRuntimeState()}
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		body    string
+		field   string
+	}{
+		{"release", h.ReleaseLimit, `{"accountId": "acc-1", "limitType": "DAILY", "amount": 0}`, "amount"},
+		{"hold", h.CreateHold, `{"accountId": "acc-1", "limitType": "DAILY", "amount": -5}`, "amount"},
+		{"override", h.OverrideLimit, `{"accountId": "acc-1", "agentId": "agent-1", "limitType": "DAILY", "amount": 0, "expiresAt": "2030-01-01T00:00:00Z"}`, "amount"},
+		{"evaluate all", h.EvaluateAllLimits, `{"accountId": "acc-1", "amount": -1}`, "amount"},
+		{"transaction max", h.SetTransactionLimit, `{"agentId": "agent-1", "maxAmount": 0}`, "maxAmount"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, response := requestValidation(t, tt.handler, "/", tt.body)
+			if status != http.StatusUnprocessableEntity || response.Code != ErrCodeValidationFailed {
+				t.Fatalf("status %d with code %s, want %d with %s", status, response.Code, http.StatusUnprocessableEntity, ErrCodeValidationFailed)
+			}
+			want := []FieldError{{Field: tt.field, Code: ErrCodeAmountNotPositive, Message: "must be positive"}}
+			if len(response.Details) != 1 || response.Details[0] != want[0] {
+				t.Errorf("details = %+v, want %+v", response.Details, want)
+			}
+		})
+	}
+}
+
+func TestValidationListsEveryInvalidField(t *testing.T) {
+	h := &LimitsHandler{runtime: NewRuntimeState()}
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		target  string
+		body    string
+		want    map[string]string
+	}{
+		{"hold", h.CreateHold, "/", `{"limitType": "WEEKLY", "amount": 1.005, "currency": "usdx", "ttlSeconds": -1}`, map[string]string{
+			"accountId": ErrCodeInvalidRequest, "limitType": ErrCodeInvalidLimitType, "amount": ErrCodeInvalidRequest,
+			"currency": ErrCodeInvalidCurrency, "ttlSeconds": ErrCodeInvalidRequest,
+		}},
+		{"override", h.OverrideLimit, "/", `{"limitType": "DAILY", "amount": 10}`, map[string]string{
+			"accountId": ErrCodeInvalidRequest, "agentId": ErrCodeInvalidRequest, "expiresAt": ErrCodeInvalidRequest,
+		}},
+		{"recompute", h.RecomputeLoan, "/", `{"accountAgeDays": -1, "previousPayments": -2}`, map[string]string{
+			"underwriterId": ErrCodeInvalidRequest, "accountAgeDays": ErrCodeInvalidRequest, "previousPayments": ErrCodeInvalidRequest,
+		}},
+		{"usage", h.GetLimitUsage, "/limits/acc-1?type=WEEKLY", "", map[string]string{"type": ErrCodeInvalidLimitType}},
+		{"effective", h.GetEffectiveLimit, "/limits/acc-1/effective", "", map[string]string{"type": ErrCodeInvalidLimitType}},
+		{"history", h.GetLimitHistory, "/limits/acc-1/history?currency=EURO&periods=0", "", map[string]string{
+			"currency": ErrCodeInvalidCurrency, "periods": ErrCodeInvalidRequest,
+		}},
+		{"trend", h.GetLimitTrend, "/limits/acc-1/trend?type=MONTHLY&days=many", "", map[string]string{
+			"type": ErrCodeInvalidLimitType, "days": ErrCodeInvalidRequest,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, response := requestValidation(t, tt.handler, tt.target, tt.body)
+			if status != http.StatusUnprocessableEntity || response.Code != ErrCodeValidationFailed {
+				t.Fatalf("status %d with code %s, want %d with %s", status, response.Code, http.StatusUnprocessableEntity, ErrCodeValidationFailed)
+			}
+			got := make(map[string]string, len(response.Details))
+			for _, fieldErr := range response.Details {
+				got[fieldErr.Field] = fieldErr.Code
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("details = %+v, want fields %v", response.Details, tt.want)
+			}
+			for field, code := range tt.want {
+				if got[field] != code {
+					t.Errorf("%s code = %q, want %q", field, got[field], code)
+				}
+			}
+		})
+	}
+}
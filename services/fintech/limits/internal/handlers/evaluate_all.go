@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/internal/infrastructure"
+	"fintech/limits-service/pkg/otel"
+	"fintech/limits-service/pkg/respond"
+
+	"github.com/sirupsen/logrus"
+)
+
+// evaluatedLimitTypes are the limit types a payment is checked against by EvaluateAll, in order,
+// skipping any disabled by a control command
+var evaluatedLimitTypes = []domain.LimitType{domain.DailyLimit, domain.MonthlyLimit}
+
+// EvaluateAllRequest represents a request to check a payment against every limit type
+type EvaluateAllRequest struct {
+	AccountID string  `json:"accountId"`
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+}
+
+// EvaluateAll checks amount against each limit type in turn and spends it from all of them only
+// if every one allows it; a payment denied by any limit spends from none. A payment over the
+// per-transaction maximum is denied before any limit type is checked.
+func (h *LimitsHandler) EvaluateAll(ctx context.Context, accountID string, amount float64, currency string) (*domain.AggregateLimitResult, error) {
+	requests := make([]infrastructure.SpendRequest, 0, len(evaluatedLimitTypes))
+	for _, limitType := range evaluatedLimitTypes {
+		if h.runtime.LimitTypeDisabled(limitType) {
+			continue
+		}
+		requests = append(requests, infrastructure.SpendRequest{
+			AccountID:    accountID,
+			Type:         limitType,
+			Amount:       amount,
+			DefaultLimit: h.getDefaultLimit(limitType),
+			Currency:     currency,
+		})
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, h.config.LimitCheckTimeout)
+	defer cancel()
+
+	denied, err := h.checkPerTransactionLimit(checkCtx, accountID, amount, currency)
+	if err != nil {
+		return nil, err
+	}
+	if denied != nil {
+		return &domain.AggregateLimitResult{FailedType: denied.LimitType, Results: []*domain.LimitCheckResult{denied}}, nil
+	}
+
+	result, err := h.repo.EvaluateAll(checkCtx, requests)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Allowed {
+		for _, spent := range result.Results {
+			h.auditSpend("LimitEvaluation", amount, currency, spent)
+		}
+	}
+	return result, nil
+}
+
+// EvaluateAllLimits handles POST /limits/evaluate/all. It responds 200 with the per-type results
+// if the payment was spent from every limit, or 403 naming the first limit that denied it.
+func (h *LimitsHandler) EvaluateAllLimits(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "EvaluateAllLimits")
+	defer span.End()
+
+	var req EvaluateAllRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logrus.WithError(err).Error("Failed to decode request")
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid request body")
+		return
+	}
+	req.Currency = normalizeCurrency(req.Currency)
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("account_id", req.AccountID),
+		otel.Attribute("amount", req.Amount),
+	)
+
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	result, err := h.EvaluateAll(ctx, req.AccountID, req.Amount, req.Currency)
+	if err != nil {
+		logrus.WithError(err).WithField("account", req.AccountID).Error("Failed to evaluate limits")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	status := http.StatusOK
+	if !result.Allowed {
+		status = http.StatusForbidden
+	}
+
+	if err := respond.JSON(ctx, w, status, result); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,121 @@
+//go:build integration
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fintech/limits-service/internal/domain"
+)
+
+// explainLimit dry-runs a daily limit evaluation with ?explain=true, returning the explanation both
+// decoded and as raw JSON fields
+func explainLimit(t *testing.T, h *LimitsHandler, accountID string, amount float64, currency string) (domain.LimitExplanation, map[string]interface{}) {
+	t.Helper()
+
+	body, _ := json.Marshal(EvaluateLimitRequest{AccountID: accountID, LimitType: "DAILY", Amount: amount, Currency: currency})
+	rec := httptest.NewRecorder()
+	h.EvaluateLimit(rec, httptest.NewRequest(http.MethodPost, "/limits/evaluate?explain=true", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("EvaluateLimit status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var explanation domain.LimitExplanation
+	var fields map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &explanation); err != nil {
+		t.Fatalf("failed to decode explanation: %v", err)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &fields); err != nil {
+		t.Fatalf("failed to decode explanation: %v", err)
+	}
+	for _, field := range []string{"allowed", "reason_code", "limit_source", "period_label", "limit_amount", "used_amount", "remaining", "limit_currency", "requested_amount", "converted_amount", "tolerance_bps", "tolerance_applied"} {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("explanation is missing %s: %s", field, rec.Body.String())
+		}
+	}
+	return explanation, fields
+}
+
+func TestExplainLimitAllowedDefault(t *testing.T) {
+	h, _, _ := newTestHandler(t, map[string]string{"FX_TOLERANCE_BPS": "100"})
+	accountID := newID("acc")
+
+	explanation, fields := explainLimit(t, h, accountID, 100, "USD")
+	if !explanation.Allowed || explanation.ReasonCode != domain.ReasonWithinLimit {
+		t.Errorf("allowed=%v reason=%s, want allowed with %s", explanation.Allowed, explanation.ReasonCode, domain.ReasonWithinLimit)
+	}
+	if explanation.LimitSource != domain.LimitSourceDefault || explanation.LimitID != "" {
+		t.Errorf("limit source = %s (%q), want the default limit", explanation.LimitSource, explanation.LimitID)
+	}
+	if explanation.LimitAmount != 10000 || explanation.UsedAmount != 0 || explanation.Remaining != 10000 || explanation.LimitCurrency != "USD" {
+		t.Errorf("limit = %.2f used of %.2f %s, %.2f remaining, want the 10000 USD default unused",
+			explanation.UsedAmount, explanation.LimitAmount, explanation.LimitCurrency, explanation.Remaining)
+	}
+	if _, converted := fields["conversion_rate"]; converted || explanation.ConvertedAmount != 100 {
+		t.Errorf("converted %.2f at %v, want no conversion", explanation.ConvertedAmount, fields["conversion_rate"])
+	}
+	// An unconverted spend gets no FX tolerance
+	if explanation.ToleranceBps != 0 || explanation.ToleranceApplied {
+		t.Errorf("tolerance = %.0f bps (applied %v), want none", explanation.ToleranceBps, explanation.ToleranceApplied)
+	}
+
+	// A dry run creates no limit
+	if _, ok := currentLimitRows(t, h, accountID)["DAILY"]; ok {
+		t.Error("explain created a daily limit")
+	}
+}
+
+func TestExplainLimitConvertedSpends(t *testing.T) {
+	tests := []struct {
+		name          string
+		amount        float64
+		wantAllowed   bool
+		wantReason    string
+		wantTolerance bool
+	}{
+		// 9900 of 10000 USD used and 1% tolerance: 150 EUR is 163.04 USD, 200 EUR is 217.39 USD
+		{"allowed within tolerance", 150, true, domain.ReasonWithinTolerance, true},
+		{"denied", 200, false, domain.ReasonLimitExceeded, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, _ := newTestHandler(t, map[string]string{"FX_TOLERANCE_BPS": "100"})
+			accountID := newID("acc")
+			if _, err := h.repo.CheckAndSpend(context.Background(), accountID, domain.DailyLimit, 9900, 10000, "USD"); err != nil {
+				t.Fatalf("CheckAndSpend: %v", err)
+			}
+			stored := currentLimitRows(t, h, accountID)["DAILY"]
+
+			explanation, _ := explainLimit(t, h, accountID, tt.amount, "EUR")
+			if explanation.Allowed != tt.wantAllowed || explanation.ReasonCode != tt.wantReason || explanation.ToleranceApplied != tt.wantTolerance {
+				t.Errorf("allowed=%v reason=%s tolerance_applied=%v, want allowed=%v reason=%s tolerance_applied=%v",
+					explanation.Allowed, explanation.ReasonCode, explanation.ToleranceApplied, tt.wantAllowed, tt.wantReason, tt.wantTolerance)
+			}
+			if explanation.LimitSource != domain.LimitSourceStored || explanation.LimitID != stored.ID {
+				t.Errorf("limit source = %s (%q), want the stored limit %s", explanation.LimitSource, explanation.LimitID, stored.ID)
+			}
+			if explanation.UsedAmount != 9900 || explanation.Remaining != 100 || explanation.LimitCurrency != "USD" {
+				t.Errorf("used %.2f, %.2f remaining in %s, want 9900 used and 100 remaining in USD", explanation.UsedAmount, explanation.Remaining, explanation.LimitCurrency)
+			}
+			if explanation.RequestedCurrency != "EUR" || math.Abs(explanation.ConvertedAmount-tt.amount/0.92) > 0.01 || math.Abs(explanation.ConversionRate-1/0.92) > 1e-4 {
+				t.Errorf("converted %.2f %s to %.2f USD at %v, want the 0.92 EUR per USD rate",
+					explanation.RequestedAmount, explanation.RequestedCurrency, explanation.ConvertedAmount, explanation.ConversionRate)
+			}
+			if explanation.ToleranceBps != 100 {
+				t.Errorf("tolerance = %.0f bps, want 100", explanation.ToleranceBps)
+			}
+
+			// A dry run spends nothing
+			if used := currentLimitRows(t, h, accountID)["DAILY"].Used; used != 9900 {
+				t.Errorf("used after explain = %.2f, want 9900", used)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/otel"
+	"fintech/limits-service/pkg/respond"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// CreateHoldRequest represents a request to hold part of a limit
+type CreateHoldRequest struct {
+	AccountID  string  `json:"accountId"`
+	LimitType  string  `json:"limitType"`
+	Amount     float64 `json:"amount"`
+	Currency   string  `json:"currency"`
+	TTLSeconds int     `json:"ttlSeconds,omitempty"`
+}
+
+// HoldResponse represents the response for a hold request
+type HoldResponse struct {
+	HoldToken   string                   `json:"holdToken,omitempty"`
+	ExpiresAt   *time.Time               `json:"expiresAt,omitempty"`
+	LimitResult *domain.LimitCheckResult `json:"limitResult"`
+}
+
+// CreateHold handles POST /limits/hold
+func (h *LimitsHandler) CreateHold(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "CreateHold")
+	defer span.End()
+
+	var req CreateHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logrus.WithError(err).Error("Failed to decode hold request")
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid request body")
+		return
+	}
+	req.Currency = normalizeCurrency(req.Currency)
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("account_id", req.AccountID),
+		otel.Attribute("limit_type", req.LimitType),
+		otel.Attribute("amount", req.Amount),
+	)
+
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+	limitType := domain.LimitType(req.LimitType)
+	if h.runtime.LimitTypeDisabled(limitType) {
+		writeError(w, http.StatusConflict, ErrCodeLimitTypeDisabled, fmt.Sprintf("Limit type %s is disabled", limitType))
+		return
+	}
+
+	ttl := h.config.HoldTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl > h.config.MaxHoldTTL {
+		ttl = h.config.MaxHoldTTL
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, h.config.LimitCheckTimeout)
+	defer cancel()
+
+	// A hold is for a single payment, so it is subject to the per-transaction maximum
+	var hold *domain.LimitHold
+	result, err := h.checkPerTransactionLimit(checkCtx, req.AccountID, req.Amount, req.Currency)
+	if err == nil && result == nil {
+		hold, result, err = h.repo.CreateHold(checkCtx, req.AccountID, limitType, req.Amount, h.getDefaultLimit(limitType), req.Currency, ttl)
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("account", req.AccountID).Error("Failed to create limit hold")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	response := HoldResponse{LimitResult: result}
+	status := http.StatusForbidden
+	if hold != nil {
+		response.HoldToken = hold.Token
+		response.ExpiresAt = &hold.ExpiresAt
+		status = http.StatusCreated
+	}
+
+	if err := respond.JSON(ctx, w, status, response); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// CommitHold handles POST /limits/hold/{token}/commit
+func (h *LimitsHandler) CommitHold(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "CommitHold")
+	defer span.End()
+
+	token := mux.Vars(r)["token"]
+	otel.AddSpanAttributes(span, otel.Attribute("hold_token", token))
+
+	h.finishHold(w, h.repo.CommitHold(ctx, token), token)
+}
+
+// CancelHold handles DELETE /limits/hold/{token}
+func (h *LimitsHandler) CancelHold(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "CancelHold")
+	defer span.End()
+
+	token := mux.Vars(r)["token"]
+	otel.AddSpanAttributes(span, otel.Attribute("hold_token", token))
+
+	h.finishHold(w, h.repo.ReleaseHold(ctx, token), token)
+}
+
+// SweepExpiredHolds releases holds whose TTL has passed
+func (h *LimitsHandler) SweepExpiredHolds(ctx context.Context) error {
+	return h.repo.ExpireHolds(ctx)
+}
+
+// PruneExpiredLimits deletes limits whose period ended beyond the LIMIT_RETENTION_PERIODS history horizon
+func (h *LimitsHandler) PruneExpiredLimits(ctx context.Context) error {
+	return h.repo.PruneExpiredLimits(ctx, h.config.LimitRetentionPeriods)
+}
+
+func (h *LimitsHandler) finishHold(w http.ResponseWriter, err error, token string) {
+	switch {
+	case errors.Is(err, domain.ErrHoldNotActive):
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, "Hold not found or no longer active")
+	case err != nil:
+		logrus.WithError(err).WithField("hold_token", token).Error("Failed to update limit hold")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
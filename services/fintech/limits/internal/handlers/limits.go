@@ -3,40 +3,95 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"fintech/limits-service/internal/config"
 	"fintech/limits-service/internal/domain"
 	"fintech/limits-service/internal/infrastructure"
+	"fintech/limits-service/pkg/accounts"
 	"fintech/limits-service/pkg/database"
+	"fintech/limits-service/pkg/flags"
+	"fintech/limits-service/pkg/fx"
 	"fintech/limits-service/pkg/kafka"
+	"fintech/limits-service/pkg/lock"
+	"fintech/limits-service/pkg/metrics"
 	"fintech/limits-service/pkg/otel"
+	"fintech/limits-service/pkg/respond"
 
-	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
 // LimitsHandler handles HTTP requests for limit operations
 type LimitsHandler struct {
 	repo          *infrastructure.LimitRepository
+	loans         *infrastructure.LoanDecisionRepository
 	scoringSvc    *domain.ScoringService
 	auditSvc      *domain.AuditService
+	auditWriter   *infrastructure.AuditWriter
+	runtime       *RuntimeState
+	publisher     *kafka.Producer  // nil unless limit check results are published
+	accounts      *accounts.Client // nil unless an accounts service is configured
 	config        *config.Config
 }
 
 // NewLimitsHandler creates a new limits handler
-func NewLimitsHandler(db *database.DB) *LimitsHandler {
+func NewLimitsHandler(db *database.DB, converter *fx.Converter, auditWriter *infrastructure.AuditWriter) *LimitsHandler {
 	return &LimitsHandler{
-		repo:       infrastructure.NewLimitRepository(db),
-		scoringSvc: domain.NewScoringService(),
-		auditSvc:   domain.NewAuditService(),
+		repo:        infrastructure.NewLimitRepository(db, converter),
+		loans:       infrastructure.NewLoanDecisionRepository(db),
+		scoringSvc:  domain.NewScoringService(),
+		auditSvc:    domain.NewAuditService(),
+		auditWriter: auditWriter,
+		runtime:     NewRuntimeState(),
 	}
 }
 
 // SetConfig sets the configuration (called after creation)
 func (h *LimitsHandler) SetConfig(cfg *config.Config) {
 	h.config = cfg
+	h.repo.SetFXTolerance(cfg.FXToleranceBps)
+	h.repo.SetPerCurrency(cfg.PerCurrencyLimits)
+	h.repo.SetMaxCurrencies(cfg.MaxLimitCurrencies)
+	h.scoringSvc.SetConfig(domain.ScoringConfig{
+		BaseScore:               cfg.ScoringBaseScore,
+		MinScore:                cfg.ScoringMinScore,
+		MaxScore:                cfg.ScoringMaxScore,
+		NewAccountDays:          cfg.ScoringNewAccountDays,
+		NewAccountPenalty:       cfg.ScoringNewAccountPenalty,
+		EstablishedAccountDays:  cfg.ScoringEstablishedAccountDays,
+		EstablishedAccountBonus: cfg.ScoringEstablishedAccountBonus,
+		ManyPayments:            cfg.ScoringManyPayments,
+		ManyPaymentsBonus:       cfg.ScoringManyPaymentsBonus,
+		SomePayments:            cfg.ScoringSomePayments,
+		SomePaymentsBonus:       cfg.ScoringSomePaymentsBonus,
+		NoPaymentsPenalty:       cfg.ScoringNoPaymentsPenalty,
+		LargeAmount:             cfg.ScoringLargeAmount,
+		LargeAmountPenalty:      cfg.ScoringLargeAmountPenalty,
+		SmallAmount:             cfg.ScoringSmallAmount,
+		SmallAmountBonus:        cfg.ScoringSmallAmountBonus,
+		GradeThresholds:         cfg.ScoringGradeThresholds,
+		ApprovalCaps:            cfg.ScoringApprovalCaps,
+		GradeMultipliers:        cfg.LoanGradeMultipliers,
+	})
+}
+
+// SetLocker sets the distributed lock taken around each limit spend; nil disables locking
+func (h *LimitsHandler) SetLocker(locker *lock.RedisLocker, ttl time.Duration) {
+	h.repo.SetLocker(locker, ttl)
+}
+
+// SetAccountsClient sets the accounts service that loan scoring inputs are fetched from
+func (h *LimitsHandler) SetAccountsClient(client *accounts.Client) {
+	h.accounts = client
+}
+
+// SetPublisher publishes the result of each payment event's limit check to KAFKA_DECISIONS_TOPIC
+func (h *LimitsHandler) SetPublisher(producer *kafka.Producer) {
+	h.publisher = producer
 }
 
 // EvaluateLimit handles POST /limits/evaluate
@@ -47,7 +102,7 @@ func (h *LimitsHandler) EvaluateLimit(w http.ResponseWriter, r *http.Request) {
 	var req EvaluateLimitRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		logrus.WithError(err).Error("Failed to decode request")
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid request body")
 		return
 	}
 
@@ -58,20 +113,15 @@ func (h *LimitsHandler) EvaluateLimit(w http.ResponseWriter, r *http.Request) {
 	)
 
 	// Validate request
-	if req.AccountID == "" || req.Amount <= 0 {
-		http.Error(w, "Invalid request parameters", http.StatusBadRequest)
+	req.Currency = normalizeCurrency(req.Currency)
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
 		return
 	}
 
-	// Determine limit type
-	var limitType domain.LimitType
-	switch req.LimitType {
-	case "DAILY":
-		limitType = domain.DailyLimit
-	case "MONTHLY":
-		limitType = domain.MonthlyLimit
-	default:
-		http.Error(w, "Invalid limit type. Must be DAILY or MONTHLY", http.StatusBadRequest)
+	limitType := domain.LimitType(req.LimitType)
+	if h.runtime.LimitTypeDisabled(limitType) {
+		writeError(w, http.StatusConflict, ErrCodeLimitTypeDisabled, fmt.Sprintf("Limit type %s is disabled", limitType))
 		return
 	}
 
@@ -79,91 +129,389 @@ func (h *LimitsHandler) EvaluateLimit(w http.ResponseWriter, r *http.Request) {
 	checkCtx, cancel := context.WithTimeout(ctx, h.config.LimitCheckTimeout)
 	defer cancel()
 
-	result, err := h.repo.CheckAndSpend(
-		checkCtx,
-		req.AccountID,
-		limitType,
-		req.Amount,
-		h.getDefaultLimit(limitType),
-		req.Currency,
-	)
+	// ?explain=true is a dry run breaking down the decision instead of spending
+	if r.URL.Query().Get("explain") == "true" {
+		explanation, err := h.repo.ExplainSpend(checkCtx, req.AccountID, limitType, req.Amount, h.getDefaultLimit(limitType), req.Currency)
+		if err != nil {
+			logrus.WithError(err).WithField("account", req.AccountID).Error("Failed to explain limit")
+			writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+			return
+		}
+		if err := respond.JSON(ctx, w, http.StatusOK, explanation); err != nil {
+			logrus.WithError(err).Error("Failed to encode response")
+		}
+		return
+	}
+
+	// A payment over the per-transaction maximum is rejected without spending
+	result, err := h.checkPerTransactionLimit(checkCtx, req.AccountID, req.Amount, req.Currency)
+	if err == nil && result == nil {
+		result, err = h.repo.CheckAndSpend(
+			checkCtx,
+			req.AccountID,
+			limitType,
+			req.Amount,
+			h.getDefaultLimit(limitType),
+			req.Currency,
+		)
+		if err == nil {
+			h.auditSpend("LimitEvaluation", req.Amount, req.Currency, result)
+		}
+	}
 
 	if err != nil {
 		logrus.WithError(err).WithField("account", req.AccountID).Error("Failed to check limit")
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
 		return
 	}
 
 	// Return result
-	w.Header().Set("Content-Type", "application/json")
+	status := http.StatusOK
 	if !result.Allowed {
-		w.WriteHeader(http.StatusForbidden)
-	} else {
-		w.WriteHeader(http.StatusOK)
+		status = http.StatusForbidden
 	}
 
-	if err := json.NewEncoder(w).Encode(result); err != nil {
+	if err := respond.JSON(ctx, w, status, result); err != nil {
 		logrus.WithError(err).Error("Failed to encode response")
 	}
 }
 
-// HandlePaymentEvent handles payment events from Kafka
-func (h *LimitsHandler) HandlePaymentEvent(event *kafka.PaymentInitiatedEvent) error {
-	ctx, span := otel.StartSpan(context.Background(), "HandlePaymentEvent")
+// HandlePaymentEvent handles payment events from Kafka. ctx carries the producer's trace context,
+// so the span continues the upstream payment trace.
+func (h *LimitsHandler) HandlePaymentEvent(ctx context.Context, event *kafka.PaymentInitiatedEvent) error {
+	ctx, span := otel.StartSpan(ctx, "HandlePaymentEvent")
 	defer span.End()
 
 	otel.AddSpanAttributes(span,
+		otel.Attribute("event_type", event.EventType),
 		otel.Attribute("payment_id", event.PaymentID),
 		otel.Attribute("account_id", event.FromAccountID),
 		otel.Attribute("amount", event.Amount),
 	)
 
+	// Sent to the dead letter topic as retryable, to be reprocessed once writes are allowed again
+	if h.runtime.ReadOnly() {
+		return ErrReadOnly
+	}
+
+	// Only initiation spends; completion changes nothing and failures refund the spend
+	switch event.EventType {
+	case kafka.PaymentCompleted:
+		logrus.WithField("payment_id", event.PaymentID).Debug("Payment completed, limits already spent")
+		return nil
+	case kafka.PaymentFailed:
+		return h.releasePayment(ctx, event.EventType, event.PaymentID, event.FromAccountID, event.Amount, event.Currency,
+			fmt.Sprintf("Released %.2f %s for failed payment %s: %s", event.Amount, event.Currency, event.PaymentID, event.Reason))
+	case kafka.PaymentReversed:
+		return h.releasePayment(ctx, event.EventType, event.PaymentID, event.FromAccountID, event.Amount, event.Currency,
+			fmt.Sprintf("Released %.2f %s for reversed payment %s: %s", event.Amount, event.Currency, event.PaymentID, event.Reason))
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"payment_id": event.PaymentID,
 		"account_id": event.FromAccountID,
 		"amount":     event.Amount,
 	}).Info("Processing payment event for limit check")
 
+	// Skip a payment published twice. The claim is dropped again if processing fails and is undone,
+	// so the redelivered event is processed.
+	key := idempotencyKey(event)
+	claimed, err := h.repo.ClaimEvent(ctx, key, event.PaymentID, event.FromAccountID)
+	if err != nil {
+		logrus.WithError(err).WithField("payment", event.PaymentID).Error("Failed to check payment idempotency")
+		return err
+	}
+	if !claimed {
+		logrus.WithFields(logrus.Fields{
+			"payment_id":      event.PaymentID,
+			"idempotency_key": key,
+		}).Info("Payment event already processed, skipping")
+		return nil
+	}
+
+	// A payment over the per-transaction maximum is rejected before it is counted or spent
+	maxResult, err := h.checkPerTransactionLimit(ctx, event.FromAccountID, event.Amount, event.Currency)
+	if err != nil {
+		logrus.WithError(err).WithField("payment", event.PaymentID).Error("Failed to check per-transaction maximum")
+		h.unclaimEvent(ctx, key)
+		return err
+	}
+	if maxResult != nil {
+		logrus.WithFields(logrus.Fields{
+			"payment_id": event.PaymentID,
+			"account_id": event.FromAccountID,
+			"max_amount": maxResult.LimitAmount,
+		}).Info("Per-transaction maximum exceeded, payment rejected")
+		h.publishDecision(ctx, event, maxResult.LimitType, false, maxResult)
+		return nil
+	}
+
+	// Count the payment against the velocity limits first; a payment over either count is rejected
+	// without spending from the amount limits
+	counted, exceeded, err := h.checkVelocity(ctx, event)
+	if err != nil {
+		logrus.WithError(err).WithField("payment", event.PaymentID).Error("Failed to check velocity limits")
+		h.unclaimEvent(ctx, key)
+		return err
+	}
+	if exceeded != nil {
+		logrus.WithFields(logrus.Fields{
+			"payment_id": event.PaymentID,
+			"account_id": event.FromAccountID,
+			"limit_type": exceeded.Type,
+			"max_count":  exceeded.MaxCount,
+		}).Info("Velocity limit exceeded, payment rejected")
+		h.publishDecision(ctx, event, string(exceeded.Type), true)
+		return nil
+	}
+
 	// Check daily limit
-	dailyResult, err := h.repo.CheckAndSpend(
-		ctx,
-		event.FromAccountID,
-		domain.DailyLimit,
-		event.Amount,
-		h.config.DefaultDailyLimit,
-		event.Currency,
-	)
+	dailyResult, err := h.spendForPayment(ctx, event, domain.DailyLimit)
 	if err != nil {
 		logrus.WithError(err).WithField("payment", event.PaymentID).Error("Failed to check daily limit")
+		h.undoVelocity(ctx, event, counted)
+		h.unclaimEvent(ctx, key)
 		return err
 	}
 
 	// Check monthly limit
-	monthlyResult, err := h.repo.CheckAndSpend(
-		ctx,
-		event.FromAccountID,
-		domain.MonthlyLimit,
-		event.Amount,
-		h.config.DefaultMonthlyLimit,
-		event.Currency,
-	)
+	monthlyResult, err := h.spendForPayment(ctx, event, domain.MonthlyLimit)
 	if err != nil {
 		logrus.WithError(err).WithField("payment", event.PaymentID).Error("Failed to check monthly limit")
+		// Undo the daily spend so the redelivered event doesn't consume it twice. Without the rollback
+		// the claim is kept, so redelivery doesn't spend the daily limit again either.
+		if h.config.Flags.Enabled(flags.SpendRollback) {
+			h.rollbackSpends(ctx, event)
+			h.unclaimEvent(ctx, key)
+		}
+		h.undoVelocity(ctx, event, counted)
 		return err
 	}
 
-	// Log limit check results
+	// A payment denied by either amount limit spends from neither and doesn't count as a transaction,
+	// so the other limit's spend is rolled back
+	if !spendAllowed(dailyResult) || !spendAllowed(monthlyResult) {
+		h.rollbackSpends(ctx, event)
+		h.undoVelocity(ctx, event, counted)
+	} else {
+		h.auditSpend("PaymentInitiated", event.Amount, event.Currency, dailyResult)
+		h.auditSpend("PaymentInitiated", event.Amount, event.Currency, monthlyResult)
+	}
+
+	// Log limit check results; a disabled limit type has none
+	fields := logrus.Fields{
+		"payment_id": event.PaymentID,
+		"account_id": event.FromAccountID,
+	}
+	if dailyResult != nil {
+		fields["daily_allowed"], fields["daily_remaining"] = dailyResult.Allowed, dailyResult.Remaining
+	}
+	if monthlyResult != nil {
+		fields["monthly_allowed"], fields["monthly_remaining"] = monthlyResult.Allowed, monthlyResult.Remaining
+	}
+	logrus.WithFields(fields).Info("Limit check completed")
+
+	h.publishDecision(ctx, event, deniedBy(dailyResult, monthlyResult), false, dailyResult, monthlyResult)
+
+	return nil
+}
+
+// spendForPayment spends a payment from the account's limit of limitType. A limit type disabled by a
+// control command isn't enforced: nothing is spent and the result is nil.
+func (h *LimitsHandler) spendForPayment(ctx context.Context, event *kafka.PaymentInitiatedEvent, limitType domain.LimitType) (*domain.LimitCheckResult, error) {
+	if h.runtime.LimitTypeDisabled(limitType) {
+		return nil, nil
+	}
+	return h.repo.CheckAndSpendForPayment(ctx, event.PaymentID, event.FromAccountID, limitType, event.Amount, h.getDefaultLimit(limitType), event.Currency)
+}
+
+// spendAllowed reports whether a spend was allowed, or skipped because its limit type is disabled
+func spendAllowed(result *domain.LimitCheckResult) bool {
+	return result == nil || result.Allowed
+}
+
+// idempotencyKey returns the key a payment event is deduplicated by: its idempotency key, or its
+// payment ID for producers that don't send one
+func idempotencyKey(event *kafka.PaymentInitiatedEvent) string {
+	if event.IdempotencyKey != "" {
+		return event.IdempotencyKey
+	}
+	return event.PaymentID
+}
+
+// unclaimEvent drops the claim on a payment event whose processing failed, logging rather than
+// returning failures since the event's own error is returned
+func (h *LimitsHandler) unclaimEvent(ctx context.Context, key string) {
+	if err := h.repo.UnclaimEvent(ctx, key); err != nil {
+		logrus.WithError(err).WithField("idempotency_key", key).Error("Failed to unclaim payment event")
+	}
+}
+
+// checkVelocity counts a payment against each configured velocity limit, returning the limit types
+// counted. If one is already at its maximum, the counts taken so far are undone and that limit is
+// returned as exceeded.
+func (h *LimitsHandler) checkVelocity(ctx context.Context, event *kafka.PaymentInitiatedEvent) ([]domain.LimitType, *domain.VelocityLimit, error) {
+	limits := []struct {
+		limitType domain.LimitType
+		maxCount  int
+	}{
+		{domain.DailyLimit, h.config.DailyTxCountLimit},
+		{domain.MonthlyLimit, h.config.MonthlyTxCountLimit},
+	}
+
+	var counted []domain.LimitType
+	for _, l := range limits {
+		if l.maxCount <= 0 {
+			continue
+		}
+
+		velocity, ok, err := h.repo.CheckAndIncrement(ctx, event.FromAccountID, l.limitType, l.maxCount)
+		if err != nil {
+			h.undoVelocity(ctx, event, counted)
+			return nil, nil, err
+		}
+		if !ok {
+			h.undoVelocity(ctx, event, counted)
+			return nil, velocity, nil
+		}
+		counted = append(counted, l.limitType)
+	}
+
+	return counted, nil, nil
+}
+
+// undoVelocity takes back the payment's count from each velocity limit it was counted against
+func (h *LimitsHandler) undoVelocity(ctx context.Context, event *kafka.PaymentInitiatedEvent, counted []domain.LimitType) {
+	for _, limitType := range counted {
+		if err := h.repo.DecrementCount(ctx, event.FromAccountID, limitType); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"payment_id": event.PaymentID,
+				"limit_type": limitType,
+			}).Error("Failed to undo velocity count")
+		}
+	}
+}
+
+// rollbackSpends is the compensating step for a payment event that failed or was denied after
+// spending: it releases every spend recorded for the payment, so a denied payment keeps none of
+// its spends and a failed event can be safely redelivered
+func (h *LimitsHandler) rollbackSpends(ctx context.Context, event *kafka.PaymentInitiatedEvent) {
+	rolledBack, err := h.repo.RollbackPaymentSpends(ctx, event.PaymentID)
+	if err != nil {
+		logrus.WithError(err).WithField("payment_id", event.PaymentID).Error("Failed to roll back limit spends")
+		return
+	}
+	if rolledBack == 0 {
+		return
+	}
+
 	logrus.WithFields(logrus.Fields{
-		"payment_id":      event.PaymentID,
-		"account_id":      event.FromAccountID,
-		"daily_allowed":   dailyResult.Allowed,
-		"daily_remaining": dailyResult.Remaining,
-		"monthly_allowed": monthlyResult.Allowed,
-		"monthly_remaining": monthlyResult.Remaining,
-	}).Info("Limit check completed")
+		"payment_id": event.PaymentID,
+		"limits":     rolledBack,
+		"amount":     event.Amount,
+	}).Warn("Rolled back limit spends of a payment that failed or was denied")
+}
+
+// HandlePaymentReversedEvent releases a reversed payment's amount back to the account's limits
+func (h *LimitsHandler) HandlePaymentReversedEvent(event *kafka.PaymentReversedEvent) error {
+	ctx, span := otel.StartSpan(context.Background(), "HandlePaymentReversedEvent")
+	defer span.End()
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("payment_id", event.PaymentID),
+		otel.Attribute("account_id", event.FromAccountID),
+		otel.Attribute("amount", event.Amount),
+	)
+
+	if event.PaymentID == "" || event.FromAccountID == "" || event.Amount <= 0 {
+		return fmt.Errorf("invalid payment reversed event for payment %q", event.PaymentID)
+	}
+	if h.runtime.ReadOnly() {
+		return ErrReadOnly
+	}
+
+	return h.releasePayment(ctx, kafka.PaymentReversed, event.PaymentID, event.FromAccountID, event.Amount, event.Currency,
+		fmt.Sprintf("Released %.2f %s for reversed payment %s: %s", event.Amount, event.Currency, event.PaymentID, event.Reason))
+}
 
-	// In a real implementation, you might publish limit check results to Kafka
-	// or update payment status based on limit checks
+// releasePayment returns a failed or reversed payment's amount to the limits it was spent from and
+// audits it with details. A payment is released at most once, whichever event arrives first, and a
+// payment that spent nothing, e.g. because it was denied, releases nothing.
+func (h *LimitsHandler) releasePayment(ctx context.Context, eventType, paymentID, accountID string, amount float64, currency, details string) error {
+	released, err := h.repo.ReleaseForPayment(ctx, paymentID, accountID, amount, currency)
+	if err != nil {
+		logrus.WithError(err).WithField("payment", paymentID).Error("Failed to release limit")
+		return err
+	}
+
+	if released == 0 {
+		logrus.WithFields(logrus.Fields{
+			"payment_id": paymentID,
+			"event_type": eventType,
+		}).Info("Payment release already applied or nothing was spent, skipping")
+		return nil
+	}
+
+	auditEntry := h.auditSvc.LogAction(
+		eventType,
+		accountID,
+		"",
+		"RELEASE",
+		"limit",
+		details,
+		"",
+		"",
+		"INFO",
+	)
+	h.auditWriter.Write(auditEntry)
+
+	logrus.WithFields(logrus.Fields{
+		"payment_id":  paymentID,
+		"event_type":  eventType,
+		"account_id":  accountID,
+		"amount":      amount,
+		"audit_entry": auditEntry.ID,
+	}).Info("Limit released for payment")
+
+	return nil
+}
+
+// HandleAccountCreatedEvent pre-creates a new account's default limits for the current periods,
+// so its first payment doesn't pay for limit creation. Redelivered events find the rows already present.
+func (h *LimitsHandler) HandleAccountCreatedEvent(event *kafka.AccountCreatedEvent) error {
+	ctx, span := otel.StartSpan(context.Background(), "HandleAccountCreatedEvent")
+	defer span.End()
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("account_id", event.AccountID),
+	)
+
+	if event.AccountID == "" {
+		return fmt.Errorf("invalid account created event: missing account ID")
+	}
+	if h.runtime.ReadOnly() {
+		return ErrReadOnly
+	}
+
+	currency := event.Currency
+	if currency == "" {
+		currency = h.config.FXBaseCurrency
+	}
+
+	for _, limitType := range []domain.LimitType{domain.DailyLimit, domain.MonthlyLimit} {
+		if _, err := h.repo.GetOrCreateLimit(ctx, event.AccountID, limitType, h.getDefaultLimit(limitType), currency); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"account_id": event.AccountID,
+				"limit_type": limitType,
+			}).Error("Failed to pre-create limit")
+			return err
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"account_id": event.AccountID,
+		"currency":   currency,
+	}).Info("Limits pre-created for new account")
 
 	return nil
 }
@@ -176,7 +524,7 @@ func (h *LimitsHandler) ApplyForLoan(w http.ResponseWriter, r *http.Request) {
 	var req LoanApplicationRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		logrus.WithError(err).Error("Failed to decode loan application request")
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid request body")
 		return
 	}
 
@@ -187,17 +535,40 @@ func (h *LimitsHandler) ApplyForLoan(w http.ResponseWriter, r *http.Request) {
 	)
 
 	// Validate request
-	if req.AccountID == "" || req.Amount <= 0 {
-		http.Error(w, "Invalid request parameters", http.StatusBadRequest)
+	req.Currency = normalizeCurrency(req.Currency)
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
 		return
 	}
 
-	// Get account age and payment history (stub implementation)
-	accountAgeDays := h.getAccountAge(req.AccountID)
-	previousPayments := h.getPreviousPayments(req.AccountID)
+	// Loan limits are created in the requested currency, or the configured default
+	currency := req.Currency
+	if currency == "" {
+		currency = h.config.DefaultLoanCurrency
+	}
 
-	// Perform credit scoring
-	scoringResult := h.scoringSvc.EvaluateScore(req.AccountID, req.Amount, accountAgeDays, previousPayments)
+	// Perform credit scoring; without account data SCORING_ON_MISSING_DATA decides the outcome
+	var scoringResult *domain.ScoringResult
+	severity := "INFO"
+	accountAgeDays, previousPayments, err := h.getScoringInputs(ctx, req.AccountID)
+	switch {
+	case err == nil:
+		scoringResult = h.scoringSvc.EvaluateScore(req.AccountID, req.Amount, accountAgeDays, previousPayments)
+	case domain.MissingDataPolicy(h.config.ScoringOnMissingData) == domain.MissingDataDecline:
+		logrus.WithError(err).WithField("account_id", req.AccountID).Warn("Account data unavailable, declining loan application")
+		scoringResult = h.scoringSvc.DeclineForMissingData()
+		severity = "ERROR"
+	default:
+		logrus.WithError(err).WithField("account_id", req.AccountID).Warn("Account data unavailable, scoring loan application conservatively")
+		accountAgeDays, previousPayments = 0, 0
+		scoringResult = h.scoringSvc.EvaluateConservative(req.AccountID, req.Amount)
+		severity = "WARN"
+	}
+	metrics.LoanDecisions.WithLabelValues(
+		scoringResult.Grade,
+		strconv.FormatBool(scoringResult.Approved),
+		metrics.AmountBucket(req.Amount, h.config.LoanAmountBuckets),
+	).Inc()
 
 	// Create audit entry
 	auditEntry := h.auditSvc.LogAction(
@@ -206,11 +577,30 @@ func (h *LimitsHandler) ApplyForLoan(w http.ResponseWriter, r *http.Request) {
 		req.UserID,
 		"APPLY",
 		"loan",
-		fmt.Sprintf("Loan application for $%.2f, score: %d, approved: %v", req.Amount, scoringResult.Score, scoringResult.Approved),
+		fmt.Sprintf("Loan application for %.2f %s, score: %d, approved: %v", req.Amount, currency, scoringResult.Score, scoringResult.Approved),
 		r.RemoteAddr,
 		r.Header.Get("User-Agent"),
-		"INFO",
+		severity,
 	)
+	h.auditWriter.Write(auditEntry)
+
+	// Persist the original decision so it can later be recomputed
+	decision := &domain.LoanDecision{
+		ApplicationID:    auditEntry.ID,
+		Version:          1,
+		AccountID:        req.AccountID,
+		RequestedAmount:  req.Amount,
+		Currency:         currency,
+		AccountAgeDays:   accountAgeDays,
+		PreviousPayments: previousPayments,
+		Result:           *scoringResult,
+		CreatedAt:        scoringResult.CalculatedAt,
+	}
+	if err := h.loans.Save(ctx, decision); err != nil {
+		logrus.WithError(err).Error("Failed to save loan decision")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Failed to process loan application")
+		return
+	}
 
 	logrus.WithFields(logrus.Fields{
 		"account_id":   req.AccountID,
@@ -231,13 +621,14 @@ func (h *LimitsHandler) ApplyForLoan(w http.ResponseWriter, r *http.Request) {
 			domain.MonthlyLimit, // Loan limits are typically monthly
 			scoringResult.MaxAmount,
 			scoringResult.MaxAmount, // Set limit to approved amount
-			"USD", // Default currency
+			currency,
 		)
 		if err != nil {
 			logrus.WithError(err).Error("Failed to create loan limit")
-			http.Error(w, "Failed to process loan limit", http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Failed to process loan limit")
 			return
 		}
+		h.auditSpend("LoanApplication", scoringResult.MaxAmount, currency, limitResult)
 	}
 
 	// Prepare response
@@ -253,37 +644,28 @@ func (h *LimitsHandler) ApplyForLoan(w http.ResponseWriter, r *http.Request) {
 		response.LimitResult = limitResult
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	respond.JSON(ctx, w, http.StatusOK, response)
 }
 
-// getAccountAge returns account age in days (stub implementation)
-func (h *LimitsHandler) getAccountAge(accountID string) int {
-	// In a real implementation, this would query the accounts service
-	// For now, return a random-ish value based on account ID
-	hash := 0
-	for _, char := range accountID {
-		hash += int(char)
-	}
-	return (hash % 365) + 30 // Between 30-395 days
-}
+// errAccountsNotConfigured is returned for scoring inputs when no accounts service is configured
+var errAccountsNotConfigured = errors.New("accounts service not configured")
 
-// getPreviousPayments returns number of previous payments (stub implementation)
-func (h *LimitsHandler) getPreviousPayments(accountID string) int {
-	// In a real implementation, this would query payment history
-	// For now, return a value based on account ID
-	hash := 0
-	for _, char := range accountID {
-		hash += int(char)
+// getScoringInputs returns the account age in days and number of previous payments scoring needs,
+// from the accounts service
+func (h *LimitsHandler) getScoringInputs(ctx context.Context, accountID string) (int, int, error) {
+	if h.accounts == nil {
+		return 0, 0, errAccountsNotConfigured
+	}
+	profile, err := h.accounts.GetAccountProfile(ctx, accountID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get account profile: %w", err)
 	}
-	return hash % 20 // 0-19 payments
+	return profile.AccountAgeDays, profile.PaymentCount, nil
 }
 
 // HealthCheck handles GET /health
 func HealthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
+	respond.JSON(r.Context(), w, http.StatusOK, map[string]string{
 		"status": "healthy",
 		"time":   time.Now().UTC().Format(time.RFC3339),
 	})
@@ -316,6 +698,26 @@ type LoanApplicationResponse struct {
 	LimitResult   *domain.LimitCheckResult `json:"limitResult,omitempty"`
 }
 
+// auditSpend records a successful limit spend when spend auditing is enabled. Denied checks
+// spend nothing and are not audited.
+func (h *LimitsHandler) auditSpend(eventType string, amount float64, currency string, result *domain.LimitCheckResult) {
+	if !h.config.AuditSpends || result == nil || !result.Allowed {
+		return
+	}
+
+	h.auditWriter.Write(h.auditSvc.LogAction(
+		eventType,
+		result.AccountID,
+		"",
+		"SPEND",
+		"limit",
+		fmt.Sprintf("Spent %.2f %s against %s limit; remaining %.2f; decision ALLOWED", amount, currency, result.LimitType, result.Remaining),
+		"",
+		"",
+		"INFO",
+	))
+}
+
 func (h *LimitsHandler) getDefaultLimit(limitType domain.LimitType) float64 {
 	switch limitType {
 	case domain.DailyLimit:
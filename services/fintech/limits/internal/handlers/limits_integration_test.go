@@ -0,0 +1,349 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/internal/infrastructure"
+	"fintech/limits-service/pkg/database"
+	"fintech/limits-service/pkg/kafka"
+)
+
+func initiatePayment(t *testing.T, h *LimitsHandler, paymentID, accountID string, amount float64) {
+	t.Helper()
+
+	err := h.HandlePaymentEvent(context.Background(), &kafka.PaymentInitiatedEvent{
+		EventType:     kafka.PaymentInitiated,
+		PaymentID:     paymentID,
+		FromAccountID: accountID,
+		ToAccountID:   newID("acc"),
+		Amount:        amount,
+		Currency:      "USD",
+	})
+	if err != nil {
+		t.Fatalf("HandlePaymentEvent(%s): %v", paymentID, err)
+	}
+}
+
+func TestPaymentReversedReleasesSpendOnce(t *testing.T) {
+	h, auditWriter, db := newTestHandler(t, nil)
+	accountID := newID("acc")
+	reversed, kept := newID("pay"), newID("pay")
+
+	initiatePayment(t, h, reversed, accountID, 100)
+	initiatePayment(t, h, kept, accountID, 50)
+
+	event := &kafka.PaymentReversedEvent{
+		PaymentID:     reversed,
+		FromAccountID: accountID,
+		Amount:        100,
+		Currency:      "USD",
+		Reason:        "chargeback",
+	}
+	// The redelivered reversal must not release the other payment's spend
+	for i := 0; i < 2; i++ {
+		if err := h.HandlePaymentReversedEvent(event); err != nil {
+			t.Fatalf("HandlePaymentReversedEvent (delivery %d): %v", i+1, err)
+		}
+	}
+
+	for _, limitType := range []string{"DAILY", "MONTHLY"} {
+		if used := limitUsed(t, db, accountID, limitType); used != 50 {
+			t.Errorf("%s used = %.2f, want 50", limitType, used)
+		}
+	}
+
+	if err := auditWriter.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if count := auditCount(t, db, accountID, kafka.PaymentReversed); count != 1 {
+		t.Errorf("audited %d reversals, want 1", count)
+	}
+}
+
+func TestPartialPaymentReversalReleasesItsAmount(t *testing.T) {
+	h, _, db := newTestHandler(t, nil)
+	accountID := newID("acc")
+	paymentID := newID("pay")
+
+	initiatePayment(t, h, paymentID, accountID, 100)
+
+	event := &kafka.PaymentReversedEvent{
+		PaymentID:     paymentID,
+		FromAccountID: accountID,
+		Amount:        40,
+		Currency:      "USD",
+	}
+	for i := 0; i < 2; i++ {
+		if err := h.HandlePaymentReversedEvent(event); err != nil {
+			t.Fatalf("HandlePaymentReversedEvent (delivery %d): %v", i+1, err)
+		}
+	}
+
+	if used := limitUsed(t, db, accountID, "DAILY"); used != 60 {
+		t.Errorf("DAILY used = %.2f, want 60", used)
+	}
+}
+
+func TestPaymentReversedWithoutSpendReleasesNothing(t *testing.T) {
+	h, _, db := newTestHandler(t, nil)
+	accountID := newID("acc")
+
+	initiatePayment(t, h, newID("pay"), accountID, 70)
+
+	err := h.HandlePaymentReversedEvent(&kafka.PaymentReversedEvent{
+		PaymentID:     newID("pay"),
+		FromAccountID: accountID,
+		Amount:        70,
+		Currency:      "USD",
+	})
+	if err != nil {
+		t.Fatalf("HandlePaymentReversedEvent: %v", err)
+	}
+
+	if used := limitUsed(t, db, accountID, "DAILY"); used != 70 {
+		t.Errorf("DAILY used = %.2f, want 70", used)
+	}
+}
+
+// accountLimitRow is the part of a limits row the account-created tests check
+type accountLimitRow struct {
+	ID       string
+	Amount   float64
+	Used     float64
+	Currency string
+}
+
+// currentLimitRows returns the account's limit rows for the current periods, by type
+func currentLimitRows(t *testing.T, h *LimitsHandler, accountID string) map[string]accountLimitRow {
+	t.Helper()
+
+	rows := make(map[string]accountLimitRow)
+	for _, limitType := range []domain.LimitType{domain.DailyLimit, domain.MonthlyLimit} {
+		limit, err := h.repo.GetCurrentLimit(context.Background(), accountID, limitType)
+		if err != nil {
+			t.Fatalf("GetCurrentLimit(%s): %v", limitType, err)
+		}
+		if limit != nil {
+			rows[string(limitType)] = accountLimitRow{ID: limit.ID, Amount: limit.Amount, Used: limit.Used, Currency: limit.Currency}
+		}
+	}
+	return rows
+}
+
+func TestAccountCreatedPreCreatesLimits(t *testing.T) {
+	h, _, db := newTestHandler(t, map[string]string{"DEFAULT_DAILY_LIMIT": "2000", "DEFAULT_MONTHLY_LIMIT": "9000"})
+	accountID := newID("acc")
+
+	event := &kafka.AccountCreatedEvent{AccountID: accountID, Currency: "EUR", CreatedAt: time.Now()}
+	if err := h.HandleAccountCreatedEvent(event); err != nil {
+		t.Fatalf("HandleAccountCreatedEvent: %v", err)
+	}
+
+	rows := currentLimitRows(t, h, accountID)
+	want := map[string]float64{"DAILY": 2000, "MONTHLY": 9000}
+	for limitType, amount := range want {
+		row, ok := rows[limitType]
+		if !ok {
+			t.Errorf("no %s limit pre-created", limitType)
+			continue
+		}
+		if row.Amount != amount || row.Used != 0 || row.Currency != "EUR" {
+			t.Errorf("%s limit = %+v, want %.0f EUR unused", limitType, row, amount)
+		}
+	}
+
+	// A redelivered event leaves the rows as they are
+	if err := h.HandleAccountCreatedEvent(event); err != nil {
+		t.Fatalf("HandleAccountCreatedEvent (redelivered): %v", err)
+	}
+	again := currentLimitRows(t, h, accountID)
+	for limitType, row := range rows {
+		if again[limitType] != row {
+			t.Errorf("%s limit after redelivery = %+v, want unchanged %+v", limitType, again[limitType], row)
+		}
+	}
+
+	var count int
+	if err := db.QueryRow(context.Background(), "SELECT COUNT(*) FROM limits WHERE account_id = $1", accountID).Scan(&count); err != nil {
+		t.Fatalf("failed to count limit rows: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("account has %d limit rows, want 2", count)
+	}
+}
+
+func TestAccountCreatedDefaultsToBaseCurrency(t *testing.T) {
+	h, _, _ := newTestHandler(t, nil)
+	accountID := newID("acc")
+
+	if err := h.HandleAccountCreatedEvent(&kafka.AccountCreatedEvent{AccountID: accountID}); err != nil {
+		t.Fatalf("HandleAccountCreatedEvent: %v", err)
+	}
+
+	for limitType, row := range currentLimitRows(t, h, accountID) {
+		if row.Currency != h.config.FXBaseCurrency {
+			t.Errorf("%s limit currency = %s, want the base currency %s", limitType, row.Currency, h.config.FXBaseCurrency)
+		}
+	}
+}
+
+// evaluateLimit spends amount USD against the account's daily limit over HTTP, returning the status code
+func evaluateLimit(t *testing.T, h *LimitsHandler, accountID string, amount float64) int {
+	t.Helper()
+
+	body := `{"accountId": "` + accountID + `", "limitType": "DAILY", "amount": ` + strconv.FormatFloat(amount, 'f', -1, 64) + `, "currency": "USD"}`
+	rec := httptest.NewRecorder()
+	h.EvaluateLimit(rec, httptest.NewRequest(http.MethodPost, "/limits/evaluate", strings.NewReader(body)))
+	return rec.Code
+}
+
+// spendAuditDetails returns the details of the account's spend audit entries
+func spendAuditDetails(t *testing.T, db *database.DB, auditWriter *infrastructure.AuditWriter, accountID string) []string {
+	t.Helper()
+
+	if err := auditWriter.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	rows, err := db.Query(context.Background(), `
+		SELECT details FROM audit_log
+		WHERE account_id = $1 AND event_type = 'LimitEvaluation' AND action = 'SPEND' AND resource = 'limit'
+		ORDER BY timestamp
+	`, accountID)
+	if err != nil {
+		t.Fatalf("failed to query audit entries: %v", err)
+	}
+	defer rows.Close()
+
+	var details []string
+	for rows.Next() {
+		var detail string
+		if err := rows.Scan(&detail); err != nil {
+			t.Fatalf("failed to scan audit entry: %v", err)
+		}
+		details = append(details, detail)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("failed to read audit entries: %v", err)
+	}
+	return details
+}
+
+func TestEvaluateLimitAuditsSpend(t *testing.T) {
+	h, auditWriter, db := newTestHandler(t, map[string]string{"DEFAULT_DAILY_LIMIT": "1000"})
+	accountID := newID("acc")
+
+	if code := evaluateLimit(t, h, accountID, 250); code != http.StatusOK {
+		t.Fatalf("allowed spend status = %d, want 200", code)
+	}
+	// A denied check spends nothing and isn't audited
+	if code := evaluateLimit(t, h, accountID, 5000); code != http.StatusForbidden {
+		t.Fatalf("denied spend status = %d, want 403", code)
+	}
+
+	details := spendAuditDetails(t, db, auditWriter, accountID)
+	want := "Spent 250.00 USD against DAILY limit; remaining 750.00; decision ALLOWED"
+	if len(details) != 1 || details[0] != want {
+		t.Errorf("spend audit entries = %q, want [%q]", details, want)
+	}
+}
+
+func TestEvaluateLimitSpendAuditingDisabled(t *testing.T) {
+	h, auditWriter, db := newTestHandler(t, map[string]string{"AUDIT_SPENDS": "false"})
+	accountID := newID("acc")
+
+	if code := evaluateLimit(t, h, accountID, 250); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+
+	if details := spendAuditDetails(t, db, auditWriter, accountID); len(details) != 0 {
+		t.Errorf("spend audit entries = %q with auditing disabled, want none", details)
+	}
+}
+
+// failMonthlySpends pre-creates the account's monthly limit in a currency without an FX rate, so
+// spending a USD payment from it fails after the daily spend has succeeded
+func failMonthlySpends(t *testing.T, h *LimitsHandler, accountID string) {
+	t.Helper()
+
+	if _, err := h.repo.GetOrCreateLimit(context.Background(), accountID, domain.MonthlyLimit, 50000, "JPY"); err != nil {
+		t.Fatalf("GetOrCreateLimit: %v", err)
+	}
+}
+
+// repairMonthlySpends moves the monthly limit to USD so the payment can be spent from it
+func repairMonthlySpends(t *testing.T, db *database.DB, accountID string) {
+	t.Helper()
+
+	if _, err := db.Exec(context.Background(), "UPDATE limits SET currency = 'USD' WHERE account_id = $1 AND type = 'MONTHLY'", accountID); err != nil {
+		t.Fatalf("failed to repair monthly limit: %v", err)
+	}
+}
+
+func TestPaymentSpendRolledBackWhenLaterStepFails(t *testing.T) {
+	h, _, db := newTestHandler(t, map[string]string{"FEATURE_FLAGS": "spend_rollback"})
+	accountID := newID("acc")
+	failMonthlySpends(t, h, accountID)
+
+	event := &kafka.PaymentInitiatedEvent{
+		EventType:     kafka.PaymentInitiated,
+		PaymentID:     newID("pay"),
+		FromAccountID: accountID,
+		ToAccountID:   newID("acc"),
+		Amount:        100,
+		Currency:      "USD",
+	}
+	if err := h.HandlePaymentEvent(context.Background(), event); err == nil {
+		t.Fatal("expected the monthly spend to fail")
+	}
+	if used := limitUsed(t, db, accountID, "DAILY"); used != 0 {
+		t.Errorf("DAILY used = %.2f after the failed event, want the spend released", used)
+	}
+
+	// The redelivered event is spent once
+	repairMonthlySpends(t, db, accountID)
+	if err := h.HandlePaymentEvent(context.Background(), event); err != nil {
+		t.Fatalf("HandlePaymentEvent (redelivered): %v", err)
+	}
+	for _, limitType := range []string{"DAILY", "MONTHLY"} {
+		if used := limitUsed(t, db, accountID, limitType); used != 100 {
+			t.Errorf("%s used = %.2f after redelivery, want 100", limitType, used)
+		}
+	}
+}
+
+func TestPaymentSpendKeptWithoutRollback(t *testing.T) {
+	h, _, db := newTestHandler(t, map[string]string{"FEATURE_FLAGS": ""})
+	accountID := newID("acc")
+	failMonthlySpends(t, h, accountID)
+
+	event := &kafka.PaymentInitiatedEvent{
+		EventType:     kafka.PaymentInitiated,
+		PaymentID:     newID("pay"),
+		FromAccountID: accountID,
+		ToAccountID:   newID("acc"),
+		Amount:        100,
+		Currency:      "USD",
+	}
+	if err := h.HandlePaymentEvent(context.Background(), event); err == nil {
+		t.Fatal("expected the monthly spend to fail")
+	}
+
+	// The claim is kept, so the redelivered event doesn't spend the daily limit again
+	repairMonthlySpends(t, db, accountID)
+	if err := h.HandlePaymentEvent(context.Background(), event); err != nil {
+		t.Fatalf("HandlePaymentEvent (redelivered): %v", err)
+	}
+	if used := limitUsed(t, db, accountID, "DAILY"); used != 100 {
+		t.Errorf("DAILY used = %.2f, want the first spend kept once", used)
+	}
+}
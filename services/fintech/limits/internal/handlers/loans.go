@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/otel"
+	"fintech/limits-service/pkg/respond"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// RecomputeLoanRequest carries corrected scoring inputs; omitted inputs keep their previous values
+type RecomputeLoanRequest struct {
+	UnderwriterID    string `json:"underwriterId"`
+	AccountAgeDays   *int   `json:"accountAgeDays,omitempty"`
+	PreviousPayments *int   `json:"previousPayments,omitempty"`
+	Reason           string `json:"reason,omitempty"`
+}
+
+// RecomputeLoanResponse represents the response for a recomputed loan decision
+type RecomputeLoanResponse struct {
+	Decision   domain.LoanDecision `json:"decision"`
+	AuditEntry domain.AuditEntry   `json:"auditEntry"`
+}
+
+// RecomputeLoan handles POST /loans/{applicationId}/recompute
+func (h *LimitsHandler) RecomputeLoan(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "RecomputeLoan")
+	defer span.End()
+
+	applicationID := mux.Vars(r)["applicationId"]
+
+	var req RecomputeLoanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logrus.WithError(err).Error("Failed to decode recompute request")
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("application_id", applicationID),
+		otel.Attribute("underwriter_id", req.UnderwriterID),
+	)
+
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	previous, err := h.loans.FindLatest(ctx, applicationID)
+	if errors.Is(err, domain.ErrDecisionNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, "Loan application not found")
+		return
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("application_id", applicationID).Error("Failed to load loan decision")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	accountAgeDays, previousPayments := previous.AccountAgeDays, previous.PreviousPayments
+	if req.AccountAgeDays != nil {
+		accountAgeDays = *req.AccountAgeDays
+	}
+	if req.PreviousPayments != nil {
+		previousPayments = *req.PreviousPayments
+	}
+
+	scoringResult := h.scoringSvc.EvaluateScore(previous.AccountID, previous.RequestedAmount, accountAgeDays, previousPayments)
+	decision := previous.Recompute(scoringResult, accountAgeDays, previousPayments, req.UnderwriterID)
+
+	if err := h.loans.Save(ctx, decision); err != nil {
+		logrus.WithError(err).WithField("application_id", applicationID).Error("Failed to save recomputed loan decision")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	auditEntry := h.auditSvc.LogAction(
+		"LoanDecisionOverride",
+		previous.AccountID,
+		req.UnderwriterID,
+		"RECOMPUTE",
+		"loan",
+		fmt.Sprintf("Recomputed application %s as v%d (from v%d): account age %d->%d days, payments %d->%d, score %d->%d, approved %v->%v. %s",
+			applicationID, decision.Version, previous.Version,
+			previous.AccountAgeDays, accountAgeDays, previous.PreviousPayments, previousPayments,
+			previous.Result.Score, scoringResult.Score, previous.Result.Approved, scoringResult.Approved, req.Reason),
+		r.RemoteAddr,
+		r.Header.Get("User-Agent"),
+		"WARN",
+	)
+
+	// Overrides are critical: persist synchronously rather than batching
+	if err := h.auditWriter.WriteSync(ctx, auditEntry); err != nil {
+		logrus.WithError(err).WithField("application_id", applicationID).Error("Failed to write override audit entry")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"application_id": applicationID,
+		"decision_id":    decision.ID,
+		"version":        decision.Version,
+		"underwriter_id": req.UnderwriterID,
+		"audit_entry":    auditEntry.ID,
+	}).Info("Loan decision recomputed")
+
+	if err := respond.JSON(ctx, w, http.StatusCreated, RecomputeLoanResponse{
+		Decision:   *decision,
+		AuditEntry: *auditEntry,
+	}); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,258 @@
+//go:build integration
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fintech/limits-service/pkg/accounts"
+	"fintech/limits-service/pkg/metrics"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// applyForLoan submits a loan application in currency, or the default when empty, and returns its ID
+func applyForLoan(t *testing.T, h *LimitsHandler, accountID string, amount float64, currency string) string {
+	t.Helper()
+
+	body, _ := json.Marshal(LoanApplicationRequest{AccountID: accountID, UserID: "user-1", Amount: amount, Currency: currency})
+	rec := httptest.NewRecorder()
+	h.ApplyForLoan(rec, httptest.NewRequest(http.MethodPost, "/loans/apply", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ApplyForLoan status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response LoanApplicationResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode loan application response: %v", err)
+	}
+	return response.ApplicationID
+}
+
+// recomputeLoan recomputes a loan application with req, returning the response
+func recomputeLoan(t *testing.T, h *LimitsHandler, applicationID string, req RecomputeLoanRequest) RecomputeLoanResponse {
+	t.Helper()
+
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest(http.MethodPost, "/loans/"+applicationID+"/recompute", bytes.NewReader(body))
+	httpReq = mux.SetURLVars(httpReq, map[string]string{"applicationId": applicationID})
+	rec := httptest.NewRecorder()
+	h.RecomputeLoan(rec, httpReq)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("RecomputeLoan status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response RecomputeLoanResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode recompute response: %v", err)
+	}
+	return response
+}
+
+func TestRecomputeLoanLinksToOriginalAndIsAudited(t *testing.T) {
+	h, _, db := newTestHandler(t, nil)
+	ctx := context.Background()
+	accountID := newID("acc")
+
+	applicationID := applyForLoan(t, h, accountID, 5000, "")
+	original, err := h.loans.FindLatest(ctx, applicationID)
+	if err != nil {
+		t.Fatalf("FindLatest: %v", err)
+	}
+
+	accountAge, payments := 800, 60
+	response := recomputeLoan(t, h, applicationID, RecomputeLoanRequest{
+		UnderwriterID:    "underwriter-7",
+		AccountAgeDays:   &accountAge,
+		PreviousPayments: &payments,
+		Reason:           "verified account age",
+	})
+
+	decision := response.Decision
+	if decision.OriginalDecisionID != original.ID {
+		t.Errorf("original decision = %q, want %q", decision.OriginalDecisionID, original.ID)
+	}
+	if decision.Version != original.Version+1 || decision.ApplicationID != applicationID {
+		t.Errorf("decision is v%d of %s, want v%d of %s", decision.Version, decision.ApplicationID, original.Version+1, applicationID)
+	}
+	if decision.OverriddenBy != "underwriter-7" || decision.AccountAgeDays != accountAge || decision.PreviousPayments != payments {
+		t.Errorf("decision = %+v, want the underwriter's inputs", decision)
+	}
+
+	latest, err := h.loans.FindLatest(ctx, applicationID)
+	if err != nil {
+		t.Fatalf("FindLatest: %v", err)
+	}
+	if latest.ID != decision.ID || latest.OriginalDecisionID != original.ID {
+		t.Errorf("latest decision = %+v, want the recomputed one linked to %s", latest, original.ID)
+	}
+
+	// The override is audited synchronously, before the response
+	var eventType, userID, action string
+	err = db.QueryRow(ctx, `SELECT event_type, user_id, action FROM audit_log WHERE id = $1`, response.AuditEntry.ID).
+		Scan(&eventType, &userID, &action)
+	if err != nil {
+		t.Fatalf("failed to read override audit entry: %v", err)
+	}
+	if eventType != "LoanDecisionOverride" || userID != "underwriter-7" || action != "RECOMPUTE" {
+		t.Errorf("audit entry = %s by %s (%s), want LoanDecisionOverride by underwriter-7 (RECOMPUTE)", eventType, userID, action)
+	}
+
+	// A second recompute still links to the original, not to the first recompute
+	again := recomputeLoan(t, h, applicationID, RecomputeLoanRequest{UnderwriterID: "underwriter-8"})
+	if again.Decision.OriginalDecisionID != original.ID || again.Decision.Version != original.Version+2 {
+		t.Errorf("second recompute = v%d linked to %q, want v%d linked to %q",
+			again.Decision.Version, again.Decision.OriginalDecisionID, original.Version+2, original.ID)
+	}
+	if again.Decision.AccountAgeDays != accountAge {
+		t.Errorf("second recompute account age = %d, want the previous override's %d", again.Decision.AccountAgeDays, accountAge)
+	}
+}
+
+func TestRecomputeLoanUnknownApplication(t *testing.T) {
+	h, _, _ := newTestHandler(t, nil)
+
+	body, _ := json.Marshal(RecomputeLoanRequest{UnderwriterID: "underwriter-7"})
+	req := httptest.NewRequest(http.MethodPost, "/loans/missing/recompute", bytes.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"applicationId": newID("app")})
+	rec := httptest.NewRecorder()
+	h.RecomputeLoan(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestApplyForLoanCurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency string
+		want     string
+	}{
+		{"requested currency", "eur", "EUR"},
+		{"configured default", "", "GBP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A high base score approves the application, so the loan limit is created
+			h, _, _ := newTestHandler(t, map[string]string{"SCORING_BASE_SCORE": "800", "DEFAULT_LOAN_CURRENCY": "GBP"})
+			accountID := newID("acc")
+
+			applicationID := applyForLoan(t, h, accountID, 500, tt.currency)
+
+			decision, err := h.loans.FindLatest(context.Background(), applicationID)
+			if err != nil {
+				t.Fatalf("FindLatest: %v", err)
+			}
+			if decision.Currency != tt.want {
+				t.Errorf("decision currency = %s, want %s", decision.Currency, tt.want)
+			}
+			if !decision.Result.Approved {
+				t.Fatalf("application declined: %s", decision.Result.Reason)
+			}
+			limit, ok := currentLimitRows(t, h, accountID)["MONTHLY"]
+			if !ok {
+				t.Fatal("no loan limit created")
+			}
+			if limit.Currency != tt.want {
+				t.Errorf("loan limit currency = %s, want %s", limit.Currency, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyForLoanRejectsInvalidCurrency(t *testing.T) {
+	h, _, _ := newTestHandler(t, nil)
+
+	body, _ := json.Marshal(LoanApplicationRequest{AccountID: newID("acc"), Amount: 500, Currency: "DOLLARS"})
+	rec := httptest.NewRecorder()
+	h.ApplyForLoan(rec, httptest.NewRequest(http.MethodPost, "/loans/apply", bytes.NewReader(body)))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestApplyForLoanCountsDecisionByAmountBucket(t *testing.T) {
+	tests := []struct {
+		buckets string
+		amount  float64
+		want    string
+	}{
+		{"1000,5000,10000", 500, "<1000"},
+		{"100,1000", 500, "100-1000"},
+		{"100,250", 500, "250+"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.buckets, func(t *testing.T) {
+			// A high base score grades the application B and approves it
+			h, _, _ := newTestHandler(t, map[string]string{"SCORING_BASE_SCORE": "800", "LOAN_AMOUNT_BUCKETS": tt.buckets})
+			counter := metrics.LoanDecisions.WithLabelValues("B", "true", tt.want)
+			before := testutil.ToFloat64(counter)
+
+			applyForLoan(t, h, newID("acc"), tt.amount, "")
+
+			if got := testutil.ToFloat64(counter); got != before+1 {
+				t.Errorf("loan_decisions_total{amount_bucket=%q} = %v, want %v", tt.want, got, before+1)
+			}
+		})
+	}
+}
+
+func TestApplyForLoanWhenAccountDataUnavailable(t *testing.T) {
+	tests := []struct {
+		mode         string
+		wantApproved bool
+		wantReason   string
+		wantSeverity string
+	}{
+		{"decline", false, "Account data unavailable - application declined", "ERROR"},
+		{"conservative", true, "conservative assumptions applied", "WARN"},
+	}
+
+	accountsService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer accountsService.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			// A base score high enough to approve even a brand-new account without payments
+			h, auditWriter, db := newTestHandler(t, map[string]string{"SCORING_BASE_SCORE": "800", "SCORING_ON_MISSING_DATA": tt.mode})
+			h.SetAccountsClient(accounts.NewClient(accountsService.URL, time.Second))
+
+			applicationID := applyForLoan(t, h, newID("acc"), 500, "")
+
+			decision, err := h.loans.FindLatest(context.Background(), applicationID)
+			if err != nil {
+				t.Fatalf("FindLatest: %v", err)
+			}
+			if decision.Result.Approved != tt.wantApproved || !strings.Contains(decision.Result.Reason, tt.wantReason) {
+				t.Errorf("decision approved=%v reason=%q, want approved=%v with %q", decision.Result.Approved, decision.Result.Reason, tt.wantApproved, tt.wantReason)
+			}
+			if decision.AccountAgeDays != 0 || decision.PreviousPayments != 0 {
+				t.Errorf("decision inputs = %d days, %d payments, want none", decision.AccountAgeDays, decision.PreviousPayments)
+			}
+
+			if err := auditWriter.Flush(context.Background()); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+			var severity string
+			if err := db.QueryRow(context.Background(), "SELECT severity FROM audit_log WHERE id = $1", applicationID).Scan(&severity); err != nil {
+				t.Fatalf("failed to read application audit entry: %v", err)
+			}
+			if severity != tt.wantSeverity {
+				t.Errorf("audit severity = %s, want %s", severity, tt.wantSeverity)
+			}
+		})
+	}
+}
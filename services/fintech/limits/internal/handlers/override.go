@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/otel"
+	"fintech/limits-service/pkg/respond"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OverrideLimitRequest represents a temporary increase of an account's current limit
+type OverrideLimitRequest struct {
+	AccountID string    `json:"accountId"`
+	LimitType string    `json:"limitType"`
+	Amount    float64   `json:"amount"`
+	Currency  string    `json:"currency"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	AgentID   string    `json:"agentId"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// OverrideLimitResponse represents the limit after a temporary increase
+type OverrideLimitResponse struct {
+	Limit      *domain.Limit     `json:"limit"`
+	Remaining  float64           `json:"remaining"`
+	AuditEntry domain.AuditEntry `json:"auditEntry"`
+}
+
+// OverrideLimit handles POST /limits/override, letting a support agent raise an account's current
+// limit until expiresAt, e.g. for a one-day increase after verification
+func (h *LimitsHandler) OverrideLimit(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "OverrideLimit")
+	defer span.End()
+
+	var req OverrideLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logrus.WithError(err).Error("Failed to decode override request")
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid request body")
+		return
+	}
+	req.Currency = normalizeCurrency(req.Currency)
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("account_id", req.AccountID),
+		otel.Attribute("limit_type", req.LimitType),
+		otel.Attribute("amount", req.Amount),
+	)
+
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+	limitType := domain.LimitType(req.LimitType)
+
+	checkCtx, cancel := context.WithTimeout(ctx, h.config.LimitCheckTimeout)
+	defer cancel()
+
+	limit, err := h.repo.GrantTemporaryIncrease(checkCtx, req.AccountID, limitType, req.Amount, req.ExpiresAt, h.getDefaultLimit(limitType), req.Currency)
+	if errors.Is(err, domain.ErrInvalidOverride) {
+		// The request was valid, but doesn't raise the account's stored limit
+		var errs fieldErrors
+		errs.add("amount", err.Error())
+		writeValidationErrors(w, errs)
+		return
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("account", req.AccountID).Error("Failed to grant temporary limit increase")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	auditEntry := h.auditSvc.LogAction(
+		"LimitOverride",
+		req.AccountID,
+		req.AgentID,
+		"OVERRIDE",
+		"limit",
+		fmt.Sprintf("Increased %s limit from %.2f to %.2f %s until %s. %s",
+			limitType, limit.Amount, *limit.OverrideAmount, limit.Currency, limit.OverrideExpiresAt.Format(time.RFC3339), req.Reason),
+		r.RemoteAddr,
+		r.Header.Get("User-Agent"),
+		"WARN",
+	)
+
+	// Overrides are critical: persist synchronously rather than batching
+	if err := h.auditWriter.WriteSync(ctx, auditEntry); err != nil {
+		logrus.WithError(err).WithField("account", req.AccountID).Error("Failed to write override audit entry")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"account_id": req.AccountID,
+		"limit_type": limitType,
+		"agent_id":   req.AgentID,
+		"amount":     *limit.OverrideAmount,
+		"expires_at": limit.OverrideExpiresAt,
+	}).Info("Temporary limit increase granted")
+
+	response := OverrideLimitResponse{Limit: limit, Remaining: limit.GetRemaining(), AuditEntry: *auditEntry}
+	if err := respond.JSON(ctx, w, http.StatusOK, response); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
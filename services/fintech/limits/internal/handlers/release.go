@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/otel"
+	"fintech/limits-service/pkg/respond"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ReleaseLimitRequest represents a request to return a spent amount to a limit
+type ReleaseLimitRequest struct {
+	AccountID string  `json:"accountId"`
+	LimitType string  `json:"limitType"`
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+}
+
+// ReleaseLimitResponse represents the limit after a release
+type ReleaseLimitResponse struct {
+	Limit     *domain.Limit `json:"limit"`
+	Remaining float64       `json:"remaining"`
+}
+
+// ReleaseLimit handles POST /limits/release, refunding an amount spent this period, e.g. for a
+// payment that failed downstream. Usage never drops below zero.
+func (h *LimitsHandler) ReleaseLimit(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "ReleaseLimit")
+	defer span.End()
+
+	var req ReleaseLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logrus.WithError(err).Error("Failed to decode release request")
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid request body")
+		return
+	}
+	req.Currency = normalizeCurrency(req.Currency)
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("account_id", req.AccountID),
+		otel.Attribute("limit_type", req.LimitType),
+		otel.Attribute("amount", req.Amount),
+	)
+
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+	limitType := domain.LimitType(req.LimitType)
+
+	checkCtx, cancel := context.WithTimeout(ctx, h.config.LimitCheckTimeout)
+	defer cancel()
+
+	limit, err := h.repo.Release(checkCtx, req.AccountID, limitType, req.Amount, req.Currency)
+	if err != nil {
+		logrus.WithError(err).WithField("account", req.AccountID).Error("Failed to release limit")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+	if limit == nil {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, "No limit for the current period")
+		return
+	}
+
+	response := ReleaseLimitResponse{Limit: limit, Remaining: limit.GetRemaining()}
+	if err := respond.JSON(ctx, w, http.StatusOK, response); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/otel"
+	"fintech/limits-service/pkg/respond"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHistoryPeriods = 12
+	maxHistoryPeriods     = 366
+)
+
+// LimitSummaryResponse represents the response for the summary and history endpoints
+type LimitSummaryResponse struct {
+	AccountID string                 `json:"accountId"`
+	Currency  string                 `json:"currency"`
+	Limits    []*domain.LimitSummary `json:"limits"`
+}
+
+// GetLimitSummary handles GET /limits/{accountId}/summary?currency=
+func (h *LimitsHandler) GetLimitSummary(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "GetLimitSummary")
+	defer span.End()
+
+	accountID := mux.Vars(r)["accountId"]
+	var errs fieldErrors
+	currency := errs.queryCurrency(r)
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("account_id", accountID),
+		otel.Attribute("currency", currency),
+	)
+
+	limits, err := h.repo.FindCurrentLimits(ctx, accountID, currency)
+	if err != nil {
+		logrus.WithError(err).WithField("account_id", accountID).Error("Failed to load limit summary")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	h.writeSummaries(ctx, w, accountID, currency, limits)
+}
+
+// GetLimitHistory handles GET /limits/{accountId}/history?currency=&periods=
+func (h *LimitsHandler) GetLimitHistory(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "GetLimitHistory")
+	defer span.End()
+
+	accountID := mux.Vars(r)["accountId"]
+	var errs fieldErrors
+	currency := errs.queryCurrency(r)
+	periods := errs.positiveInt("periods", r.URL.Query().Get("periods"), defaultHistoryPeriods)
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+	if periods > maxHistoryPeriods {
+		periods = maxHistoryPeriods
+	}
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("account_id", accountID),
+		otel.Attribute("currency", currency),
+		otel.Attribute("periods", periods),
+	)
+
+	limits, err := h.repo.FindLimitHistory(ctx, accountID, currency, periods)
+	if err != nil {
+		logrus.WithError(err).WithField("account_id", accountID).Error("Failed to load limit history")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	h.writeSummaries(ctx, w, accountID, currency, limits)
+}
+
+// writeSummaries reports limits in their own currency when one was requested,
+// otherwise aggregated per period in the base currency
+func (h *LimitsHandler) writeSummaries(ctx context.Context, w http.ResponseWriter, accountID, currency string, limits []*domain.Limit) {
+	response := LimitSummaryResponse{AccountID: accountID, Currency: currency}
+
+	if currency != "" {
+		response.Limits = make([]*domain.LimitSummary, 0, len(limits))
+		for _, limit := range limits {
+			response.Limits = append(response.Limits, domain.NewLimitSummary(limit))
+		}
+	} else {
+		response.Currency = h.config.FXBaseCurrency
+		summaries, err := h.repo.AggregateLimits(ctx, limits, h.config.FXBaseCurrency)
+		if err != nil {
+			logrus.WithError(err).WithField("account_id", accountID).Error("Failed to aggregate limits")
+			writeError(w, http.StatusServiceUnavailable, ErrCodeUnavailable, "Currency conversion unavailable")
+			return
+		}
+		response.Limits = summaries
+	}
+
+	if err := respond.JSON(ctx, w, http.StatusOK, response); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
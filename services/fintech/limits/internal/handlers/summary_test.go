@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fintech/limits-service/internal/config"
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/internal/infrastructure"
+	"fintech/limits-service/pkg/fx"
+)
+
+// newSummaryHandler returns a handler that converts at 0.5 EUR per USD, the base currency
+func newSummaryHandler() *LimitsHandler {
+	converter := fx.NewConverter(fx.NewStaticRateProvider("USD", map[string]float64{"EUR": 0.5}))
+	return &LimitsHandler{
+		repo:   infrastructure.NewLimitRepository(nil, converter),
+		config: &config.Config{FXBaseCurrency: "USD"},
+	}
+}
+
+func summaryLimit(limitType domain.LimitType, amount, used float64, currency string, periodStart time.Time) *domain.Limit {
+	return &domain.Limit{
+		AccountID:   "acc-1",
+		Type:        limitType,
+		Amount:      amount,
+		Used:        used,
+		Currency:    currency,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodStart.Add(24 * time.Hour),
+	}
+}
+
+func writeTestSummaries(t *testing.T, h *LimitsHandler, currency string, limits []*domain.Limit) LimitSummaryResponse {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.writeSummaries(context.Background(), rec, "acc-1", currency, limits)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response LimitSummaryResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode summary: %v", err)
+	}
+	return response
+}
+
+func TestLimitSummaryForCurrency(t *testing.T) {
+	today := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	limits := []*domain.Limit{summaryLimit(domain.DailyLimit, 500, 50, "EUR", today)}
+
+	response := writeTestSummaries(t, newSummaryHandler(), "EUR", limits)
+
+	if response.Currency != "EUR" || len(response.Limits) != 1 {
+		t.Fatalf("summary = %+v, want one EUR limit", response)
+	}
+	summary := response.Limits[0]
+	if summary.Aggregated || summary.Currency != "EUR" || summary.Amount != 500 || summary.Used != 50 || summary.Remaining != 450 {
+		t.Errorf("summary = %+v, want 500 EUR with 50 used in its own currency", summary)
+	}
+}
+
+func TestLimitSummaryAggregated(t *testing.T) {
+	today := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	month := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	limits := []*domain.Limit{
+		summaryLimit(domain.DailyLimit, 1000, 100, "USD", today),
+		summaryLimit(domain.DailyLimit, 500, 50, "EUR", today),
+		summaryLimit(domain.MonthlyLimit, 5000, 300, "USD", month),
+	}
+
+	response := writeTestSummaries(t, newSummaryHandler(), "", limits)
+
+	if response.Currency != "USD" || len(response.Limits) != 2 {
+		t.Fatalf("summary = %+v, want one USD summary per limit type", response)
+	}
+
+	daily := response.Limits[0]
+	if daily.Type != domain.DailyLimit || !daily.Aggregated || daily.Currency != "USD" {
+		t.Fatalf("daily summary = %+v, want aggregated in USD", daily)
+	}
+	// 500 EUR is 1000 USD at 0.5 EUR per USD
+	if daily.Amount != 2000 || daily.Used != 200 || daily.Remaining != 1800 {
+		t.Errorf("daily summary = %.2f used of %.2f, %.2f remaining; want 200 of 2000, 1800 remaining", daily.Used, daily.Amount, daily.Remaining)
+	}
+
+	monthly := response.Limits[1]
+	if monthly.Type != domain.MonthlyLimit || monthly.Amount != 5000 || monthly.Used != 300 {
+		t.Errorf("monthly summary = %+v, want 300 used of 5000 USD", monthly)
+	}
+}
+
+func TestLimitSummaryAggregatedWithoutRate(t *testing.T) {
+	today := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	limits := []*domain.Limit{summaryLimit(domain.DailyLimit, 500, 50, "JPY", today)}
+
+	rec := httptest.NewRecorder()
+	newSummaryHandler().writeSummaries(context.Background(), rec, "acc-1", "", limits)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestQueryCurrency(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+		ok    bool
+	}{
+		{"", "", true},
+		{"?currency=eur", "EUR", true},
+		{"?currency=EURO", "EURO", false},
+		{"?currency=EU1", "EU1", false},
+	}
+
+	for _, tt := range tests {
+		var errs fieldErrors
+		got := errs.queryCurrency(httptest.NewRequest(http.MethodGet, "/limits/acc-1/summary"+tt.query, nil))
+		if got != tt.want || (len(errs) == 0) != tt.ok {
+			t.Errorf("queryCurrency(%q) = %q with errors %v; want %q, valid %v", tt.query, got, errs, tt.want, tt.ok)
+		}
+		if !tt.ok && (len(errs) != 1 || errs[0].Field != "currency" || errs[0].Code != ErrCodeInvalidCurrency) {
+			t.Errorf("queryCurrency(%q) errors = %v, want one %s for currency", tt.query, errs, ErrCodeInvalidCurrency)
+		}
+	}
+}
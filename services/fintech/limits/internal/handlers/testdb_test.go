@@ -0,0 +1,111 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"fintech/limits-service/internal/config"
+	"fintech/limits-service/internal/infrastructure"
+	"fintech/limits-service/pkg/database"
+	"fintech/limits-service/pkg/fx"
+
+	"github.com/google/uuid"
+)
+
+// migrationLock serializes RunMigrations across test packages sharing TEST_DATABASE_URL
+const migrationLock = 741001
+
+// newTestDB connects to TEST_DATABASE_URL and migrates it, skipping the test when it isn't set.
+// Tests share the database, so each one works on its own accounts and payments.
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := database.NewConnection(url)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	ctx := context.Background()
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("failed to acquire connection: %v", err)
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLock); err != nil {
+		t.Fatalf("failed to take migration lock: %v", err)
+	}
+	defer conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", migrationLock)
+
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	return db
+}
+
+// newTestHandler returns a handler over the test database, configured from the environment plus
+// env, and its audit writer. Audit entries are buffered until the writer is flushed.
+func newTestHandler(t *testing.T, env map[string]string) (*LimitsHandler, *infrastructure.AuditWriter, *database.DB) {
+	t.Helper()
+
+	db := newTestDB(t)
+
+	t.Setenv("DATABASE_URL", os.Getenv("TEST_DATABASE_URL"))
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	rates := fx.NewStaticRateProvider(cfg.FXBaseCurrency, cfg.FXRates)
+	auditWriter := infrastructure.NewAuditWriter(infrastructure.NewAuditRepository(db), 100, cfg.AuditFlushInterval)
+	h := NewLimitsHandler(db, fx.NewConverter(rates), auditWriter)
+	h.SetConfig(cfg)
+
+	return h, auditWriter, db
+}
+
+// newID returns a fresh account or payment ID, so tests don't see each other's rows
+func newID(prefix string) string {
+	return prefix + "-" + uuid.New().String()
+}
+
+// limitUsed returns the used amount of the account's current limit of limitType
+func limitUsed(t *testing.T, db *database.DB, accountID, limitType string) float64 {
+	t.Helper()
+
+	var used float64
+	err := db.QueryRow(context.Background(), `
+		SELECT used FROM limits
+		WHERE account_id = $1 AND type = $2 AND period_start <= NOW() AND period_end > NOW()
+	`, accountID, limitType).Scan(&used)
+	if err != nil {
+		t.Fatalf("failed to read %s limit of %s: %v", limitType, accountID, err)
+	}
+	return used
+}
+
+// auditCount returns the number of audit entries of eventType written for the account
+func auditCount(t *testing.T, db *database.DB, accountID, eventType string) int {
+	t.Helper()
+
+	var count int
+	err := db.QueryRow(context.Background(), `
+		SELECT COUNT(*) FROM audit_log WHERE account_id = $1 AND event_type = $2
+	`, accountID, eventType).Scan(&count)
+	if err != nil {
+		t.Fatalf("failed to count audit entries: %v", err)
+	}
+	return count
+}
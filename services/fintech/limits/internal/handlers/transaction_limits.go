@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/otel"
+	"fintech/limits-service/pkg/respond"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// SetTransactionLimitRequest represents a per-transaction maximum for an account
+type SetTransactionLimitRequest struct {
+	MaxAmount float64 `json:"maxAmount"`
+	Currency  string  `json:"currency,omitempty"` // Defaults to FX_BASE_CURRENCY
+	AgentID   string  `json:"agentId"`
+}
+
+// checkPerTransactionLimit checks a single payment against the account's per-transaction maximum,
+// or MAX_SINGLE_TXN for accounts without one. It runs before any limit is spent and returns the
+// denied result, or nil if the payment is within the maximum.
+func (h *LimitsHandler) checkPerTransactionLimit(ctx context.Context, accountID string, amount float64, currency string) (*domain.LimitCheckResult, error) {
+	return h.repo.CheckPerTransactionLimit(ctx, accountID, amount, currency, h.config.MaxSingleTxn, h.config.FXBaseCurrency)
+}
+
+// SetTransactionLimit handles PUT /limits/{accountId}/transaction-max, replacing the account's
+// per-transaction maximum
+func (h *LimitsHandler) SetTransactionLimit(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "SetTransactionLimit")
+	defer span.End()
+
+	accountID := mux.Vars(r)["accountId"]
+
+	var req SetTransactionLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logrus.WithError(err).Error("Failed to decode transaction limit request")
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid request body")
+		return
+	}
+	req.Currency = normalizeCurrency(req.Currency)
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("account_id", accountID),
+		otel.Attribute("max_amount", req.MaxAmount),
+	)
+
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = h.config.FXBaseCurrency
+	}
+	limit, err := domain.NewTransactionLimit(accountID, req.MaxAmount, currency)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, h.config.LimitCheckTimeout)
+	defer cancel()
+
+	if err := h.repo.SetTransactionLimit(checkCtx, limit); err != nil {
+		logrus.WithError(err).WithField("account", accountID).Error("Failed to set transaction limit")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	auditEntry := h.auditSvc.LogAction(
+		"TransactionLimitSet",
+		accountID,
+		req.AgentID,
+		"UPDATE",
+		"transaction_limit",
+		fmt.Sprintf("Set per-transaction maximum to %.2f %s", limit.MaxAmount, limit.Currency),
+		r.RemoteAddr,
+		r.Header.Get("User-Agent"),
+		"INFO",
+	)
+	h.auditWriter.Write(auditEntry)
+
+	logrus.WithFields(logrus.Fields{
+		"account_id": accountID,
+		"agent_id":   req.AgentID,
+		"max_amount": limit.MaxAmount,
+		"currency":   limit.Currency,
+	}).Info("Per-transaction maximum set")
+
+	if err := respond.JSON(ctx, w, http.StatusOK, limit); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
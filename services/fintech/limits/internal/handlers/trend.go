@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/otel"
+	"fintech/limits-service/pkg/respond"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultTrendDays = 30
+	maxTrendDays     = 366
+)
+
+// LimitTrendResponse represents the response for the trend endpoint
+type LimitTrendResponse struct {
+	AccountID string              `json:"accountId"`
+	Type      domain.LimitType    `json:"type"`
+	Currency  string              `json:"currency"`
+	Points    []domain.UsagePoint `json:"points"`
+}
+
+// GetLimitTrend handles GET /limits/{accountId}/trend?type=DAILY&days=&currency=, returning one
+// point per day up to and including today
+func (h *LimitsHandler) GetLimitTrend(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "GetLimitTrend")
+	defer span.End()
+
+	accountID := mux.Vars(r)["accountId"]
+	var errs fieldErrors
+	currency := errs.queryCurrency(r)
+	if t := r.URL.Query().Get("type"); t != "" {
+		errs.limitType("type", t, domain.DailyLimit)
+	}
+	days := errs.positiveInt("days", r.URL.Query().Get("days"), defaultTrendDays)
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+	if days > maxTrendDays {
+		days = maxTrendDays
+	}
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("account_id", accountID),
+		otel.Attribute("currency", currency),
+		otel.Attribute("days", days),
+	)
+
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -days)
+
+	limits, err := h.repo.FindLimitsInRange(ctx, accountID, domain.DailyLimit, currency, from, to)
+	if err != nil {
+		logrus.WithError(err).WithField("account_id", accountID).Error("Failed to load limit trend")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	// Like the summary, report a requested currency as is and otherwise aggregate in the base currency
+	response := LimitTrendResponse{AccountID: accountID, Type: domain.DailyLimit, Currency: currency}
+	var summaries []*domain.LimitSummary
+	if currency != "" {
+		for _, limit := range limits {
+			summaries = append(summaries, domain.NewLimitSummary(limit))
+		}
+	} else {
+		response.Currency = h.config.FXBaseCurrency
+		if summaries, err = h.repo.AggregateLimits(ctx, limits, h.config.FXBaseCurrency); err != nil {
+			logrus.WithError(err).WithField("account_id", accountID).Error("Failed to aggregate limits")
+			writeError(w, http.StatusServiceUnavailable, ErrCodeUnavailable, "Currency conversion unavailable")
+			return
+		}
+	}
+	response.Points = domain.BuildDailyTrend(summaries, from, days)
+
+	if err := respond.JSON(ctx, w, http.StatusOK, response); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,123 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fintech/limits-service/pkg/database"
+
+	"github.com/gorilla/mux"
+)
+
+// seedDailyLimit stores a USD daily limit row for the day daysAgo days before today
+func seedDailyLimit(t *testing.T, db *database.DB, accountID string, daysAgo int, amount, used float64) {
+	t.Helper()
+
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO limits (account_id, type, amount, used, currency, period_start, period_end)
+		VALUES ($1, 'DAILY', $2, $3, 'USD',
+			date_trunc('day', NOW() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' - make_interval(days => $4),
+			date_trunc('day', NOW() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' - make_interval(days => $4 - 1))
+	`, accountID, amount, used, daysAgo)
+	if err != nil {
+		t.Fatalf("failed to seed limit: %v", err)
+	}
+}
+
+func TestGetLimitTrendFillsGaps(t *testing.T) {
+	h, _, db := newTestHandler(t, nil)
+	accountID := newID("acc")
+	seedDailyLimit(t, db, accountID, 3, 1000, 250)
+	seedDailyLimit(t, db, accountID, 1, 1000, 600)
+	seedDailyLimit(t, db, accountID, 10, 1000, 900) // Before the requested days
+
+	req := httptest.NewRequest(http.MethodGet, "/limits/"+accountID+"/trend?type=DAILY&days=5", nil)
+	req = mux.SetURLVars(req, map[string]string{"accountId": accountID})
+	rec := httptest.NewRecorder()
+	h.GetLimitTrend(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response LimitTrendResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Points) != 5 {
+		t.Fatalf("got %d points, want 5: %+v", len(response.Points), response.Points)
+	}
+
+	today := time.Now().UTC()
+	wantUsed := map[int]float64{3: 250, 1: 600}
+	for i, point := range response.Points {
+		daysAgo := len(response.Points) - 1 - i
+		if want := today.AddDate(0, 0, -daysAgo).Format("2006-01-02"); point.Date != want {
+			t.Errorf("point %d date = %s, want %s", i, point.Date, want)
+		}
+		used, seeded := wantUsed[daysAgo]
+		var amount float64
+		if seeded {
+			amount = 1000
+		}
+		if point.Used != used || point.Amount != amount || (seeded && point.Utilization != used/amount) {
+			t.Errorf("point %s = %+v, want %.2f of %.2f used", point.Date, point, used, amount)
+		}
+	}
+}
+
+func TestGetLimitTrendRejectsInvalidRequests(t *testing.T) {
+	h, _, _ := newTestHandler(t, nil)
+
+	for _, query := range []string{"type=MONTHLY", "days=0", "days=many"} {
+		req := httptest.NewRequest(http.MethodGet, "/limits/acc-1/trend?"+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"accountId": "acc-1"})
+		rec := httptest.NewRecorder()
+		h.GetLimitTrend(rec, req)
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: status = %d, want %d", query, rec.Code, http.StatusUnprocessableEntity)
+		}
+	}
+}
+
+func TestPruneExpiredLimitsKeepsHistory(t *testing.T) {
+	h, _, db := newTestHandler(t, nil)
+	ctx := context.Background()
+	accountID := newID("acc")
+	seedDailyLimit(t, db, accountID, 3, 1000, 250)
+	seedDailyLimit(t, db, accountID, 400, 1000, 900) // Beyond the 366 day retention
+
+	if err := h.PruneExpiredLimits(ctx); err != nil {
+		t.Fatalf("PruneExpiredLimits: %v", err)
+	}
+
+	// The trend still reports the past day's usage
+	req := httptest.NewRequest(http.MethodGet, "/limits/"+accountID+"/trend?type=DAILY&days=5", nil)
+	req = mux.SetURLVars(req, map[string]string{"accountId": accountID})
+	rec := httptest.NewRecorder()
+	h.GetLimitTrend(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var response LimitTrendResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Points) != 5 || response.Points[1].Used != 250 {
+		t.Errorf("trend after pruning = %+v, want 250 used three days ago", response.Points)
+	}
+
+	// History keeps the retained period and loses only the one beyond the horizon
+	history, err := h.repo.FindLimitHistory(ctx, accountID, "", maxHistoryPeriods)
+	if err != nil {
+		t.Fatalf("FindLimitHistory: %v", err)
+	}
+	if len(history) != 1 || history[0].Used != 250 {
+		t.Errorf("history after pruning = %+v, want only the period with 250 used", history)
+	}
+}
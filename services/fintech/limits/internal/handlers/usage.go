@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/otel"
+	"fintech/limits-service/pkg/respond"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// LimitUsageResponse reports a limit's current usage in the shape of a limit check result
+type LimitUsageResponse struct {
+	*domain.LimitCheckResult
+	Currency  string    `json:"currency"`
+	PeriodEnd time.Time `json:"period_end"`
+	Exists    bool      `json:"exists"` // False if nothing was spent yet and the default is reported
+}
+
+// GetLimitUsage handles GET /limits/{accountId}?type=DAILY, reporting how much of the current
+// limit is used and remaining without spending from it. An account with no limit row for the
+// period yet gets the configured default, fully remaining; no row is created.
+func (h *LimitsHandler) GetLimitUsage(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "GetLimitUsage")
+	defer span.End()
+
+	accountID := mux.Vars(r)["accountId"]
+	limitType := domain.LimitType(r.URL.Query().Get("type"))
+	var errs fieldErrors
+	if errs.limitType("type", string(limitType), domain.DailyLimit, domain.MonthlyLimit); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("account_id", accountID),
+		otel.Attribute("limit_type", string(limitType)),
+	)
+
+	limit, err := h.repo.GetCurrentLimit(ctx, accountID, limitType)
+	if err != nil {
+		logrus.WithError(err).WithField("account_id", accountID).Error("Failed to load current limit")
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	exists := limit != nil
+	if !exists {
+		limit, err = domain.NewLimit(accountID, limitType, h.getDefaultLimit(limitType), h.config.FXBaseCurrency)
+		if err != nil {
+			logrus.WithError(err).WithField("account_id", accountID).Error("Failed to build default limit")
+			writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+			return
+		}
+	}
+
+	response := LimitUsageResponse{
+		LimitCheckResult: domain.NewLimitCheckResult(limit.GetRemaining() > 0, limit, ""),
+		Currency:         limit.Currency,
+		PeriodEnd:        limit.PeriodEnd,
+		Exists:           exists,
+	}
+	if err := respond.JSON(ctx, w, http.StatusOK, response); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fintech/limits-service/internal/domain"
+)
+
+// FieldError describes why one request field is invalid. Code is one of the ErrorResponse codes,
+// e.g. AMOUNT_NOT_POSITIVE, or INVALID_REQUEST when none is more specific.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// fieldErrors collects every invalid field of a request, so that a client sees all its problems at once
+type fieldErrors []FieldError
+
+// add records that field is invalid
+func (e *fieldErrors) add(field, message string) {
+	e.addCode(field, ErrCodeInvalidRequest, message)
+}
+
+// addCode records that field is invalid for the reason code names
+func (e *fieldErrors) addCode(field, code, message string) {
+	*e = append(*e, FieldError{Field: field, Code: code, Message: message})
+}
+
+// required checks that value is not blank
+func (e *fieldErrors) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		e.add(field, "is required")
+	}
+}
+
+// amount checks that value is positive with at most two decimal places
+func (e *fieldErrors) amount(field string, value float64) {
+	switch {
+	case value <= 0:
+		e.addCode(field, ErrCodeAmountNotPositive, "must be positive")
+	case math.Abs(value*100-math.Round(value*100)) > 1e-6:
+		e.add(field, "must have at most 2 decimal places")
+	}
+}
+
+// currency checks that value, if set, is a 3-letter ISO 4217 code in upper case, as normalizeCurrency
+// leaves it
+func (e *fieldErrors) currency(field, value string) {
+	if value != "" && !isCurrencyCode(value) {
+		e.addCode(field, ErrCodeInvalidCurrency, "must be a 3-letter ISO currency code")
+	}
+}
+
+// limitType checks that value is one of the allowed limit types
+func (e *fieldErrors) limitType(field, value string, allowed ...domain.LimitType) {
+	names := make([]string, len(allowed))
+	for i, limitType := range allowed {
+		if value == string(limitType) {
+			return
+		}
+		names[i] = string(limitType)
+	}
+	e.addCode(field, ErrCodeInvalidLimitType, "must be one of "+strings.Join(names, ", "))
+}
+
+// positiveInt parses value as a positive integer, returning fallback if it is empty or invalid
+func (e *fieldErrors) positiveInt(field, value string, fallback int) int {
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		e.add(field, "must be a positive integer")
+		return fallback
+	}
+	return n
+}
+
+// notNegative checks that value, if set, is not negative
+func (e *fieldErrors) notNegative(field string, value *int) {
+	if value != nil && *value < 0 {
+		e.add(field, "must not be negative")
+	}
+}
+
+// queryCurrency reads the optional ?currency= filter, trimmed and upper-cased, checking it is a
+// 3-letter ISO code
+func (e *fieldErrors) queryCurrency(r *http.Request) string {
+	currency := normalizeCurrency(r.URL.Query().Get("currency"))
+	e.currency("currency", currency)
+	return currency
+}
+
+// String joins the errors into one message, e.g. for a batch item's error
+func (e fieldErrors) String() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Field + " " + fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// isCurrencyCode reports whether value is three upper-case ASCII letters
+func isCurrencyCode(value string) bool {
+	if len(value) != 3 {
+		return false
+	}
+	for _, c := range value {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeCurrency trims and upper-cases a requested currency before it is validated and stored,
+// since FX tolerances and per-currency limits match currencies case-sensitively
+func normalizeCurrency(value string) string {
+	return strings.ToUpper(strings.TrimSpace(value))
+}
+
+// writeValidationErrors rejects a request with 422, listing every invalid field
+func writeValidationErrors(w http.ResponseWriter, errs fieldErrors) {
+	writeErrorDetails(w, http.StatusUnprocessableEntity, ErrCodeValidationFailed, "Request validation failed", []FieldError(errs))
+}
+
+// validate checks the fields of a limit evaluation
+func (r EvaluateLimitRequest) validate() fieldErrors {
+	var errs fieldErrors
+	errs.required("accountId", r.AccountID)
+	errs.limitType("limitType", r.LimitType, domain.DailyLimit, domain.MonthlyLimit)
+	errs.amount("amount", r.Amount)
+	errs.currency("currency", r.Currency)
+	return errs
+}
+
+// validate checks the fields of a loan application; currency is optional and defaults to
+// DEFAULT_LOAN_CURRENCY
+func (r LoanApplicationRequest) validate() fieldErrors {
+	var errs fieldErrors
+	errs.required("accountId", r.AccountID)
+	errs.amount("amount", r.Amount)
+	errs.currency("currency", r.Currency)
+	return errs
+}
+
+// validate checks the fields of a limit release
+func (r ReleaseLimitRequest) validate() fieldErrors {
+	var errs fieldErrors
+	errs.required("accountId", r.AccountID)
+	errs.limitType("limitType", r.LimitType, domain.DailyLimit, domain.MonthlyLimit)
+	errs.amount("amount", r.Amount)
+	errs.currency("currency", r.Currency)
+	return errs
+}
+
+// validate checks the fields of a limit hold; ttlSeconds is optional and defaults to HOLD_TTL
+func (r CreateHoldRequest) validate() fieldErrors {
+	var errs fieldErrors
+	errs.required("accountId", r.AccountID)
+	errs.limitType("limitType", r.LimitType, domain.DailyLimit, domain.MonthlyLimit)
+	errs.amount("amount", r.Amount)
+	errs.currency("currency", r.Currency)
+	errs.notNegative("ttlSeconds", &r.TTLSeconds)
+	return errs
+}
+
+// validate checks the fields of a temporary limit increase
+func (r OverrideLimitRequest) validate() fieldErrors {
+	var errs fieldErrors
+	errs.required("accountId", r.AccountID)
+	errs.required("agentId", r.AgentID)
+	errs.limitType("limitType", r.LimitType, domain.DailyLimit, domain.MonthlyLimit)
+	errs.amount("amount", r.Amount)
+	errs.currency("currency", r.Currency)
+	switch {
+	case r.ExpiresAt.IsZero():
+		errs.add("expiresAt", "is required")
+	case !r.ExpiresAt.After(time.Now()):
+		errs.add("expiresAt", "must be in the future")
+	}
+	return errs
+}
+
+// validate checks the fields of an evaluation against every limit type
+func (r EvaluateAllRequest) validate() fieldErrors {
+	var errs fieldErrors
+	errs.required("accountId", r.AccountID)
+	errs.amount("amount", r.Amount)
+	errs.currency("currency", r.Currency)
+	return errs
+}
+
+// validate checks the fields of a per-transaction maximum; currency is optional and defaults to
+// FX_BASE_CURRENCY
+func (r SetTransactionLimitRequest) validate() fieldErrors {
+	var errs fieldErrors
+	errs.required("agentId", r.AgentID)
+	errs.amount("maxAmount", r.MaxAmount)
+	errs.currency("currency", r.Currency)
+	return errs
+}
+
+// validate checks the fields of a loan recompute; omitted scoring inputs keep their original values
+func (r RecomputeLoanRequest) validate() fieldErrors {
+	var errs fieldErrors
+	errs.required("underwriterId", r.UnderwriterID)
+	errs.notNegative("accountAgeDays", r.AccountAgeDays)
+	errs.notNegative("previousPayments", r.PreviousPayments)
+	return errs
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"fintech/limits-service/internal/domain"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Warmup pre-loads the current-period limits of the configured hot accounts, creating any that
+// don't exist yet in FX_BASE_CURRENCY as account created events do. It stops after
+// WarmupMaxAccounts accounts or WarmupTimeout, whichever comes first, so a long list can't hold
+// up startup; accounts left over are simply loaded on their first check.
+func (h *LimitsHandler) Warmup(ctx context.Context) {
+	accountIDs := h.config.WarmupAccountIDs
+	if len(accountIDs) == 0 {
+		return
+	}
+	if len(accountIDs) > h.config.WarmupMaxAccounts {
+		logrus.WithFields(logrus.Fields{
+			"accounts": len(accountIDs),
+			"max":      h.config.WarmupMaxAccounts,
+		}).Warn("Warmup account list truncated")
+		accountIDs = accountIDs[:h.config.WarmupMaxAccounts]
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.config.WarmupTimeout)
+	defer cancel()
+
+	start := time.Now()
+	warmed := 0
+	for _, accountID := range accountIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		if h.warmAccount(ctx, accountID) {
+			warmed++
+		}
+	}
+
+	log := logrus.WithFields(logrus.Fields{
+		"warmed":   warmed,
+		"accounts": len(accountIDs),
+		"duration": time.Since(start).String(),
+	})
+	if ctx.Err() != nil {
+		log.Warn("Limit warmup stopped at the timeout")
+		return
+	}
+	log.Info("Limit warmup completed")
+}
+
+// warmAccount loads both current-period limits of an account, reporting whether it succeeded
+func (h *LimitsHandler) warmAccount(ctx context.Context, accountID string) bool {
+	for _, limitType := range []domain.LimitType{domain.DailyLimit, domain.MonthlyLimit} {
+		if _, err := h.repo.GetOrCreateLimit(ctx, accountID, limitType, h.getDefaultLimit(limitType), h.config.FXBaseCurrency); err != nil {
+			if ctx.Err() == nil {
+				logrus.WithError(err).WithFields(logrus.Fields{
+					"account_id": accountID,
+					"limit_type": limitType,
+				}).Warn("Failed to warm up limit")
+			}
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWarmupLoadsConfiguredAccounts(t *testing.T) {
+	accountIDs := []string{newID("acc"), newID("acc")}
+	h, _, _ := newTestHandler(t, map[string]string{
+		"WARMUP_ACCOUNT_IDS":    strings.Join(accountIDs, ","),
+		"DEFAULT_DAILY_LIMIT":   "2000",
+		"DEFAULT_MONTHLY_LIMIT": "9000",
+	})
+
+	h.Warmup(context.Background())
+
+	for _, accountID := range accountIDs {
+		rows := currentLimitRows(t, h, accountID)
+		for limitType, amount := range map[string]float64{"DAILY": 2000, "MONTHLY": 9000} {
+			row, ok := rows[limitType]
+			if !ok {
+				t.Errorf("%s: no %s limit loaded", accountID, limitType)
+				continue
+			}
+			if row.Amount != amount || row.Currency != "USD" || row.Used != 0 {
+				t.Errorf("%s: %s limit = %+v, want %.0f USD unused", accountID, limitType, row, amount)
+			}
+		}
+	}
+}
+
+func TestWarmupStopsAtMaxAccounts(t *testing.T) {
+	accountIDs := []string{newID("acc"), newID("acc"), newID("acc")}
+	h, _, _ := newTestHandler(t, map[string]string{
+		"WARMUP_ACCOUNT_IDS":  strings.Join(accountIDs, ","),
+		"WARMUP_MAX_ACCOUNTS": "2",
+	})
+
+	h.Warmup(context.Background())
+
+	for i, accountID := range accountIDs {
+		loaded := len(currentLimitRows(t, h, accountID)) == 2
+		if want := i < 2; loaded != want {
+			t.Errorf("account %d loaded = %v, want %v", i+1, loaded, want)
+		}
+	}
+}
+
+func TestWarmupStopsAtTimeout(t *testing.T) {
+	accountID := newID("acc")
+	h, _, _ := newTestHandler(t, map[string]string{"WARMUP_ACCOUNT_IDS": accountID})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	h.Warmup(ctx)
+
+	if rows := currentLimitRows(t, h, accountID); len(rows) != 0 {
+		t.Errorf("loaded %v after the deadline, want nothing", rows)
+	}
+}
@@ -0,0 +1,162 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/database"
+
+	"github.com/sirupsen/logrus"
+)
+
+// auditColumns is the number of columns written per audit entry
+const auditColumns = 11
+
+// AuditRepository persists audit entries
+type AuditRepository struct {
+	db *database.DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *database.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// SaveBatch inserts entries with a single multi-row INSERT
+func (r *AuditRepository) SaveBatch(ctx context.Context, entries []*domain.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(entries))
+	args := make([]interface{}, 0, len(entries)*auditColumns)
+	for i, entry := range entries {
+		values := make([]string, auditColumns)
+		for j := range values {
+			values[j] = fmt.Sprintf("$%d", i*auditColumns+j+1)
+		}
+		placeholders = append(placeholders, "("+strings.Join(values, ", ")+")")
+		args = append(args,
+			entry.ID,
+			entry.EventType,
+			entry.AccountID,
+			entry.UserID,
+			entry.Action,
+			entry.Resource,
+			entry.Details,
+			entry.IPAddress,
+			entry.UserAgent,
+			entry.Severity,
+			entry.Timestamp,
+		)
+	}
+
+	query := `
+		INSERT INTO audit_log (id, event_type, account_id, user_id, action, resource, details, ip_address, user_agent, severity, timestamp)
+		VALUES ` + strings.Join(placeholders, ", ") + `
+		ON CONFLICT (id) DO NOTHING
+	`
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to save audit entries: %w", err)
+	}
+
+	return nil
+}
+
+// AuditWriter buffers audit entries and flushes them in batches, either when
+// batchSize entries have accumulated or every interval, whichever comes first
+type AuditWriter struct {
+	repo      *AuditRepository
+	batchSize int
+	interval  time.Duration
+
+	mu     sync.Mutex
+	buffer []*domain.AuditEntry
+	full   chan struct{}
+}
+
+// NewAuditWriter creates a new batching audit writer
+func NewAuditWriter(repo *AuditRepository, batchSize int, interval time.Duration) *AuditWriter {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	return &AuditWriter{
+		repo:      repo,
+		batchSize: batchSize,
+		interval:  interval,
+		buffer:    make([]*domain.AuditEntry, 0, batchSize),
+		full:      make(chan struct{}, 1),
+	}
+}
+
+// Write buffers an entry for the next batch flush
+func (w *AuditWriter) Write(entry *domain.AuditEntry) {
+	w.mu.Lock()
+	w.buffer = append(w.buffer, entry)
+	full := len(w.buffer) >= w.batchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// WriteSync persists an entry immediately, for critical events that must not be lost
+func (w *AuditWriter) WriteSync(ctx context.Context, entry *domain.AuditEntry) error {
+	return w.repo.SaveBatch(ctx, []*domain.AuditEntry{entry})
+}
+
+// Run flushes buffered entries by size or interval until ctx is cancelled
+func (w *AuditWriter) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.full:
+		}
+
+		if err := w.Flush(ctx); err != nil {
+			logrus.WithError(err).Error("Failed to flush audit entries")
+		}
+	}
+}
+
+// Flush writes all buffered entries; on failure they are kept for the next flush
+func (w *AuditWriter) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	batch := w.buffer
+	w.buffer = make([]*domain.AuditEntry, 0, w.batchSize)
+	w.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	if err := w.repo.SaveBatch(ctx, batch); err != nil {
+		w.mu.Lock()
+		w.buffer = append(batch, w.buffer...)
+		w.mu.Unlock()
+		return err
+	}
+
+	logrus.WithField("count", len(batch)).Debug("Audit entries flushed")
+	return nil
+}
+
+// Close flushes whatever is still buffered; call after Run has stopped
+func (w *AuditWriter) Close(ctx context.Context) error {
+	return w.Flush(ctx)
+}
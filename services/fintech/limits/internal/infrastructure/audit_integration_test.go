@@ -0,0 +1,122 @@
+//go:build integration
+
+package infrastructure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/database"
+)
+
+// writeAuditEntries buffers n entries for accountID
+func writeAuditEntries(writer *AuditWriter, accountID string, n int) {
+	audit := domain.NewAuditService()
+	for i := 0; i < n; i++ {
+		writer.Write(audit.LogAction("LimitEvaluation", accountID, "", "SPEND", "limit", "test entry", "", "", "INFO"))
+	}
+}
+
+// savedAuditEntries returns the number of audit entries persisted for accountID
+func savedAuditEntries(t *testing.T, db *database.DB, accountID string) int {
+	t.Helper()
+
+	var count int
+	if err := db.QueryRow(context.Background(), `SELECT COUNT(*) FROM audit_log WHERE account_id = $1`, accountID).Scan(&count); err != nil {
+		t.Fatalf("failed to count audit entries: %v", err)
+	}
+	return count
+}
+
+// waitForAuditEntries polls until want entries are persisted for accountID or timeout passes
+func waitForAuditEntries(t *testing.T, db *database.DB, accountID string, want int, timeout time.Duration) int {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		got := savedAuditEntries(t, db, accountID)
+		if got == want || time.Now().After(deadline) {
+			return got
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAuditWriterFlushesBySize(t *testing.T) {
+	db := newTestDB(t)
+	writer := NewAuditWriter(NewAuditRepository(db), 3, time.Hour)
+	accountID := newID("acc")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go writer.Run(ctx)
+
+	writeAuditEntries(writer, accountID, 2)
+	time.Sleep(100 * time.Millisecond)
+	if got := savedAuditEntries(t, db, accountID); got != 0 {
+		t.Fatalf("%d entries flushed below the batch size, want 0", got)
+	}
+
+	writeAuditEntries(writer, accountID, 1)
+	if got := waitForAuditEntries(t, db, accountID, 3, 2*time.Second); got != 3 {
+		t.Errorf("%d entries flushed once the batch was full, want 3", got)
+	}
+}
+
+func TestAuditWriterFlushesByInterval(t *testing.T) {
+	db := newTestDB(t)
+	writer := NewAuditWriter(NewAuditRepository(db), 100, 50*time.Millisecond)
+	accountID := newID("acc")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go writer.Run(ctx)
+
+	writeAuditEntries(writer, accountID, 2)
+	if got := waitForAuditEntries(t, db, accountID, 2, 2*time.Second); got != 2 {
+		t.Errorf("%d entries flushed after the interval, want 2", got)
+	}
+}
+
+func TestAuditWriterFlushesOnShutdown(t *testing.T) {
+	db := newTestDB(t)
+	writer := NewAuditWriter(NewAuditRepository(db), 100, time.Hour)
+	accountID := newID("acc")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		writer.Run(ctx)
+		close(done)
+	}()
+
+	writeAuditEntries(writer, accountID, 5)
+	cancel()
+	<-done
+
+	if got := savedAuditEntries(t, db, accountID); got != 0 {
+		t.Fatalf("%d entries flushed before Close, want 0", got)
+	}
+	if err := writer.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := savedAuditEntries(t, db, accountID); got != 5 {
+		t.Errorf("%d entries flushed on shutdown, want 5", got)
+	}
+}
+
+func TestAuditWriterWriteSync(t *testing.T) {
+	db := newTestDB(t)
+	writer := NewAuditWriter(NewAuditRepository(db), 100, time.Hour)
+	accountID := newID("acc")
+
+	entry := domain.NewAuditService().LogAction("LoanDecisionOverride", accountID, "underwriter-7", "RECOMPUTE", "loan", "test entry", "", "", "WARN")
+	if err := writer.WriteSync(context.Background(), entry); err != nil {
+		t.Fatalf("WriteSync: %v", err)
+	}
+	if got := savedAuditEntries(t, db, accountID); got != 1 {
+		t.Errorf("%d entries persisted by WriteSync, want 1", got)
+	}
+}
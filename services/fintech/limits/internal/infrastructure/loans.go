@@ -0,0 +1,110 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/database"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// LoanDecisionRepository handles database operations for loan decisions
+type LoanDecisionRepository struct {
+	db *database.DB
+}
+
+// NewLoanDecisionRepository creates a new loan decision repository
+func NewLoanDecisionRepository(db *database.DB) *LoanDecisionRepository {
+	return &LoanDecisionRepository{db: db}
+}
+
+// Save inserts a new decision version
+func (r *LoanDecisionRepository) Save(ctx context.Context, decision *domain.LoanDecision) error {
+	if decision.ID == "" {
+		decision.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO loan_decisions (id, application_id, version, original_decision_id, account_id, requested_amount, currency,
+			account_age_days, previous_payments, score, grade, risk_level, approved, max_amount, reason, overridden_by, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NULLIF($16, ''), $17)
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		decision.ID,
+		decision.ApplicationID,
+		decision.Version,
+		decision.OriginalDecisionID,
+		decision.AccountID,
+		decision.RequestedAmount,
+		decision.Currency,
+		decision.AccountAgeDays,
+		decision.PreviousPayments,
+		decision.Result.Score,
+		decision.Result.Grade,
+		decision.Result.RiskLevel,
+		decision.Result.Approved,
+		decision.Result.MaxAmount,
+		decision.Result.Reason,
+		decision.OverriddenBy,
+		decision.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save loan decision: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"decision_id":    decision.ID,
+		"application_id": decision.ApplicationID,
+		"version":        decision.Version,
+	}).Debug("Loan decision saved")
+
+	return nil
+}
+
+// FindLatest returns the most recent decision version for an application
+func (r *LoanDecisionRepository) FindLatest(ctx context.Context, applicationID string) (*domain.LoanDecision, error) {
+	query := `
+		SELECT id, application_id, version, COALESCE(original_decision_id::text, ''), account_id, requested_amount, currency,
+			account_age_days, previous_payments, score, grade, risk_level, approved, max_amount, reason,
+			COALESCE(overridden_by, ''), created_at
+		FROM loan_decisions
+		WHERE application_id = $1
+		ORDER BY version DESC
+		LIMIT 1
+	`
+
+	var decision domain.LoanDecision
+	err := r.db.QueryRow(ctx, query, applicationID).Scan(
+		&decision.ID,
+		&decision.ApplicationID,
+		&decision.Version,
+		&decision.OriginalDecisionID,
+		&decision.AccountID,
+		&decision.RequestedAmount,
+		&decision.Currency,
+		&decision.AccountAgeDays,
+		&decision.PreviousPayments,
+		&decision.Result.Score,
+		&decision.Result.Grade,
+		&decision.Result.RiskLevel,
+		&decision.Result.Approved,
+		&decision.Result.MaxAmount,
+		&decision.Result.Reason,
+		&decision.OverriddenBy,
+		&decision.CreatedAt,
+	)
+
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, domain.ErrDecisionNotFound
+		}
+		return nil, fmt.Errorf("failed to find loan decision: %w", err)
+	}
+
+	decision.Result.CalculatedAt = decision.CreatedAt
+	return &decision, nil
+}
@@ -0,0 +1,200 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// paymentSpend is what a payment spent from one limit, in the limit's currency
+type paymentSpend struct {
+	limitID  string
+	amount   float64
+	currency string
+}
+
+// spend reserves amount against the limit. For a payment the spend is recorded in payment_spends in
+// the same transaction, so the record exists exactly when the limit was spent.
+func (r *LimitRepository) spend(ctx context.Context, paymentID, limitID string, amount float64, toleranceBps float64) (*domain.Limit, error) {
+	if paymentID == "" {
+		return r.reserve(ctx, r.db, limitID, amount, toleranceBps)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin spend transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	reserved, err := r.reserve(ctx, tx, limitID, amount, toleranceBps)
+	if err != nil || reserved == nil {
+		return reserved, err
+	}
+
+	// A payment spent twice from the same limit, e.g. under two idempotency keys, is recorded as the sum
+	_, err = database.TracedExec(ctx, tx, "LimitRepository.spend", "payment_spends", `
+		INSERT INTO payment_spends (payment_id, limit_id, amount)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (payment_id, limit_id) DO UPDATE
+		SET amount = payment_spends.amount + EXCLUDED.amount
+	`, paymentID, limitID, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record payment spend: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit payment spend: %w", err)
+	}
+
+	return reserved, nil
+}
+
+// ReleaseForPayment returns a failed or reversed payment's amount to the limits the payment was
+// spent from, exactly once. Only recorded spends are released, each capped at what was spent, so a
+// payment that was denied releases nothing and a payment from an earlier period is released to that
+// period's limits. It returns the number of limits released, 0 if the payment's release was
+// already applied or nothing was spent.
+func (r *LimitRepository) ReleaseForPayment(ctx context.Context, paymentID, accountID string, amount float64, currency string) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin release transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := database.TracedExec(ctx, tx, "LimitRepository.ReleaseForPayment", "limit_releases", `
+		INSERT INTO limit_releases (payment_id, account_id, amount, currency)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (payment_id) DO NOTHING
+	`, paymentID, accountID, amount, currency)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record limit release: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return 0, nil
+	}
+
+	spends, err := r.unreleasedSpends(ctx, tx, paymentID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, spend := range spends {
+		// A partial reversal releases its own amount, never more than the payment spent
+		released, err := r.converter.Convert(ctx, amount, currency, spend.currency)
+		if err != nil {
+			return 0, err
+		}
+		released = math.Min(released, spend.amount)
+
+		if err := r.releaseSpend(ctx, tx, spend.limitID, released); err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = database.TracedExec(ctx, tx, "LimitRepository.ReleaseForPayment", "payment_spends", `
+		UPDATE payment_spends
+		SET released_at = CURRENT_TIMESTAMP
+		WHERE payment_id = $1 AND released_at IS NULL
+	`, paymentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark payment spends released: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit limit release: %w", err)
+	}
+
+	return len(spends), nil
+}
+
+// RollbackPaymentSpends undoes every unreleased spend recorded for a payment and forgets it, so that
+// a redelivered event spends and records it again
+func (r *LimitRepository) RollbackPaymentSpends(ctx context.Context, paymentID string) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin rollback transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	spends, err := r.unreleasedSpends(ctx, tx, paymentID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, spend := range spends {
+		if err := r.releaseSpend(ctx, tx, spend.limitID, spend.amount); err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = database.TracedExec(ctx, tx, "LimitRepository.RollbackPaymentSpends", "payment_spends", `
+		DELETE FROM payment_spends
+		WHERE payment_id = $1 AND released_at IS NULL
+	`, paymentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete payment spends: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit spend rollback: %w", err)
+	}
+
+	return len(spends), nil
+}
+
+// unreleasedSpends locks and returns the spends recorded for a payment that haven't been released
+func (r *LimitRepository) unreleasedSpends(ctx context.Context, tx pgx.Tx, paymentID string) ([]paymentSpend, error) {
+	rows, err := database.TracedQuery(ctx, tx, "LimitRepository.unreleasedSpends", "payment_spends", `
+		SELECT s.limit_id, s.amount, l.currency
+		FROM payment_spends s
+		JOIN limits l ON l.id = s.limit_id
+		WHERE s.payment_id = $1 AND s.released_at IS NULL
+		ORDER BY s.limit_id
+		FOR UPDATE OF s
+	`, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payment spends: %w", err)
+	}
+	defer rows.Close()
+
+	var spends []paymentSpend
+	for rows.Next() {
+		var spend paymentSpend
+		if err := rows.Scan(&spend.limitID, &spend.amount, &spend.currency); err != nil {
+			return nil, fmt.Errorf("failed to scan payment spend: %w", err)
+		}
+		spends = append(spends, spend)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating payment spends: %w", err)
+	}
+
+	return spends, nil
+}
+
+// releaseSpend returns amount, in the limit's currency, to the limit it was spent from, clamping
+// used at zero
+func (r *LimitRepository) releaseSpend(ctx context.Context, tx pgx.Tx, limitID string, amount float64) error {
+	_, err := database.TracedExec(ctx, tx, "LimitRepository.releaseSpend", "limits", `
+		UPDATE limits
+		SET used = GREATEST(used - $1, 0), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, amount, limitID)
+	if err != nil {
+		return fmt.Errorf("failed to release limit: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"limit_id": limitID,
+		"released": amount,
+	}).Debug("Payment spend released")
+
+	return nil
+}
@@ -0,0 +1,78 @@
+//go:build integration
+
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/database"
+	"fintech/limits-service/pkg/fx"
+)
+
+// replicaSchema stands in for the replica: a schema of its own in the test database, so rows
+// written to the primary don't show up in it
+const replicaSchema = "limits_replica"
+
+// newReplicatedRepository returns a limit repository whose primary is the test database and whose
+// replica is the replica schema, and a connection to the replica for seeding it
+func newReplicatedRepository(t *testing.T) (*LimitRepository, *database.DB, *database.DB) {
+	t.Helper()
+
+	db := newTestDB(t)
+	if _, err := db.Exec(context.Background(), "CREATE SCHEMA IF NOT EXISTS "+replicaSchema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+
+	replicaURL := withSearchPath(t, os.Getenv("TEST_DATABASE_URL"), replicaSchema)
+	replica := connectTestDB(t, replicaURL, database.RunMigrations)
+	if err := db.ConnectReplica(replicaURL); err != nil {
+		t.Fatalf("ConnectReplica: %v", err)
+	}
+
+	rates := fx.NewStaticRateProvider("USD", map[string]float64{"EUR": 0.5, "SEK": 10})
+	return NewLimitRepository(db, fx.NewConverter(rates)), db, replica
+}
+
+func TestReadsUseReplicaAndWritesUsePrimary(t *testing.T) {
+	repo, primary, replica := newReplicatedRepository(t)
+	ctx := context.Background()
+	accountID := newID("acc")
+
+	// The write lands on the primary only
+	if _, err := repo.CheckAndSpend(ctx, accountID, domain.DailyLimit, 100, 1000, "USD"); err != nil {
+		t.Fatalf("CheckAndSpend: %v", err)
+	}
+	var used float64
+	if err := primary.Pool.QueryRow(ctx, "SELECT used FROM limits WHERE account_id = $1 AND type = 'DAILY'", accountID).Scan(&used); err != nil {
+		t.Fatalf("failed to read primary limit: %v", err)
+	}
+	if used != 100 {
+		t.Errorf("primary used = %.2f, want 100", used)
+	}
+
+	limit, err := repo.GetCurrentLimit(ctx, accountID, domain.DailyLimit)
+	if err != nil {
+		t.Fatalf("GetCurrentLimit: %v", err)
+	}
+	if limit != nil {
+		t.Errorf("GetCurrentLimit = %+v, want nothing before the replica has the row", limit)
+	}
+
+	// The read sees the replica's row
+	if _, err := replica.Exec(ctx, `
+		INSERT INTO limits (account_id, type, amount, used, currency, period_start, period_end)
+		VALUES ($1, 'DAILY', 1000, 40, 'USD', date_trunc('day', NOW()), date_trunc('day', NOW()) + INTERVAL '1 day')
+	`, accountID); err != nil {
+		t.Fatalf("failed to seed replica: %v", err)
+	}
+	limit, err = repo.GetCurrentLimit(ctx, accountID, domain.DailyLimit)
+	if err != nil {
+		t.Fatalf("GetCurrentLimit: %v", err)
+	}
+	if limit == nil || limit.Used != 40 {
+		t.Errorf("GetCurrentLimit = %+v, want the replica's limit with 40 used", limit)
+	}
+}
@@ -2,30 +2,80 @@ package infrastructure
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"fintech/limits-service/internal/domain"
 	"fintech/limits-service/pkg/database"
+	"fintech/limits-service/pkg/fx"
+	"fintech/limits-service/pkg/lock"
+	"fintech/limits-service/pkg/metrics"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/sirupsen/logrus"
 )
 
-// LimitRepository handles database operations for limits
+// querier is satisfied by both the connection pool and a transaction
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// LimitRepository handles database operations for limits. Read-only history, summary and
+// effective limit queries read from the replica when one is configured; spends always use the primary.
 type LimitRepository struct {
-	db *database.DB
+	db             *database.DB
+	converter      *fx.Converter
+	fxToleranceBps float64
+	perCurrency    bool
+	maxCurrencies  int
+	locker         *lock.RedisLocker
+	lockTTL        time.Duration
+}
+
+// NewLimitRepository creates a new limit repository. Amounts in a currency other than
+// the limit's are converted with converter before being applied.
+func NewLimitRepository(db *database.DB, converter *fx.Converter) *LimitRepository {
+	return &LimitRepository{db: db, converter: converter}
+}
+
+// SetFXTolerance sets how far, in basis points of the limit amount, a spend converted
+// from another currency may overshoot the limit and still be allowed
+func (r *LimitRepository) SetFXTolerance(bps float64) {
+	r.fxToleranceBps = bps
 }
 
-// NewLimitRepository creates a new limit repository
-func NewLimitRepository(db *database.DB) *LimitRepository {
-	return &LimitRepository{db: db}
+// SetPerCurrency keeps an independent limit per currency instead of converting every spend into
+// the currency of the account's single limit. It requires the per-currency unique constraint
+// applied by database.MigratePerCurrencyLimits.
+func (r *LimitRepository) SetPerCurrency(enabled bool) {
+	r.perCurrency = enabled
 }
 
-// GetOrCreateLimit gets an existing limit or creates a new one for the account and period
+// SetMaxCurrencies caps how many distinct currencies an account may have current-period limits in
+// when limits are per currency. A spend that would exceed it is denied instead of creating another
+// limit. Zero disables the cap.
+func (r *LimitRepository) SetMaxCurrencies(max int) {
+	r.maxCurrencies = max
+}
+
+// SetLocker serializes CheckAndSpend per account and limit type across replicas, holding
+// each lock for at most ttl. A nil locker disables locking.
+func (r *LimitRepository) SetLocker(locker *lock.RedisLocker, ttl time.Duration) {
+	r.locker = locker
+	r.lockTTL = ttl
+}
+
+// GetOrCreateLimit gets an existing limit or creates a new one for the account and period.
+// In per-currency mode the limit is also keyed on currency. Creation is an upsert, so concurrent
+// callers for the same key all get the same row. It returns domain.ErrTooManyCurrencies rather than
+// create a limit in a currency beyond the SetMaxCurrencies cap.
 func (r *LimitRepository) GetOrCreateLimit(ctx context.Context, accountID string, limitType domain.LimitType, defaultAmount float64, currency string) (*domain.Limit, error) {
 	// First try to find existing limit for current period
-	limit, err := r.getCurrentLimit(ctx, accountID, limitType)
+	limit, err := r.currentLimit(ctx, r.db, accountID, limitType, r.limitCurrency(currency))
 	if err != nil {
 		return nil, err
 	}
@@ -34,6 +84,10 @@ func (r *LimitRepository) GetOrCreateLimit(ctx context.Context, accountID string
 		return limit, nil
 	}
 
+	if err := r.checkCurrencyCap(ctx, accountID, currency); err != nil {
+		return nil, err
+	}
+
 	// Create new limit if none exists
 	newLimit, err := domain.NewLimit(accountID, limitType, defaultAmount, currency)
 	if err != nil {
@@ -43,9 +97,10 @@ func (r *LimitRepository) GetOrCreateLimit(ctx context.Context, accountID string
 	return r.saveLimit(ctx, newLimit)
 }
 
-// GetCurrentLimit gets the current limit for an account and type
+// GetCurrentLimit gets the current limit for an account and type, read from the replica if one is
+// configured. With per-currency limits it returns the most recently created one.
 func (r *LimitRepository) GetCurrentLimit(ctx context.Context, accountID string, limitType domain.LimitType) (*domain.Limit, error) {
-	return r.getCurrentLimit(ctx, accountID, limitType)
+	return r.currentLimit(ctx, r.db.Reader(), accountID, limitType, "")
 }
 
 // UpdateLimit updates a limit in the database
@@ -56,7 +111,7 @@ func (r *LimitRepository) UpdateLimit(ctx context.Context, limit *domain.Limit)
 		WHERE id = $3
 	`
 
-	_, err := r.db.Exec(ctx, query, limit.Used, limit.UpdatedAt, limit.ID)
+	_, err := database.TracedExec(ctx, r.db, "LimitRepository.UpdateLimit", "limits", query, limit.Used, limit.UpdatedAt, limit.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update limit: %w", err)
 	}
@@ -69,64 +124,907 @@ func (r *LimitRepository) UpdateLimit(ctx context.Context, limit *domain.Limit)
 	return nil
 }
 
-// CheckAndSpend attempts to spend from the limit if allowed
+// CheckAndSpend attempts to spend from the limit if allowed. The check and the deduction are a
+// single conditional update, so concurrent spends against the same limit cannot overspend it.
+// Every call is counted in limit_checks_total and timed in limit_check_duration_seconds.
 func (r *LimitRepository) CheckAndSpend(ctx context.Context, accountID string, limitType domain.LimitType, amount float64, defaultLimit float64, currency string) (*domain.LimitCheckResult, error) {
+	return r.observedCheckAndSpend(ctx, "", accountID, limitType, amount, defaultLimit, currency)
+}
+
+// CheckAndSpendForPayment is CheckAndSpend for a payment: an allowed spend is recorded against
+// paymentID in the same transaction, so that ReleaseForPayment and RollbackPaymentSpends return
+// exactly what the payment spent, to the limit it was spent from.
+func (r *LimitRepository) CheckAndSpendForPayment(ctx context.Context, paymentID, accountID string, limitType domain.LimitType, amount float64, defaultLimit float64, currency string) (*domain.LimitCheckResult, error) {
+	return r.observedCheckAndSpend(ctx, paymentID, accountID, limitType, amount, defaultLimit, currency)
+}
+
+func (r *LimitRepository) observedCheckAndSpend(ctx context.Context, paymentID, accountID string, limitType domain.LimitType, amount float64, defaultLimit float64, currency string) (*domain.LimitCheckResult, error) {
+	start := time.Now()
+	result, err := r.checkAndSpend(ctx, paymentID, accountID, limitType, amount, defaultLimit, currency)
+	metrics.ObserveLimitCheck(string(limitType), result != nil && result.Allowed, err, time.Since(start))
+	return result, err
+}
+
+func (r *LimitRepository) checkAndSpend(ctx context.Context, paymentID, accountID string, limitType domain.LimitType, amount float64, defaultLimit float64, currency string) (*domain.LimitCheckResult, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("failed to spend from limit: spend amount must be positive")
+	}
+
+	lockKey := "limits:" + accountID + ":" + string(limitType)
+	lockToken, err := r.locker.Acquire(ctx, lockKey, r.lockTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock limit: %w", err)
+	}
+	defer func() {
+		if err := r.locker.Release(context.WithoutCancel(ctx), lockKey, lockToken); err != nil {
+			logrus.WithError(err).WithField("key", lockKey).Warn("Failed to release limit lock")
+		}
+	}()
+
 	// Get or create limit
 	limit, err := r.GetOrCreateLimit(ctx, accountID, limitType, defaultLimit, currency)
+	if errors.Is(err, domain.ErrTooManyCurrencies) {
+		return currencyCapResult(accountID, limitType, defaultLimit, currency)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get/create limit: %w", err)
 	}
 
-	// Check if spending is allowed
-	if !limit.CanSpend(amount) {
-		return domain.NewLimitCheckResult(false, limit, "Limit exceeded"), nil
+	// Express the amount in the limit's currency
+	amount, err = r.converter.Convert(ctx, amount, currency, limit.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Spend only if it fits, tolerating FX noise on converted amounts
+	reserved, err := r.spend(ctx, paymentID, limit.ID, amount, r.toleranceFor(currency, limit.Currency))
+	if err != nil {
+		return nil, err
+	}
+	if reserved == nil {
+		// Report usage as it stood when the spend was refused, not as first read
+		current, err := r.currentLimit(ctx, r.db, accountID, limitType, r.limitCurrency(currency))
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			current = limit
+		}
+		return domain.NewLimitCheckResult(false, current, "Limit exceeded"), nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"limit_id": reserved.ID,
+		"used":     reserved.Used,
+	}).Debug("Limit updated")
+
+	result := domain.NewLimitCheckResult(true, reserved, "")
+	result.ToleranceApplied = reserved.Used > reserved.EffectiveAmount()
+	return result, nil
+}
+
+// ExplainSpend reports how CheckAndSpend would decide a spend right now, component by component,
+// without spending it or creating a limit. An account with no limit for the period is explained
+// against the default a spend would create.
+func (r *LimitRepository) ExplainSpend(ctx context.Context, accountID string, limitType domain.LimitType, amount float64, defaultLimit float64, currency string) (*domain.LimitExplanation, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("failed to explain spend: spend amount must be positive")
+	}
+
+	limit, err := r.currentLimit(ctx, r.db, accountID, limitType, r.limitCurrency(currency))
+	if err != nil {
+		return nil, err
+	}
+
+	source := domain.LimitSourceStored
+	var capErr error
+	if limit == nil {
+		source = domain.LimitSourceDefault
+		if capErr = r.checkCurrencyCap(ctx, accountID, currency); capErr != nil && !errors.Is(capErr, domain.ErrTooManyCurrencies) {
+			return nil, capErr
+		}
+		if limit, err = domain.NewLimit(accountID, limitType, defaultLimit, currency); err != nil {
+			return nil, err
+		}
+	}
+
+	converted, err := r.converter.Convert(ctx, amount, currency, limit.Currency)
+	if err != nil {
+		return nil, err
+	}
+	tolerance := r.toleranceFor(currency, limit.Currency)
+
+	explanation := &domain.LimitExplanation{
+		AccountID:         accountID,
+		LimitType:         string(limitType),
+		LimitSource:       source,
+		LimitID:           limit.ID,
+		PeriodLabel:       limit.PeriodLabel(),
+		PeriodEnd:         limit.PeriodEnd,
+		LimitAmount:       limit.EffectiveAmount(),
+		UsedAmount:        limit.Used,
+		Remaining:         limit.GetRemaining(),
+		LimitCurrency:     limit.Currency,
+		RequestedAmount:   amount,
+		RequestedCurrency: currency,
+		ConvertedAmount:   converted,
+		ToleranceBps:      tolerance,
+	}
+	if converted != amount {
+		explanation.ConversionRate = converted / amount
+	}
+
+	switch {
+	case capErr != nil:
+		explanation.ReasonCode = domain.ReasonTooManyCurrencies
+	case limit.CanSpend(converted):
+		explanation.Allowed, explanation.ReasonCode = true, domain.ReasonWithinLimit
+	case limit.CanSpendWithTolerance(converted, tolerance):
+		explanation.Allowed, explanation.ReasonCode = true, domain.ReasonWithinTolerance
+		explanation.ToleranceApplied = true
+	default:
+		explanation.ReasonCode = domain.ReasonLimitExceeded
+	}
+
+	return explanation, nil
+}
+
+// SpendRequest is a single spend within a batch
+type SpendRequest struct {
+	AccountID    string
+	Type         domain.LimitType
+	Amount       float64
+	DefaultLimit float64
+	Currency     string
+}
+
+// CheckAndSpendAll spends every request in one transaction, or none of them. It stops at the
+// first denied request and returns the results so far, the last being the denial. On error the
+// results returned cover the requests before the one that failed, or every request if the
+// commit itself failed.
+func (r *LimitRepository) CheckAndSpendAll(ctx context.Context, requests []SpendRequest) ([]*domain.LimitCheckResult, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin batch transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	results := make([]*domain.LimitCheckResult, 0, len(requests))
+	for _, req := range requests {
+		// Limits are created outside the transaction; creation is idempotent and never rolled back
+		limit, err := r.GetOrCreateLimit(ctx, req.AccountID, req.Type, req.DefaultLimit, req.Currency)
+		if errors.Is(err, domain.ErrTooManyCurrencies) {
+			denied, err := currencyCapResult(req.AccountID, req.Type, req.DefaultLimit, req.Currency)
+			if err != nil {
+				return results, err
+			}
+			return append(results, denied), nil
+		}
+		if err != nil {
+			return results, fmt.Errorf("failed to get/create limit: %w", err)
+		}
+
+		amount, err := r.converter.Convert(ctx, req.Amount, req.Currency, limit.Currency)
+		if err != nil {
+			return results, err
+		}
+
+		reserved, err := r.reserve(ctx, tx, limit.ID, amount, r.toleranceFor(req.Currency, limit.Currency))
+		if err != nil {
+			return results, err
+		}
+		if reserved == nil {
+			// Report usage as seen by this batch, including its earlier spends
+			current, err := r.currentLimit(ctx, tx, req.AccountID, req.Type, r.limitCurrency(req.Currency))
+			if err != nil {
+				return results, err
+			}
+			if current == nil {
+				current = limit
+			}
+			return append(results, domain.NewLimitCheckResult(false, current, "Limit exceeded")), nil
+		}
+
+		result := domain.NewLimitCheckResult(true, reserved, "")
+		result.ToleranceApplied = reserved.Used > reserved.EffectiveAmount()
+		results = append(results, result)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return results, fmt.Errorf("failed to commit batch spend: %w", err)
+	}
+
+	return results, nil
+}
+
+// batchLimitKey identifies the limit a batched spend is made from
+type batchLimitKey struct {
+	accountID string
+	limitType domain.LimitType
+	currency  string
+}
+
+// batchLimit is a limit resolved once for every spend of a batch made from it
+type batchLimit struct {
+	limit *domain.Limit
+	err   error
+}
+
+// CheckAndSpendBatch spends each request independently and returns a result or an error per
+// request, in request order. Each distinct limit is resolved once however many requests spend from
+// it, and the spends share one transaction with a savepoint per request, so a request that fails is
+// undone on its own while the rest are committed together. Every request reports the error if the
+// transaction itself can't be begun or committed.
+func (r *LimitRepository) CheckAndSpendBatch(ctx context.Context, requests []SpendRequest) ([]*domain.LimitCheckResult, []error) {
+	results := make([]*domain.LimitCheckResult, len(requests))
+	errs := make([]error, len(requests))
+	failAll := func(err error) ([]*domain.LimitCheckResult, []error) {
+		for i := range requests {
+			results[i], errs[i] = nil, err
+		}
+		return results, errs
+	}
+
+	// Limits are created outside the transaction; creation is idempotent and never rolled back
+	limits := make(map[batchLimitKey]batchLimit)
+	for _, req := range requests {
+		key := batchLimitKey{req.AccountID, req.Type, r.limitCurrency(req.Currency)}
+		if _, ok := limits[key]; !ok {
+			limit, err := r.GetOrCreateLimit(ctx, req.AccountID, req.Type, req.DefaultLimit, req.Currency)
+			limits[key] = batchLimit{limit: limit, err: err}
+		}
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return failAll(fmt.Errorf("failed to begin batch transaction: %w", err))
+	}
+	defer tx.Rollback(ctx)
+
+	durations := make([]time.Duration, len(requests))
+	for i, req := range requests {
+		start := time.Now()
+		results[i], errs[i] = r.spendInBatch(ctx, tx, req, limits[batchLimitKey{req.AccountID, req.Type, r.limitCurrency(req.Currency)}])
+		durations[i] = time.Since(start)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return failAll(fmt.Errorf("failed to commit batch spend: %w", err))
+	}
+
+	for i, req := range requests {
+		metrics.ObserveLimitCheck(string(req.Type), results[i] != nil && results[i].Allowed, errs[i], durations[i])
 	}
+	return results, errs
+}
 
-	// Spend from limit
-	if err := limit.Spend(amount); err != nil {
-		return nil, fmt.Errorf("failed to spend from limit: %w", err)
+// spendInBatch spends one request of a CheckAndSpendBatch from its resolved limit under a savepoint,
+// releasing the savepoint if the spend succeeds and rolling back to it otherwise
+func (r *LimitRepository) spendInBatch(ctx context.Context, tx pgx.Tx, req SpendRequest, resolved batchLimit) (*domain.LimitCheckResult, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("failed to spend from limit: spend amount must be positive")
+	}
+	if errors.Is(resolved.err, domain.ErrTooManyCurrencies) {
+		return currencyCapResult(req.AccountID, req.Type, req.DefaultLimit, req.Currency)
+	}
+	if resolved.err != nil {
+		return nil, fmt.Errorf("failed to get/create limit: %w", resolved.err)
 	}
+	limit := resolved.limit
 
-	// Update in database
-	if err := r.UpdateLimit(ctx, limit); err != nil {
-		return nil, fmt.Errorf("failed to update limit in database: %w", err)
+	amount, err := r.converter.Convert(ctx, req.Amount, req.Currency, limit.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin batch savepoint: %w", err)
 	}
+	defer savepoint.Rollback(ctx)
 
-	return domain.NewLimitCheckResult(true, limit, ""), nil
+	var result *domain.LimitCheckResult
+	reserved, err := r.reserve(ctx, savepoint, limit.ID, amount, r.toleranceFor(req.Currency, limit.Currency))
+	if err != nil {
+		return nil, err
+	}
+	if reserved == nil {
+		// Report usage as seen by this batch, including its earlier spends
+		current, err := r.currentLimit(ctx, savepoint, req.AccountID, req.Type, r.limitCurrency(req.Currency))
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			current = limit
+		}
+		result = domain.NewLimitCheckResult(false, current, "Limit exceeded")
+	} else {
+		result = domain.NewLimitCheckResult(true, reserved, "")
+		result.ToleranceApplied = reserved.Used > reserved.EffectiveAmount()
+	}
+
+	if err := savepoint.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to release batch savepoint: %w", err)
+	}
+	return result, nil
+}
+
+// EvaluateAll checks one payment against several limits in order and spends from all of them or
+// none. Every limit is checked first so the result has the full breakdown; a denied payment
+// reports each limit's usage untouched. The spends then happen in one transaction, so a limit
+// used up concurrently in between denies the payment without a partial spend.
+func (r *LimitRepository) EvaluateAll(ctx context.Context, requests []SpendRequest) (*domain.AggregateLimitResult, error) {
+	aggregate := &domain.AggregateLimitResult{Allowed: true, Results: make([]*domain.LimitCheckResult, len(requests))}
+	limits := make([]*domain.Limit, len(requests))
+	amounts := make([]float64, len(requests))
+
+	deny := func(i int, result *domain.LimitCheckResult) {
+		aggregate.Results[i] = result
+		if aggregate.Allowed {
+			aggregate.Allowed = false
+			aggregate.FailedType = string(requests[i].Type)
+		}
+	}
+
+	for i, req := range requests {
+		limit, err := r.GetOrCreateLimit(ctx, req.AccountID, req.Type, req.DefaultLimit, req.Currency)
+		if errors.Is(err, domain.ErrTooManyCurrencies) {
+			denied, err := currencyCapResult(req.AccountID, req.Type, req.DefaultLimit, req.Currency)
+			if err != nil {
+				return nil, err
+			}
+			deny(i, denied)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get/create limit: %w", err)
+		}
+
+		amount, err := r.converter.Convert(ctx, req.Amount, req.Currency, limit.Currency)
+		if err != nil {
+			return nil, err
+		}
+		limits[i], amounts[i] = limit, amount
+
+		if !limit.CanSpendWithTolerance(amount, r.toleranceFor(req.Currency, limit.Currency)) {
+			deny(i, domain.NewLimitCheckResult(false, limit, "Limit exceeded"))
+			continue
+		}
+		aggregate.Results[i] = domain.NewLimitCheckResult(true, limit, "")
+	}
+
+	if !aggregate.Allowed {
+		return aggregate, nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin evaluation transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	spent := make([]*domain.LimitCheckResult, len(requests))
+	for i, req := range requests {
+		reserved, err := r.reserve(ctx, tx, limits[i].ID, amounts[i], r.toleranceFor(req.Currency, limits[i].Currency))
+		if err != nil {
+			return nil, err
+		}
+		if reserved == nil {
+			current, err := r.currentLimit(ctx, r.db, req.AccountID, req.Type, r.limitCurrency(req.Currency))
+			if err != nil {
+				return nil, err
+			}
+			if current == nil {
+				current = limits[i]
+			}
+			deny(i, domain.NewLimitCheckResult(false, current, "Limit exceeded"))
+			return aggregate, nil
+		}
+
+		spent[i] = domain.NewLimitCheckResult(true, reserved, "")
+		spent[i].ToleranceApplied = reserved.Used > reserved.EffectiveAmount()
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit evaluation: %w", err)
+	}
+
+	aggregate.Results = spent
+	return aggregate, nil
+}
+
+// Release returns amount to the current limit of the given type, clamping used at zero.
+// It returns nil if the account has no limit for the current period.
+func (r *LimitRepository) Release(ctx context.Context, accountID string, limitType domain.LimitType, amount float64, currency string) (*domain.Limit, error) {
+	return r.release(ctx, r.db, accountID, limitType, amount, currency)
+}
+
+// GrantTemporaryIncrease raises the account's current limit of limitType to newAmount, given in
+// currency, until expiresAt, creating the limit with defaultLimit if nothing was spent this
+// period. Afterwards the limit reverts to its base amount. A later grant replaces an earlier one.
+func (r *LimitRepository) GrantTemporaryIncrease(ctx context.Context, accountID string, limitType domain.LimitType, newAmount float64, expiresAt time.Time, defaultLimit float64, currency string) (*domain.Limit, error) {
+	limit, err := r.GetOrCreateLimit(ctx, accountID, limitType, defaultLimit, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	newAmount, err = r.converter.Convert(ctx, newAmount, currency, limit.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if err := limit.ValidateOverride(newAmount, expiresAt); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE limits
+		SET override_amount = $1, override_expires_at = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING id, account_id, type, amount, used, currency, period_start, period_end, created_at, updated_at, override_amount, override_expires_at
+	`
+	granted, err := scanLimit(database.TracedQueryRow(ctx, r.db, "LimitRepository.GrantTemporaryIncrease", "limits", query, newAmount, expiresAt, limit.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to grant temporary limit increase: %w", err)
+	}
+	return granted, nil
+}
+
+// ClaimEvent records that the payment event with idempotencyKey is being processed, reporting false
+// if it already was, so a payment published twice is only spent once
+func (r *LimitRepository) ClaimEvent(ctx context.Context, idempotencyKey, paymentID, accountID string) (bool, error) {
+	tag, err := database.TracedExec(ctx, r.db, "LimitRepository.ClaimEvent", "processed_events", `
+		INSERT INTO processed_events (idempotency_key, payment_id, account_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (idempotency_key) DO NOTHING
+	`, idempotencyKey, paymentID, accountID)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim payment event: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// UnclaimEvent removes the claim on a payment event whose processing failed and was undone, so
+// redelivery processes it again
+func (r *LimitRepository) UnclaimEvent(ctx context.Context, idempotencyKey string) error {
+	_, err := database.TracedExec(ctx, r.db, "LimitRepository.UnclaimEvent", "processed_events",
+		`DELETE FROM processed_events WHERE idempotency_key = $1`, idempotencyKey)
+	if err != nil {
+		return fmt.Errorf("failed to unclaim payment event: %w", err)
+	}
+	return nil
 }
 
-// ResetExpiredLimits resets limits that have expired (should be called periodically)
-func (r *LimitRepository) ResetExpiredLimits(ctx context.Context) error {
+func (r *LimitRepository) release(ctx context.Context, q querier, accountID string, limitType domain.LimitType, amount float64, currency string) (*domain.Limit, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("release amount must be positive")
+	}
+
+	current, err := r.currentLimit(ctx, q, accountID, limitType, r.limitCurrency(currency))
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, nil // Nothing spent this period
+	}
+
+	amount, err = r.converter.Convert(ctx, amount, currency, current.Currency)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 		UPDATE limits
-		SET used = 0, updated_at = CURRENT_TIMESTAMP
-		WHERE period_end < CURRENT_TIMESTAMP AND used > 0
+		SET used = GREATEST(used - $1, 0), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING id, account_id, type, amount, used, currency, period_start, period_end, created_at, updated_at, override_amount, override_expires_at
 	`
 
-	result, err := r.db.Exec(ctx, query)
+	limit, err := scanLimit(database.TracedQueryRow(ctx, q, "LimitRepository.release", "limits", query, amount, current.ID))
 	if err != nil {
-		return fmt.Errorf("failed to reset expired limits: %w", err)
+		return nil, fmt.Errorf("failed to release limit: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"limit_id": limit.ID,
+		"released": amount,
+		"currency": limit.Currency,
+		"used":     limit.Used,
+	}).Debug("Limit released")
+
+	return limit, nil
+}
+
+// CreateHold reserves amount against the current limit until the hold is committed, cancelled
+// or expires. If the amount does not fit, no hold is created and the denied result is returned.
+func (r *LimitRepository) CreateHold(ctx context.Context, accountID string, limitType domain.LimitType, amount float64, defaultLimit float64, currency string, ttl time.Duration) (*domain.LimitHold, *domain.LimitCheckResult, error) {
+	limit, err := r.GetOrCreateLimit(ctx, accountID, limitType, defaultLimit, currency)
+	if errors.Is(err, domain.ErrTooManyCurrencies) {
+		denied, err := currencyCapResult(accountID, limitType, defaultLimit, currency)
+		return nil, denied, err
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get/create limit: %w", err)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin hold transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	held, err := r.converter.Convert(ctx, amount, currency, limit.Currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reserved, err := r.reserve(ctx, tx, limit.ID, held, r.toleranceFor(currency, limit.Currency))
+	if err != nil {
+		return nil, nil, err
+	}
+	if reserved == nil {
+		return nil, domain.NewLimitCheckResult(false, limit, "Limit exceeded"), nil
+	}
+
+	now := time.Now().UTC()
+	hold := &domain.LimitHold{
+		Token:     uuid.New().String(),
+		LimitID:   reserved.ID,
+		AccountID: accountID,
+		Type:      limitType,
+		Amount:    held,
+		Currency:  limit.Currency,
+		Status:    domain.HoldActive,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	_, err = database.TracedExec(ctx, tx, "LimitRepository.CreateHold", "limit_holds", `
+		INSERT INTO limit_holds (token, limit_id, account_id, type, amount, currency, status, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, hold.Token, hold.LimitID, hold.AccountID, string(hold.Type), hold.Amount, hold.Currency, string(hold.Status), hold.ExpiresAt, hold.CreatedAt, hold.UpdatedAt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to save hold: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit hold: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"hold_token": hold.Token,
+		"limit_id":   hold.LimitID,
+		"amount":     held,
+		"expires_at": hold.ExpiresAt,
+	}).Debug("Limit hold created")
+
+	result := domain.NewLimitCheckResult(true, reserved, "")
+	result.ToleranceApplied = reserved.Used > reserved.EffectiveAmount()
+	return hold, result, nil
+}
+
+// CommitHold converts an active hold into a permanent spend
+func (r *LimitRepository) CommitHold(ctx context.Context, token string) error {
+	tag, err := database.TracedExec(ctx, r.db, "LimitRepository.CommitHold", "limit_holds", `
+		UPDATE limit_holds
+		SET status = 'COMMITTED', updated_at = CURRENT_TIMESTAMP
+		WHERE token = $1 AND status = 'HELD' AND expires_at > CURRENT_TIMESTAMP
+	`, token)
+	if err != nil {
+		return fmt.Errorf("failed to commit hold: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrHoldNotActive
+	}
+
+	logrus.WithField("hold_token", token).Debug("Limit hold committed")
+	return nil
+}
+
+// ReleaseHold cancels an active hold and returns its amount to the limit
+func (r *LimitRepository) ReleaseHold(ctx context.Context, token string) error {
+	query := `
+		WITH released AS (
+			UPDATE limit_holds
+			SET status = 'RELEASED', updated_at = CURRENT_TIMESTAMP
+			WHERE token = $1 AND status = 'HELD'
+			RETURNING limit_id, amount
+		)
+		UPDATE limits l
+		SET used = GREATEST(l.used - released.amount, 0), updated_at = CURRENT_TIMESTAMP
+		FROM released
+		WHERE l.id = released.limit_id
+	`
+
+	tag, err := database.TracedExec(ctx, r.db, "LimitRepository.ReleaseHold", "limit_holds", query, token)
+	if err != nil {
+		return fmt.Errorf("failed to release hold: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrHoldNotActive
+	}
+
+	logrus.WithField("hold_token", token).Debug("Limit hold released")
+	return nil
+}
+
+// ExpireHolds releases every active hold past its expiry (should be called periodically)
+func (r *LimitRepository) ExpireHolds(ctx context.Context) error {
+	query := `
+		WITH expired AS (
+			UPDATE limit_holds
+			SET status = 'EXPIRED', updated_at = CURRENT_TIMESTAMP
+			WHERE status = 'HELD' AND expires_at <= CURRENT_TIMESTAMP
+			RETURNING limit_id, amount
+		), totals AS (
+			SELECT limit_id, SUM(amount) AS amount FROM expired GROUP BY limit_id
+		)
+		UPDATE limits l
+		SET used = GREATEST(l.used - totals.amount, 0), updated_at = CURRENT_TIMESTAMP
+		FROM totals
+		WHERE l.id = totals.limit_id
+	`
+
+	result, err := database.TracedExec(ctx, r.db, "LimitRepository.ExpireHolds", "limit_holds", query)
+	if err != nil {
+		return fmt.Errorf("failed to expire holds: %w", err)
 	}
 
 	rowsAffected := result.RowsAffected()
 	if rowsAffected > 0 {
-		logrus.WithField("count", rowsAffected).Info("Reset expired limits")
+		logrus.WithField("limits", rowsAffected).Info("Released expired limit holds")
+	}
+
+	return nil
+}
+
+// effectiveAmountSQL is the amount of a limits row that can be spent: its temporary increase while
+// active, its base amount otherwise
+const effectiveAmountSQL = `CASE WHEN override_expires_at > CURRENT_TIMESTAMP THEN override_amount ELSE amount END`
+
+// reserve atomically adds amount to a limit's usage if it fits. It returns nil if the limit would be exceeded.
+func (r *LimitRepository) reserve(ctx context.Context, q querier, limitID string, amount float64, toleranceBps float64) (*domain.Limit, error) {
+	query := `
+		UPDATE limits
+		SET used = used + $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND used + $1 <= ` + effectiveAmountSQL + ` * (1 + $3::numeric / 10000)
+		RETURNING id, account_id, type, amount, used, currency, period_start, period_end, created_at, updated_at, override_amount, override_expires_at
+	`
+
+	limit, err := scanLimit(database.TracedQueryRow(ctx, q, "LimitRepository.reserve", "limits", query, amount, limitID, toleranceBps))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve limit: %w", err)
+	}
+
+	return limit, nil
+}
+
+// PruneExpiredLimits deletes limits whose period ended more than periods periods ago, together with
+// their payment spends and finished holds. Newer expired limits are kept with their usage, as history,
+// summary and trend read them.
+func (r *LimitRepository) PruneExpiredLimits(ctx context.Context, periods int) error {
+	query := `
+		WITH expired AS (
+			SELECT id FROM limits l
+			WHERE ((type = 'DAILY' AND period_end < CURRENT_TIMESTAMP - make_interval(days => $1))
+				OR (type = 'MONTHLY' AND period_end < CURRENT_TIMESTAMP - make_interval(months => $1)))
+			AND NOT EXISTS (SELECT 1 FROM limit_holds h WHERE h.limit_id = l.id AND h.status = 'HELD')
+		), spends AS (
+			DELETE FROM payment_spends WHERE limit_id IN (SELECT id FROM expired)
+		), holds AS (
+			DELETE FROM limit_holds WHERE limit_id IN (SELECT id FROM expired)
+		)
+		DELETE FROM limits WHERE id IN (SELECT id FROM expired)
+	`
+
+	result, err := database.TracedExec(ctx, r.db, "LimitRepository.PruneExpiredLimits", "limits", query, periods)
+	if err != nil {
+		return fmt.Errorf("failed to prune expired limits: %w", err)
 	}
 
+	logrus.WithField("count", result.RowsAffected()).Info("Pruned expired limits")
+
 	return nil
 }
 
-func (r *LimitRepository) getCurrentLimit(ctx context.Context, accountID string, limitType domain.LimitType) (*domain.Limit, error) {
+// FindCurrentLimits returns the account's limits for the current periods. An empty currency matches all currencies.
+func (r *LimitRepository) FindCurrentLimits(ctx context.Context, accountID, currency string) ([]*domain.Limit, error) {
+	query := `
+		SELECT id, account_id, type, amount, used, currency, period_start, period_end, created_at, updated_at, override_amount, override_expires_at
+		FROM limits
+		WHERE account_id = $1 AND ($2 = '' OR currency = $2)
+			AND period_start <= CURRENT_TIMESTAMP AND period_end >= CURRENT_TIMESTAMP
+		ORDER BY type, currency
+	`
+
+	return r.queryLimits(ctx, "LimitRepository.FindCurrentLimits", query, accountID, currency)
+}
+
+// FindLimitHistory returns the account's limits for the most recent periods (up to periods
+// per limit type), newest first. An empty currency matches all currencies.
+func (r *LimitRepository) FindLimitHistory(ctx context.Context, accountID, currency string, periods int) ([]*domain.Limit, error) {
 	query := `
-		SELECT id, account_id, type, amount, used, currency, period_start, period_end, created_at, updated_at
+		WITH recent AS (
+			SELECT type, period_start
+			FROM (
+				SELECT type, period_start, ROW_NUMBER() OVER (PARTITION BY type ORDER BY period_start DESC) AS rn
+				FROM (
+					SELECT DISTINCT type, period_start
+					FROM limits
+					WHERE account_id = $1 AND ($2 = '' OR currency = $2)
+				) p
+			) ranked
+			WHERE rn <= $3
+		)
+		SELECT l.id, l.account_id, l.type, l.amount, l.used, l.currency, l.period_start, l.period_end, l.created_at, l.updated_at, l.override_amount, l.override_expires_at
+		FROM limits l
+		JOIN recent USING (type, period_start)
+		WHERE l.account_id = $1 AND ($2 = '' OR l.currency = $2)
+		ORDER BY l.period_start DESC, l.type, l.currency
+	`
+
+	return r.queryLimits(ctx, "LimitRepository.FindLimitHistory", query, accountID, currency, periods)
+}
+
+// FindLimitsInRange returns the account's limits of a type whose periods start within [from, to),
+// oldest first. An empty currency matches all currencies.
+func (r *LimitRepository) FindLimitsInRange(ctx context.Context, accountID string, limitType domain.LimitType, currency string, from, to time.Time) ([]*domain.Limit, error) {
+	query := `
+		SELECT id, account_id, type, amount, used, currency, period_start, period_end, created_at, updated_at, override_amount, override_expires_at
 		FROM limits
-		WHERE account_id = $1 AND type = $2 AND period_end >= CURRENT_TIMESTAMP
-		ORDER BY period_end DESC
+		WHERE account_id = $1 AND type = $2 AND ($3 = '' OR currency = $3)
+		AND period_start >= $4 AND period_start < $5
+		ORDER BY period_start, currency
+	`
+
+	return r.queryLimits(ctx, "LimitRepository.FindLimitsInRange", query, accountID, string(limitType), currency, from, to)
+}
+
+// AggregateLimits sums limits sharing a type and period across currencies, converted to baseCurrency
+func (r *LimitRepository) AggregateLimits(ctx context.Context, limits []*domain.Limit, baseCurrency string) ([]*domain.LimitSummary, error) {
+	type periodKey struct {
+		limitType   domain.LimitType
+		periodStart time.Time
+	}
+
+	summaries := []*domain.LimitSummary{}
+	byPeriod := make(map[periodKey]*domain.LimitSummary)
+	for _, limit := range limits {
+		amount, err := r.converter.Convert(ctx, limit.EffectiveAmount(), limit.Currency, baseCurrency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert limit amount: %w", err)
+		}
+		used, err := r.converter.Convert(ctx, limit.Used, limit.Currency, baseCurrency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert used amount: %w", err)
+		}
+
+		key := periodKey{limitType: limit.Type, periodStart: limit.PeriodStart}
+		summary, ok := byPeriod[key]
+		if !ok {
+			summary = &domain.LimitSummary{
+				Type:        limit.Type,
+				Currency:    baseCurrency,
+				PeriodStart: limit.PeriodStart,
+				PeriodEnd:   limit.PeriodEnd,
+				Aggregated:  true,
+			}
+			byPeriod[key] = summary
+			summaries = append(summaries, summary)
+		}
+
+		summary.Amount += amount
+		summary.Used += used
+		summary.Remaining = summary.Amount - summary.Used
+		if summary.Remaining < 0 {
+			summary.Remaining = 0
+		}
+	}
+
+	return summaries, nil
+}
+
+// queryLimits runs a read-only limits query against the replica if one is configured, traced as operation
+func (r *LimitRepository) queryLimits(ctx context.Context, operation, query string, args ...interface{}) ([]*domain.Limit, error) {
+	rows, err := database.TracedQuery(ctx, r.db.Reader(), operation, "limits", query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query limits: %w", err)
+	}
+	defer rows.Close()
+
+	limits := []*domain.Limit{}
+	for rows.Next() {
+		limit, err := scanLimit(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan limit: %w", err)
+		}
+		limits = append(limits, limit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating limits: %w", err)
+	}
+
+	return limits, nil
+}
+
+// currentLimit returns the limit for the current period. An empty currency matches any currency.
+func (r *LimitRepository) currentLimit(ctx context.Context, q querier, accountID string, limitType domain.LimitType, currency string) (*domain.Limit, error) {
+	query := `
+		SELECT id, account_id, type, amount, used, currency, period_start, period_end, created_at, updated_at, override_amount, override_expires_at
+		FROM limits
+		WHERE account_id = $1 AND type = $2 AND ($3 = '' OR currency = $3) AND period_end >= CURRENT_TIMESTAMP
+		ORDER BY period_end DESC, created_at DESC
 		LIMIT 1
 	`
 
+	limit, err := scanLimit(database.TracedQueryRow(ctx, q, "LimitRepository.currentLimit", "limits", query, accountID, string(limitType), currency))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current limit: %w", err)
+	}
+
+	return limit, nil // nil if no limit found
+}
+
+// checkCurrencyCap returns domain.ErrTooManyCurrencies if creating a per-currency limit in currency
+// would give the account current-period limits in more than maxCurrencies currencies
+func (r *LimitRepository) checkCurrencyCap(ctx context.Context, accountID, currency string) error {
+	if !r.perCurrency || r.maxCurrencies <= 0 {
+		return nil
+	}
+
+	query := `
+		SELECT COUNT(DISTINCT currency), COALESCE(BOOL_OR(currency = $2), false)
+		FROM limits
+		WHERE account_id = $1 AND period_end >= CURRENT_TIMESTAMP
+	`
+
+	var currencies int
+	var known bool
+	if err := database.TracedQueryRow(ctx, r.db, "LimitRepository.checkCurrencyCap", "limits", query, accountID, currency).Scan(&currencies, &known); err != nil {
+		return fmt.Errorf("failed to count limit currencies: %w", err)
+	}
+
+	if !known && currencies >= r.maxCurrencies {
+		return fmt.Errorf("%w: %d", domain.ErrTooManyCurrencies, r.maxCurrencies)
+	}
+	return nil
+}
+
+// currencyCapResult denies a spend in a currency beyond the per-account cap. The limit it reports
+// is the default the spend would have created, not a stored one.
+func currencyCapResult(accountID string, limitType domain.LimitType, defaultLimit float64, currency string) (*domain.LimitCheckResult, error) {
+	limit, err := domain.NewLimit(accountID, limitType, defaultLimit, currency)
+	if err != nil {
+		return nil, err
+	}
+	result := domain.NewLimitCheckResult(false, limit, "Too many limit currencies for account")
+	result.ErrorCode = domain.ErrCodeTooManyCurrencies
+	return result, nil
+}
+
+// limitCurrency returns the currency a spend's limit is keyed on: its own in per-currency mode,
+// otherwise "" so the account's single limit matches whatever its currency
+func (r *LimitRepository) limitCurrency(currency string) string {
+	if r.perCurrency {
+		return currency
+	}
+	return ""
+}
+
+// toleranceFor returns the FX tolerance for a spend in currency against a limit in limitCurrency;
+// only converted amounts are subject to rate noise
+func (r *LimitRepository) toleranceFor(currency, limitCurrency string) float64 {
+	if currency == "" || currency == limitCurrency {
+		return 0
+	}
+	return r.fxToleranceBps
+}
+
+// scanLimit scans a limits row, returning nil if there was no row
+func scanLimit(row pgx.Row) (*domain.Limit, error) {
 	var limit domain.Limit
-	err := r.db.QueryRow(ctx, query, accountID, string(limitType)).Scan(
+	err := row.Scan(
 		&limit.ID,
 		&limit.AccountID,
 		&limit.Type,
@@ -137,31 +1035,40 @@ func (r *LimitRepository) getCurrentLimit(ctx context.Context, accountID string,
 		&limit.PeriodEnd,
 		&limit.CreatedAt,
 		&limit.UpdatedAt,
+		&limit.OverrideAmount,
+		&limit.OverrideExpiresAt,
 	)
 
 	if err != nil {
 		if err.Error() == "no rows in result set" {
-			return nil, nil // No limit found
+			return nil, nil
 		}
-		return nil, fmt.Errorf("failed to get current limit: %w", err)
+		return nil, err
 	}
 
 	return &limit, nil
 }
 
+// saveLimit inserts limit, or returns the row that already exists for its account, type, period
+// (and currency in per-currency mode) if another request created it first. The no-op update makes
+// the conflicting row visible to RETURNING, so both racers get the same authoritative row.
 func (r *LimitRepository) saveLimit(ctx context.Context, limit *domain.Limit) (*domain.Limit, error) {
+	conflictTarget := "(account_id, type, period_start)"
+	if r.perCurrency {
+		conflictTarget = "(account_id, type, currency, period_start)"
+	}
+
 	query := `
 		INSERT INTO limits (id, account_id, type, amount, used, currency, period_start, period_end, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		ON CONFLICT (account_id, type, period_start)
-		DO NOTHING
-		RETURNING id
+		ON CONFLICT ` + conflictTarget + ` DO UPDATE SET updated_at = limits.updated_at
+		RETURNING id, account_id, type, amount, used, currency, period_start, period_end, created_at, updated_at, override_amount, override_expires_at
 	`
 
-	limit.ID = uuid.New().String()
+	id := uuid.New().String()
 
-	err := r.db.QueryRow(ctx, query,
-		limit.ID,
+	saved, err := scanLimit(database.TracedQueryRow(ctx, r.db, "LimitRepository.saveLimit", "limits", query,
+		id,
 		limit.AccountID,
 		string(limit.Type),
 		limit.Amount,
@@ -171,21 +1078,21 @@ func (r *LimitRepository) saveLimit(ctx context.Context, limit *domain.Limit) (*
 		limit.PeriodEnd,
 		limit.CreatedAt,
 		limit.UpdatedAt,
-	).Scan(&limit.ID)
-
+	))
 	if err != nil {
-		// If conflict occurred, try to get existing limit
-		if err.Error() == "no rows in result set" {
-			return r.getCurrentLimit(ctx, limit.AccountID, limit.Type)
-		}
 		return nil, fmt.Errorf("failed to save limit: %w", err)
 	}
+	if saved == nil {
+		return nil, fmt.Errorf("failed to save limit: no row returned")
+	}
 
-	logrus.WithFields(logrus.Fields{
-		"limit_id": limit.ID,
-		"account":  limit.AccountID,
-		"type":     limit.Type,
-	}).Debug("Limit created")
+	if saved.ID == id {
+		logrus.WithFields(logrus.Fields{
+			"limit_id": saved.ID,
+			"account":  saved.AccountID,
+			"type":     saved.Type,
+		}).Debug("Limit created")
+	}
 
-	return limit, nil
+	return saved, nil
 }
@@ -0,0 +1,411 @@
+//go:build integration
+
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/fx"
+)
+
+// currentUsed returns the used amount of the account's current limit of limitType
+func currentUsed(t *testing.T, repo *LimitRepository, accountID string, limitType domain.LimitType) float64 {
+	t.Helper()
+
+	limit, err := repo.GetCurrentLimit(context.Background(), accountID, limitType)
+	if err != nil {
+		t.Fatalf("GetCurrentLimit: %v", err)
+	}
+	if limit == nil {
+		t.Fatalf("no current %s limit for %s", limitType, accountID)
+	}
+	return limit.Used
+}
+
+func createHold(t *testing.T, repo *LimitRepository, accountID string, amount float64, ttl time.Duration) *domain.LimitHold {
+	t.Helper()
+
+	hold, result, err := repo.CreateHold(context.Background(), accountID, domain.DailyLimit, amount, 1000, "USD", ttl)
+	if err != nil {
+		t.Fatalf("CreateHold: %v", err)
+	}
+	if hold == nil || !result.Allowed {
+		t.Fatalf("CreateHold denied %.2f", amount)
+	}
+	return hold
+}
+
+func TestHoldCommit(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+	accountID := newID("acc")
+
+	hold := createHold(t, repo, accountID, 300, time.Minute)
+	if used := currentUsed(t, repo, accountID, domain.DailyLimit); used != 300 {
+		t.Fatalf("used after hold = %.2f, want 300", used)
+	}
+
+	if err := repo.CommitHold(ctx, hold.Token); err != nil {
+		t.Fatalf("CommitHold: %v", err)
+	}
+	if used := currentUsed(t, repo, accountID, domain.DailyLimit); used != 300 {
+		t.Errorf("used after commit = %.2f, want 300", used)
+	}
+
+	// A committed hold is spent for good
+	if err := repo.ReleaseHold(ctx, hold.Token); !errors.Is(err, domain.ErrHoldNotActive) {
+		t.Errorf("ReleaseHold after commit = %v, want ErrHoldNotActive", err)
+	}
+	if used := currentUsed(t, repo, accountID, domain.DailyLimit); used != 300 {
+		t.Errorf("used after release of a committed hold = %.2f, want 300", used)
+	}
+}
+
+func TestHoldCancel(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+	accountID := newID("acc")
+
+	hold := createHold(t, repo, accountID, 300, time.Minute)
+
+	if err := repo.ReleaseHold(ctx, hold.Token); err != nil {
+		t.Fatalf("ReleaseHold: %v", err)
+	}
+	if used := currentUsed(t, repo, accountID, domain.DailyLimit); used != 0 {
+		t.Errorf("used after cancel = %.2f, want 0", used)
+	}
+
+	if err := repo.CommitHold(ctx, hold.Token); !errors.Is(err, domain.ErrHoldNotActive) {
+		t.Errorf("CommitHold after cancel = %v, want ErrHoldNotActive", err)
+	}
+	if err := repo.ReleaseHold(ctx, hold.Token); !errors.Is(err, domain.ErrHoldNotActive) {
+		t.Errorf("second ReleaseHold = %v, want ErrHoldNotActive", err)
+	}
+}
+
+func TestHoldExpire(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+	accountID := newID("acc")
+
+	expired := createHold(t, repo, accountID, 300, -time.Second)
+	createHold(t, repo, accountID, 200, time.Minute)
+
+	if err := repo.ExpireHolds(ctx); err != nil {
+		t.Fatalf("ExpireHolds: %v", err)
+	}
+	if used := currentUsed(t, repo, accountID, domain.DailyLimit); used != 200 {
+		t.Errorf("used after expiry = %.2f, want the active hold's 200", used)
+	}
+
+	if err := repo.CommitHold(ctx, expired.Token); !errors.Is(err, domain.ErrHoldNotActive) {
+		t.Errorf("CommitHold after expiry = %v, want ErrHoldNotActive", err)
+	}
+}
+
+func TestHoldDeniedOverLimit(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	accountID := newID("acc")
+
+	createHold(t, repo, accountID, 800, time.Minute)
+
+	hold, result, err := repo.CreateHold(context.Background(), accountID, domain.DailyLimit, 300, 1000, "USD", time.Minute)
+	if err != nil {
+		t.Fatalf("CreateHold: %v", err)
+	}
+	if hold != nil || result.Allowed {
+		t.Error("expected a hold over the limit to be denied")
+	}
+	if used := currentUsed(t, repo, accountID, domain.DailyLimit); used != 800 {
+		t.Errorf("used = %.2f, want 800", used)
+	}
+}
+
+func TestCheckAndSpendFXTolerance(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	// 1% of a 1000 USD limit; EUR spends are converted at 0.5 EUR per USD
+	repo.SetFXTolerance(100)
+
+	tests := []struct {
+		name         string
+		amount       float64
+		currency     string
+		wantAllowed  bool
+		wantTolerant bool
+	}{
+		{"converted spend within tolerance", 504, "EUR", true, true},
+		{"converted spend beyond tolerance", 510, "EUR", false, false},
+		{"unconverted spend gets no tolerance", 1008, "USD", false, false},
+		{"converted spend within the limit", 400, "EUR", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accountID := newID("acc")
+			if _, err := repo.GetOrCreateLimit(context.Background(), accountID, domain.DailyLimit, 1000, "USD"); err != nil {
+				t.Fatalf("GetOrCreateLimit: %v", err)
+			}
+
+			result, err := repo.CheckAndSpend(context.Background(), accountID, domain.DailyLimit, tt.amount, 1000, tt.currency)
+			if err != nil {
+				t.Fatalf("CheckAndSpend: %v", err)
+			}
+			if result.Allowed != tt.wantAllowed || result.ToleranceApplied != tt.wantTolerant {
+				t.Errorf("%.2f %s: allowed=%v tolerance_applied=%v, want allowed=%v tolerance_applied=%v",
+					tt.amount, tt.currency, result.Allowed, result.ToleranceApplied, tt.wantAllowed, tt.wantTolerant)
+			}
+		})
+	}
+}
+
+func TestCheckAndSpendPerCurrency(t *testing.T) {
+	repo := newPerCurrencyRepository(t)
+	ctx := context.Background()
+	accountID := newID("acc")
+
+	spends := []struct {
+		amount   float64
+		currency string
+	}{
+		{300, "USD"},
+		{200, "EUR"},
+		{150, "USD"},
+	}
+	for _, spend := range spends {
+		result, err := repo.CheckAndSpend(ctx, accountID, domain.DailyLimit, spend.amount, 1000, spend.currency)
+		if err != nil {
+			t.Fatalf("CheckAndSpend(%.2f %s): %v", spend.amount, spend.currency, err)
+		}
+		if !result.Allowed {
+			t.Fatalf("CheckAndSpend(%.2f %s) denied", spend.amount, spend.currency)
+		}
+	}
+
+	// Each currency has its own limit, spent without conversion
+	want := map[string]float64{"USD": 450, "EUR": 200}
+	for currency, used := range want {
+		limit, err := repo.GetOrCreateLimit(ctx, accountID, domain.DailyLimit, 1000, currency)
+		if err != nil {
+			t.Fatalf("GetOrCreateLimit(%s): %v", currency, err)
+		}
+		if limit.Currency != currency || limit.Used != used {
+			t.Errorf("%s limit = %.2f used in %s, want %.2f", currency, limit.Used, limit.Currency, used)
+		}
+	}
+
+	// Exhausting one currency's limit leaves the other's untouched
+	result, err := repo.CheckAndSpend(ctx, accountID, domain.DailyLimit, 600, 1000, "USD")
+	if err != nil {
+		t.Fatalf("CheckAndSpend: %v", err)
+	}
+	if result.Allowed {
+		t.Error("expected a USD spend beyond the USD limit to be denied")
+	}
+	result, err = repo.CheckAndSpend(ctx, accountID, domain.DailyLimit, 600, 1000, "EUR")
+	if err != nil {
+		t.Fatalf("CheckAndSpend: %v", err)
+	}
+	if !result.Allowed {
+		t.Error("EUR spend within the EUR limit was denied")
+	}
+}
+
+func TestCheckAndSpendConcurrentNeverExceedsLimit(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+	accountID := newID("acc")
+	if _, err := repo.GetOrCreateLimit(ctx, accountID, domain.DailyLimit, 1000, "USD"); err != nil {
+		t.Fatalf("GetOrCreateLimit: %v", err)
+	}
+
+	// 20 spends of 100 race for a 1000 limit: exactly 10 fit
+	const spends = 20
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for i := 0; i < spends; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := repo.CheckAndSpend(ctx, accountID, domain.DailyLimit, 100, 1000, "USD")
+			if err != nil {
+				t.Errorf("CheckAndSpend: %v", err)
+				return
+			}
+			if result.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 10 {
+		t.Errorf("%d of %d concurrent spends allowed, want 10", allowed, spends)
+	}
+	if used := currentUsed(t, repo, accountID, domain.DailyLimit); used != 1000 {
+		t.Errorf("used = %.2f, want exactly the 1000 limit", used)
+	}
+}
+
+func TestGetOrCreateLimitConcurrentReturnsSameRow(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+	accountID := newID("acc")
+
+	const callers = 10
+	var wg sync.WaitGroup
+	ids := make([]string, callers)
+	start := make(chan struct{})
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			limit, err := repo.GetOrCreateLimit(ctx, accountID, domain.DailyLimit, 1000, "USD")
+			if err != nil {
+				t.Errorf("GetOrCreateLimit: %v", err)
+				return
+			}
+			ids[i] = limit.ID
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	for i, id := range ids {
+		if id == "" || id != ids[0] {
+			t.Errorf("caller %d got limit %q, want %q like caller 0", i, id, ids[0])
+		}
+	}
+}
+
+func TestGetOrCreateLimitCurrencyCap(t *testing.T) {
+	repo := newPerCurrencyRepository(t)
+	repo.SetMaxCurrencies(2)
+	ctx := context.Background()
+	accountID := newID("acc")
+
+	// Up to the cap, and any currency the account already has, is allowed
+	for _, currency := range []string{"USD", "EUR", "USD"} {
+		if _, err := repo.GetOrCreateLimit(ctx, accountID, domain.DailyLimit, 1000, currency); err != nil {
+			t.Fatalf("GetOrCreateLimit(%s) at the cap: %v", currency, err)
+		}
+	}
+
+	if _, err := repo.GetOrCreateLimit(ctx, accountID, domain.DailyLimit, 1000, "SEK"); !errors.Is(err, domain.ErrTooManyCurrencies) {
+		t.Errorf("GetOrCreateLimit(SEK) over the cap = %v, want ErrTooManyCurrencies", err)
+	}
+
+	result, err := repo.CheckAndSpend(ctx, accountID, domain.DailyLimit, 100, 1000, "SEK")
+	if err != nil {
+		t.Fatalf("CheckAndSpend: %v", err)
+	}
+	if result.Allowed || result.ErrorCode != domain.ErrCodeTooManyCurrencies {
+		t.Errorf("SEK spend over the cap: allowed=%v error_code=%s, want denied with %s", result.Allowed, result.ErrorCode, domain.ErrCodeTooManyCurrencies)
+	}
+
+	var sekLimits int
+	if err := repo.db.QueryRow(ctx, "SELECT COUNT(*) FROM limits WHERE account_id = $1 AND currency = 'SEK'", accountID).Scan(&sekLimits); err != nil {
+		t.Fatalf("failed to count limits: %v", err)
+	}
+	if sekLimits != 0 {
+		t.Errorf("%d SEK limits created over the cap, want 0", sekLimits)
+	}
+}
+
+// paymentRequests returns a payment of amount USD against the account's daily and monthly limits
+func paymentRequests(accountID string, amount float64) []SpendRequest {
+	return []SpendRequest{
+		{AccountID: accountID, Type: domain.DailyLimit, Amount: amount, DefaultLimit: 1000, Currency: "USD"},
+		{AccountID: accountID, Type: domain.MonthlyLimit, Amount: amount, DefaultLimit: 5000, Currency: "USD"},
+	}
+}
+
+func TestEvaluateAllPasses(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	accountID := newID("acc")
+
+	result, err := repo.EvaluateAll(context.Background(), paymentRequests(accountID, 300))
+	if err != nil {
+		t.Fatalf("EvaluateAll: %v", err)
+	}
+	if !result.Allowed || result.FailedType != "" {
+		t.Fatalf("allowed=%v failed_type=%q, want allowed", result.Allowed, result.FailedType)
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("%d results, want one per limit type", len(result.Results))
+	}
+	for _, limitType := range []domain.LimitType{domain.DailyLimit, domain.MonthlyLimit} {
+		if used := currentUsed(t, repo, accountID, limitType); used != 300 {
+			t.Errorf("%s used = %.2f, want 300", limitType, used)
+		}
+	}
+}
+
+func TestEvaluateAllMidChainFailureSpendsNothing(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+	accountID := newID("acc")
+
+	// The daily limit allows the payment; the monthly limit after it doesn't
+	if _, err := repo.GetOrCreateLimit(ctx, accountID, domain.MonthlyLimit, 200, "USD"); err != nil {
+		t.Fatalf("GetOrCreateLimit: %v", err)
+	}
+
+	result, err := repo.EvaluateAll(ctx, paymentRequests(accountID, 300))
+	if err != nil {
+		t.Fatalf("EvaluateAll: %v", err)
+	}
+	if result.Allowed || result.FailedType != string(domain.MonthlyLimit) {
+		t.Fatalf("allowed=%v failed_type=%q, want denied by MONTHLY", result.Allowed, result.FailedType)
+	}
+	if len(result.Results) != 2 || !result.Results[0].Allowed || result.Results[1].Allowed {
+		t.Errorf("results = %+v, want DAILY allowed and MONTHLY denied", result.Results)
+	}
+	for _, limitType := range []domain.LimitType{domain.DailyLimit, domain.MonthlyLimit} {
+		if used := currentUsed(t, repo, accountID, limitType); used != 0 {
+			t.Errorf("%s used = %.2f, want 0 with no partial spend", limitType, used)
+		}
+	}
+}
+
+func TestCheckAndSpendConvertsToLimitCurrency(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+	accountID := newID("acc")
+	if _, err := repo.GetOrCreateLimit(ctx, accountID, domain.DailyLimit, 1000, "USD"); err != nil {
+		t.Fatalf("GetOrCreateLimit: %v", err)
+	}
+
+	// 400 EUR is 800 USD at 0.5 EUR per USD: within the 1000 USD limit, but a second one isn't
+	result, err := repo.CheckAndSpend(ctx, accountID, domain.DailyLimit, 400, 1000, "EUR")
+	if err != nil {
+		t.Fatalf("CheckAndSpend: %v", err)
+	}
+	if !result.Allowed {
+		t.Fatal("400 EUR spend within the USD limit was denied")
+	}
+	if used := currentUsed(t, repo, accountID, domain.DailyLimit); used != 800 {
+		t.Errorf("used = %.2f, want 800 USD", used)
+	}
+	if result, err = repo.CheckAndSpend(ctx, accountID, domain.DailyLimit, 400, 1000, "EUR"); err != nil || result.Allowed {
+		t.Errorf("second 400 EUR spend: allowed=%v err=%v, want denied", result != nil && result.Allowed, err)
+	}
+
+	// Without a rate the spend fails rather than being checked unconverted
+	if _, err := repo.CheckAndSpend(ctx, accountID, domain.DailyLimit, 1, 1000, "JPY"); !errors.Is(err, fx.ErrRateUnavailable) {
+		t.Errorf("JPY spend error = %v, want ErrRateUnavailable", err)
+	}
+	if used := currentUsed(t, repo, accountID, domain.DailyLimit); used != 800 {
+		t.Errorf("used after refused spends = %.2f, want 800 USD", used)
+	}
+}
@@ -0,0 +1,114 @@
+//go:build integration
+
+package infrastructure
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"fintech/limits-service/pkg/database"
+	"fintech/limits-service/pkg/fx"
+
+	"github.com/google/uuid"
+)
+
+// migrationLock serializes RunMigrations across test packages sharing TEST_DATABASE_URL
+const migrationLock = 741001
+
+// newTestDB connects to TEST_DATABASE_URL and migrates it, skipping the test when it isn't set.
+// Tests share the database, so each one works on its own accounts and payments.
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	return connectTestDB(t, url, database.RunMigrations)
+}
+
+// connectTestDB connects to connString and applies migrations under the migration lock
+func connectTestDB(t *testing.T, connString string, migrations ...func(db *database.DB) error) *database.DB {
+	t.Helper()
+
+	db, err := database.NewConnection(connString)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	ctx := context.Background()
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("failed to acquire connection: %v", err)
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLock); err != nil {
+		t.Fatalf("failed to take migration lock: %v", err)
+	}
+	defer conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", migrationLock)
+
+	for _, migrate := range migrations {
+		if err := migrate(db); err != nil {
+			t.Fatalf("failed to run migrations: %v", err)
+		}
+	}
+
+	return db
+}
+
+// perCurrencySchema holds the per-currency limits tables. Widening the unique constraint would
+// break single-currency conflict handling for the other tests, so it gets a schema of its own.
+const perCurrencySchema = "limits_per_currency"
+
+// newPerCurrencyRepository returns a per-currency limit repository over its own schema of the test
+// database, converting at the same rates as newTestRepository
+func newPerCurrencyRepository(t *testing.T) *LimitRepository {
+	t.Helper()
+
+	base := newTestDB(t)
+	if _, err := base.Exec(context.Background(), "CREATE SCHEMA IF NOT EXISTS "+perCurrencySchema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+
+	db := connectTestDB(t, withSearchPath(t, os.Getenv("TEST_DATABASE_URL"), perCurrencySchema), database.RunMigrations, database.MigratePerCurrencyLimits)
+	rates := fx.NewStaticRateProvider("USD", map[string]float64{"EUR": 0.5, "SEK": 10})
+	repo := NewLimitRepository(db, fx.NewConverter(rates))
+	repo.SetPerCurrency(true)
+	return repo
+}
+
+// withSearchPath returns the connection string with its search path set to schema
+func withSearchPath(t *testing.T, connString, schema string) string {
+	t.Helper()
+
+	if !strings.Contains(connString, "://") {
+		return connString + " search_path=" + schema
+	}
+	u, err := url.Parse(connString)
+	if err != nil {
+		t.Fatalf("invalid TEST_DATABASE_URL: %v", err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// newTestRepository returns a limit repository over the test database converting at fixed rates
+// of 0.5 EUR and 10 SEK per USD
+func newTestRepository(t *testing.T) (*LimitRepository, *database.DB) {
+	t.Helper()
+
+	db := newTestDB(t)
+	rates := fx.NewStaticRateProvider("USD", map[string]float64{"EUR": 0.5, "SEK": 10})
+	return NewLimitRepository(db, fx.NewConverter(rates)), db
+}
+
+// newID returns a fresh account or payment ID, so tests don't see each other's rows
+func newID(prefix string) string {
+	return prefix + "-" + uuid.New().String()
+}
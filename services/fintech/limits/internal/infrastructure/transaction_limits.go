@@ -0,0 +1,69 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+
+	"fintech/limits-service/internal/domain"
+	"fintech/limits-service/pkg/database"
+)
+
+// CheckPerTransactionLimit checks a single payment against the account's per-transaction maximum,
+// or defaultMax in defaultCurrency for accounts without one. The amount is converted to the
+// maximum's currency first. It returns nil if the payment is within the maximum or no maximum
+// applies, and the denied result otherwise. Nothing is spent.
+func (r *LimitRepository) CheckPerTransactionLimit(ctx context.Context, accountID string, amount float64, currency string, defaultMax float64, defaultCurrency string) (*domain.LimitCheckResult, error) {
+	limit, err := r.GetTransactionLimit(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if limit == nil {
+		if defaultMax <= 0 {
+			return nil, nil
+		}
+		limit = &domain.TransactionLimit{AccountID: accountID, MaxAmount: defaultMax, Currency: defaultCurrency}
+	}
+
+	converted, err := r.converter.Convert(ctx, amount, currency, limit.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if limit.Allows(converted) {
+		return nil, nil
+	}
+
+	return limit.DeniedResult(), nil
+}
+
+// GetTransactionLimit returns the account's per-transaction maximum, or nil if it has none
+func (r *LimitRepository) GetTransactionLimit(ctx context.Context, accountID string) (*domain.TransactionLimit, error) {
+	var limit domain.TransactionLimit
+	err := database.TracedQueryRow(ctx, r.db, "LimitRepository.GetTransactionLimit", "transaction_limits", `
+		SELECT account_id, max_amount, currency, updated_at
+		FROM transaction_limits
+		WHERE account_id = $1
+	`, accountID).Scan(&limit.AccountID, &limit.MaxAmount, &limit.Currency, &limit.UpdatedAt)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get transaction limit: %w", err)
+	}
+
+	return &limit, nil
+}
+
+// SetTransactionLimit creates or replaces the account's per-transaction maximum
+func (r *LimitRepository) SetTransactionLimit(ctx context.Context, limit *domain.TransactionLimit) error {
+	_, err := database.TracedExec(ctx, r.db, "LimitRepository.SetTransactionLimit", "transaction_limits", `
+		INSERT INTO transaction_limits (account_id, max_amount, currency, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (account_id) DO UPDATE
+		SET max_amount = EXCLUDED.max_amount, currency = EXCLUDED.currency, updated_at = EXCLUDED.updated_at
+	`, limit.AccountID, limit.MaxAmount, limit.Currency, limit.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set transaction limit: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+
+	"fintech/limits-service/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const velocityColumns = "id, account_id, type, max_count, tx_count, period_start, period_end, created_at, updated_at"
+
+// CheckAndIncrement counts one transaction against the account's velocity limit for the current
+// period, creating it with maxCount if needed. Creation, the check and the increment are a single
+// statement. It reports false, with the limit's current state, if the count is already at its maximum.
+func (r *LimitRepository) CheckAndIncrement(ctx context.Context, accountID string, limitType domain.LimitType, maxCount int) (*domain.VelocityLimit, bool, error) {
+	limit, err := domain.NewVelocityLimit(accountID, limitType, maxCount)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := limit.IncrementCount(); err != nil {
+		return nil, false, err
+	}
+
+	query := `
+		INSERT INTO velocity_limits (` + velocityColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (account_id, type, period_start) DO UPDATE
+		SET tx_count = velocity_limits.tx_count + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE velocity_limits.tx_count < velocity_limits.max_count
+		RETURNING ` + velocityColumns
+
+	counted, err := scanVelocityLimit(r.db.QueryRow(ctx, query,
+		uuid.New().String(),
+		limit.AccountID,
+		string(limit.Type),
+		limit.MaxCount,
+		limit.TxCount,
+		limit.PeriodStart,
+		limit.PeriodEnd,
+		limit.CreatedAt,
+		limit.UpdatedAt,
+	))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to increment velocity limit: %w", err)
+	}
+	if counted != nil {
+		return counted, true, nil
+	}
+
+	current, err := scanVelocityLimit(r.db.QueryRow(ctx, `
+		SELECT `+velocityColumns+`
+		FROM velocity_limits
+		WHERE account_id = $1 AND type = $2 AND period_start = $3
+	`, accountID, string(limitType), limit.PeriodStart))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get velocity limit: %w", err)
+	}
+	if current == nil {
+		return nil, false, fmt.Errorf("failed to get velocity limit: no row for account %s", accountID)
+	}
+
+	return current, false, nil
+}
+
+// DecrementCount takes back a transaction counted by CheckAndIncrement, e.g. when the payment was
+// then denied by its amount limit. The count never drops below zero.
+func (r *LimitRepository) DecrementCount(ctx context.Context, accountID string, limitType domain.LimitType) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE velocity_limits
+		SET tx_count = GREATEST(tx_count - 1, 0), updated_at = CURRENT_TIMESTAMP
+		WHERE account_id = $1 AND type = $2 AND period_end >= CURRENT_TIMESTAMP
+	`, accountID, string(limitType))
+	if err != nil {
+		return fmt.Errorf("failed to decrement velocity limit: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"account": accountID,
+		"type":    limitType,
+	}).Debug("Velocity limit decremented")
+
+	return nil
+}
+
+// scanVelocityLimit scans a velocity_limits row, returning nil if there was no row
+func scanVelocityLimit(row pgx.Row) (*domain.VelocityLimit, error) {
+	var limit domain.VelocityLimit
+	err := row.Scan(
+		&limit.ID,
+		&limit.AccountID,
+		&limit.Type,
+		&limit.MaxCount,
+		&limit.TxCount,
+		&limit.PeriodStart,
+		&limit.PeriodEnd,
+		&limit.CreatedAt,
+		&limit.UpdatedAt,
+	)
+
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &limit, nil
+}
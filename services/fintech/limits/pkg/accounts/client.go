@@ -0,0 +1,72 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"fintech/limits-service/pkg/otel"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// ErrAccountNotFound is returned when the accounts service doesn't know the account
+var ErrAccountNotFound = errors.New("account not found")
+
+// Profile is the account history credit scoring needs
+type Profile struct {
+	AccountAgeDays int `json:"accountAgeDays"`
+	PaymentCount   int `json:"paymentCount"`
+}
+
+// Client fetches account profiles from an accounts service that answers
+// GET {baseURL}/accounts/{accountId}/profile with {"accountAgeDays": 400, "paymentCount": 12}
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewClient creates a client for the accounts service at baseURL
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// GetAccountProfile fetches the age and payment history of an account
+func (c *Client) GetAccountProfile(ctx context.Context, accountID string) (*Profile, error) {
+	ctx, span := otel.StartSpan(ctx, "AccountsClient.GetAccountProfile")
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/accounts/"+url.PathEscape(accountID)+"/profile", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build accounts request: %w", err)
+	}
+	otel.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account profile: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, accountID)
+	default:
+		return nil, fmt.Errorf("accounts service returned status %d", resp.StatusCode)
+	}
+
+	var profile Profile
+	if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil {
+		return nil, fmt.Errorf("failed to decode account profile: %w", err)
+	}
+	return &profile, nil
+}
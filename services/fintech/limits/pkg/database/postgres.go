@@ -2,7 +2,6 @@ package database
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -10,13 +9,51 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// DB represents database connection
+// DB represents database connection. Writes and reads that need fresh data go through the
+// embedded primary pool; read-only queries that tolerate replication lag go through Reader.
 type DB struct {
 	*pgxpool.Pool
+	replica *pgxpool.Pool // nil when no replica is configured
 }
 
 // NewConnection creates a new PostgreSQL connection pool
 func NewConnection(databaseURL string) (*DB, error) {
+	pool, err := connect(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Info("Successfully connected to PostgreSQL")
+	return &DB{Pool: pool}, nil
+}
+
+// ConnectReplica opens a pool to a read-only replica that Reader routes queries to. An empty URL
+// leaves reads on the primary.
+func (db *DB) ConnectReplica(replicaURL string) error {
+	if replicaURL == "" {
+		return nil
+	}
+
+	pool, err := connect(replicaURL)
+	if err != nil {
+		return fmt.Errorf("replica: %w", err)
+	}
+
+	db.replica = pool
+	logrus.Info("Successfully connected to PostgreSQL replica")
+	return nil
+}
+
+// Reader returns the pool for read-only queries: the replica if one is configured, otherwise the primary
+func (db *DB) Reader() *pgxpool.Pool {
+	if db.replica != nil {
+		return db.replica
+	}
+	return db.Pool
+}
+
+// connect creates and pings a connection pool
+func connect(databaseURL string) (*pgxpool.Pool, error) {
 	config, err := pgxpool.ParseConfig(databaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse database URL: %w", err)
@@ -37,8 +74,7 @@ func NewConnection(databaseURL string) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	logrus.Info("Successfully connected to PostgreSQL")
-	return &DB{pool}, nil
+	return pool, nil
 }
 
 // RunMigrations runs database migrations
@@ -70,6 +106,16 @@ func RunMigrations(db *DB) error {
 		return fmt.Errorf("failed to create limits table: %w", err)
 	}
 
+	// Temporary increases, added after the limits table shipped
+	_, err = db.Exec(ctx, `
+		ALTER TABLE limits
+			ADD COLUMN IF NOT EXISTS override_amount DECIMAL(19,4),
+			ADD COLUMN IF NOT EXISTS override_expires_at TIMESTAMP WITH TIME ZONE
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add limits override columns: %w", err)
+	}
+
 	// Create indexes
 	_, err = db.Exec(ctx, `
 		CREATE INDEX IF NOT EXISTS idx_limits_account_type_period
@@ -87,12 +133,194 @@ func RunMigrations(db *DB) error {
 		return fmt.Errorf("failed to create index: %w", err)
 	}
 
+	// Create velocity limits table (transaction counts per period)
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS velocity_limits (
+			id UUID PRIMARY KEY,
+			account_id VARCHAR(255) NOT NULL,
+			type VARCHAR(20) NOT NULL CHECK (type IN ('DAILY', 'MONTHLY')),
+			max_count INTEGER NOT NULL,
+			tx_count INTEGER NOT NULL DEFAULT 0,
+			period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+			period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(account_id, type, period_start)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create velocity_limits table: %w", err)
+	}
+
+	// Create limit releases table (idempotency for payment reversals)
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS limit_releases (
+			payment_id VARCHAR(255) PRIMARY KEY,
+			account_id VARCHAR(255) NOT NULL,
+			amount DECIMAL(19,4) NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create limit_releases table: %w", err)
+	}
+
+	// Create payment spends table (what each payment spent from which limit, for releases and rollbacks)
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS payment_spends (
+			payment_id VARCHAR(255) NOT NULL,
+			limit_id UUID NOT NULL REFERENCES limits(id),
+			amount DECIMAL(19,4) NOT NULL,
+			released_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (payment_id, limit_id)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create payment_spends table: %w", err)
+	}
+
+	// Create processed events table (idempotency for initiated payments)
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS processed_events (
+			idempotency_key VARCHAR(255) PRIMARY KEY,
+			payment_id VARCHAR(255) NOT NULL,
+			account_id VARCHAR(255) NOT NULL,
+			processed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create processed_events table: %w", err)
+	}
+
+	// Create transaction limits table (per-account maximum for a single payment)
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS transaction_limits (
+			account_id VARCHAR(255) PRIMARY KEY,
+			max_amount DECIMAL(19,4) NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction_limits table: %w", err)
+	}
+
+	// Create limit holds table
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS limit_holds (
+			token UUID PRIMARY KEY,
+			limit_id UUID NOT NULL REFERENCES limits(id),
+			account_id VARCHAR(255) NOT NULL,
+			type VARCHAR(20) NOT NULL,
+			amount DECIMAL(19,4) NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			status VARCHAR(20) NOT NULL CHECK (status IN ('HELD', 'COMMITTED', 'RELEASED', 'EXPIRED')),
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create limit_holds table: %w", err)
+	}
+
+	_, err = db.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS idx_limit_holds_status_expires
+		ON limit_holds(status, expires_at)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	// Create loan decisions table (versioned scoring outcomes)
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS loan_decisions (
+			id UUID PRIMARY KEY,
+			application_id VARCHAR(255) NOT NULL,
+			version INTEGER NOT NULL,
+			original_decision_id UUID REFERENCES loan_decisions(id),
+			account_id VARCHAR(255) NOT NULL,
+			requested_amount DECIMAL(19,4) NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			account_age_days INTEGER NOT NULL,
+			previous_payments INTEGER NOT NULL,
+			score INTEGER NOT NULL,
+			grade VARCHAR(2) NOT NULL,
+			risk_level VARCHAR(20) NOT NULL,
+			approved BOOLEAN NOT NULL,
+			max_amount DECIMAL(19,4) NOT NULL,
+			reason TEXT NOT NULL,
+			overridden_by VARCHAR(255),
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(application_id, version)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create loan_decisions table: %w", err)
+	}
+
+	// Create audit log table
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS audit_log (
+			id VARCHAR(64) PRIMARY KEY,
+			event_type VARCHAR(100) NOT NULL,
+			account_id VARCHAR(255) NOT NULL,
+			user_id VARCHAR(255),
+			action VARCHAR(50) NOT NULL,
+			resource VARCHAR(50) NOT NULL,
+			details TEXT,
+			ip_address VARCHAR(64),
+			user_agent TEXT,
+			severity VARCHAR(10) NOT NULL,
+			timestamp TIMESTAMP WITH TIME ZONE NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create audit_log table: %w", err)
+	}
+
+	_, err = db.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS idx_audit_log_account_timestamp ON audit_log(account_id, timestamp)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create audit_log index: %w", err)
+	}
+
 	logrus.Info("Database migrations completed successfully")
 	return nil
 }
 
+// MigratePerCurrencyLimits widens the limits unique constraint to include currency, so an account
+// can hold one limit per currency for each period. It isn't reverted automatically: going back to
+// a single limit per period means merging the per-currency rows first.
+func MigratePerCurrencyLimits(db *DB) error {
+	_, err := db.Exec(context.Background(), `
+		DO $$
+		BEGIN
+			ALTER TABLE limits DROP CONSTRAINT IF EXISTS limits_account_id_type_period_start_key;
+			IF NOT EXISTS (
+				SELECT 1 FROM pg_constraint WHERE conname = 'limits_account_id_type_currency_period_start_key'
+			) THEN
+				ALTER TABLE limits ADD CONSTRAINT limits_account_id_type_currency_period_start_key
+					UNIQUE (account_id, type, currency, period_start);
+			END IF;
+		END $$
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to migrate limits to per-currency: %w", err)
+	}
+
+	logrus.Info("Limits are keyed per currency")
+	return nil
+}
+
 // Close closes the database connection
 func (db *DB) Close() {
+	if db.replica != nil {
+		db.replica.Close()
+	}
 	if db.Pool != nil {
 		db.Pool.Close()
 		logrus.Info("Database connection closed")
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// newPool returns a pool that is never connected; pgxpool only dials on first use
+func newPool(t *testing.T, url string) *pgxpool.Pool {
+	t.Helper()
+
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("pgxpool.New: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestReaderFallsBackToPrimary(t *testing.T) {
+	primary := newPool(t, "postgres://primary.invalid:5432/app")
+	db := &DB{Pool: primary}
+
+	if db.Reader() != primary {
+		t.Error("Reader without a replica is not the primary pool")
+	}
+	if err := db.ConnectReplica(""); err != nil || db.Reader() != primary {
+		t.Errorf("ConnectReplica(\"\") = %v, want reads left on the primary", err)
+	}
+}
+
+func TestReaderUsesReplica(t *testing.T) {
+	primary := newPool(t, "postgres://primary.invalid:5432/app")
+	replica := newPool(t, "postgres://replica.invalid:5432/app")
+	db := &DB{Pool: primary, replica: replica}
+
+	if db.Reader() != replica {
+		t.Error("Reader with a replica is not the replica pool")
+	}
+	if db.Pool != primary {
+		t.Error("writes no longer go to the primary pool")
+	}
+}
@@ -0,0 +1,55 @@
+package flags
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want bool
+	}{
+		{"empty", "", false},
+		{"single flag", "spend_rollback", true},
+		{"case and spacing", " SPEND_ROLLBACK ,", true},
+		{"among unknown flags", "rolling_limits,spend_rollback,step_up", true},
+		{"only unknown flags", "rolling_limits,step_up", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.raw).Enabled(SpendRollback); got != tt.want {
+				t.Errorf("Parse(%q).Enabled(%s) = %v, want %v", tt.raw, SpendRollback, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWarnsOnUnknownFlags(t *testing.T) {
+	hook := test.NewGlobal()
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+
+	s := Parse("spend_rollback,rolling_limits")
+	if s.Enabled("rolling_limits") {
+		t.Error("unknown flag rolling_limits is enabled")
+	}
+
+	entries := hook.AllEntries()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(entries))
+	}
+	if entries[0].Level != logrus.WarnLevel || entries[0].Data["flag"] != "rolling_limits" {
+		t.Errorf("logged %s %v, want a warning for rolling_limits", entries[0].Level, entries[0].Data)
+	}
+}
+
+func TestNilSetDisablesEveryFlag(t *testing.T) {
+	var s *Set
+	if s.Enabled(SpendRollback) {
+		t.Errorf("nil set has %s enabled", SpendRollback)
+	}
+}
@@ -0,0 +1,168 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrCircuitOpen is returned when the breaker is open and no last-known rate exists
+var ErrCircuitOpen = errors.New("fx rate provider circuit open")
+
+// BreakerState is the state of a circuit breaker
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerHalfOpen
+	BreakerOpen
+)
+
+var (
+	breakerStateGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "fx_rate_breaker_state",
+		Help: "State of the FX rate provider circuit breaker (0 closed, 1 half-open, 2 open).",
+	})
+	rateStalenessGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "fx_rate_staleness_seconds",
+		Help: "Age of the last-known FX rate served while the rate provider is unavailable.",
+	}, []string{"pair"})
+)
+
+// CircuitBreakerRateProvider stops calling a failing provider for a cool-down period after
+// consecutive failures, serving the last rate it successfully fetched for the pair instead
+type CircuitBreakerRateProvider struct {
+	provider    RateProvider
+	maxFailures int
+	openTimeout time.Duration
+	now         func() time.Time
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	lastKnown map[string]cachedRate
+}
+
+// NewCircuitBreakerRateProvider opens the breaker after maxFailures consecutive failures
+// and lets a trial request through once openTimeout has passed
+func NewCircuitBreakerRateProvider(provider RateProvider, maxFailures int, openTimeout time.Duration) *CircuitBreakerRateProvider {
+	if maxFailures <= 0 {
+		maxFailures = 1
+	}
+
+	return &CircuitBreakerRateProvider{
+		provider:    provider,
+		maxFailures: maxFailures,
+		openTimeout: openTimeout,
+		now:         time.Now,
+		lastKnown:   make(map[string]cachedRate),
+	}
+}
+
+// State returns the current breaker state
+func (p *CircuitBreakerRateProvider) State() BreakerState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state
+}
+
+// Rate returns the provider's rate while the breaker is closed, or the last-known rate while it is open
+func (p *CircuitBreakerRateProvider) Rate(ctx context.Context, from, to string) (float64, error) {
+	key := strings.ToUpper(from) + "/" + strings.ToUpper(to)
+
+	if !p.allow() {
+		return p.lastKnownRate(key, ErrCircuitOpen)
+	}
+
+	rate, err := p.provider.Rate(ctx, from, to)
+	if err != nil {
+		if p.recordFailure() {
+			return p.lastKnownRate(key, err)
+		}
+		return 0, err
+	}
+
+	p.recordSuccess(key, rate)
+	return rate, nil
+}
+
+// allow reports whether a request may reach the provider, moving an expired open breaker to half-open
+func (p *CircuitBreakerRateProvider) allow() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.state == BreakerOpen {
+		if p.now().Sub(p.openedAt) < p.openTimeout {
+			return false
+		}
+		p.setState(BreakerHalfOpen)
+	}
+	return true
+}
+
+// recordFailure counts a failure and reports whether the breaker is now open
+func (p *CircuitBreakerRateProvider) recordFailure() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.failures++
+	if p.state == BreakerHalfOpen || p.failures >= p.maxFailures {
+		if p.state != BreakerOpen {
+			logrus.WithField("failures", p.failures).Warn("FX rate provider circuit opened")
+		}
+		p.setState(BreakerOpen)
+		p.openedAt = p.now()
+	}
+	return p.state == BreakerOpen
+}
+
+func (p *CircuitBreakerRateProvider) recordSuccess(key string, rate float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.state != BreakerClosed {
+		logrus.Info("FX rate provider circuit closed")
+	}
+	p.failures = 0
+	p.setState(BreakerClosed)
+	p.lastKnown[key] = cachedRate{rate: rate, fetchedAt: p.now()}
+	rateStalenessGauge.WithLabelValues(key).Set(0)
+}
+
+// lastKnownRate serves the last fetched rate for key, or cause wrapped in ErrCircuitOpen if there is none
+func (p *CircuitBreakerRateProvider) lastKnownRate(key string, cause error) (float64, error) {
+	p.mu.Lock()
+	cached, ok := p.lastKnown[key]
+	now := p.now()
+	p.mu.Unlock()
+
+	if !ok {
+		if errors.Is(cause, ErrCircuitOpen) {
+			return 0, fmt.Errorf("%w: no last-known rate for %s", ErrCircuitOpen, key)
+		}
+		return 0, fmt.Errorf("%w: no last-known rate for %s: %v", ErrCircuitOpen, key, cause)
+	}
+
+	staleness := now.Sub(cached.fetchedAt)
+	rateStalenessGauge.WithLabelValues(key).Set(staleness.Seconds())
+	logrus.WithFields(logrus.Fields{
+		"pair":      key,
+		"staleness": staleness.String(),
+	}).Warn("FX rate provider unavailable, using last-known rate")
+
+	return cached.rate, nil
+}
+
+// setState updates the state and its gauge; callers must hold p.mu
+func (p *CircuitBreakerRateProvider) setState(state BreakerState) {
+	p.state = state
+	breakerStateGauge.Set(float64(state))
+}
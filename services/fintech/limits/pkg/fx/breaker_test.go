@@ -0,0 +1,130 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeRateProvider returns rate, or err when set, counting its calls
+type fakeRateProvider struct {
+	rate  float64
+	err   error
+	calls int
+}
+
+func (p *fakeRateProvider) Rate(ctx context.Context, from, to string) (float64, error) {
+	p.calls++
+	if p.err != nil {
+		return 0, p.err
+	}
+	return p.rate, nil
+}
+
+// newTestBreaker returns a breaker over provider opening after two failures for a minute, and a
+// pointer to its clock
+func newTestBreaker(provider RateProvider) (*CircuitBreakerRateProvider, *time.Time) {
+	breaker := NewCircuitBreakerRateProvider(provider, 2, time.Minute)
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+	return breaker, &now
+}
+
+func TestCircuitBreakerClosed(t *testing.T) {
+	provider := &fakeRateProvider{rate: 1.08}
+	breaker, _ := newTestBreaker(provider)
+
+	for i := 0; i < 3; i++ {
+		rate, err := breaker.Rate(context.Background(), "EUR", "USD")
+		if err != nil {
+			t.Fatalf("Rate: %v", err)
+		}
+		if rate != 1.08 {
+			t.Errorf("rate = %v, want 1.08", rate)
+		}
+	}
+	if provider.calls != 3 {
+		t.Errorf("provider called %d times, want 3", provider.calls)
+	}
+	if state := breaker.State(); state != BreakerClosed {
+		t.Errorf("state = %v, want closed", state)
+	}
+}
+
+func TestCircuitBreakerOpenServesLastKnownRate(t *testing.T) {
+	provider := &fakeRateProvider{rate: 1.08}
+	breaker, now := newTestBreaker(provider)
+	ctx := context.Background()
+
+	if _, err := breaker.Rate(ctx, "EUR", "USD"); err != nil {
+		t.Fatalf("Rate: %v", err)
+	}
+
+	provider.err = errors.New("rate source down")
+	*now = now.Add(10 * time.Second)
+	if _, err := breaker.Rate(ctx, "EUR", "USD"); err == nil {
+		t.Error("expected the first failure to be returned while the breaker is closed")
+	}
+	rate, err := breaker.Rate(ctx, "EUR", "USD")
+	if err != nil {
+		t.Fatalf("Rate on opening: %v", err)
+	}
+	if rate != 1.08 || breaker.State() != BreakerOpen {
+		t.Fatalf("rate = %v in state %v, want the last-known 1.08 with the breaker open", rate, breaker.State())
+	}
+
+	// While open the provider isn't called
+	calls := provider.calls
+	*now = now.Add(20 * time.Second)
+	if rate, err := breaker.Rate(ctx, "EUR", "USD"); err != nil || rate != 1.08 {
+		t.Errorf("Rate while open = %v, %v, want the last-known 1.08", rate, err)
+	}
+	if provider.calls != calls {
+		t.Errorf("provider called %d times while open, want 0", provider.calls-calls)
+	}
+	if staleness := testutil.ToFloat64(rateStalenessGauge.WithLabelValues("EUR/USD")); staleness != 30 {
+		t.Errorf("staleness = %vs, want 30s", staleness)
+	}
+	if state := testutil.ToFloat64(breakerStateGauge); state != float64(BreakerOpen) {
+		t.Errorf("state gauge = %v, want %d", state, BreakerOpen)
+	}
+}
+
+func TestCircuitBreakerOpenWithoutLastKnownRate(t *testing.T) {
+	provider := &fakeRateProvider{err: errors.New("rate source down")}
+	breaker, _ := newTestBreaker(provider)
+	ctx := context.Background()
+
+	breaker.Rate(ctx, "EUR", "SEK")
+	if _, err := breaker.Rate(ctx, "EUR", "SEK"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Rate on opening = %v, want ErrCircuitOpen", err)
+	}
+	if _, err := breaker.Rate(ctx, "EUR", "SEK"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Rate while open = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestCircuitBreakerClosesAfterSuccessfulTrial(t *testing.T) {
+	provider := &fakeRateProvider{err: errors.New("rate source down")}
+	breaker, now := newTestBreaker(provider)
+	ctx := context.Background()
+
+	breaker.Rate(ctx, "EUR", "USD")
+	breaker.Rate(ctx, "EUR", "USD")
+	if state := breaker.State(); state != BreakerOpen {
+		t.Fatalf("state = %v, want open", state)
+	}
+
+	provider.err, provider.rate = nil, 1.1
+	*now = now.Add(time.Minute)
+	rate, err := breaker.Rate(ctx, "EUR", "USD")
+	if err != nil || rate != 1.1 {
+		t.Errorf("Rate after the open timeout = %v, %v, want the live 1.1", rate, err)
+	}
+	if state := breaker.State(); state != BreakerClosed {
+		t.Errorf("state = %v after a successful trial, want closed", state)
+	}
+}
@@ -0,0 +1,74 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrRateUnavailable is returned when no exchange rate is known for a currency pair
+var ErrRateUnavailable = errors.New("exchange rate unavailable")
+
+// RateProvider supplies exchange rates between currencies
+type RateProvider interface {
+	// Rate returns how many units of `to` one unit of `from` buys
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// Converter converts amounts between currencies using a RateProvider
+type Converter struct {
+	provider RateProvider
+}
+
+// NewConverter creates a new converter backed by the given rate provider
+func NewConverter(provider RateProvider) *Converter {
+	return &Converter{provider: provider}
+}
+
+// Convert converts amount from one currency to another. Empty or equal currencies are a no-op.
+func (c *Converter) Convert(ctx context.Context, amount float64, from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == "" || to == "" || from == to {
+		return amount, nil
+	}
+
+	rate, err := c.provider.Rate(ctx, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert %s to %s: %w", from, to, err)
+	}
+
+	return amount * rate, nil
+}
+
+// StaticRateProvider serves fixed rates from configuration. Rates are expressed as
+// units of each currency per one unit of the base currency.
+type StaticRateProvider struct {
+	base  string
+	rates map[string]float64
+}
+
+// NewStaticRateProvider creates a provider from fixed rates relative to base
+func NewStaticRateProvider(base string, rates map[string]float64) *StaticRateProvider {
+	normalized := make(map[string]float64, len(rates)+1)
+	for currency, rate := range rates {
+		normalized[strings.ToUpper(currency)] = rate
+	}
+	normalized[strings.ToUpper(base)] = 1
+
+	return &StaticRateProvider{base: strings.ToUpper(base), rates: normalized}
+}
+
+// Rate returns the cross rate between two configured currencies
+func (p *StaticRateProvider) Rate(ctx context.Context, from, to string) (float64, error) {
+	fromRate, ok := p.rates[strings.ToUpper(from)]
+	if !ok || fromRate <= 0 {
+		return 0, fmt.Errorf("%w: %s", ErrRateUnavailable, from)
+	}
+	toRate, ok := p.rates[strings.ToUpper(to)]
+	if !ok || toRate <= 0 {
+		return 0, fmt.Errorf("%w: %s", ErrRateUnavailable, to)
+	}
+
+	return toRate / fromRate, nil
+}
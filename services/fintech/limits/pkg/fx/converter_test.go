@@ -0,0 +1,49 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestConverterConvert(t *testing.T) {
+	converter := NewConverter(NewStaticRateProvider("usd", map[string]float64{"eur": 0.5, "SEK": 10}))
+
+	tests := []struct {
+		name   string
+		amount float64
+		from   string
+		to     string
+		want   float64
+	}{
+		{"same currency", 1000, "USD", "USD", 1000},
+		{"no currency", 1000, "", "USD", 1000},
+		{"from base", 1000, "USD", "EUR", 500},
+		{"to base", 1000, "EUR", "USD", 2000},
+		{"cross rate", 1000, "EUR", "SEK", 20000},
+		{"lowercase codes", 1000, "eur", "usd", 2000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := converter.Convert(context.Background(), tt.amount, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("Convert: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Convert(%.2f %s to %s) = %.2f, want %.2f", tt.amount, tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConverterRateUnavailable(t *testing.T) {
+	converter := NewConverter(NewStaticRateProvider("USD", map[string]float64{"EUR": 0.5}))
+
+	for _, pair := range [][2]string{{"JPY", "USD"}, {"USD", "JPY"}} {
+		if _, err := converter.Convert(context.Background(), 1000, pair[0], pair[1]); !errors.Is(err, ErrRateUnavailable) {
+			t.Errorf("Convert(%s to %s) error = %v, want ErrRateUnavailable", pair[0], pair[1], err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HTTPRateProvider fetches rates from an FX service that answers
+// GET {baseURL}?base=EUR&symbols=USD with {"rates": {"USD": 1.08}}
+type HTTPRateProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPRateProvider creates a provider for the FX service at baseURL
+func NewHTTPRateProvider(baseURL string, timeout time.Duration) *HTTPRateProvider {
+	return &HTTPRateProvider{
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Rate fetches the current rate for a currency pair
+func (p *HTTPRateProvider) Rate(ctx context.Context, from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+
+	query := url.Values{"base": {from}, "symbols": {to}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build FX request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch FX rate: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("FX service returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("failed to decode FX response: %w", err)
+	}
+
+	rate, ok := body.Rates[to]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("%w: %s/%s", ErrRateUnavailable, from, to)
+	}
+
+	return rate, nil
+}
+
+// FallbackRateProvider uses the primary provider, falling back to the secondary on error
+type FallbackRateProvider struct {
+	primary  RateProvider
+	fallback RateProvider
+}
+
+// NewFallbackRateProvider creates a provider that falls back when primary fails
+func NewFallbackRateProvider(primary, fallback RateProvider) *FallbackRateProvider {
+	return &FallbackRateProvider{primary: primary, fallback: fallback}
+}
+
+// Rate returns the primary rate, or the fallback rate if the primary errors
+func (p *FallbackRateProvider) Rate(ctx context.Context, from, to string) (float64, error) {
+	rate, err := p.primary.Rate(ctx, from, to)
+	if err == nil {
+		return rate, nil
+	}
+
+	logrus.WithError(err).WithFields(logrus.Fields{
+		"from": from,
+		"to":   to,
+	}).Warn("FX rate source failed, using fallback rates")
+
+	return p.fallback.Rate(ctx, from, to)
+}
+
+// CachingRateProvider caches rates from another provider for a TTL
+type CachingRateProvider struct {
+	provider RateProvider
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedRate
+}
+
+type cachedRate struct {
+	rate      float64
+	fetchedAt time.Time
+}
+
+// NewCachingRateProvider wraps provider with a TTL cache
+func NewCachingRateProvider(provider RateProvider, ttl time.Duration) *CachingRateProvider {
+	return &CachingRateProvider{
+		provider: provider,
+		ttl:      ttl,
+		now:      time.Now,
+		cache:    make(map[string]cachedRate),
+	}
+}
+
+// Rate returns a cached rate if still fresh, otherwise fetches and caches a new one
+func (p *CachingRateProvider) Rate(ctx context.Context, from, to string) (float64, error) {
+	key := strings.ToUpper(from) + "/" + strings.ToUpper(to)
+
+	p.mu.Lock()
+	cached, ok := p.cache[key]
+	p.mu.Unlock()
+	if ok && p.now().Sub(cached.fetchedAt) < p.ttl {
+		return cached.rate, nil
+	}
+
+	rate, err := p.provider.Rate(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	p.mu.Lock()
+	p.cache[key] = cachedRate{rate: rate, fetchedAt: p.now()}
+	p.mu.Unlock()
+
+	return rate, nil
+}
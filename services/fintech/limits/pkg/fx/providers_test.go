@@ -0,0 +1,128 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeRateSource answers every request with status and body, counting the requests
+func fakeRateSource(t *testing.T, status int, body string) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestHTTPRateProvider(t *testing.T) {
+	var gotBase, gotSymbols string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBase, gotSymbols = r.URL.Query().Get("base"), r.URL.Query().Get("symbols")
+		w.Write([]byte(`{"rates": {"USD": 1.08}}`))
+	}))
+	defer server.Close()
+
+	rate, err := NewHTTPRateProvider(server.URL, time.Second).Rate(context.Background(), "eur", "usd")
+	if err != nil {
+		t.Fatalf("Rate: %v", err)
+	}
+	if rate != 1.08 {
+		t.Errorf("rate = %v, want 1.08", rate)
+	}
+	if gotBase != "EUR" || gotSymbols != "USD" {
+		t.Errorf("requested base=%s symbols=%s, want base=EUR symbols=USD", gotBase, gotSymbols)
+	}
+}
+
+func TestHTTPRateProviderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"server error", http.StatusInternalServerError, ""},
+		{"malformed body", http.StatusOK, "not json"},
+		{"missing rate", http.StatusOK, `{"rates": {"GBP": 0.85}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := fakeRateSource(t, tt.status, tt.body)
+			if _, err := NewHTTPRateProvider(server.URL, time.Second).Rate(context.Background(), "EUR", "USD"); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestFallbackRateProviderUsesLiveRate(t *testing.T) {
+	server, _ := fakeRateSource(t, http.StatusOK, `{"rates": {"EUR": 0.9}}`)
+	provider := NewFallbackRateProvider(NewHTTPRateProvider(server.URL, time.Second), NewStaticRateProvider("USD", map[string]float64{"EUR": 0.92}))
+
+	rate, err := provider.Rate(context.Background(), "USD", "EUR")
+	if err != nil {
+		t.Fatalf("Rate: %v", err)
+	}
+	if rate != 0.9 {
+		t.Errorf("rate = %v, want the live 0.9", rate)
+	}
+}
+
+func TestFallbackRateProviderFallsBackToConfigRates(t *testing.T) {
+	server, requests := fakeRateSource(t, http.StatusServiceUnavailable, "")
+	provider := NewFallbackRateProvider(NewHTTPRateProvider(server.URL, time.Second), NewStaticRateProvider("USD", map[string]float64{"EUR": 0.92}))
+
+	rate, err := provider.Rate(context.Background(), "USD", "EUR")
+	if err != nil {
+		t.Fatalf("Rate: %v", err)
+	}
+	if rate != 0.92 {
+		t.Errorf("rate = %v, want the configured 0.92", rate)
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("live source requested %d times, want 1", got)
+	}
+}
+
+func TestFallbackRateProviderUnknownCurrency(t *testing.T) {
+	server, _ := fakeRateSource(t, http.StatusServiceUnavailable, "")
+	provider := NewFallbackRateProvider(NewHTTPRateProvider(server.URL, time.Second), NewStaticRateProvider("USD", map[string]float64{"EUR": 0.92}))
+
+	if _, err := provider.Rate(context.Background(), "USD", "JPY"); !errors.Is(err, ErrRateUnavailable) {
+		t.Errorf("Rate error = %v, want ErrRateUnavailable", err)
+	}
+}
+
+func TestCachingRateProvider(t *testing.T) {
+	server, requests := fakeRateSource(t, http.StatusOK, `{"rates": {"USD": 1.08}}`)
+	provider := NewCachingRateProvider(NewHTTPRateProvider(server.URL, time.Second), time.Minute)
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := provider.Rate(context.Background(), "EUR", "USD"); err != nil {
+			t.Fatalf("Rate: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("live source requested %d times within the TTL, want 1", got)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := provider.Rate(context.Background(), "EUR", "USD"); err != nil {
+		t.Fatalf("Rate: %v", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("live source requested %d times after the TTL, want 2", got)
+	}
+}
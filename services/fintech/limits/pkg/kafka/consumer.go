@@ -3,81 +3,427 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
 )
 
-// PaymentInitiatedEvent represents a payment initiation event
+// Payment lifecycle event types carried in PaymentInitiatedEvent.EventType
+const (
+	PaymentInitiated = "PaymentInitiated"
+	PaymentCompleted = "PaymentCompleted"
+	PaymentFailed    = "PaymentFailed"
+	PaymentReversed  = "PaymentReversed"
+)
+
+// PaymentInitiatedEvent represents an event on the payments topic. Despite its name it carries
+// every payment lifecycle event; EventType tells them apart and is PaymentInitiated when empty,
+// as sent by producers that predate it.
 type PaymentInitiatedEvent struct {
+	EventType     string  `json:"eventType,omitempty"`
 	PaymentID    string  `json:"paymentId"`
 	IdempotencyKey string `json:"idempotencyKey"`
 	FromAccountID string `json:"fromAccountId"`
 	ToAccountID   string `json:"toAccountId"`
 	Amount        float64 `json:"amount"`
 	Currency      string `json:"currency"`
+	Reason        string  `json:"reason,omitempty"` // Why a payment failed or was reversed
+}
+
+// ErrInvalidEvent marks an event that decoded but is structurally invalid; such events are
+// dead-lettered instead of handled
+var ErrInvalidEvent = errors.New("invalid event")
+
+// Validate checks the event is structurally sound before it reaches the business handler
+func (e *PaymentInitiatedEvent) Validate() error {
+	switch {
+	case e.PaymentID == "":
+		return fmt.Errorf("%w: paymentId is required", ErrInvalidEvent)
+	case e.FromAccountID == "":
+		return fmt.Errorf("%w: fromAccountId is required", ErrInvalidEvent)
+	case !(e.Amount > 0) || math.IsInf(e.Amount, 0):
+		return fmt.Errorf("%w: amount must be a positive number", ErrInvalidEvent)
+	case e.Currency != "" && len(e.Currency) != 3:
+		return fmt.Errorf("%w: currency must be a 3-letter ISO 4217 code", ErrInvalidEvent)
+	}
+
+	switch e.EventType {
+	case "", PaymentInitiated, PaymentCompleted, PaymentFailed, PaymentReversed:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown eventType %q", ErrInvalidEvent, e.EventType)
+}
+
+// PaymentReversedEvent represents a full or partial reversal of a previously initiated payment
+type PaymentReversedEvent struct {
+	PaymentID     string  `json:"paymentId"`
+	FromAccountID string  `json:"fromAccountId"`
+	Amount        float64 `json:"amount"` // Amount being reversed (may be less than the original)
+	Currency      string  `json:"currency"`
+	Reason        string  `json:"reason,omitempty"`
+}
+
+// AccountCreatedEvent represents a newly opened account
+type AccountCreatedEvent struct {
+	AccountID string    `json:"accountId"`
+	Currency  string    `json:"currency,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Waits between attempts at a message that failed and couldn't be dead-lettered; the wait doubles
+// per attempt up to maxRetryBackoff
+const (
+	retryBackoff    = time.Second
+	maxRetryBackoff = time.Minute
+)
+
+// messageReader is the part of *kafka.Reader the consumer and reprocessor use
+type messageReader interface {
+	Config() kafka.ReaderConfig
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, messages ...kafka.Message) error
+	Close() error
+}
+
+// messageWriter is the part of *kafka.Writer the consumer and reprocessor use to dead-letter and park messages
+type messageWriter interface {
+	WriteMessages(ctx context.Context, messages ...kafka.Message) error
+	Close() error
 }
 
 // Consumer handles Kafka message consumption
 type Consumer struct {
-	reader *kafka.Reader
+	reader      messageReader
+	concurrency int
+	retryBackoff time.Duration // Wait before the first retry of a message that couldn't be dead-lettered
+	maxEventAge time.Duration    // 0 disables the age check
+	deadLetter  messageWriter    // nil when no dead letter topic is configured
+	transport   *kafka.Transport // Dead letter writer transport, with the reader's security settings
+	handlers    map[string]EventHandler
 }
 
-// NewConsumer creates a new Kafka consumer
-func NewConsumer(brokers string, groupID string, topic string) (*Consumer, error) {
+// NewConsumer creates a new Kafka consumer, connecting with the given TLS and SASL settings
+func NewConsumer(brokers string, groupID string, topic string, security SecurityConfig) (*Consumer, error) {
+	dialer, err := newDialer(security)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := newTransport(security)
+	if err != nil {
+		return nil, err
+	}
+
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:     []string{brokers},
 		GroupID:     groupID,
 		Topic:       topic,
+		Dialer:      dialer,
 		MinBytes:    10e3, // 10KB
 		MaxBytes:    10e6, // 10MB
 		StartOffset: kafka.LastOffset, // Start from the end
 	})
 
-	return &Consumer{reader: reader}, nil
+	return &Consumer{reader: reader, concurrency: 1, retryBackoff: retryBackoff, transport: transport}, nil
+}
+
+// WithConcurrency lets up to n messages be handled at once. Messages from the same
+// partition are still handled one at a time, in order.
+func (c *Consumer) WithConcurrency(n int) *Consumer {
+	if n > 1 {
+		c.concurrency = n
+	}
+	return c
+}
+
+// WithMaxEventAge skips events whose Kafka timestamp is older than age instead of handling them.
+// A zero age disables the check.
+func (c *Consumer) WithMaxEventAge(age time.Duration) *Consumer {
+	if age > 0 {
+		c.maxEventAge = age
+	}
+	return c
+}
+
+// WithDeadLetterTopic sends skipped and invalid events to topic rather than dropping them. An empty topic disables it.
+func (c *Consumer) WithDeadLetterTopic(brokers string, topic string) *Consumer {
+	if topic != "" {
+		c.deadLetter = &kafka.Writer{
+			Addr:      kafka.TCP(brokers),
+			Topic:     topic,
+			Balancer:  &kafka.Hash{},
+			Transport: c.transport,
+		}
+	}
+	return c
+}
+
+// Start begins consuming messages and calls the handler for each message, with a context carrying
+// the producer's trace context from the message headers. Enveloped events of a type registered with
+// RegisterHandler go to that handler instead.
+func (c *Consumer) Start(ctx context.Context, handler func(ctx context.Context, event *PaymentInitiatedEvent) error) error {
+	return c.consume(ctx, paymentProcessor(handler, c.handlers))
+}
+
+// StartReversals begins consuming payment reversal events and calls the handler for each message
+func (c *Consumer) StartReversals(ctx context.Context, handler func(event *PaymentReversedEvent) error) error {
+	return c.consume(ctx, reversalProcessor(handler))
+}
+
+// StartAccountEvents begins consuming account created events and calls the handler for each message
+func (c *Consumer) StartAccountEvents(ctx context.Context, handler func(event *AccountCreatedEvent) error) error {
+	return c.consume(ctx, accountProcessor(handler))
+}
+
+// paymentProcessor decodes and validates payment events before calling handler, bare or enveloped.
+// Enveloped events of a type in handlers are passed to that handler instead.
+func paymentProcessor(handler func(ctx context.Context, event *PaymentInitiatedEvent) error, handlers map[string]EventHandler) func(ctx context.Context, value []byte) (string, error) {
+	return func(ctx context.Context, value []byte) (string, error) {
+		var event PaymentInitiatedEvent
+		if envelope, ok := decodeEnvelope(value); ok {
+			if registered, ok := handlers[envelope.Type]; ok {
+				return "", registered(ctx, envelope.Payload)
+			}
+			if err := json.Unmarshal(envelope.Payload, &event); err != nil {
+				return "", fmt.Errorf("%w: failed to unmarshal %s event: %v", ErrInvalidEvent, envelope.Type, err)
+			}
+			event.EventType = envelope.Type
+		} else if err := json.Unmarshal(value, &event); err != nil {
+			return "", fmt.Errorf("%w: failed to unmarshal payment event: %v", ErrInvalidEvent, err)
+		}
+		if err := event.Validate(); err != nil {
+			return event.PaymentID, err
+		}
+		return event.PaymentID, handler(ctx, &event)
+	}
+}
+
+// reversalProcessor decodes payment reversal events before calling handler
+func reversalProcessor(handler func(event *PaymentReversedEvent) error) func(ctx context.Context, value []byte) (string, error) {
+	return func(_ context.Context, value []byte) (string, error) {
+		var event PaymentReversedEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			return "", fmt.Errorf("%w: failed to unmarshal payment reversed event: %v", ErrInvalidEvent, err)
+		}
+		return event.PaymentID, handler(&event)
+	}
+}
+
+// accountProcessor decodes account created events before calling handler
+func accountProcessor(handler func(event *AccountCreatedEvent) error) func(ctx context.Context, value []byte) (string, error) {
+	return func(_ context.Context, value []byte) (string, error) {
+		var event AccountCreatedEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			return "", fmt.Errorf("%w: failed to unmarshal account created event: %v", ErrInvalidEvent, err)
+		}
+		return event.AccountID, handler(&event)
+	}
 }
 
-// Start begins consuming messages and calls the handler for each message
-func (c *Consumer) Start(ctx context.Context, handler func(event *PaymentInitiatedEvent) error) error {
+// consume reads messages until ctx is cancelled, passing each raw value to process
+func (c *Consumer) consume(ctx context.Context, process func(ctx context.Context, value []byte) (string, error)) error {
 	logrus.WithField("topic", c.reader.Config().Topic).Info("Starting Kafka consumer")
 
+	if c.concurrency > 1 {
+		return c.consumeConcurrently(ctx, process)
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			logrus.Info("Stopping Kafka consumer")
 			return ctx.Err()
 		default:
-			message, err := c.reader.ReadMessage(ctx)
+			// Fetch rather than read so the offset is only committed once the message is handled,
+			// and a message interrupted by shutdown or a crash is redelivered instead of lost
+			message, err := c.reader.FetchMessage(ctx)
 			if err != nil {
+				if ctx.Err() != nil {
+					logrus.Info("Stopping Kafka consumer")
+					return ctx.Err()
+				}
 				logrus.WithError(err).Error("Failed to read message from Kafka")
 				continue
 			}
 
-			// Parse the event
-			var event PaymentInitiatedEvent
-			if err := json.Unmarshal(message.Value, &event); err != nil {
-				logrus.WithError(err).WithField("message", string(message.Value)).Error("Failed to unmarshal payment event")
-				continue
+			if c.handleUntilDone(ctx, message, process) {
+				c.commit(message)
 			}
+		}
+	}
+}
 
-			// Handle the event
-			if err := handler(&event); err != nil {
-				logrus.WithError(err).WithField("event", event).Error("Failed to handle payment event")
-				// In production, you might want to send to a dead letter queue
-				continue
+// consumeConcurrently fans messages out to a fixed pool of workers keyed by partition, so each
+// partition is handled and committed in order by a single worker while partitions run in parallel.
+// Offsets are committed explicitly once a message has been handled.
+func (c *Consumer) consumeConcurrently(ctx context.Context, process func(ctx context.Context, value []byte) (string, error)) error {
+	workers := make([]chan kafka.Message, c.concurrency)
+	var wg sync.WaitGroup
+	for i := range workers {
+		workers[i] = make(chan kafka.Message)
+		wg.Add(1)
+		go func(messages <-chan kafka.Message) {
+			defer wg.Done()
+			for message := range messages {
+				if c.handleUntilDone(ctx, message, process) {
+					c.commit(message)
+				}
+			}
+		}(workers[i])
+	}
+
+	// Let in-flight messages finish before returning
+	defer func() {
+		for _, messages := range workers {
+			close(messages)
+		}
+		wg.Wait()
+	}()
+
+	for {
+		message, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				logrus.Info("Stopping Kafka consumer")
+				return ctx.Err()
 			}
+			logrus.WithError(err).Error("Failed to fetch message from Kafka")
+			continue
+		}
 
-			logrus.WithFields(logrus.Fields{
-				"payment_id": event.PaymentID,
-				"offset":     message.Offset,
-			}).Debug("Successfully processed payment event")
+		select {
+		case workers[message.Partition%len(workers)] <- message:
+		case <-ctx.Done():
+			logrus.Info("Stopping Kafka consumer")
+			return ctx.Err()
 		}
 	}
 }
 
+// commit commits a message's offset, even during shutdown so finished work isn't redelivered.
+// Broadcast consumers read outside a consumer group and have no offsets to commit.
+func (c *Consumer) commit(message kafka.Message) {
+	if c.reader.Config().GroupID == "" {
+		return
+	}
+	if err := c.reader.CommitMessages(context.Background(), message); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"partition": message.Partition,
+			"offset":    message.Offset,
+		}).Error("Failed to commit Kafka offset")
+	}
+}
+
+// handleUntilDone handles a message, retrying it in place with backoff while it fails and can't be
+// dead-lettered, e.g. when no dead letter topic is configured. Later messages of the partition wait,
+// since committing one of them would commit past the failed message. It reports false, leaving the
+// message uncommitted to be redelivered, only if ctx is cancelled first.
+func (c *Consumer) handleUntilDone(ctx context.Context, message kafka.Message, process func(ctx context.Context, value []byte) (string, error)) bool {
+	backoff := c.retryBackoff
+	for attempt := 1; ; attempt++ {
+		if c.handle(message, process) {
+			return true
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"partition": message.Partition,
+			"offset":    message.Offset,
+			"attempt":   attempt,
+			"backoff":   backoff,
+		}).Warn("Message failed and was not dead-lettered, retrying")
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// handle processes a single message, logging rather than returning failures. It reports whether
+// the message is done with and its offset may be committed: handled, dead-lettered, or one that
+// can never succeed. A failed message that couldn't be dead-lettered reports false, to be retried.
+func (c *Consumer) handle(message kafka.Message, process func(ctx context.Context, value []byte) (string, error)) bool {
+	if c.isStale(message) {
+		logrus.WithFields(logrus.Fields{
+			"partition": message.Partition,
+			"offset":    message.Offset,
+			"timestamp": message.Time,
+		}).Warn("Skipping event older than the maximum event age")
+		c.sendToDeadLetter(message, "event older than maximum age", false)
+		return true
+	}
+
+	// Parse and handle the event, continuing the producer's trace
+	paymentID, err := process(messageContext(message), message.Value)
+	if errors.Is(err, ErrInvalidEvent) {
+		logrus.WithError(err).WithField("message", string(message.Value)).Warn("Rejecting invalid event")
+		c.sendToDeadLetter(message, err.Error(), false)
+		return true
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("message", string(message.Value)).Error("Failed to handle payment event")
+		return c.sendToDeadLetter(message, err.Error(), true)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"payment_id": paymentID,
+		"partition":  message.Partition,
+		"offset":     message.Offset,
+	}).Debug("Successfully processed payment event")
+	return true
+}
+
+// isStale reports whether the message timestamp is older than the configured maximum event age
+func (c *Consumer) isStale(message kafka.Message) bool {
+	if c.maxEventAge == 0 || message.Time.IsZero() {
+		return false
+	}
+	return time.Since(message.Time) > c.maxEventAge
+}
+
+// sendToDeadLetter forwards a message that wasn't handled to the dead letter topic, if one is
+// configured, reporting whether it was written. Retryable messages are picked up again by the
+// Reprocessor.
+func (c *Consumer) sendToDeadLetter(message kafka.Message, reason string, retryable bool) bool {
+	if c.deadLetter == nil {
+		return false
+	}
+
+	headers := setHeader(message.Headers, headerReason, reason)
+	headers = setHeader(headers, headerSourceTopic, message.Topic)
+	headers = setHeader(headers, headerRetryable, strconv.FormatBool(retryable))
+
+	err := c.deadLetter.WriteMessages(context.Background(), kafka.Message{
+		Key:     message.Key,
+		Value:   message.Value,
+		Headers: headers,
+	})
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"partition": message.Partition,
+			"offset":    message.Offset,
+		}).Error("Failed to send message to dead letter topic")
+		return false
+	}
+	return true
+}
+
 // Close closes the Kafka consumer
 func (c *Consumer) Close() error {
 	logrus.Info("Closing Kafka consumer")
+	if c.deadLetter != nil {
+		if err := c.deadLetter.Close(); err != nil {
+			logrus.WithError(err).Error("Failed to close dead letter writer")
+		}
+	}
 	return c.reader.Close()
 }
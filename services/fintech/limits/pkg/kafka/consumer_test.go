@@ -0,0 +1,349 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeReader serves queued messages to the consumer and records its commits
+type fakeReader struct {
+	messages chan kafka.Message
+
+	mu        sync.Mutex
+	committed []kafka.Message
+}
+
+func newFakeReader(messages ...kafka.Message) *fakeReader {
+	r := &fakeReader{messages: make(chan kafka.Message, len(messages))}
+	for _, message := range messages {
+		r.messages <- message
+	}
+	return r
+}
+
+func (r *fakeReader) Config() kafka.ReaderConfig {
+	return kafka.ReaderConfig{Topic: "payments", GroupID: "limits-service"}
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case message := <-r.messages:
+		return message, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, messages ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.committed = append(r.committed, messages...)
+	return nil
+}
+
+func (r *fakeReader) Close() error {
+	return nil
+}
+
+// fakeWriter records the messages written to the dead letter topic
+type fakeWriter struct {
+	mu       sync.Mutex
+	messages []kafka.Message
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, messages ...kafka.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, messages...)
+	return nil
+}
+
+func (w *fakeWriter) Close() error {
+	return nil
+}
+
+func (w *fakeWriter) written() []kafka.Message {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]kafka.Message(nil), w.messages...)
+}
+
+// countingProcess is a process func that counts its calls and succeeds
+func countingProcess(calls *int) func(ctx context.Context, value []byte) (string, error) {
+	return func(ctx context.Context, value []byte) (string, error) {
+		*calls++
+		return "pay-1", nil
+	}
+}
+
+// committedOffsets returns the committed offsets by partition, in commit order
+func (r *fakeReader) committedOffsets() map[int][]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	offsets := make(map[int][]int64)
+	for _, message := range r.committed {
+		offsets[message.Partition] = append(offsets[message.Partition], message.Offset)
+	}
+	return offsets
+}
+
+func TestConsumeConcurrentlyKeepsPartitionOrder(t *testing.T) {
+	const partitions, perPartition = 2, 5
+
+	// Interleave the partitions, as a reader fetching from both would
+	var messages []kafka.Message
+	for offset := 0; offset < perPartition; offset++ {
+		for partition := 0; partition < partitions; partition++ {
+			messages = append(messages, kafka.Message{
+				Partition: partition,
+				Offset:    int64(offset),
+				Value:     []byte(fmt.Sprintf("%d:%d", partition, offset)),
+			})
+		}
+	}
+	reader := newFakeReader(messages...)
+	c := &Consumer{reader: reader, concurrency: partitions, retryBackoff: time.Millisecond}
+
+	var (
+		mu       sync.Mutex
+		handled  = make(map[int][]int)
+		inFlight = make(map[int]int)
+		started  sync.WaitGroup
+		done     sync.WaitGroup
+	)
+	started.Add(partitions)
+	done.Add(len(messages))
+
+	// The first message of each partition waits for the other partitions' first messages,
+	// which only arrive if partitions are handled in parallel
+	bothStarted := make(chan struct{})
+	go func() {
+		started.Wait()
+		close(bothStarted)
+	}()
+
+	process := func(ctx context.Context, value []byte) (string, error) {
+		defer done.Done()
+
+		var partition, offset int
+		if _, err := fmt.Sscanf(string(value), "%d:%d", &partition, &offset); err != nil {
+			return "", err
+		}
+
+		mu.Lock()
+		inFlight[partition]++
+		if inFlight[partition] > 1 {
+			t.Errorf("partition %d handled two messages at once", partition)
+		}
+		mu.Unlock()
+
+		if offset == 0 {
+			started.Done()
+			select {
+			case <-bothStarted:
+			case <-time.After(5 * time.Second):
+				t.Errorf("partition %d waited for the other partitions: not handled in parallel", partition)
+			}
+		}
+
+		mu.Lock()
+		inFlight[partition]--
+		handled[partition] = append(handled[partition], offset)
+		mu.Unlock()
+		return "", nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- c.consume(ctx, process) }()
+
+	done.Wait()
+	cancel()
+	if err := <-result; !errors.Is(err, context.Canceled) {
+		t.Errorf("consume = %v, want context.Canceled", err)
+	}
+
+	committed := reader.committedOffsets()
+	for partition := 0; partition < partitions; partition++ {
+		for i, offset := range handled[partition] {
+			if offset != i {
+				t.Errorf("partition %d handled offsets %v, want them in order", partition, handled[partition])
+				break
+			}
+		}
+		if got := committed[partition]; len(got) != perPartition || got[perPartition-1] != perPartition-1 {
+			t.Errorf("partition %d committed offsets %v, want each of its %d messages in order", partition, got, perPartition)
+		}
+	}
+}
+
+func TestHandleMaxEventAge(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxEventAge time.Duration
+		age         time.Duration
+		wantHandled bool
+	}{
+		{"fresh event", time.Hour, time.Minute, true},
+		{"stale event", time.Hour, 2 * time.Hour, false},
+		{"old event with the check disabled", 0, 48 * time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &fakeWriter{}
+			c := &Consumer{maxEventAge: tt.maxEventAge, deadLetter: writer}
+			message := kafka.Message{Topic: "payments", Value: []byte(`{}`), Time: time.Now().Add(-tt.age)}
+
+			var calls int
+			if done := c.handle(message, countingProcess(&calls)); !done {
+				t.Error("handle reported the message as not done")
+			}
+
+			if handled := calls == 1; handled != tt.wantHandled {
+				t.Errorf("handled = %v, want %v", handled, tt.wantHandled)
+			}
+			dead := writer.written()
+			if tt.wantHandled {
+				if len(dead) != 0 {
+					t.Errorf("dead-lettered %d messages, want none", len(dead))
+				}
+				return
+			}
+			if len(dead) != 1 || header(dead[0], headerReason) != "event older than maximum age" || header(dead[0], headerRetryable) != "false" {
+				t.Errorf("dead-lettered %+v, want the stale event as non-retryable", dead)
+			}
+		})
+	}
+}
+
+func TestHandleValidatesPaymentEvents(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantHandled bool
+		wantReason  string
+	}{
+		{"valid event", `{"paymentId": "pay-1", "fromAccountId": "acc-1", "amount": 100, "currency": "USD"}`, true, ""},
+		{"negative amount", `{"paymentId": "pay-1", "fromAccountId": "acc-1", "amount": -5, "currency": "USD"}`, false, "amount must be a positive number"},
+		{"empty account ID", `{"paymentId": "pay-1", "fromAccountId": "", "amount": 100, "currency": "USD"}`, false, "fromAccountId is required"},
+		{"malformed currency", `{"paymentId": "pay-1", "fromAccountId": "acc-1", "amount": 100, "currency": "DOLLARS"}`, false, "currency must be a 3-letter ISO 4217 code"},
+		{"unknown event type", `{"eventType": "PaymentTeleported", "paymentId": "pay-1", "fromAccountId": "acc-1", "amount": 100}`, false, `unknown eventType "PaymentTeleported"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &fakeWriter{}
+			c := &Consumer{deadLetter: writer}
+
+			var handled []*PaymentInitiatedEvent
+			process := paymentProcessor(func(ctx context.Context, event *PaymentInitiatedEvent) error {
+				handled = append(handled, event)
+				return nil
+			}, nil)
+			if done := c.handle(kafka.Message{Topic: "payments", Value: []byte(tt.value)}, process); !done {
+				t.Error("handle reported the message as not done")
+			}
+
+			dead := writer.written()
+			if tt.wantHandled {
+				if len(handled) != 1 || handled[0].PaymentID != "pay-1" {
+					t.Errorf("handled %+v, want the event", handled)
+				}
+				if len(dead) != 0 {
+					t.Errorf("dead-lettered %d messages, want none", len(dead))
+				}
+				return
+			}
+			if len(handled) != 0 {
+				t.Errorf("business handler called with %+v, want the event rejected first", handled)
+			}
+			if len(dead) != 1 || !strings.Contains(header(dead[0], headerReason), tt.wantReason) || header(dead[0], headerRetryable) != "false" {
+				t.Errorf("dead-lettered %+v, want the event as non-retryable with reason %q", dead, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestConsumeLeavesFailedMessageUncommitted(t *testing.T) {
+	reader := newFakeReader(kafka.Message{Partition: 0, Offset: 7, Value: []byte("pay-1")})
+	// No dead letter topic, so a failed message can only be retried in place
+	c := &Consumer{reader: reader, concurrency: 1, retryBackoff: time.Millisecond}
+
+	attempts := make(chan struct{}, 10)
+	process := func(ctx context.Context, value []byte) (string, error) {
+		select {
+		case attempts <- struct{}{}:
+		default:
+		}
+		return "pay-1", errors.New("database unavailable")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- c.consume(ctx, process) }()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-attempts:
+		case <-time.After(5 * time.Second):
+			t.Fatal("failed message was not retried")
+		}
+	}
+	cancel()
+	if err := <-result; !errors.Is(err, context.Canceled) {
+		t.Errorf("consume = %v, want context.Canceled", err)
+	}
+
+	if committed := reader.committedOffsets(); len(committed) != 0 {
+		t.Errorf("committed offsets %v, want none for a message whose handler failed", committed)
+	}
+}
+
+func TestConsumeCommitsOnlyAfterHandlerSucceeds(t *testing.T) {
+	reader := newFakeReader(kafka.Message{Partition: 0, Offset: 7, Value: []byte("pay-1")})
+	c := &Consumer{reader: reader, concurrency: 1, retryBackoff: time.Millisecond}
+
+	var calls int
+	handled := make(chan struct{})
+	process := func(ctx context.Context, value []byte) (string, error) {
+		calls++
+		if calls == 1 {
+			if committed := reader.committedOffsets(); len(committed) != 0 {
+				t.Errorf("committed offsets %v before the handler returned", committed)
+			}
+			return "pay-1", errors.New("database unavailable")
+		}
+		close(handled)
+		return "pay-1", nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- c.consume(ctx, process) }()
+
+	select {
+	case <-handled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was not retried after its handler failed")
+	}
+	// The commit follows the handler's return; wait for it before stopping the consumer
+	deadline := time.Now().Add(5 * time.Second)
+	for len(reader.committedOffsets()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-result
+
+	if got := reader.committedOffsets()[0]; len(got) != 1 || got[0] != 7 {
+		t.Errorf("committed offsets %v, want offset 7 once", got)
+	}
+}
@@ -0,0 +1,70 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Control commands carried in ControlCommand.Command
+const (
+	ControlSetReadOnly  = "set_read_only"
+	ControlSetLimitType = "set_limit_type"
+	ControlSetLogLevel  = "set_log_level"
+)
+
+// ControlCommand is a runtime toggle broadcast to every instance on the control topic. Commands
+// set state rather than flip it, so applying one twice, or replaying the topic, is harmless.
+//
+//	{"command": "set_read_only", "enabled": true}
+//	{"command": "set_limit_type", "limitType": "MONTHLY", "enabled": false}
+//	{"command": "set_log_level", "level": "debug"}
+type ControlCommand struct {
+	Command   string `json:"command"`
+	Enabled   *bool  `json:"enabled,omitempty"`
+	LimitType string `json:"limitType,omitempty"`
+	Level     string `json:"level,omitempty"`
+}
+
+// NewBroadcastConsumer creates a consumer outside any consumer group, so every instance reads every
+// message rather than a share of them. It reads partition 0 from the first offset, replaying past
+// messages on startup; the topic should have a single partition.
+func NewBroadcastConsumer(brokers string, topic string, security SecurityConfig) (*Consumer, error) {
+	dialer, err := newDialer(security)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := newTransport(security)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     []string{brokers},
+		Topic:       topic,
+		Partition:   0,
+		Dialer:      dialer,
+		MaxBytes:    10e6, // 10MB
+		StartOffset: kafka.FirstOffset,
+	})
+
+	return &Consumer{reader: reader, concurrency: 1, transport: transport}, nil
+}
+
+// StartControl begins consuming control commands and calls the handler for each message
+func (c *Consumer) StartControl(ctx context.Context, handler func(command *ControlCommand) error) error {
+	return c.consume(ctx, controlProcessor(handler))
+}
+
+// controlProcessor decodes control commands before calling handler
+func controlProcessor(handler func(command *ControlCommand) error) func(ctx context.Context, value []byte) (string, error) {
+	return func(_ context.Context, value []byte) (string, error) {
+		var command ControlCommand
+		if err := json.Unmarshal(value, &command); err != nil {
+			return "", fmt.Errorf("%w: failed to unmarshal control command: %v", ErrInvalidEvent, err)
+		}
+		return command.Command, handler(&command)
+	}
+}
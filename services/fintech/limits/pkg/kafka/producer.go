@@ -0,0 +1,82 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fintech/limits-service/pkg/otel"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// LimitCheckCompleted is the event type of LimitCheckCompletedEvent
+const LimitCheckCompleted = "LimitCheckCompleted"
+
+// LimitCheckCompletedEvent reports the outcome of checking a payment against the account's limits,
+// for downstream services such as payments and fraud
+type LimitCheckCompletedEvent struct {
+	EventType string          `json:"eventType"`
+	PaymentID string          `json:"paymentId"`
+	AccountID string          `json:"accountId"`
+	Amount    float64         `json:"amount"`
+	Currency  string          `json:"currency"`
+	Allowed   bool            `json:"allowed"`
+	DeniedBy  string          `json:"deniedBy,omitempty"` // Limit type that denied the payment, if any
+	Velocity  bool            `json:"velocity,omitempty"` // Denied by DeniedBy's transaction count rather than its amount
+	Results   []LimitDecision `json:"results"`
+	CheckedAt time.Time       `json:"checkedAt"`
+}
+
+// LimitDecision is the outcome of checking a payment against one limit
+type LimitDecision struct {
+	LimitType   string  `json:"limitType"`
+	Allowed     bool    `json:"allowed"`
+	Remaining   float64 `json:"remaining"`
+	LimitAmount float64 `json:"limitAmount"`
+	UsedAmount  float64 `json:"usedAmount"`
+	PeriodLabel string  `json:"periodLabel"`
+}
+
+// Producer publishes messages to Kafka topics
+type Producer struct {
+	writer *kafka.Writer
+}
+
+// NewProducer creates a Kafka producer, connecting with the given TLS and SASL settings
+func NewProducer(brokers string, security SecurityConfig) (*Producer, error) {
+	transport, err := newTransport(security)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Producer{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		Transport:    transport,
+	}}, nil
+}
+
+// Publish writes value to topic under key, so messages with the same key stay in order on one
+// partition. The trace context of ctx is carried in the message headers.
+func (p *Producer) Publish(ctx context.Context, topic, key string, value []byte) error {
+	carrier := &headerCarrier{}
+	otel.Inject(ctx, carrier)
+
+	err := p.writer.WriteMessages(ctx, kafka.Message{
+		Topic:   topic,
+		Key:     []byte(key),
+		Value:   value,
+		Headers: carrier.headers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Close flushes pending messages and closes the producer
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,295 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// Dead letter message headers
+const (
+	headerReason      = "dlq-reason"
+	headerSourceTopic = "dlq-source-topic"
+	headerRetryable   = "dlq-retryable"
+	headerAttempts    = "dlq-attempts"
+)
+
+// maxReprocessBackoff caps the wait between reprocess attempts
+const maxReprocessBackoff = time.Hour
+
+// Reprocess outcomes
+const (
+	outcomeRecovered = "recovered" // Handled successfully
+	outcomeRetried   = "retried"   // Failed again and re-queued on the dead letter topic
+	outcomeParked    = "parked"    // Moved to the parking topic
+)
+
+var reprocessOutcomes = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "kafka_dlq_reprocess_total",
+	Help: "Dead-lettered messages reprocessed, by outcome.",
+}, []string{"source_topic", "outcome"})
+
+// Reprocessor consumes the dead letter topic and retries each message through the handler for its
+// source topic, waiting longer before each attempt. Messages that still fail after maxAttempts, or
+// that can't be retried (invalid or stale events), are moved to the parking topic.
+type Reprocessor struct {
+	reader      messageReader
+	deadLetter  messageWriter
+	parking     messageWriter // nil when no parking topic is configured; parked messages are dropped
+	processors  map[string]func(ctx context.Context, value []byte) (string, error)
+	handlers    map[string]map[string]EventHandler // Enveloped event handlers by source topic and event type
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// NewReprocessor creates a dead letter reprocessor. The first retry waits backoff after the message
+// was dead-lettered, and each further retry waits twice as long.
+func NewReprocessor(brokers, groupID, deadLetterTopic, parkingTopic string, security SecurityConfig, maxAttempts int, backoff time.Duration) (*Reprocessor, error) {
+	dialer, err := newDialer(security)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := newTransport(security)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reprocessor{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers:  []string{brokers},
+			GroupID:  groupID,
+			Topic:    deadLetterTopic,
+			Dialer:   dialer,
+			MinBytes: 1,
+			MaxBytes: 10e6, // 10MB
+		}),
+		deadLetter: &kafka.Writer{
+			Addr:      kafka.TCP(brokers),
+			Topic:     deadLetterTopic,
+			Balancer:  &kafka.Hash{},
+			Transport: transport,
+		},
+		processors:  make(map[string]func(ctx context.Context, value []byte) (string, error)),
+		handlers:    make(map[string]map[string]EventHandler),
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+	}
+	if parkingTopic != "" {
+		r.parking = &kafka.Writer{
+			Addr:      kafka.TCP(brokers),
+			Topic:     parkingTopic,
+			Balancer:  &kafka.Hash{},
+			Transport: transport,
+		}
+	}
+
+	return r, nil
+}
+
+// HandlePayments reprocesses payment events dead-lettered from topic
+func (r *Reprocessor) HandlePayments(topic string, handler func(ctx context.Context, event *PaymentInitiatedEvent) error) {
+	r.processors[topic] = paymentProcessor(handler, r.eventHandlers(topic))
+}
+
+// HandleReversals reprocesses payment reversal events dead-lettered from topic
+func (r *Reprocessor) HandleReversals(topic string, handler func(event *PaymentReversedEvent) error) {
+	r.processors[topic] = reversalProcessor(handler)
+}
+
+// HandleAccountEvents reprocesses account created events dead-lettered from topic
+func (r *Reprocessor) HandleAccountEvents(topic string, handler func(event *AccountCreatedEvent) error) {
+	r.processors[topic] = accountProcessor(handler)
+}
+
+// Start reprocesses dead-lettered messages one at a time until ctx is cancelled
+func (r *Reprocessor) Start(ctx context.Context) error {
+	logrus.WithField("topic", r.reader.Config().Topic).Info("Starting dead letter reprocessor")
+
+	for {
+		message, err := r.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				logrus.Info("Stopping dead letter reprocessor")
+				return ctx.Err()
+			}
+			logrus.WithError(err).Error("Failed to fetch dead letter message")
+			continue
+		}
+
+		if err := r.reprocessUntilDone(ctx, message); err != nil {
+			// Interrupted while waiting out a backoff; the message is fetched again on restart
+			logrus.Info("Stopping dead letter reprocessor")
+			return err
+		}
+
+		if err := r.reader.CommitMessages(context.Background(), message); err != nil {
+			logrus.WithError(err).WithField("offset", message.Offset).Error("Failed to commit dead letter offset")
+		}
+	}
+}
+
+// reprocessUntilDone reprocesses a message, trying again in place with backoff while it can be
+// neither re-queued nor parked, so it is never committed without being written somewhere. It only
+// returns an error if ctx is cancelled first.
+func (r *Reprocessor) reprocessUntilDone(ctx context.Context, message kafka.Message) error {
+	backoff := retryBackoff
+	for {
+		err := r.reprocess(ctx, message)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"offset":  message.Offset,
+			"backoff": backoff,
+		}).Warn("Dead-lettered message not written back, retrying")
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// reprocess makes one attempt at a dead-lettered message. It returns an error, leaving the message
+// uncommitted, if ctx was cancelled before the attempt was made or the message couldn't be
+// re-queued or parked.
+func (r *Reprocessor) reprocess(ctx context.Context, message kafka.Message) error {
+	source := header(message, headerSourceTopic)
+	attempts, _ := strconv.Atoi(header(message, headerAttempts))
+	log := logrus.WithFields(logrus.Fields{
+		"source_topic": source,
+		"attempts":     attempts,
+		"offset":       message.Offset,
+	})
+
+	process, ok := r.processors[source]
+	if !ok || header(message, headerRetryable) != "true" {
+		return r.park(message, source, header(message, headerReason))
+	}
+
+	// Wait out the backoff, measured from when the message was dead-lettered
+	if wait := time.Until(message.Time.Add(r.backoffFor(attempts))); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+
+	_, err := process(messageContext(message), message.Value)
+	if err == nil {
+		reprocessOutcomes.WithLabelValues(source, outcomeRecovered).Inc()
+		log.Info("Recovered dead-lettered message")
+		return nil
+	}
+
+	attempts++
+	if errors.Is(err, ErrInvalidEvent) || attempts >= r.maxAttempts {
+		return r.park(message, source, err.Error())
+	}
+
+	headers := setHeader(message.Headers, headerReason, err.Error())
+	headers = setHeader(headers, headerAttempts, strconv.Itoa(attempts))
+	if err := r.deadLetter.WriteMessages(context.Background(), kafka.Message{
+		Key:     message.Key,
+		Value:   message.Value,
+		Headers: headers,
+	}); err != nil {
+		log.WithError(err).Error("Failed to re-queue dead-lettered message")
+		return fmt.Errorf("failed to re-queue dead-lettered message: %w", err)
+	}
+
+	reprocessOutcomes.WithLabelValues(source, outcomeRetried).Inc()
+	log.WithError(err).Warn("Dead-lettered message failed again, re-queued")
+	return nil
+}
+
+// park moves a message that won't be retried again to the parking topic. Without a parking topic
+// the message is dropped; a failed write returns the error, so the message isn't committed.
+func (r *Reprocessor) park(message kafka.Message, source, reason string) error {
+	log := logrus.WithFields(logrus.Fields{
+		"source_topic": source,
+		"reason":       reason,
+		"offset":       message.Offset,
+	})
+
+	if r.parking == nil {
+		reprocessOutcomes.WithLabelValues(source, outcomeParked).Inc()
+		log.Error("Dropping dead-lettered message; no parking topic configured")
+		return nil
+	}
+
+	if err := r.parking.WriteMessages(context.Background(), kafka.Message{
+		Key:     message.Key,
+		Value:   message.Value,
+		Headers: setHeader(message.Headers, headerReason, reason),
+	}); err != nil {
+		log.WithError(err).Error("Failed to park dead-lettered message")
+		return fmt.Errorf("failed to park dead-lettered message: %w", err)
+	}
+
+	reprocessOutcomes.WithLabelValues(source, outcomeParked).Inc()
+	log.Warn("Parked dead-lettered message")
+	return nil
+}
+
+// backoffFor returns the wait before the attempt following the given number of attempts
+func (r *Reprocessor) backoffFor(attempts int) time.Duration {
+	backoff := r.backoff
+	for i := 0; i < attempts && backoff < maxReprocessBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxReprocessBackoff {
+		backoff = maxReprocessBackoff
+	}
+	return backoff
+}
+
+// Close closes the reprocessor's reader and writers
+func (r *Reprocessor) Close() error {
+	logrus.Info("Closing dead letter reprocessor")
+	if err := r.deadLetter.Close(); err != nil {
+		logrus.WithError(err).Error("Failed to close dead letter writer")
+	}
+	if r.parking != nil {
+		if err := r.parking.Close(); err != nil {
+			logrus.WithError(err).Error("Failed to close parking writer")
+		}
+	}
+	return r.reader.Close()
+}
+
+// header returns the value of a message header, or "" if it is absent
+func header(message kafka.Message, key string) string {
+	for _, h := range message.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// setHeader returns a copy of headers with key set to value, replacing any existing value
+func setHeader(headers []kafka.Header, key, value string) []kafka.Header {
+	updated := make([]kafka.Header, 0, len(headers)+1)
+	for _, h := range headers {
+		if h.Key != key {
+			updated = append(updated, h)
+		}
+	}
+	return append(updated, kafka.Header{Key: key, Value: []byte(value)})
+}
@@ -0,0 +1,141 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/segmentio/kafka-go"
+)
+
+// deadLetteredMessage is a retryable payment event dead-lettered from the payments topic long enough
+// ago that no backoff is left to wait out
+func deadLetteredMessage() kafka.Message {
+	return kafka.Message{
+		Topic: "payments.dlq",
+		Value: []byte(`{"paymentId": "pay-1", "fromAccountId": "acc-1", "amount": 100, "currency": "USD"}`),
+		Headers: []kafka.Header{
+			{Key: headerSourceTopic, Value: []byte("payments")},
+			{Key: headerRetryable, Value: []byte("true")},
+			{Key: headerReason, Value: []byte("database unavailable")},
+		},
+		Time: time.Now().Add(-time.Hour),
+	}
+}
+
+// newTestReprocessor returns a reprocessor for the payments topic whose handler fails with the
+// given errors in turn and then succeeds
+func newTestReprocessor(maxAttempts int, failures ...error) (*Reprocessor, *fakeWriter, *fakeWriter, *int) {
+	deadLetter, parking := &fakeWriter{}, &fakeWriter{}
+	r := &Reprocessor{
+		deadLetter:  deadLetter,
+		parking:     parking,
+		processors:  make(map[string]func(ctx context.Context, value []byte) (string, error)),
+		handlers:    make(map[string]map[string]EventHandler),
+		maxAttempts: maxAttempts,
+		backoff:     time.Millisecond,
+	}
+
+	calls := new(int)
+	r.HandlePayments("payments", func(ctx context.Context, event *PaymentInitiatedEvent) error {
+		*calls++
+		if *calls <= len(failures) {
+			return failures[*calls-1]
+		}
+		return nil
+	})
+	return r, deadLetter, parking, calls
+}
+
+// requeued returns the last message re-queued on the dead letter topic, as the reprocessor would
+// fetch it again
+func requeued(t *testing.T, deadLetter *fakeWriter) kafka.Message {
+	t.Helper()
+
+	written := deadLetter.written()
+	if len(written) == 0 {
+		t.Fatal("nothing re-queued on the dead letter topic")
+	}
+	message := written[len(written)-1]
+	message.Time = time.Now().Add(-time.Hour)
+	return message
+}
+
+func TestReprocessRecoversTransientFailure(t *testing.T) {
+	r, deadLetter, parking, calls := newTestReprocessor(3, errors.New("database unavailable"))
+	recovered := testutil.ToFloat64(reprocessOutcomes.WithLabelValues("payments", outcomeRecovered))
+
+	if err := r.reprocess(context.Background(), deadLetteredMessage()); err != nil {
+		t.Fatalf("reprocess: %v", err)
+	}
+	message := requeued(t, deadLetter)
+	if got := header(message, headerAttempts); got != "1" {
+		t.Errorf("re-queued with %s attempts, want 1", got)
+	}
+
+	if err := r.reprocess(context.Background(), message); err != nil {
+		t.Fatalf("reprocess (second attempt): %v", err)
+	}
+	if *calls != 2 {
+		t.Errorf("handler called %d times, want 2", *calls)
+	}
+	if n := len(deadLetter.written()); n != 1 {
+		t.Errorf("re-queued %d times, want once", n)
+	}
+	if n := len(parking.written()); n != 0 {
+		t.Errorf("parked %d messages, want none", n)
+	}
+	if got := testutil.ToFloat64(reprocessOutcomes.WithLabelValues("payments", outcomeRecovered)); got != recovered+1 {
+		t.Errorf("recovered counter = %v, want %v", got, recovered+1)
+	}
+}
+
+func TestReprocessParksAfterMaxAttempts(t *testing.T) {
+	const maxAttempts = 3
+	failures := make([]error, maxAttempts+1)
+	for i := range failures {
+		failures[i] = errors.New("downstream rejected the payment")
+	}
+	r, deadLetter, parking, calls := newTestReprocessor(maxAttempts, failures...)
+	parked := testutil.ToFloat64(reprocessOutcomes.WithLabelValues("payments", outcomeParked))
+
+	message := deadLetteredMessage()
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err := r.reprocess(context.Background(), message); err != nil {
+			t.Fatalf("reprocess attempt %d: %v", attempt, err)
+		}
+		if attempt < maxAttempts {
+			message = requeued(t, deadLetter)
+		}
+	}
+
+	if *calls != maxAttempts {
+		t.Errorf("handler called %d times, want %d", *calls, maxAttempts)
+	}
+	if n := len(deadLetter.written()); n != maxAttempts-1 {
+		t.Errorf("re-queued %d times, want %d", n, maxAttempts-1)
+	}
+	parkedMessages := parking.written()
+	if len(parkedMessages) != 1 || header(parkedMessages[0], headerReason) != "downstream rejected the payment" {
+		t.Errorf("parked %+v, want the message with its last failure", parkedMessages)
+	}
+	if got := testutil.ToFloat64(reprocessOutcomes.WithLabelValues("payments", outcomeParked)); got != parked+1 {
+		t.Errorf("parked counter = %v, want %v", got, parked+1)
+	}
+}
+
+func TestReprocessParksInvalidEventsWithoutRetrying(t *testing.T) {
+	r, deadLetter, parking, calls := newTestReprocessor(3)
+	message := deadLetteredMessage()
+	message.Value = []byte(`{"paymentId": "pay-1", "fromAccountId": "acc-1", "amount": -5}`)
+
+	if err := r.reprocess(context.Background(), message); err != nil {
+		t.Fatalf("reprocess: %v", err)
+	}
+	if *calls != 0 || len(deadLetter.written()) != 0 || len(parking.written()) != 1 {
+		t.Errorf("handler calls = %d, re-queued = %d, parked = %d, want the event parked unhandled",
+			*calls, len(deadLetter.written()), len(parking.written()))
+	}
+}
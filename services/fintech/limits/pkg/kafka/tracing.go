@@ -0,0 +1,45 @@
+package kafka
+
+import (
+	"context"
+
+	"fintech/limits-service/pkg/otel"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// headerCarrier exposes Kafka message headers to the OpenTelemetry propagator
+type headerCarrier struct {
+	headers []kafka.Header
+}
+
+// Get returns the value of the header named key, or "" if there is none
+func (c *headerCarrier) Get(key string) string {
+	for _, h := range c.headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// Set sets the header named key, replacing any existing value
+func (c *headerCarrier) Set(key, value string) {
+	c.headers = setHeader(c.headers, key, value)
+}
+
+// Keys returns the names of every header
+func (c *headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.headers))
+	for _, h := range c.headers {
+		keys = append(keys, h.Key)
+	}
+	return keys
+}
+
+// messageContext returns a context carrying the producer's trace context from the message's
+// traceparent header, so handler spans continue the producer's trace. Dead-lettered messages keep
+// their headers, so reprocessing continues the same trace.
+func messageContext(message kafka.Message) context.Context {
+	return otel.Extract(context.Background(), &headerCarrier{headers: message.Headers})
+}
@@ -0,0 +1,119 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// retryInterval is how often Acquire retries a lock held by someone else
+const retryInterval = 20 * time.Millisecond
+
+// ErrNotAcquired is returned when a lock could not be acquired before the context or wait expired
+var ErrNotAcquired = errors.New("lock not acquired")
+
+// releaseScript deletes the key only if it still holds our token, so a lock that expired and
+// was taken by another holder is never released by us
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLocker provides short-lived distributed locks shared by every replica. A nil
+// RedisLocker is valid and never blocks, so locking is skipped when Redis isn't configured.
+type RedisLocker struct {
+	client *redis.Client
+}
+
+// NewRedisLocker connects to the Redis server at url. It returns nil if url is empty.
+func NewRedisLocker(url string) (*RedisLocker, error) {
+	if url == "" {
+		return nil, nil
+	}
+
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redis url: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to ping redis: %w", err)
+	}
+
+	return &RedisLocker{client: client}, nil
+}
+
+// Acquire takes the lock on key for ttl, waiting while another holder has it, and returns the token
+// identifying this acquisition, to be passed to Release. It gives up with ErrNotAcquired when ctx is
+// done or after ttl, by which time any previous holder's lock has expired.
+func (l *RedisLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if l == nil {
+		return "", nil
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	deadline := time.Now().Add(ttl)
+	for {
+		ok, err := l.client.SetNX(ctx, key, token, ttl).Result()
+		if err != nil {
+			return "", fmt.Errorf("failed to acquire lock: %w", err)
+		}
+		if ok {
+			return token, nil
+		}
+
+		if time.Now().After(deadline) {
+			return "", ErrNotAcquired
+		}
+		select {
+		case <-ctx.Done():
+			return "", ErrNotAcquired
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// Release releases the lock on key if it is still held under token, the one Acquire returned. A lock
+// that expired and was taken by another holder, even one in this process, is left alone.
+func (l *RedisLocker) Release(ctx context.Context, key, token string) error {
+	if l == nil || token == "" {
+		return nil
+	}
+
+	if err := releaseScript.Run(ctx, l.client, []string{key}, token).Err(); err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
+
+// Close closes the Redis connection
+func (l *RedisLocker) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.client.Close()
+}
+
+// newToken returns a random token identifying one acquisition of a lock
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
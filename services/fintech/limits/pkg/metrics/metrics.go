@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Collectors are registered once with the default registry at package init,
+// so they are served by the existing promhttp /metrics handler.
+var (
+	// LoanDecisions counts loan applications by outcome and requested amount bucket
+	LoanDecisions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "loan_decisions_total",
+		Help: "Loan applications scored, by grade, approval and requested amount bucket.",
+	}, []string{"grade", "approved", "amount_bucket"})
+
+	// LimitChecks counts limit checks by limit type and result
+	LimitChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "limit_checks_total",
+		Help: "Limit checks, by limit type and result (allowed, denied or error).",
+	}, []string{"type", "result"})
+
+	// LimitCheckDuration observes how long limit checks take, locking included
+	LimitCheckDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "limit_check_duration_seconds",
+		Help:    "Duration of limit checks, by limit type.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"type"})
+)
+
+// Limit check results
+const (
+	LimitCheckAllowed = "allowed"
+	LimitCheckDenied  = "denied"
+	LimitCheckError   = "error"
+)
+
+// ObserveLimitCheck records the result and duration of a limit check
+func ObserveLimitCheck(limitType string, allowed bool, err error, duration time.Duration) {
+	result := LimitCheckDenied
+	switch {
+	case err != nil:
+		result = LimitCheckError
+	case allowed:
+		result = LimitCheckAllowed
+	}
+	LimitChecks.WithLabelValues(limitType, result).Inc()
+	LimitCheckDuration.WithLabelValues(limitType).Observe(duration.Seconds())
+}
+
+// AmountBucket labels amount with the bucket it falls in, given ascending upper bounds: below the
+// first bound is "<b0", between bounds is "b0-b1" and from the last bound up is "bN+". Each bucket
+// includes its lower bound.
+func AmountBucket(amount float64, bounds []float64) string {
+	if len(bounds) == 0 {
+		return "all"
+	}
+	if amount < bounds[0] {
+		return "<" + formatBound(bounds[0])
+	}
+	for i := 1; i < len(bounds); i++ {
+		if amount < bounds[i] {
+			return formatBound(bounds[i-1]) + "-" + formatBound(bounds[i])
+		}
+	}
+	return formatBound(bounds[len(bounds)-1]) + "+"
+}
+
+func formatBound(bound float64) string {
+	return strconv.FormatFloat(bound, 'f', -1, 64)
+}
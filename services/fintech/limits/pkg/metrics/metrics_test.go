@@ -0,0 +1,36 @@
+package metrics
+
+import "testing"
+
+func TestAmountBucket(t *testing.T) {
+	bounds := []float64{1000, 5000, 10000}
+	tests := []struct {
+		amount float64
+		want   string
+	}{
+		{500, "<1000"},
+		{1000, "1000-5000"},
+		{4999.99, "1000-5000"},
+		{7500, "5000-10000"},
+		{10000, "10000+"},
+		{250000, "10000+"},
+	}
+
+	for _, tt := range tests {
+		if got := AmountBucket(tt.amount, bounds); got != tt.want {
+			t.Errorf("AmountBucket(%.2f) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestAmountBucketCustomScheme(t *testing.T) {
+	if got := AmountBucket(2500, []float64{250.5, 2500}); got != "2500+" {
+		t.Errorf("AmountBucket(2500) = %q, want %q", got, "2500+")
+	}
+	if got := AmountBucket(300, []float64{250.5, 2500}); got != "250.5-2500" {
+		t.Errorf("AmountBucket(300) = %q, want %q", got, "250.5-2500")
+	}
+	if got := AmountBucket(300, nil); got != "all" {
+		t.Errorf("AmountBucket without bounds = %q, want %q", got, "all")
+	}
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// pushJobName is the Pushgateway job every background job run is grouped under
+const pushJobName = "limits-service"
+
+// JobPusher pushes the outcome of each background job run to a Prometheus Pushgateway, for jobs
+// that finish between scrapes. Runs are grouped by a "task" label, so each job keeps its own
+// series on the gateway.
+type JobPusher struct {
+	url    string
+	client *http.Client
+}
+
+// NewJobPusher creates a pusher for the Pushgateway at url, or returns nil when url is empty
+func NewJobPusher(url string, timeout time.Duration) *JobPusher {
+	if url == "" {
+		return nil
+	}
+	return &JobPusher{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Push records one run of task: its duration, whether it failed and when it completed, plus when it
+// last succeeded if it did. The last success time of a failed run is left as it was on the gateway.
+// A nil pusher does nothing.
+func (p *JobPusher) Push(ctx context.Context, task string, duration time.Duration, runErr error) error {
+	if p == nil {
+		return nil
+	}
+
+	now := float64(time.Now().Unix())
+	registry := prometheus.NewRegistry()
+
+	durationGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "background_job_duration_seconds",
+		Help: "Duration of the last run of the background job.",
+	})
+	durationGauge.Set(duration.Seconds())
+
+	failedGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "background_job_failed",
+		Help: "1 if the last run of the background job failed, 0 otherwise.",
+	})
+
+	completionGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "background_job_last_completion_timestamp_seconds",
+		Help: "Unix time the background job last completed, successfully or not.",
+	})
+	completionGauge.Set(now)
+
+	registry.MustRegister(durationGauge, failedGauge, completionGauge)
+
+	if runErr != nil {
+		failedGauge.Set(1)
+	} else {
+		successGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "background_job_last_success_timestamp_seconds",
+			Help: "Unix time the background job last completed successfully.",
+		})
+		successGauge.Set(now)
+		registry.MustRegister(successGauge)
+	}
+
+	// Add replaces only the metrics pushed here, keeping the last success time across failures
+	err := push.New(p.url, pushJobName).
+		Client(p.client).
+		Grouping("task", task).
+		Gatherer(registry).
+		AddContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to push job metrics: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pushedRun is one push received by stubGateway
+type pushedRun struct {
+	method string
+	path   string
+	body   string
+}
+
+// stubGateway records each push it receives and answers with status
+func stubGateway(t *testing.T, status int) (*httptest.Server, *[]pushedRun) {
+	t.Helper()
+
+	var pushes []pushedRun
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		pushes = append(pushes, pushedRun{method: r.Method, path: r.URL.Path, body: string(body)})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &pushes
+}
+
+func TestJobPusherPushesOnCompletion(t *testing.T) {
+	tests := []struct {
+		name        string
+		runErr      error
+		wantSuccess bool
+	}{
+		{"successful run", nil, true},
+		{"failed run", errors.New("reset failed"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, pushes := stubGateway(t, http.StatusOK)
+
+			if err := NewJobPusher(server.URL, time.Second).Push(context.Background(), "archival", 2*time.Second, tt.runErr); err != nil {
+				t.Fatalf("Push: %v", err)
+			}
+			if len(*pushes) != 1 {
+				t.Fatalf("gateway received %d pushes, want 1", len(*pushes))
+			}
+
+			run := (*pushes)[0]
+			if run.method != http.MethodPost || run.path != "/metrics/job/limits-service/task/archival" {
+				t.Errorf("pushed %s %s, want POST to the limits-service job grouped by task", run.method, run.path)
+			}
+			for _, name := range []string{"background_job_duration_seconds", "background_job_failed", "background_job_last_completion_timestamp_seconds"} {
+				if !strings.Contains(run.body, name) {
+					t.Errorf("push is missing %s", name)
+				}
+			}
+			if got := strings.Contains(run.body, "background_job_last_success_timestamp_seconds"); got != tt.wantSuccess {
+				t.Errorf("push has the last success time = %v, want %v", got, tt.wantSuccess)
+			}
+		})
+	}
+}
+
+func TestJobPusherGatewayError(t *testing.T) {
+	server, _ := stubGateway(t, http.StatusInternalServerError)
+
+	if err := NewJobPusher(server.URL, time.Second).Push(context.Background(), "hold sweeper", time.Second, nil); err == nil {
+		t.Error("expected an error when the gateway rejects the push")
+	}
+}
+
+func TestJobPusherDisabledWithoutURL(t *testing.T) {
+	pusher := NewJobPusher("", time.Second)
+	if pusher != nil {
+		t.Fatalf("NewJobPusher(\"\") = %+v, want nil", pusher)
+	}
+	if err := pusher.Push(context.Background(), "hold sweeper", time.Second, nil); err != nil {
+		t.Errorf("Push on a nil pusher = %v, want nil", err)
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"fintech/limits-service/pkg/otel"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// statusRecorder remembers the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// REDMetrics records the rate, errors and duration of requests as OpenTelemetry metrics: the
+// http.server.requests counter and http.server.request.duration histogram, by method, route
+// template and status code
+func REDMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		// Label by route template, never the raw path, to keep the label set bounded
+		route := "unmatched"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		attrs := []attribute.KeyValue{
+			otel.Attribute("http.method", r.Method),
+			otel.Attribute("http.route", route),
+			otel.Attribute("http.status_code", recorder.status),
+		}
+		otel.Counter(r.Context(), "http.server.requests", attrs...)
+		otel.RecordDuration(r.Context(), "http.server.request.duration", time.Since(start), attrs...)
+	})
+}
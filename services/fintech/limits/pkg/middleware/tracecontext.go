@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"net/http"
+
+	"fintech/limits-service/pkg/otel"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// TraceContext continues the caller's trace: the W3C traceparent on the request is carried into
+// the request context, so handler spans become children of the caller's span
+func TraceContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,96 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// metricExportInterval is how often metrics are pushed to the collector
+const metricExportInterval = 30 * time.Second
+
+// InitMeterProvider initializes OpenTelemetry metrics exported over OTLP. As with tracing, the
+// collector doesn't need to be reachable: a failed export is reported through the error handler
+// and the next interval tries again. Until a provider is set, Counter and RecordDuration are no-ops.
+func InitMeterProvider(serviceName, otlpEndpoint string) (*sdkmetric.MeterProvider, error) {
+	// The HTTP client connects lazily on the first export
+	exporter, err := otlpmetrichttp.New(context.Background(),
+		otlpmetrichttp.WithEndpoint(otlpEndpoint),
+		otlpmetrichttp.WithInsecure(),
+		otlpmetrichttp.WithTimeout(exportTimeout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
+	}
+
+	res, err := newResource(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(metricExportInterval))),
+		sdkmetric.WithResource(res),
+	)
+
+	// Set global meter provider
+	otel.SetMeterProvider(mp)
+
+	return mp, nil
+}
+
+// Instruments are created on first use and reused after, keyed by name
+var (
+	instrumentsMu sync.Mutex
+	counters      = make(map[string]metric.Int64Counter)
+	histograms    = make(map[string]metric.Float64Histogram)
+)
+
+// GetMeter returns a meter for the given name
+func GetMeter(name string) metric.Meter {
+	return otel.Meter(name)
+}
+
+// Counter adds one to the counter named name
+func Counter(ctx context.Context, name string, attrs ...attribute.KeyValue) {
+	instrumentsMu.Lock()
+	counter, ok := counters[name]
+	if !ok {
+		var err error
+		if counter, err = GetMeter("limits-service").Int64Counter(name); err != nil {
+			instrumentsMu.Unlock()
+			logrus.WithError(err).WithField("metric", name).Debug("Failed to create OpenTelemetry counter")
+			return
+		}
+		counters[name] = counter
+	}
+	instrumentsMu.Unlock()
+
+	counter.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
+
+// RecordDuration records duration, in seconds, in the histogram named name
+func RecordDuration(ctx context.Context, name string, duration time.Duration, attrs ...attribute.KeyValue) {
+	instrumentsMu.Lock()
+	histogram, ok := histograms[name]
+	if !ok {
+		var err error
+		if histogram, err = GetMeter("limits-service").Float64Histogram(name, metric.WithUnit("s")); err != nil {
+			instrumentsMu.Unlock()
+			logrus.WithError(err).WithField("metric", name).Debug("Failed to create OpenTelemetry histogram")
+			return
+		}
+		histograms[name] = histogram
+	}
+	instrumentsMu.Unlock()
+
+	histogram.Record(ctx, duration.Seconds(), metric.WithAttributes(attrs...))
+}
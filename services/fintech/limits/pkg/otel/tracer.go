@@ -3,28 +3,85 @@ package otel
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
-// InitTracerProvider initializes OpenTelemetry tracing
+// Export limits, so an unreachable collector costs dropped spans rather than a stalled exporter
+const (
+	maxQueueSize    = 2048             // Spans buffered for export; further spans are dropped
+	exportTimeout   = 5 * time.Second  // Per export attempt
+	maxExportRetry  = 10 * time.Second // Total retry time for a batch before it is dropped
+	exportBatchSize = 512
+)
+
+var spansDropped = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "otel_spans_dropped_total",
+	Help: "Spans dropped because they could not be exported to the collector.",
+})
+
+// InitTracerProvider initializes OpenTelemetry tracing. The collector doesn't need to be reachable
+// at startup: spans are exported in the background from a bounded queue and dropped, with a
+// warning and a metric, while it is unavailable. Recording a span never blocks on the collector.
 func InitTracerProvider(serviceName, otlpEndpoint string) (*sdktrace.TracerProvider, error) {
-	// Create OTLP exporter
+	// Create OTLP exporter; the HTTP client connects lazily on the first export
 	exporter, err := otlptracehttp.New(context.Background(),
 		otlptracehttp.WithEndpoint(otlpEndpoint),
 		otlptracehttp.WithInsecure(),
+		otlptracehttp.WithTimeout(exportTimeout),
+		otlptracehttp.WithRetry(otlptracehttp.RetryConfig{
+			Enabled:         true,
+			InitialInterval: time.Second,
+			MaxInterval:     5 * time.Second,
+			MaxElapsedTime:  maxExportRetry,
+		}),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
 	}
 
-	// Create resource
+	// Export failures are reported by droppingExporter; keep the SDK's own reports out of stderr
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logrus.WithError(err).Debug("OpenTelemetry error")
+	}))
+
+	res, err := newResource(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create tracer provider
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(&droppingExporter{SpanExporter: exporter},
+			sdktrace.WithMaxQueueSize(maxQueueSize),
+			sdktrace.WithMaxExportBatchSize(exportBatchSize),
+			sdktrace.WithExportTimeout(exportTimeout+maxExportRetry),
+		),
+		sdktrace.WithResource(res),
+	)
+
+	// Set global tracer provider, and read and write W3C trace context (traceparent) and baggage
+	// so spans join the traces of the services calling us
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return tp, nil
+}
+
+// newResource describes this service on exported spans and metrics
+func newResource(serviceName string) (*resource.Resource, error) {
 	res, err := resource.New(context.Background(),
 		resource.WithAttributes(
 			semconv.ServiceNameKey.String(serviceName),
@@ -35,17 +92,31 @@ func InitTracerProvider(serviceName, otlpEndpoint string) (*sdktrace.TracerProvi
 	if err != nil {
 		return nil, fmt.Errorf("failed to create resource: %w", err)
 	}
+	return res, nil
+}
 
-	// Create tracer provider
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter),
-		sdktrace.WithResource(res),
-	)
+// droppingExporter counts and logs spans lost to failed exports. It warns once when exports start
+// failing and again when they recover, rather than on every batch.
+type droppingExporter struct {
+	sdktrace.SpanExporter
+	failing atomic.Bool
+}
 
-	// Set global tracer provider
-	otel.SetTracerProvider(tp)
+// ExportSpans exports spans, recording them as dropped if the export fails
+func (e *droppingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	if err != nil {
+		spansDropped.Add(float64(len(spans)))
+		if !e.failing.Swap(true) {
+			logrus.WithError(err).Warn("Trace collector unavailable, dropping spans")
+		}
+		return err
+	}
 
-	return tp, nil
+	if e.failing.Swap(false) {
+		logrus.Info("Trace collector reachable again, exporting spans")
+	}
+	return nil
 }
 
 // GetTracer returns a tracer for the given name
@@ -53,6 +124,17 @@ func GetTracer(name string) trace.Tracer {
 	return otel.Tracer(name)
 }
 
+// Extract returns ctx carrying the remote span context found in carrier, if any, so spans started
+// from it are children of the caller's span
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// Inject writes the span context of ctx into carrier, so the receiver's spans continue this trace
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+}
+
 // StartSpan starts a new span with the given name
 func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
 	return GetTracer("limits-service").Start(ctx, name, opts...)
@@ -62,3 +144,21 @@ func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption)
 func AddSpanAttributes(span trace.Span, attrs ...attribute.KeyValue) {
 	span.SetAttributes(attrs...)
 }
+
+// Attribute builds a span attribute from a plain Go value
+func Attribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
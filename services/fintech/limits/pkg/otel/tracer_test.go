@@ -0,0 +1,92 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// deadEndpoint returns the address of a port nothing is listening on
+func deadEndpoint(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	return addr
+}
+
+func TestSpansRecordedWithDeadCollector(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	tp, err := InitTracerProvider("test-service", deadEndpoint(t))
+	if err != nil {
+		t.Fatalf("InitTracerProvider with an unreachable collector: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		tp.Shutdown(ctx)
+		otel.SetTracerProvider(previous)
+	})
+
+	// More spans than one export batch, so an export to the dead collector starts meanwhile
+	start := time.Now()
+	for i := 0; i < 2*exportBatchSize; i++ {
+		_, span := StartSpan(context.Background(), "operation")
+		if !span.SpanContext().IsValid() {
+			t.Fatal("span not recorded")
+		}
+		span.End()
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("recording spans took %v, want it not to wait for the collector", elapsed)
+	}
+}
+
+// failingExporter fails every export
+type failingExporter struct {
+	sdktrace.SpanExporter
+	err error
+}
+
+func (e *failingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	return e.err
+}
+
+func TestDroppingExporterCountsDroppedSpans(t *testing.T) {
+	inner := &failingExporter{err: errors.New("connection refused")}
+	exporter := &droppingExporter{SpanExporter: inner}
+	before := testutil.ToFloat64(spansDropped)
+
+	spans := make([]sdktrace.ReadOnlySpan, 3)
+	if err := exporter.ExportSpans(context.Background(), spans); err == nil {
+		t.Error("expected the export error")
+	}
+	if !exporter.failing.Load() {
+		t.Error("exporter not marked as failing")
+	}
+	if got := testutil.ToFloat64(spansDropped); got != before+3 {
+		t.Errorf("dropped spans = %v, want %v", got, before+3)
+	}
+
+	// A successful export clears the failing state and drops nothing
+	inner.err = nil
+	if err := exporter.ExportSpans(context.Background(), spans); err != nil {
+		t.Errorf("ExportSpans: %v", err)
+	}
+	if exporter.failing.Load() {
+		t.Error("exporter still marked as failing after a successful export")
+	}
+	if got := testutil.ToFloat64(spansDropped); got != before+3 {
+		t.Errorf("dropped spans = %v after a successful export, want %v", got, before+3)
+	}
+}
@@ -0,0 +1,48 @@
+package respond
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"fintech/limits-service/pkg/middleware"
+)
+
+var envelope atomic.Bool
+
+// SetEnvelope controls whether JSON responses are wrapped in an Envelope (called at startup)
+func SetEnvelope(enabled bool) {
+	envelope.Store(enabled)
+}
+
+// Envelope is the standard wrapper for JSON response payloads
+type Envelope struct {
+	Data interface{} `json:"data"`
+	Meta Meta        `json:"meta"`
+}
+
+// Meta carries per-response metadata
+type Meta struct {
+	RequestID string    `json:"requestId"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// JSON writes payload with the given status, wrapped in an Envelope when enabled
+func JSON(ctx context.Context, w http.ResponseWriter, status int, payload interface{}) error {
+	body := payload
+	if envelope.Load() {
+		body = Envelope{
+			Data: payload,
+			Meta: Meta{
+				RequestID: middleware.RequestIDFromContext(ctx),
+				Timestamp: time.Now().UTC(),
+			},
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(body)
+}
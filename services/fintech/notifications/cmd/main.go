@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -15,7 +16,10 @@ import (
 	"fintech/notifications-service/pkg/aws"
 	"fintech/notifications-service/pkg/database"
 	"fintech/notifications-service/pkg/kafka"
+	"fintech/notifications-service/pkg/middleware"
 	"fintech/notifications-service/pkg/otel"
+	"fintech/notifications-service/pkg/redact"
+	"fintech/notifications-service/pkg/respond"
 
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -45,12 +49,29 @@ func main() {
 		}
 	}()
 
+	// OTLP metrics are optional; the service runs without them if they can't be set up
+	if cfg.OTLPMetricsEnabled {
+		mp, err := otel.InitMeterProvider(cfg.ServiceName, cfg.OTLPEndpoint)
+		if err != nil {
+			logrus.WithError(err).Warn("Failed to initialize OpenTelemetry metrics, continuing without them")
+		} else {
+			defer func() {
+				if err := mp.Shutdown(context.Background()); err != nil {
+					logrus.WithError(err).Error("Failed to shutdown meter provider")
+				}
+			}()
+		}
+	}
+
 	// Initialize database
 	db, err := database.NewConnection(cfg.DatabaseURL)
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to connect to database")
 	}
 	defer db.Close()
+	if err := db.ConnectReplica(cfg.DatabaseReplicaURL); err != nil {
+		logrus.WithError(err).Fatal("Failed to connect to database")
+	}
 
 	// Run migrations
 	if err := database.RunMigrations(db); err != nil {
@@ -58,40 +79,126 @@ func main() {
 	}
 
 	// Initialize AWS clients
-	snsClient, err := aws.NewSNSClient(cfg.AWSConfig)
+	snsClient, err := aws.NewSNSClient(cfg.AWSConfig.ToAWSConfig(), cfg.AWSConfig.SNSTopicARN)
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to create SNS client")
 	}
+	snsClient.WithHighPriorityTopic(cfg.AWSConfig.SNSHighPriorityTopicARN, cfg.AWSConfig.HighPriorityThreshold)
 
-	sqsClient, err := aws.NewSQSClient(cfg.AWSConfig)
+	sqsClient, err := aws.NewSQSClient(cfg.AWSConfig.ToAWSConfig())
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to create SQS client")
 	}
 
+	var sesClient *aws.SESClient
+	if cfg.AWSConfig.SESSender != "" {
+		sesClient, err = aws.NewSESClient(cfg.AWSConfig.ToAWSConfig(), cfg.AWSConfig.SESSender, cfg.AWSConfig.AttachmentsBucket, cfg.AttachmentFetchTimeout)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to create SES client")
+		}
+	}
+
 	// Initialize notification service
-	notificationSvc := handlers.NewNotificationService(db, snsClient, sqsClient, cfg)
+	notificationSvc := handlers.NewNotificationService(db, snsClient, sqsClient, sesClient, cfg)
+	notificationSvc.LoadTemplates(context.Background())
 
 	// Initialize Kafka consumers for different event types
-	paymentConsumer, err := kafka.NewConsumer(cfg.KafkaBrokers, "notifications-service-payments", "payments")
+	paymentConsumer, err := kafka.NewConsumer(cfg.KafkaBrokers, "notifications-service-payments", "payments", cfg.KafkaSecurity())
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to create payment consumer")
 	}
 	defer paymentConsumer.Close()
+	paymentConsumer.WithConcurrency(cfg.KafkaConcurrency).
+		WithDeliverySemantics(cfg.KafkaDeliverySemantics).
+		WithMaxEventAge(cfg.KafkaMaxEventAge).
+		WithDeadLetterTopic(cfg.KafkaBrokers, cfg.KafkaDLQTopic)
 
 	// Start Kafka consumers in background
+	consumerCtx, stopConsumer := context.WithCancel(context.Background())
+	consumerDone := make(chan struct{})
 	go func() {
+		defer close(consumerDone)
 		logrus.Info("Starting payment event consumer")
-		if err := paymentConsumer.Start(context.Background(), notificationSvc.HandlePaymentEvent); err != nil {
+		if err := paymentConsumer.Start(consumerCtx, notificationSvc.HandlePaymentEvent); err != nil && !errors.Is(err, context.Canceled) {
 			logrus.WithError(err).Fatal("Payment consumer failed")
 		}
 	}()
 
+	// Re-send due retries and any backlog left by downtime
+	retryDone := make(chan struct{})
+	go func() {
+		defer close(retryDone)
+		notificationSvc.RunRetryWorker(consumerCtx)
+	}()
+
+	// Flag and alert on notifications missing their delivery SLA
+	slaDone := make(chan struct{})
+	go func() {
+		defer close(slaDone)
+		notificationSvc.RunSLAWorker(consumerCtx)
+	}()
+
+	// Flag sent notifications that never got a delivery receipt
+	undeliveredDone := make(chan struct{})
+	go func() {
+		defer close(undeliveredDone)
+		notificationSvc.RunUndeliveredSweeper(consumerCtx)
+	}()
+
+	// Pick up template edits made in the database
+	templatesDone := make(chan struct{})
+	go func() {
+		defer close(templatesDone)
+		notificationSvc.RunTemplateRefresher(consumerCtx)
+	}()
+
+	// Retry dead-lettered payment events with backoff
+	reprocessorDone := make(chan struct{})
+	if cfg.KafkaDLQTopic != "" {
+		reprocessor, err := kafka.NewReprocessor(cfg.KafkaBrokers, "notifications-service-dlq", cfg.KafkaDLQTopic, cfg.KafkaParkingTopic,
+			cfg.KafkaSecurity(), cfg.KafkaDLQMaxAttempts, cfg.KafkaDLQBackoff)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to create dead letter reprocessor")
+		}
+		defer reprocessor.Close()
+		reprocessor.HandlePayments("payments", notificationSvc.HandlePaymentEvent)
+
+		go func() {
+			defer close(reprocessorDone)
+			if err := reprocessor.Start(consumerCtx); err != nil && !errors.Is(err, context.Canceled) {
+				logrus.WithError(err).Fatal("Dead letter reprocessor failed")
+			}
+		}()
+	} else {
+		close(reprocessorDone)
+	}
+
 	// Setup HTTP server
 	router := mux.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(middleware.TraceContext)
+	router.Use(middleware.REDMetrics)
+	respond.SetEnvelope(cfg.ResponseEnvelope)
 
 	// Health check endpoint
 	router.HandleFunc("/health", handlers.HealthCheck).Methods("GET")
 
+	// Notification search endpoint
+	router.HandleFunc("/notifications", notificationSvc.ListNotifications).Methods("GET")
+	router.HandleFunc("/notifications/stats", notificationSvc.GetNotificationStats).Methods("GET")
+	router.HandleFunc("/notifications/mute", notificationSvc.MuteNotifications).Methods("POST")
+	router.HandleFunc("/notifications/{id}", notificationSvc.GetNotification).Methods("GET")
+	router.HandleFunc("/notifications/{id}/attempts", notificationSvc.ListAttempts).Methods("GET")
+	router.HandleFunc("/notifications/{id}/transition", notificationSvc.TransitionNotification).Methods("POST")
+	router.HandleFunc("/preferences/{accountId}", notificationSvc.UpdatePreferences).Methods("PUT")
+
+	// Admin endpoints, protected by ADMIN_TOKEN
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.AdminAuth(cfg.AdminToken))
+	admin.HandleFunc("/loglevel", handlers.GetLogLevel).Methods("GET")
+	admin.HandleFunc("/loglevel", handlers.SetLogLevel).Methods("PUT")
+	admin.HandleFunc("/dashboard", notificationSvc.GetDashboard).Methods("GET")
+
 	// Metrics endpoint
 	router.Handle("/metrics", promhttp.Handler()).Methods("GET")
 
@@ -118,14 +225,50 @@ func main() {
 
 	logrus.Info("Shutting down server...")
 
-	// Give outstanding requests a deadline for completion
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Give outstanding requests, the consumer and in-flight sends a deadline for completion
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
 		logrus.WithError(err).Error("Server forced to shutdown")
 	}
 
+	stopConsumer()
+	select {
+	case <-consumerDone:
+	case <-ctx.Done():
+		logrus.Warn("Payment consumer did not drain before shutdown timeout")
+	}
+	select {
+	case <-retryDone:
+	case <-ctx.Done():
+		logrus.Warn("Retry worker did not stop before shutdown timeout")
+	}
+	select {
+	case <-slaDone:
+	case <-ctx.Done():
+		logrus.Warn("SLA worker did not stop before shutdown timeout")
+	}
+	select {
+	case <-undeliveredDone:
+	case <-ctx.Done():
+		logrus.Warn("Undelivered sweeper did not stop before shutdown timeout")
+	}
+	select {
+	case <-templatesDone:
+	case <-ctx.Done():
+		logrus.Warn("Template refresher did not stop before shutdown timeout")
+	}
+	select {
+	case <-reprocessorDone:
+	case <-ctx.Done():
+		logrus.Warn("Dead letter reprocessor did not stop before shutdown timeout")
+	}
+
+	if err := notificationSvc.Drain(ctx); err != nil {
+		logrus.WithError(err).Warn("In-flight notifications did not drain before shutdown timeout")
+	}
+
 	logrus.Info("Server exited")
 }
 
@@ -139,4 +282,6 @@ func setupLogging(cfg *config.Config) {
 	} else {
 		logrus.SetLevel(logrus.DebugLevel)
 	}
+
+	redact.SetLogPII(cfg.LogPII)
 }
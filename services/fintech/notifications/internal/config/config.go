@@ -1,8 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
+	"fintech/notifications-service/pkg/flags"
+	"fintech/notifications-service/pkg/kafka"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/kelseyhightower/envconfig"
 )
@@ -14,9 +18,15 @@ type AWSConfig struct {
 	AccessKeyID     string `envconfig:"AWS_ACCESS_KEY_ID" default:"test"`
 	SecretAccessKey string `envconfig:"AWS_SECRET_ACCESS_KEY" default:"test"`
 	SNSTopicARN     string `envconfig:"SNS_TOPIC_ARN" default:"arn:aws:sns:us-east-1:000000000000:fintech-notifications"`
+	// Optional dedicated topic for notifications at or above HighPriorityThreshold
+	SNSHighPriorityTopicARN string `envconfig:"SNS_HIGH_PRIORITY_TOPIC_ARN"`
+	HighPriorityThreshold   int    `envconfig:"HIGH_PRIORITY_THRESHOLD" default:"3"`
 	EmailQueueURL   string `envconfig:"EMAIL_QUEUE_URL" default:"http://localhost:4566/000000000000/fintech-email-notifications"`
 	SMSQueueURL     string `envconfig:"SMS_QUEUE_URL" default:"http://localhost:4566/000000000000/fintech-sms-notifications"`
 	PushQueueURL    string `envconfig:"PUSH_QUEUE_URL" default:"http://localhost:4566/000000000000/fintech-push-notifications"`
+	// Optional SES sender address; when set, emails are delivered through SES with attachments
+	SESSender         string `envconfig:"SES_SENDER"`
+	AttachmentsBucket string `envconfig:"ATTACHMENTS_BUCKET" default:"fintech-notification-attachments"`
 }
 
 // ToAWSConfig converts to aws.Config
@@ -31,26 +41,117 @@ func (c *AWSConfig) ToAWSConfig() *aws.Config {
 // Config holds all configuration for the notifications service
 type Config struct {
 	// Service configuration
-	ServiceName  string `envconfig:"SERVICE_NAME" default:"notifications-service"`
-	Port         int    `envconfig:"PORT" default:"8080"`
-	Environment  string `envconfig:"ENVIRONMENT" default:"development"`
+	ServiceName     string        `envconfig:"SERVICE_NAME" default:"notifications-service"`
+	Port            int           `envconfig:"PORT" default:"8080"`
+	Environment     string        `envconfig:"ENVIRONMENT" default:"development"`
+	ShutdownTimeout time.Duration `envconfig:"SHUTDOWN_TIMEOUT" default:"30s"`
+	// Wrap JSON responses as {"data": ..., "meta": {"requestId", "timestamp"}}
+	ResponseEnvelope bool `envconfig:"RESPONSE_ENVELOPE" default:"false"`
+	// Bearer token required by /admin endpoints; they reject every request when unset
+	AdminToken string `envconfig:"ADMIN_TOKEN"`
+	// Log recipients and message content unmasked; for debugging only
+	LogPII bool `envconfig:"LOG_PII" default:"false"`
+	// How long GET /admin/dashboard serves a cached response
+	DashboardCacheTTL time.Duration `envconfig:"DASHBOARD_CACHE_TTL" default:"15s"`
+
+	// Feature flags, comma separated, e.g. "mute_fail_open"
+	FeatureFlags string     `envconfig:"FEATURE_FLAGS" default:"mute_fail_open"`
+	Flags        *flags.Set `ignored:"true"` // Parsed from FeatureFlags by Load
 
 	// Database configuration
-	DatabaseURL string `envconfig:"DATABASE_URL" required:"true"`
+	DatabaseURL        string `envconfig:"DATABASE_URL" required:"true"`
+	DatabaseReplicaURL string `envconfig:"DATABASE_URL_REPLICA"` // Optional read replica for read-only queries
 
 	// Kafka configuration
-	KafkaBrokers string `envconfig:"KAFKA_BROKERS" default:"localhost:9092"`
+	KafkaBrokers     string        `envconfig:"KAFKA_BROKERS" default:"localhost:9092"`
+	KafkaConcurrency int           `envconfig:"KAFKA_HANDLER_CONCURRENCY" default:"1"` // Partitions stay ordered
+	KafkaMaxEventAge time.Duration `envconfig:"KAFKA_MAX_EVENT_AGE" default:"0"`       // Older events are skipped; 0 disables
+	KafkaDLQTopic    string        `envconfig:"KAFKA_DLQ_TOPIC" default:""`            // Skipped, invalid and failed events are sent here when set
+	// at_least_once commits after handling; at_most_once commits before, so a crash drops the event
+	KafkaDeliverySemantics kafka.DeliverySemantics `envconfig:"KAFKA_DELIVERY_SEMANTICS" default:"at_least_once"`
+
+	// Dead letter reprocessing, active when KafkaDLQTopic is set
+	KafkaParkingTopic   string        `envconfig:"KAFKA_PARKING_TOPIC" default:""`     // Events that can't be reprocessed; dropped when unset
+	KafkaDLQMaxAttempts int           `envconfig:"KAFKA_DLQ_MAX_ATTEMPTS" default:"5"` // Reprocess attempts before parking
+	KafkaDLQBackoff     time.Duration `envconfig:"KAFKA_DLQ_BACKOFF" default:"30s"`    // Wait before the first reprocess; doubles per attempt
+
+	// Kafka security; plaintext without auth by default
+	KafkaTLSEnabled    bool   `envconfig:"KAFKA_TLS_ENABLED" default:"false"`
+	KafkaTLSCAFile     string `envconfig:"KAFKA_TLS_CA_FILE"`
+	KafkaSASLMechanism string `envconfig:"KAFKA_SASL_MECHANISM"` // PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512
+	KafkaSASLUsername  string `envconfig:"KAFKA_SASL_USERNAME"`
+	KafkaSASLPassword  string `envconfig:"KAFKA_SASL_PASSWORD"`
 
 	// AWS configuration
 	AWSConfig AWSConfig
 
 	// OpenTelemetry configuration
-	OTLPEndpoint string `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT" default:"http://otel-collector:4318"`
+	OTLPEndpoint       string `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT" default:"http://otel-collector:4318"`
+	OTLPMetricsEnabled bool   `envconfig:"OTEL_METRICS_ENABLED" default:"false"` // Also export RED metrics over OTLP
 
 	// Notification configuration
 	MaxRetries        int           `envconfig:"MAX_RETRIES" default:"3"`
+	GlobalMaxRetries  int           `envconfig:"GLOBAL_MAX_RETRIES" default:"5"` // Ceiling applied over template values; 0 disables
 	RetryDelay        time.Duration `envconfig:"RETRY_DELAY" default:"5s"`
 	NotificationTimeout time.Duration `envconfig:"NOTIFICATION_TIMEOUT" default:"30s"`
+	SendWorkers         int           `envconfig:"SEND_WORKERS" default:"10"`
+	RetryPollInterval   time.Duration `envconfig:"RETRY_POLL_INTERVAL" default:"10s"`
+	RetryBatchSize      int           `envconfig:"RETRY_BATCH_SIZE" default:"100"`
+	PendingRecoveryAfter time.Duration `envconfig:"PENDING_RECOVERY_AFTER" default:"2m"` // Unsent PENDING rows older than this are re-queued
+	RetryClaimTTL       time.Duration `envconfig:"RETRY_CLAIM_TTL" default:"5m"`      // How long a swept row is reserved for the replica that claimed it
+	SendRateLimit       float64       `envconfig:"SEND_RATE_LIMIT" default:"50"`      // Steady-state retry sends per second; 0 disables pacing
+	SendRampStartRate   float64       `envconfig:"SEND_RAMP_START_RATE" default:"1"`  // Retry sends per second when a ramp begins
+	SendRampWindow      time.Duration `envconfig:"SEND_RAMP_WINDOW" default:"2m"`     // Time to ramp from the start rate to SEND_RATE_LIMIT
+	AttachmentFetchTimeout time.Duration `envconfig:"ATTACHMENT_FETCH_TIMEOUT" default:"10s"`
+	RecordAttempts      bool          `envconfig:"RECORD_ATTEMPTS" default:"true"` // Keep a row per send attempt in notification_attempts
+	TemplateOverrides   map[string]string `envconfig:"TEMPLATE_OVERRIDES"` // eventType:templateEventType pairs, e.g. "RefundIssued:PaymentCompleted"
+
+	// Delivery SLAs by priority, e.g. "3:5m,2:30m"; priorities without one have no deadline
+	DeliverySLAs       map[int]time.Duration `envconfig:"DELIVERY_SLAS"`
+	SLACheckInterval   time.Duration         `envconfig:"SLA_CHECK_INTERVAL" default:"30s"`
+	SLAAlertWebhookURL string                `envconfig:"SLA_ALERT_WEBHOOK_URL"` // Optional Slack incoming webhook for breach alerts
+
+	// SENT notifications without a delivery receipt after this long are flagged undelivered; 0 disables
+	UndeliveredAfter         time.Duration `envconfig:"UNDELIVERED_AFTER" default:"24h"`
+	UndeliveredSweepInterval time.Duration `envconfig:"UNDELIVERED_SWEEP_INTERVAL" default:"10m"`
+
+	// How often templates are reloaded from the notification_templates table; 0 loads them once at startup
+	TemplateRefreshInterval time.Duration `envconfig:"TEMPLATE_REFRESH_INTERVAL" default:"1m"`
+
+	// Cross-channel throttle per recipient, e.g. at most 5 notifications per 10 minutes; 0 disables
+	RecipientThrottleLimit  int           `envconfig:"RECIPIENT_THROTTLE_LIMIT" default:"0"`
+	RecipientThrottleWindow time.Duration `envconfig:"RECIPIENT_THROTTLE_WINDOW" default:"10m"`
+
+	// Re-resolve recipients of delayed and retried sends from the profile service, so a contact
+	// change since creation is picked up; first sends keep the recipient resolved at creation
+	ResolveRecipientAtSend bool          `envconfig:"RESOLVE_RECIPIENT_AT_SEND" default:"false"`
+	ProfileServiceURL      string        `envconfig:"PROFILE_SERVICE_URL"`
+	ProfileServiceTimeout  time.Duration `envconfig:"PROFILE_SERVICE_TIMEOUT" default:"2s"`
+
+	// Quiet hours in each account's local time (from its preferences, else QUIET_HOURS_DEFAULT_TIMEZONE).
+	// SMS and push below QUIET_HOURS_PRIORITY_THRESHOLD are deferred to the end of the window; a start
+	// hour after the end hour spans midnight, e.g. 22 to 7
+	QuietHoursEnabled           bool           `envconfig:"QUIET_HOURS_ENABLED" default:"false"`
+	QuietHoursStart             int            `envconfig:"QUIET_HOURS_START" default:"22"` // Hour 0-23
+	QuietHoursEnd               int            `envconfig:"QUIET_HOURS_END" default:"7"`    // Hour 0-23
+	QuietHoursPriorityThreshold int            `envconfig:"QUIET_HOURS_PRIORITY_THRESHOLD" default:"2"`
+	QuietHoursDefaultTimezone   string         `envconfig:"QUIET_HOURS_DEFAULT_TIMEZONE" default:"UTC"`
+	QuietHoursLocation          *time.Location `ignored:"true"` // Parsed from QuietHoursDefaultTimezone by Load
+
+	// Recipient validation configuration
+	DefaultPhoneRegion string `envconfig:"DEFAULT_PHONE_REGION" default:"US"`
+	ValidateEmailMX    bool   `envconfig:"VALIDATE_EMAIL_MX" default:"false"` // Adds a DNS lookup per email
+}
+
+// KafkaSecurity returns the TLS and SASL settings for Kafka connections
+func (c *Config) KafkaSecurity() kafka.SecurityConfig {
+	return kafka.SecurityConfig{
+		TLS:           c.KafkaTLSEnabled,
+		CAFile:        c.KafkaTLSCAFile,
+		SASLMechanism: c.KafkaSASLMechanism,
+		Username:      c.KafkaSASLUsername,
+		Password:      c.KafkaSASLPassword,
+	}
 }
 
 // Load loads configuration from environment variables
@@ -67,5 +168,18 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	cfg.Flags = flags.Parse(cfg.FeatureFlags)
+
+	if !cfg.KafkaDeliverySemantics.Valid() {
+		return nil, fmt.Errorf("invalid KAFKA_DELIVERY_SEMANTICS %q: must be at_least_once or at_most_once", cfg.KafkaDeliverySemantics)
+	}
+
+	if cfg.QuietHoursStart < 0 || cfg.QuietHoursStart > 23 || cfg.QuietHoursEnd < 0 || cfg.QuietHoursEnd > 23 {
+		return nil, fmt.Errorf("invalid quiet hours %d-%d: hours must be between 0 and 23", cfg.QuietHoursStart, cfg.QuietHoursEnd)
+	}
+	if cfg.QuietHoursLocation, err = time.LoadLocation(cfg.QuietHoursDefaultTimezone); err != nil {
+		return nil, fmt.Errorf("invalid QUIET_HOURS_DEFAULT_TIMEZONE %q: %w", cfg.QuietHoursDefaultTimezone, err)
+	}
+
 	return &cfg, nil
 }
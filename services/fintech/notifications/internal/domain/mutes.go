@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Mute suppresses notifications for one account, or for every account when AccountID is empty,
+// until it expires. Used during incidents to avoid notifying accounts about the incident itself.
+type Mute struct {
+	ID        string    `json:"id"`
+	AccountID string    `json:"account_id,omitempty"`
+	Reason    string    `json:"reason"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewMute creates a mute for accountID, or a global mute when accountID is empty
+func NewMute(accountID, reason, createdBy string, expiresAt time.Time) (*Mute, error) {
+	if reason == "" {
+		return nil, errors.New("mute reason cannot be empty")
+	}
+	if createdBy == "" {
+		return nil, errors.New("mute operator cannot be empty")
+	}
+
+	now := time.Now().UTC()
+	if !expiresAt.After(now) {
+		return nil, errors.New("mute expiry must be in the future")
+	}
+
+	return &Mute{
+		AccountID: accountID,
+		Reason:    reason,
+		ExpiresAt: expiresAt.UTC(),
+		CreatedBy: createdBy,
+		CreatedAt: now,
+	}, nil
+}
+
+// IsGlobal reports whether the mute applies to every account
+func (m *Mute) IsGlobal() bool {
+	return m.AccountID == ""
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"errors"
+	"strings"
 	"time"
 )
 
@@ -22,26 +23,77 @@ const (
 	SentStatus      NotificationStatus = "SENT"
 	FailedStatus    NotificationStatus = "FAILED"
 	DeliveredStatus NotificationStatus = "DELIVERED"
+	MutedStatus     NotificationStatus = "MUTED"     // Suppressed by a mute; never sent
+	ThrottledStatus NotificationStatus = "THROTTLED" // Skipped by the per-recipient throttle; never sent
 )
 
 // Notification represents a notification to be sent
 type Notification struct {
-	ID          string             `json:"id"`
-	EventID     string             `json:"event_id"`
-	EventType   string             `json:"event_type"`
-	Type        NotificationType   `json:"type"`
-	Recipient   string             `json:"recipient"`
-	Subject     string             `json:"subject,omitempty"`
-	Body        string             `json:"body"`
-	Status      NotificationStatus `json:"status"`
-	Priority    int                `json:"priority"`
-	RetryCount  int                `json:"retry_count"`
-	MaxRetries  int                `json:"max_retries"`
-	NextRetryAt *time.Time         `json:"next_retry_at,omitempty"`
-	Error       string             `json:"error,omitempty"`
-	CreatedAt   time.Time          `json:"created_at"`
-	UpdatedAt   time.Time          `json:"updated_at"`
-	SentAt      *time.Time         `json:"sent_at,omitempty"`
+	ID                string             `json:"id"`
+	EventID           string             `json:"event_id"`
+	EventType         string             `json:"event_type"`
+	Type              NotificationType   `json:"type"`
+	AccountID         string             `json:"account_id,omitempty"`
+	Recipient         string             `json:"recipient"`
+	OriginalRecipient string             `json:"original_recipient,omitempty"` // Recipient at creation, if re-resolved at send time to a different one
+	Subject           string             `json:"subject,omitempty"`
+	Body              string             `json:"body"`
+	HTMLBody          string             `json:"html_body,omitempty"`       // Email only; sent alongside Body as an alternative part
+	Status            NotificationStatus `json:"status"`
+	Priority          int                `json:"priority"`
+	RetryCount        int                `json:"retry_count"`
+	MaxRetries        int                `json:"max_retries"`
+	NextRetryAt       *time.Time         `json:"next_retry_at,omitempty"`
+	Error             string             `json:"error,omitempty"`
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+	SentAt            *time.Time         `json:"sent_at,omitempty"`
+	Attachments       []AttachmentRef    `json:"attachments,omitempty"`     // Email only
+	DeliverBy         *time.Time         `json:"deliver_by,omitempty"`      // Delivery SLA deadline, if the priority has one
+	BreachedAt        *time.Time         `json:"sla_breached_at,omitempty"` // When the SLA breach was flagged
+	UndeliveredAt     *time.Time         `json:"undelivered_at,omitempty"`  // When it was flagged as sent without a delivery receipt
+}
+
+// AttachmentRef points at a file to attach to an email by URL or S3 key; the
+// bytes are fetched by the email sender at send time, never stored
+type AttachmentRef struct {
+	Filename    string `json:"filename"`
+	URL         string `json:"url,omitempty"`
+	S3Key       string `json:"s3_key,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
+// Validate checks that the reference names exactly one source
+func (a AttachmentRef) Validate() error {
+	if a.Filename == "" {
+		return errors.New("attachment filename cannot be empty")
+	}
+	if (a.URL == "") == (a.S3Key == "") {
+		return errors.New("attachment must have exactly one of url or s3_key")
+	}
+	return nil
+}
+
+// ErrNotificationNotFound is returned when a notification does not exist
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// AttemptOutcome represents the result of a single send attempt
+type AttemptOutcome string
+
+const (
+	AttemptSucceeded AttemptOutcome = "SUCCEEDED"
+	AttemptRetrying  AttemptOutcome = "RETRYING"
+	AttemptFailed    AttemptOutcome = "FAILED"
+)
+
+// NotificationAttempt records one send attempt of a notification
+type NotificationAttempt struct {
+	ID             string         `json:"id"`
+	NotificationID string         `json:"notification_id"`
+	AttemptNumber  int            `json:"attempt_number"`
+	Outcome        AttemptOutcome `json:"outcome"`
+	Error          string         `json:"error,omitempty"`
+	AttemptedAt    time.Time      `json:"attempted_at"`
 }
 
 // NewNotification creates a new notification
@@ -81,6 +133,12 @@ func (n *Notification) MarkAsSent() {
 	n.NextRetryAt = nil
 }
 
+// SetDeliverySLA sets the deadline for sending the notification to sla after its creation
+func (n *Notification) SetDeliverySLA(sla time.Duration) {
+	deliverBy := n.CreatedAt.Add(sla)
+	n.DeliverBy = &deliverBy
+}
+
 // MarkAsDelivered marks the notification as delivered
 func (n *Notification) MarkAsDelivered() {
 	n.Status = DeliveredStatus
@@ -103,6 +161,14 @@ func (n *Notification) MarkForRetry(delay time.Duration, errorMsg string) error
 	return nil
 }
 
+// DeferUntil keeps the notification pending without sending it, so the retry worker picks it up
+// at until. It doesn't count as a retry.
+func (n *Notification) DeferUntil(until time.Time) {
+	n.Status = PendingStatus
+	n.NextRetryAt = &until
+	n.UpdatedAt = time.Now().UTC()
+}
+
 // MarkAsFailed marks the notification as permanently failed
 func (n *Notification) MarkAsFailed(errorMsg string) {
 	n.Status = FailedStatus
@@ -111,17 +177,34 @@ func (n *Notification) MarkAsFailed(errorMsg string) {
 	n.NextRetryAt = nil
 }
 
+// MarkAsMuted records that the notification was suppressed by a mute rather than sent
+func (n *Notification) MarkAsMuted(reason string) {
+	n.Status = MutedStatus
+	n.Error = "Muted: " + reason
+	n.UpdatedAt = time.Now().UTC()
+	n.NextRetryAt = nil
+}
+
+// MarkAsThrottled records that the notification was skipped by the per-recipient throttle
+func (n *Notification) MarkAsThrottled(reason string) {
+	n.Status = ThrottledStatus
+	n.Error = "Throttled: " + reason
+	n.UpdatedAt = time.Now().UTC()
+	n.NextRetryAt = nil
+}
+
 // CanRetry checks if the notification can be retried
 func (n *Notification) CanRetry() bool {
 	return n.Status == PendingStatus && n.RetryCount < n.MaxRetries
 }
 
-// IsReadyForRetry checks if the notification is ready for retry
-func (n *Notification) IsReadyForRetry() bool {
-	if n.NextRetryAt == nil {
-		return false
-	}
-	return time.Now().UTC().After(*n.NextRetryAt)
+// IsReadyForRetry checks if the notification is due at now, matching the filter of
+// ClaimPendingNotifications (which also holds back unscheduled rows for PENDING_RECOVERY_AFTER):
+// no retry scheduled, or one due at or before now. The database clock
+// is the source of truth for due retries, so now should come from it rather than time.Now; the
+// retry worker relies on the query alone and never re-checks fetched rows.
+func (n *Notification) IsReadyForRetry(now time.Time) bool {
+	return n.NextRetryAt == nil || !n.NextRetryAt.After(now)
 }
 
 // NotificationTemplate represents a template for notifications
@@ -130,95 +213,207 @@ type NotificationTemplate struct {
 	NotificationType  NotificationType
 	SubjectTemplate   string
 	BodyTemplate      string
+	// HTMLBodyTemplate is rendered with html/template for email, escaping the event data
+	HTMLBodyTemplate  string
 	Priority          int
 	MaxRetries        int
+	// Defaults supplies fallback values for fields an event may omit
+	Defaults          map[string]interface{}
+	// Attachments are rendered with the template data; only used for email
+	Attachments       []AttachmentRef
 }
 
-// GetTemplate returns the appropriate template for an event type and notification type
-func GetTemplate(eventType string, notificationType NotificationType) *NotificationTemplate {
-	templates := map[string]map[NotificationType]*NotificationTemplate{
-		"PaymentInitiated": {
-			EmailNotification: {
-				EventType:        "PaymentInitiated",
-				NotificationType: EmailNotification,
-				SubjectTemplate:  "Payment Initiated - {{.PaymentID}}",
-				BodyTemplate:     "Your payment of {{.Amount}} {{.Currency}} has been initiated. Payment ID: {{.PaymentID}}",
-				Priority:         1,
-				MaxRetries:       3,
-			},
-			SMSNotification: {
-				EventType:        "PaymentInitiated",
-				NotificationType: SMSNotification,
-				BodyTemplate:     "Payment initiated: {{.Amount}} {{.Currency}}. ID: {{.PaymentID}}",
-				Priority:         1,
-				MaxRetries:       2,
-			},
-			PushNotification: {
-				EventType:        "PaymentInitiated",
-				NotificationType: PushNotification,
-				SubjectTemplate:  "Payment Started",
-				BodyTemplate:     "Your payment of {{.Amount}} {{.Currency}} is being processed",
-				Priority:         1,
-				MaxRetries:       2,
-			},
-		},
-		"PaymentCompleted": {
-			EmailNotification: {
-				EventType:        "PaymentCompleted",
-				NotificationType: EmailNotification,
-				SubjectTemplate:  "Payment Completed - {{.PaymentID}}",
-				BodyTemplate:     "Your payment of {{.Amount}} {{.Currency}} has been completed successfully. Payment ID: {{.PaymentID}}",
-				Priority:         2,
-				MaxRetries:       3,
-			},
-			SMSNotification: {
-				EventType:        "PaymentCompleted",
-				NotificationType: SMSNotification,
-				BodyTemplate:     "Payment completed: {{.Amount}} {{.Currency}}. ID: {{.PaymentID}}",
-				Priority:         2,
-				MaxRetries:       2,
-			},
-			PushNotification: {
-				EventType:        "PaymentCompleted",
-				NotificationType: PushNotification,
-				SubjectTemplate:  "Payment Successful",
-				BodyTemplate:     "Payment of {{.Amount}} {{.Currency}} completed successfully",
-				Priority:         2,
-				MaxRetries:       2,
-			},
-		},
-		"PaymentFailed": {
-			EmailNotification: {
-				EventType:        "PaymentFailed",
-				NotificationType: EmailNotification,
-				SubjectTemplate:  "Payment Failed - {{.PaymentID}}",
-				BodyTemplate:     "Your payment of {{.Amount}} {{.Currency}} has failed. Payment ID: {{.PaymentID}}. Reason: {{.Reason}}",
-				Priority:         3,
-				MaxRetries:       3,
-			},
-			SMSNotification: {
-				EventType:        "PaymentFailed",
-				NotificationType: SMSNotification,
-				BodyTemplate:     "Payment failed: {{.Amount}} {{.Currency}}. ID: {{.PaymentID}}. Contact support.",
-				Priority:         3,
-				MaxRetries:       2,
-			},
-			PushNotification: {
-				EventType:        "PaymentFailed",
-				NotificationType: PushNotification,
-				SubjectTemplate:  "Payment Failed",
-				BodyTemplate:     "Payment of {{.Amount}} {{.Currency}} failed. Please try again.",
-				Priority:         3,
-				MaxRetries:       2,
-			},
-		},
+// ApplyDefaults fills in template defaults for any field missing from data
+func (t *NotificationTemplate) ApplyDefaults(data map[string]interface{}) {
+	for key, value := range t.Defaults {
+		if _, exists := data[key]; !exists {
+			data[key] = value
+		}
 	}
+}
+
+// RecipientAttributes describe a recipient for template selection
+type RecipientAttributes struct {
+	Tier   string // e.g. "premium"; empty for the basic tier
+	Locale string // BCP 47 tag, e.g. "de-DE"
+}
 
-	if eventTemplates, exists := templates[eventType]; exists {
-		if template, exists := eventTemplates[notificationType]; exists {
+// TemplateKey identifies a template variant. An empty Tier or Locale matches any recipient.
+type TemplateKey struct {
+	EventType        string
+	NotificationType NotificationType
+	Tier             string
+	Locale           string
+}
+
+// templateVariants override the generic templates for recipients with matching attributes
+var templateVariants = map[TemplateKey]*NotificationTemplate{
+	{EventType: "PaymentCompleted", NotificationType: EmailNotification, Tier: "premium"}: {
+		EventType:        "PaymentCompleted",
+		NotificationType: EmailNotification,
+		SubjectTemplate:  "Your payment is complete - {{.PaymentID}}",
+		BodyTemplate:     "Thank you for being a premium customer. Your payment of {{.Amount}} {{.Currency}} has been completed successfully and your receipt is attached. Payment ID: {{.PaymentID}}. Your dedicated support line is available around the clock.",
+		Priority:         2,
+		MaxRetries:       5,
+		Attachments: []AttachmentRef{{
+			Filename:    "receipt-{{.PaymentID}}.pdf",
+			S3Key:       "receipts/{{.PaymentID}}.pdf",
+			ContentType: "application/pdf",
+		}},
+	},
+}
+
+// GetTemplate returns the most specific built-in template for an event type, notification type and
+// recipient. See SelectTemplate for the order variants are tried in.
+func GetTemplate(eventType string, notificationType NotificationType, recipient RecipientAttributes) *NotificationTemplate {
+	return SelectTemplate(BuiltinTemplate, eventType, notificationType, recipient)
+}
+
+// SelectTemplate returns the most specific template found by lookup for an event type, notification
+// type and recipient: tier and locale, then tier, then locale, then the generic template. Locales also
+// fall back from region to language ("de-DE" to "de").
+func SelectTemplate(lookup func(key TemplateKey) *NotificationTemplate, eventType string, notificationType NotificationType, recipient RecipientAttributes) *NotificationTemplate {
+	var locales []string
+	if recipient.Locale != "" {
+		locales = append(locales, recipient.Locale)
+		if language, _, ok := strings.Cut(recipient.Locale, "-"); ok {
+			locales = append(locales, language)
+		}
+	}
+
+	var candidates []TemplateKey
+	if recipient.Tier != "" {
+		for _, locale := range append(locales, "") {
+			candidates = append(candidates, TemplateKey{eventType, notificationType, recipient.Tier, locale})
+		}
+	}
+	for _, locale := range locales {
+		candidates = append(candidates, TemplateKey{eventType, notificationType, "", locale})
+	}
+	candidates = append(candidates, TemplateKey{EventType: eventType, NotificationType: notificationType})
+
+	for _, key := range candidates {
+		if template := lookup(key); template != nil {
 			return template
 		}
 	}
 
 	return nil
 }
+
+// BuiltinTemplate returns the template compiled in for exactly key, or nil. Generic templates have
+// an empty tier and locale.
+func BuiltinTemplate(key TemplateKey) *NotificationTemplate {
+	if template, exists := templateVariants[key]; exists {
+		return template
+	}
+	if key.Tier != "" || key.Locale != "" {
+		return nil
+	}
+	if eventTemplates, exists := genericTemplates[key.EventType]; exists {
+		return eventTemplates[key.NotificationType]
+	}
+	return nil
+}
+
+// BuiltinTemplates returns every template compiled in, keyed as BuiltinTemplate looks them up
+func BuiltinTemplates() map[TemplateKey]*NotificationTemplate {
+	templates := make(map[TemplateKey]*NotificationTemplate, len(templateVariants))
+	for key, template := range templateVariants {
+		templates[key] = template
+	}
+	for eventType, eventTemplates := range genericTemplates {
+		for notificationType, template := range eventTemplates {
+			templates[TemplateKey{EventType: eventType, NotificationType: notificationType}] = template
+		}
+	}
+	return templates
+}
+
+// genericTemplates are the templates for each event type and notification type that apply to any recipient
+var genericTemplates = map[string]map[NotificationType]*NotificationTemplate{
+	"PaymentInitiated": {
+		EmailNotification: {
+			EventType:        "PaymentInitiated",
+			NotificationType: EmailNotification,
+			SubjectTemplate:  "Payment Initiated - {{.PaymentID}}",
+			BodyTemplate:     "Your payment of {{.Amount}} {{.Currency}} has been initiated. Payment ID: {{.PaymentID}}",
+			HTMLBodyTemplate: "<p>Your payment of <strong>{{.Amount}} {{.Currency}}</strong> has been initiated.</p><p>Payment ID: {{.PaymentID}}</p>",
+			Priority:         1,
+			MaxRetries:       3,
+		},
+		SMSNotification: {
+			EventType:        "PaymentInitiated",
+			NotificationType: SMSNotification,
+			BodyTemplate:     "Payment initiated: {{.Amount}} {{.Currency}}. ID: {{.PaymentID}}",
+			Priority:         1,
+			MaxRetries:       2,
+		},
+		PushNotification: {
+			EventType:        "PaymentInitiated",
+			NotificationType: PushNotification,
+			SubjectTemplate:  "Payment Started",
+			BodyTemplate:     "Your payment of {{.Amount}} {{.Currency}} is being processed",
+			Priority:         1,
+			MaxRetries:       2,
+		},
+	},
+	"PaymentCompleted": {
+		EmailNotification: {
+			EventType:        "PaymentCompleted",
+			NotificationType: EmailNotification,
+			SubjectTemplate:  "Payment Completed - {{.PaymentID}}",
+			BodyTemplate:     "Your payment of {{.Amount}} {{.Currency}} has been completed successfully. Payment ID: {{.PaymentID}}",
+			HTMLBodyTemplate: "<p>Your payment of <strong>{{.Amount}} {{.Currency}}</strong> has been completed successfully.</p><p>Payment ID: {{.PaymentID}}</p>",
+			Priority:         2,
+			MaxRetries:       3,
+			Attachments: []AttachmentRef{{
+				Filename:    "receipt-{{.PaymentID}}.pdf",
+				S3Key:       "receipts/{{.PaymentID}}.pdf",
+				ContentType: "application/pdf",
+			}},
+		},
+		SMSNotification: {
+			EventType:        "PaymentCompleted",
+			NotificationType: SMSNotification,
+			BodyTemplate:     "Payment completed: {{.Amount}} {{.Currency}}. ID: {{.PaymentID}}",
+			Priority:         2,
+			MaxRetries:       2,
+		},
+		PushNotification: {
+			EventType:        "PaymentCompleted",
+			NotificationType: PushNotification,
+			SubjectTemplate:  "Payment Successful",
+			BodyTemplate:     "Payment of {{.Amount}} {{.Currency}} completed successfully",
+			Priority:         2,
+			MaxRetries:       2,
+		},
+	},
+	"PaymentFailed": {
+		EmailNotification: {
+			EventType:        "PaymentFailed",
+			NotificationType: EmailNotification,
+			SubjectTemplate:  "Payment Failed - {{.PaymentID}}",
+			BodyTemplate:     "Your payment of {{.Amount}} {{.Currency}} has failed. Payment ID: {{.PaymentID}}. Reason: {{.Reason}}",
+			HTMLBodyTemplate: "<p>Your payment of <strong>{{.Amount}} {{.Currency}}</strong> has failed.</p><p>Payment ID: {{.PaymentID}}</p><p>Reason: {{.Reason}}</p>",
+			Priority:         3,
+			MaxRetries:       3,
+			Defaults:         map[string]interface{}{"Reason": "Not specified"},
+		},
+		SMSNotification: {
+			EventType:        "PaymentFailed",
+			NotificationType: SMSNotification,
+			BodyTemplate:     "Payment failed: {{.Amount}} {{.Currency}}. ID: {{.PaymentID}}. Contact support.",
+			Priority:         3,
+			MaxRetries:       2,
+		},
+		PushNotification: {
+			EventType:        "PaymentFailed",
+			NotificationType: PushNotification,
+			SubjectTemplate:  "Payment Failed",
+			BodyTemplate:     "Payment of {{.Amount}} {{.Currency}} failed. Please try again.",
+			Priority:         3,
+			MaxRetries:       2,
+		},
+	},
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// NotificationPreferences records which channels an account wants notifications on.
+// Accounts without a stored row get DefaultPreferences, which enables every channel.
+type NotificationPreferences struct {
+	AccountID    string    `json:"account_id"`
+	EmailEnabled bool      `json:"email_enabled"`
+	SMSEnabled   bool      `json:"sms_enabled"`
+	PushEnabled  bool      `json:"push_enabled"`
+	Timezone     string    `json:"timezone,omitempty"` // IANA name for quiet hours; empty uses the service default
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// DefaultPreferences returns the all-enabled preferences used when an account has none stored
+func DefaultPreferences(accountID string) *NotificationPreferences {
+	return &NotificationPreferences{
+		AccountID:    accountID,
+		EmailEnabled: true,
+		SMSEnabled:   true,
+		PushEnabled:  true,
+	}
+}
+
+// Allows reports whether the account accepts notifications of the given type
+func (p *NotificationPreferences) Allows(notificationType NotificationType) bool {
+	switch notificationType {
+	case EmailNotification:
+		return p.EmailEnabled
+	case SMSNotification:
+		return p.SMSEnabled
+	case PushNotification:
+		return p.PushEnabled
+	default:
+		return true
+	}
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// QuietHours is a daily window, in whole local hours, during which non-urgent notifications are held
+// back. A Start after End spans midnight (22 to 7 covers 22:00-06:59); Start equal to End is empty.
+type QuietHours struct {
+	Start int
+	End   int
+}
+
+// Until reports whether now falls inside the window and, if so, when the window ends. The end is in
+// now's location, so pass now in the recipient's timezone.
+func (q QuietHours) Until(now time.Time) (time.Time, bool) {
+	hour := now.Hour()
+
+	var inside bool
+	if q.Start <= q.End {
+		inside = hour >= q.Start && hour < q.End
+	} else {
+		inside = hour >= q.Start || hour < q.End
+	}
+	if !inside {
+		return time.Time{}, false
+	}
+
+	// Before midnight in a spanning window the end falls on the next day
+	end := time.Date(now.Year(), now.Month(), now.Day(), q.End, 0, 0, 0, now.Location())
+	if !end.After(now) {
+		end = time.Date(now.Year(), now.Month(), now.Day()+1, q.End, 0, 0, 0, now.Location())
+	}
+	return end, true
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHoursUntil(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		quiet      QuietHours
+		now        time.Time
+		wantInside bool
+		wantEnd    time.Time
+	}{
+		// A 22-7 window spans midnight, ending the next morning before midnight and the same morning after it
+		{"before midnight", QuietHours{Start: 22, End: 7}, time.Date(2024, 1, 15, 23, 0, 0, 0, time.UTC), true, time.Date(2024, 1, 16, 7, 0, 0, 0, time.UTC)},
+		{"after midnight", QuietHours{Start: 22, End: 7}, time.Date(2024, 1, 16, 3, 0, 0, 0, time.UTC), true, time.Date(2024, 1, 16, 7, 0, 0, 0, time.UTC)},
+		{"after the window", QuietHours{Start: 22, End: 7}, time.Date(2024, 1, 16, 8, 0, 0, 0, time.UTC), false, time.Time{}},
+		{"at the start", QuietHours{Start: 22, End: 7}, time.Date(2024, 1, 15, 22, 0, 0, 0, time.UTC), true, time.Date(2024, 1, 16, 7, 0, 0, 0, time.UTC)},
+		{"at the end", QuietHours{Start: 22, End: 7}, time.Date(2024, 1, 16, 7, 0, 0, 0, time.UTC), false, time.Time{}},
+		{"same day window", QuietHours{Start: 1, End: 5}, time.Date(2024, 1, 16, 3, 0, 0, 0, time.UTC), true, time.Date(2024, 1, 16, 5, 0, 0, 0, time.UTC)},
+		// The window is in now's location: 23:00 in New York is 04:00 UTC the next day
+		{"non-UTC location", QuietHours{Start: 22, End: 7}, time.Date(2024, 1, 15, 23, 0, 0, 0, newYork), true, time.Date(2024, 1, 16, 7, 0, 0, 0, newYork)},
+		{"empty window", QuietHours{Start: 22, End: 22}, time.Date(2024, 1, 15, 22, 30, 0, 0, time.UTC), false, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, inside := tt.quiet.Until(tt.now)
+			if inside != tt.wantInside || !end.Equal(tt.wantEnd) {
+				t.Errorf("Until(%s) = %s, %v, want %s, %v", tt.now, end, inside, tt.wantEnd, tt.wantInside)
+			}
+		})
+	}
+}
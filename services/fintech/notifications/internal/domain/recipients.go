@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+// ErrInvalidRecipient is returned when a recipient can never be delivered to
+var ErrInvalidRecipient = errors.New("invalid recipient")
+
+// NormalizePhoneNumber validates a phone number and formats it as E.164.
+// Numbers without a country code are parsed using defaultRegion (e.g. "US").
+func NormalizePhoneNumber(raw, defaultRegion string) (string, error) {
+	number, err := phonenumbers.Parse(raw, defaultRegion)
+	if err != nil {
+		return "", fmt.Errorf("%w: phone number %q: %v", ErrInvalidRecipient, raw, err)
+	}
+	if !phonenumbers.IsValidNumber(number) {
+		return "", fmt.Errorf("%w: phone number %q is not a valid number", ErrInvalidRecipient, raw)
+	}
+
+	return phonenumbers.Format(number, phonenumbers.E164), nil
+}
+
+// NormalizeEmailAddress performs syntactic validation of a bare email address
+// and returns it with the domain lower-cased.
+func NormalizeEmailAddress(raw string) (string, error) {
+	address, err := mail.ParseAddress(raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: email address %q: %v", ErrInvalidRecipient, raw, err)
+	}
+	if address.Name != "" || address.Address != strings.TrimSpace(raw) {
+		return "", fmt.Errorf("%w: email address %q must not include a display name", ErrInvalidRecipient, raw)
+	}
+
+	local, domain := EmailDomain(address.Address)
+	if local == "" || !strings.Contains(domain, ".") {
+		return "", fmt.Errorf("%w: email address %q has no valid domain", ErrInvalidRecipient, raw)
+	}
+
+	return local + "@" + strings.ToLower(domain), nil
+}
+
+// EmailDomain splits an email address into its local part and domain
+func EmailDomain(address string) (string, string) {
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return address, ""
+	}
+	return address[:at], address[at+1:]
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		region string
+		want   string
+	}{
+		{"international US", "+1 650-253-0000", "", "+16502530000"},
+		{"international DE", "+49 30 123456", "US", "+4930123456"},
+		{"local with default region", "(650) 253-0000", "US", "+16502530000"},
+		{"local GB with default region", "020 7946 0958", "GB", "+442079460958"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizePhoneNumber(tt.raw, tt.region)
+			if err != nil {
+				t.Fatalf("NormalizePhoneNumber(%q, %q): %v", tt.raw, tt.region, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizePhoneNumber(%q, %q) = %q, want %q", tt.raw, tt.region, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizePhoneNumberInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		region string
+	}{
+		{"too short", "+1 555 01", "US"},
+		{"not a number", "call me", "US"},
+		{"local without default region", "(650) 253-0000", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NormalizePhoneNumber(tt.raw, tt.region)
+			if !errors.Is(err, ErrInvalidRecipient) {
+				t.Errorf("NormalizePhoneNumber(%q, %q) error = %v, want ErrInvalidRecipient", tt.raw, tt.region, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeEmailAddress(t *testing.T) {
+	got, err := NormalizeEmailAddress("Jane.Doe@Example.COM")
+	if err != nil {
+		t.Fatalf("NormalizeEmailAddress: %v", err)
+	}
+	if got != "Jane.Doe@example.com" {
+		t.Errorf("NormalizeEmailAddress = %q, want %q", got, "Jane.Doe@example.com")
+	}
+}
+
+func TestNormalizeEmailAddressInvalid(t *testing.T) {
+	for _, raw := range []string{
+		"not-an-address",
+		"jane@",
+		"jane@localhost",
+		"Jane Doe <jane@example.com>",
+	} {
+		if _, err := NormalizeEmailAddress(raw); !errors.Is(err, ErrInvalidRecipient) {
+			t.Errorf("NormalizeEmailAddress(%q) error = %v, want ErrInvalidRecipient", raw, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetTemplatePremiumVariant(t *testing.T) {
+	generic := GetTemplate("PaymentCompleted", EmailNotification, RecipientAttributes{})
+	if generic == nil {
+		t.Fatal("expected a generic PaymentCompleted email template")
+	}
+
+	premium := GetTemplate("PaymentCompleted", EmailNotification, RecipientAttributes{Tier: "premium", Locale: "de-DE"})
+	if premium == nil || premium == generic {
+		t.Fatalf("premium template = %+v, want the premium variant", premium)
+	}
+	if !strings.Contains(premium.BodyTemplate, "premium customer") {
+		t.Errorf("premium body = %q, want the premium wording", premium.BodyTemplate)
+	}
+}
+
+func TestGetTemplateFallsBackToGeneric(t *testing.T) {
+	tests := []struct {
+		name             string
+		eventType        string
+		notificationType NotificationType
+		recipient        RecipientAttributes
+	}{
+		{"basic tier", "PaymentCompleted", EmailNotification, RecipientAttributes{}},
+		{"unknown tier", "PaymentCompleted", EmailNotification, RecipientAttributes{Tier: "gold"}},
+		{"premium without a channel variant", "PaymentCompleted", SMSNotification, RecipientAttributes{Tier: "premium"}},
+		{"premium without an event variant", "PaymentFailed", EmailNotification, RecipientAttributes{Tier: "premium", Locale: "en-GB"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := BuiltinTemplate(TemplateKey{EventType: tt.eventType, NotificationType: tt.notificationType})
+			if want == nil {
+				t.Fatalf("expected a generic %s/%s template", tt.eventType, tt.notificationType)
+			}
+			if got := GetTemplate(tt.eventType, tt.notificationType, tt.recipient); got != want {
+				t.Errorf("GetTemplate(%s, %s, %+v) = %+v, want the generic template", tt.eventType, tt.notificationType, tt.recipient, got)
+			}
+		})
+	}
+}
+
+func TestSelectTemplateMostSpecificFirst(t *testing.T) {
+	keys := []TemplateKey{
+		{EventType: "PaymentCompleted", NotificationType: EmailNotification, Tier: "premium", Locale: "de-DE"},
+		{EventType: "PaymentCompleted", NotificationType: EmailNotification, Tier: "premium", Locale: "de"},
+		{EventType: "PaymentCompleted", NotificationType: EmailNotification, Tier: "premium"},
+		{EventType: "PaymentCompleted", NotificationType: EmailNotification, Locale: "de-DE"},
+		{EventType: "PaymentCompleted", NotificationType: EmailNotification, Locale: "de"},
+		{EventType: "PaymentCompleted", NotificationType: EmailNotification},
+	}
+	recipient := RecipientAttributes{Tier: "premium", Locale: "de-DE"}
+
+	// Remove the most specific template each round: the next one in order is selected
+	for i, want := range keys {
+		available := make(map[TemplateKey]*NotificationTemplate)
+		for _, key := range keys[i:] {
+			available[key] = &NotificationTemplate{EventType: key.EventType, NotificationType: key.NotificationType, SubjectTemplate: key.Tier + "/" + key.Locale}
+		}
+
+		got := SelectTemplate(func(key TemplateKey) *NotificationTemplate { return available[key] }, "PaymentCompleted", EmailNotification, recipient)
+		if got == nil || got != available[want] {
+			t.Errorf("with %d variants removed: selected %+v, want %s/%s", i, got, want.Tier, want.Locale)
+		}
+	}
+
+	if got := SelectTemplate(func(TemplateKey) *NotificationTemplate { return nil }, "PaymentCompleted", EmailNotification, recipient); got != nil {
+		t.Errorf("with no templates: selected %+v, want nil", got)
+	}
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidTransition is returned when a status change is not allowed by the state machine
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// allowedTransitions is the notification state machine. PENDING -> PENDING re-queues a
+// notification whose in-flight send was lost; FAILED, MUTED or THROTTLED -> PENDING re-triggers one.
+var allowedTransitions = map[NotificationStatus][]NotificationStatus{
+	PendingStatus:   {PendingStatus, SentStatus, FailedStatus},
+	SentStatus:      {DeliveredStatus, FailedStatus},
+	FailedStatus:    {PendingStatus},
+	DeliveredStatus: {},
+	MutedStatus:     {PendingStatus},
+	ThrottledStatus: {PendingStatus},
+}
+
+// CanTransition reports whether a notification may move from one status to another
+func CanTransition(from, to NotificationStatus) bool {
+	for _, allowed := range allowedTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusTransition records a manual status change made by an operator
+type StatusTransition struct {
+	ID             string             `json:"id"`
+	NotificationID string             `json:"notification_id"`
+	FromStatus     NotificationStatus `json:"from_status"`
+	ToStatus       NotificationStatus `json:"to_status"`
+	Operator       string             `json:"operator"`
+	Reason         string             `json:"reason,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+// TransitionTo moves the notification to status if the state machine allows it
+func (n *Notification) TransitionTo(status NotificationStatus, reason string) error {
+	if !CanTransition(n.Status, status) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, n.Status, status)
+	}
+
+	switch status {
+	case PendingStatus:
+		n.Status = PendingStatus
+		n.NextRetryAt = nil
+		n.Error = ""
+		n.UpdatedAt = time.Now().UTC()
+	case SentStatus:
+		n.MarkAsSent()
+	case FailedStatus:
+		n.MarkAsFailed(reason)
+	case DeliveredStatus:
+		n.MarkAsDelivered()
+	}
+
+	return nil
+}
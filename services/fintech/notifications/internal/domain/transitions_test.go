@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTransitionToAllowed(t *testing.T) {
+	n := &Notification{Status: PendingStatus}
+
+	if err := n.TransitionTo(FailedStatus, "Manually transitioned by ops-1"); err != nil {
+		t.Fatalf("PENDING -> FAILED: %v", err)
+	}
+	if n.Status != FailedStatus || n.Error != "Manually transitioned by ops-1" {
+		t.Errorf("after PENDING -> FAILED: status=%s error=%q", n.Status, n.Error)
+	}
+
+	if err := n.TransitionTo(PendingStatus, ""); err != nil {
+		t.Fatalf("FAILED -> PENDING: %v", err)
+	}
+	if n.Status != PendingStatus || n.Error != "" || n.NextRetryAt != nil {
+		t.Errorf("after FAILED -> PENDING: status=%s error=%q next_retry_at=%v, want a fresh pending notification", n.Status, n.Error, n.NextRetryAt)
+	}
+}
+
+func TestTransitionToRejected(t *testing.T) {
+	tests := []struct {
+		from NotificationStatus
+		to   NotificationStatus
+	}{
+		{DeliveredStatus, PendingStatus},
+		{FailedStatus, SentStatus},
+		{SentStatus, PendingStatus},
+		{MutedStatus, SentStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			n := &Notification{Status: tt.from}
+			if err := n.TransitionTo(tt.to, ""); !errors.Is(err, ErrInvalidTransition) {
+				t.Fatalf("TransitionTo error = %v, want ErrInvalidTransition", err)
+			}
+			if n.Status != tt.from {
+				t.Errorf("status = %s after a rejected transition, want %s", n.Status, tt.from)
+			}
+		})
+	}
+}
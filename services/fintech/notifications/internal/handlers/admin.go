@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"fintech/notifications-service/pkg/respond"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogLevelRequest represents a request to change the log level
+type LogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// LogLevelResponse reports the current log level
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
+
+// GetLogLevel handles GET /admin/loglevel
+func GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	if err := respond.JSON(r.Context(), w, http.StatusOK, LogLevelResponse{Level: logrus.GetLevel().String()}); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// SetLogLevel handles PUT /admin/loglevel, changing the log level without a restart
+func SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req LogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	level, err := logrus.ParseLevel(req.Level)
+	if err != nil {
+		http.Error(w, "Invalid log level. Must be one of panic, fatal, error, warn, info, debug, trace", http.StatusBadRequest)
+		return
+	}
+
+	previous := logrus.GetLevel()
+	logrus.SetLevel(level)
+	logrus.WithFields(logrus.Fields{
+		"previous": previous.String(),
+		"level":    level.String(),
+	}).Warn("Log level changed")
+
+	if err := respond.JSON(r.Context(), w, http.StatusOK, LogLevelResponse{Level: level.String()}); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/otel"
+	"fintech/notifications-service/pkg/respond"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	dashboardFailureWindow = time.Hour      // Window for the failure rate
+	dashboardVolumeWindow  = 24 * time.Hour // Window for per-channel volume
+)
+
+// FailureRate reports the share of recent notifications that failed
+type FailureRate struct {
+	Window string  `json:"window"`
+	Failed int     `json:"failed"`
+	Total  int     `json:"total"`
+	Rate   float64 `json:"rate"` // failed / total; 0 when there were none
+}
+
+// PendingBacklog reports how far behind pending notifications are
+type PendingBacklog struct {
+	Count            int        `json:"count"`
+	OldestCreatedAt  *time.Time `json:"oldest_created_at,omitempty"`
+	OldestAgeSeconds float64    `json:"oldest_age_seconds"`
+}
+
+// ChannelVolume reports recent notification volume per channel
+type ChannelVolume struct {
+	Window   string         `json:"window"`
+	Channels map[string]int `json:"channels"`
+}
+
+// DashboardResponse aggregates the key notification metrics for the ops dashboard
+type DashboardResponse struct {
+	StatusCounts  map[string]int `json:"status_counts"`
+	FailureRate   FailureRate    `json:"failure_rate"`
+	Pending       PendingBacklog `json:"pending"`
+	ChannelVolume ChannelVolume  `json:"channel_volume"`
+	GeneratedAt   time.Time      `json:"generated_at"`
+}
+
+// dashboardCache holds the last dashboard response so frequent refreshes don't hit the database
+type dashboardCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	response *DashboardResponse
+}
+
+// GetDashboard handles GET /admin/dashboard
+func (s *NotificationService) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "GetDashboard")
+	defer span.End()
+
+	response, err := s.dashboardResponse(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build dashboard")
+		http.Error(w, "Failed to build dashboard", http.StatusInternalServerError)
+		return
+	}
+
+	if err := respond.JSON(ctx, w, http.StatusOK, response); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// dashboardResponse returns the cached dashboard, rebuilding it once the cache has expired
+func (s *NotificationService) dashboardResponse(ctx context.Context) (*DashboardResponse, error) {
+	s.dashboard.mu.Lock()
+	defer s.dashboard.mu.Unlock()
+
+	now := time.Now().UTC()
+	if cached := s.dashboard.response; cached != nil && now.Sub(cached.GeneratedAt) < s.dashboard.ttl {
+		return cached, nil
+	}
+
+	response, err := s.buildDashboard(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	s.dashboard.response = response
+	return response, nil
+}
+
+// buildDashboard runs the dashboard aggregations as of now
+func (s *NotificationService) buildDashboard(ctx context.Context, now time.Time) (*DashboardResponse, error) {
+	statusCounts, err := s.repo.GetNotificationStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	failed, total, err := s.repo.GetFailureCounts(ctx, now.Add(-dashboardFailureWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	oldestPending, err := s.repo.GetOldestPending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	channels, err := s.repo.GetChannelVolume(ctx, now.Add(-dashboardVolumeWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DashboardResponse{
+		StatusCounts: statusCounts,
+		FailureRate: FailureRate{
+			Window: dashboardFailureWindow.String(),
+			Failed: failed,
+			Total:  total,
+		},
+		Pending: PendingBacklog{
+			Count:           statusCounts[string(domain.PendingStatus)],
+			OldestCreatedAt: oldestPending,
+		},
+		ChannelVolume: ChannelVolume{
+			Window:   dashboardVolumeWindow.String(),
+			Channels: channels,
+		},
+		GeneratedAt: now,
+	}
+	if total > 0 {
+		response.FailureRate.Rate = float64(failed) / float64(total)
+	}
+	if oldestPending != nil {
+		response.Pending.OldestAgeSeconds = now.Sub(*oldestPending).Seconds()
+	}
+
+	return response, nil
+}
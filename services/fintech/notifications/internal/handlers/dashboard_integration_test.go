@@ -0,0 +1,136 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/database"
+)
+
+// seedNotification saves a notification on channel with status, created at createdAt, and
+// deletes it when the test ends
+func seedNotification(t *testing.T, s *NotificationService, db *database.DB, channel domain.NotificationType, status domain.NotificationStatus, createdAt time.Time) {
+	t.Helper()
+	ctx := context.Background()
+
+	notification, err := domain.NewNotification(newID("pay"), "PaymentCompleted", channel, "jane@example.com", "Payment Completed", "Your payment has completed.", 1, 3)
+	if err != nil {
+		t.Fatalf("NewNotification: %v", err)
+	}
+	if _, err := s.repo.Create(ctx, notification); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Exec(context.Background(), "DELETE FROM notifications WHERE id = $1", notification.ID)
+	})
+
+	if _, err := db.Exec(ctx, "UPDATE notifications SET status = $2, created_at = $3 WHERE id = $1", notification.ID, string(status), createdAt); err != nil {
+		t.Fatalf("failed to seed notification: %v", err)
+	}
+}
+
+func TestBuildDashboard(t *testing.T) {
+	s, db := newTestService(t, nil)
+
+	// The windows are measured back from a far-future now, so only the seeded rows fall inside them
+	now := time.Date(2200, time.January, 1, 0, 0, 0, 0, time.UTC)
+	oldestPending := time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	lastHour := now.Add(-30 * time.Minute)
+	seedNotification(t, s, db, domain.EmailNotification, domain.FailedStatus, lastHour)
+	seedNotification(t, s, db, domain.EmailNotification, domain.SentStatus, lastHour)
+	seedNotification(t, s, db, domain.SMSNotification, domain.SentStatus, lastHour)
+	seedNotification(t, s, db, domain.PushNotification, domain.PendingStatus, lastHour)
+	seedNotification(t, s, db, domain.SMSNotification, domain.SentStatus, now.Add(-5*time.Hour))
+	seedNotification(t, s, db, domain.PushNotification, domain.SentStatus, now.Add(-48*time.Hour))
+	seedNotification(t, s, db, domain.EmailNotification, domain.PendingStatus, oldestPending)
+
+	dashboard, err := s.buildDashboard(context.Background(), now)
+	if err != nil {
+		t.Fatalf("buildDashboard: %v", err)
+	}
+
+	// Status counts cover the whole table, which other tests share
+	for status, seeded := range map[string]int{"FAILED": 1, "SENT": 4, "PENDING": 2} {
+		if got := dashboard.StatusCounts[status]; got < seeded {
+			t.Errorf("status count %s = %d, want at least the %d seeded", status, got, seeded)
+		}
+	}
+
+	if got := dashboard.FailureRate; got.Failed != 1 || got.Total != 4 || got.Rate != 0.25 || got.Window != "1h0m0s" {
+		t.Errorf("failure rate = %+v, want 1 of 4 failed over 1h0m0s", got)
+	}
+
+	if dashboard.Pending.Count != dashboard.StatusCounts["PENDING"] {
+		t.Errorf("pending count = %d, want the PENDING status count %d", dashboard.Pending.Count, dashboard.StatusCounts["PENDING"])
+	}
+	if got := dashboard.Pending.OldestCreatedAt; got == nil || !got.Equal(oldestPending) {
+		t.Errorf("oldest pending = %v, want %v", got, oldestPending)
+	}
+	if got, want := dashboard.Pending.OldestAgeSeconds, now.Sub(oldestPending).Seconds(); got != want {
+		t.Errorf("oldest pending age = %v, want %v", got, want)
+	}
+
+	wantChannels := map[string]int{"EMAIL": 2, "SMS": 2, "PUSH": 1}
+	if len(dashboard.ChannelVolume.Channels) != len(wantChannels) {
+		t.Errorf("channel volume = %v, want %v", dashboard.ChannelVolume.Channels, wantChannels)
+	}
+	for channel, want := range wantChannels {
+		if got := dashboard.ChannelVolume.Channels[channel]; got != want {
+			t.Errorf("%s volume = %d, want %d", channel, got, want)
+		}
+	}
+}
+
+func TestGetDashboardIncludesEverySection(t *testing.T) {
+	s, _ := newTestService(t, nil)
+
+	rec := httptest.NewRecorder()
+	s.GetDashboard(rec, httptest.NewRequest(http.MethodGet, "/admin/dashboard", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var sections map[string]json.RawMessage
+	if err := json.NewDecoder(rec.Body).Decode(&sections); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	for _, section := range []string{"status_counts", "failure_rate", "pending", "channel_volume", "generated_at"} {
+		if _, ok := sections[section]; !ok {
+			t.Errorf("response has no %s section", section)
+		}
+	}
+}
+
+func TestDashboardResponseIsCached(t *testing.T) {
+	s, _ := newTestService(t, map[string]string{"DASHBOARD_CACHE_TTL": "1m"})
+
+	first, err := s.dashboardResponse(context.Background())
+	if err != nil {
+		t.Fatalf("dashboardResponse: %v", err)
+	}
+	second, err := s.dashboardResponse(context.Background())
+	if err != nil {
+		t.Fatalf("dashboardResponse: %v", err)
+	}
+	if first != second {
+		t.Error("dashboard rebuilt within the cache TTL")
+	}
+
+	// An expired response is rebuilt
+	first.GeneratedAt = first.GeneratedAt.Add(-2 * time.Minute)
+	third, err := s.dashboardResponse(context.Background())
+	if err != nil {
+		t.Fatalf("dashboardResponse: %v", err)
+	}
+	if third == first {
+		t.Error("dashboard not rebuilt after the cache TTL")
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"container/heap"
+	"sync"
+
+	"fintech/notifications-service/internal/domain"
+)
+
+// sendQueue is a blocking priority queue of notifications awaiting send.
+// Higher priority notifications are popped first; equal priorities are FIFO.
+type sendQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	items  notificationHeap
+	seq    uint64
+	closed bool
+}
+
+func newSendQueue() *sendQueue {
+	q := &sendQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// Push enqueues a notification
+func (q *sendQueue) Push(notification *domain.Notification) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.seq++
+	heap.Push(&q.items, queuedNotification{notification: notification, seq: q.seq})
+	q.cond.Signal()
+}
+
+// Pop blocks until a notification is available, returning false once the queue is closed and empty
+func (q *sendQueue) Pop() (*domain.Notification, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if len(q.items) == 0 {
+		return nil, false
+	}
+
+	return heap.Pop(&q.items).(queuedNotification).notification, true
+}
+
+// Close wakes all waiting workers; remaining items are still handed out
+func (q *sendQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+type queuedNotification struct {
+	notification *domain.Notification
+	seq          uint64
+}
+
+// notificationHeap implements heap.Interface ordered by priority desc, then enqueue order
+type notificationHeap []queuedNotification
+
+func (h notificationHeap) Len() int { return len(h) }
+
+func (h notificationHeap) Less(i, j int) bool {
+	if h[i].notification.Priority != h[j].notification.Priority {
+		return h[i].notification.Priority > h[j].notification.Priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h notificationHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *notificationHeap) Push(x interface{}) { *h = append(*h, x.(queuedNotification)) }
+
+func (h *notificationHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"fintech/notifications-service/internal/domain"
+)
+
+func queuedNotificationWith(id string, priority int) *domain.Notification {
+	return &domain.Notification{ID: id, Priority: priority}
+}
+
+func TestSendQueueHighPriorityFirst(t *testing.T) {
+	q := newSendQueue()
+	q.Push(queuedNotificationWith("low-1", 1))
+	q.Push(queuedNotificationWith("low-2", 1))
+	q.Push(queuedNotificationWith("normal", 2))
+	q.Push(queuedNotificationWith("high", 3))
+
+	want := []string{"high", "normal", "low-1", "low-2"}
+	for _, id := range want {
+		notification, ok := q.Pop()
+		if !ok {
+			t.Fatalf("Pop returned no notification, want %s", id)
+		}
+		if notification.ID != id {
+			t.Errorf("Pop = %s, want %s", notification.ID, id)
+		}
+	}
+}
+
+func TestSendQueueCloseDrainsRemaining(t *testing.T) {
+	q := newSendQueue()
+	q.Push(queuedNotificationWith("queued", 1))
+	q.Close()
+
+	if notification, ok := q.Pop(); !ok || notification.ID != "queued" {
+		t.Fatalf("Pop after Close = %v, %v; want the queued notification", notification, ok)
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Pop on a closed, empty queue returned a notification")
+	}
+}
+
+func TestSendQueuePopWaitsForPush(t *testing.T) {
+	q := newSendQueue()
+	popped := make(chan string, 1)
+	go func() {
+		notification, ok := q.Pop()
+		if ok {
+			popped <- notification.ID
+		}
+		close(popped)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	q.Push(queuedNotificationWith("late", 1))
+
+	select {
+	case id := <-popped:
+		if id != "late" {
+			t.Errorf("Pop = %s, want late", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Pop did not return after Push")
+	}
+}
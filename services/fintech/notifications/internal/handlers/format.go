@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// symbolAfterAmount are the languages whose amounts are written with the currency symbol after the
+// number, e.g. "1.234,50 €" in German
+var symbolAfterAmount = map[string]bool{
+	"cs": true, "da": true, "de": true, "es": true, "fi": true, "fr": true,
+	"it": true, "nb": true, "pl": true, "pt": true, "ru": true, "sv": true,
+}
+
+// dateLayouts are the numeric date layouts by locale; a language entry covers each of its regions
+// without one of their own, and anything else is written as ISO 8601 (2006-01-02)
+var dateLayouts = map[string]string{
+	"en-US": "01/02/2006",
+	"en":    "02/01/2006",
+	"de":    "02.01.2006",
+	"da":    "02.01.2006",
+	"fi":    "02.01.2006",
+	"nb":    "02.01.2006",
+	"pl":    "02.01.2006",
+	"ru":    "02.01.2006",
+	"cs":    "02.01.2006",
+	"fr":    "02/01/2006",
+	"es":    "02/01/2006",
+	"it":    "02/01/2006",
+	"pt":    "02/01/2006",
+}
+
+// localeFuncs returns the template funcs formatting dynamic values for the recipient's locale, a
+// BCP 47 tag such as "de-DE". An empty or unknown locale formats as en-US.
+//
+//	{{money .Amount .Currency}}  renders 1234.5 EUR as "€ 1,234.50" in en-US and "1.234,50 €" in de-DE
+//	{{dateFmt .Date}}            renders a time.Time or RFC3339 string as "01/15/2024" or "15.01.2024"
+func localeFuncs(locale string) template.FuncMap {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		tag = language.AmericanEnglish
+	}
+	printer := message.NewPrinter(tag)
+
+	return template.FuncMap{
+		"money": func(amount interface{}, code string) (string, error) {
+			return formatMoney(printer, tag, amount, code)
+		},
+		"dateFmt": func(value interface{}) (string, error) {
+			return formatDate(tag, value)
+		},
+	}
+}
+
+// formatMoney writes amount in the currency with ISO code, using the currency's standard number
+// of decimals and the locale's separators and symbol position
+func formatMoney(printer *message.Printer, tag language.Tag, amount interface{}, code string) (string, error) {
+	value, err := toFloat(amount)
+	if err != nil {
+		return "", err
+	}
+
+	unit, err := currency.ParseISO(strings.TrimSpace(code))
+	if err != nil {
+		return "", fmt.Errorf("money: unknown currency %q", code)
+	}
+	scale, _ := currency.Standard.Rounding(unit)
+
+	digits := printer.Sprint(number.Decimal(value, number.Scale(scale)))
+	symbol := printer.Sprint(currency.NarrowSymbol(unit))
+
+	base, _ := tag.Base()
+	if symbolAfterAmount[base.String()] {
+		return digits + " " + symbol, nil
+	}
+	return symbol + " " + digits, nil
+}
+
+// formatDate writes a time.Time or RFC3339 string as a numeric date in the locale's order
+func formatDate(tag language.Tag, value interface{}) (string, error) {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return "", fmt.Errorf("dateFmt: nil time")
+		}
+		t = *v
+	case string:
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return "", fmt.Errorf("dateFmt: %w", err)
+		}
+		t = parsed
+	default:
+		return "", fmt.Errorf("dateFmt: unsupported value of type %T", value)
+	}
+
+	base, _ := tag.Base()
+	region, _ := tag.Region()
+	if layout, ok := dateLayouts[base.String()+"-"+region.String()]; ok {
+		return t.Format(layout), nil
+	}
+	if layout, ok := dateLayouts[base.String()]; ok {
+		return t.Format(layout), nil
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+// toFloat converts a numeric template value to float64
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	default:
+		return 0, fmt.Errorf("money: unsupported amount of type %T", value)
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderTemplateLocalizesDynamicValues(t *testing.T) {
+	data := map[string]interface{}{
+		"Amount":   1234.5,
+		"Currency": "EUR",
+		"Date":     time.Date(2024, time.January, 15, 10, 30, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"en-US", "€ 1,234.50 on 01/15/2024"},
+		{"de-DE", "1.234,50 € on 15.01.2024"},
+		{"", "€ 1,234.50 on 01/15/2024"},
+	}
+
+	s := &NotificationService{}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			body, err := s.renderTemplate("{{money .Amount .Currency}} on {{dateFmt .Date}}", data, tt.locale)
+			if err != nil {
+				t.Fatalf("renderTemplate: %v", err)
+			}
+			if body != tt.want {
+				t.Errorf("body in %q = %q, want %q", tt.locale, body, tt.want)
+			}
+
+			html, err := s.renderHTMLTemplate("<p>{{money .Amount .Currency}} on {{dateFmt .Date}}</p>", data, tt.locale)
+			if err != nil {
+				t.Fatalf("renderHTMLTemplate: %v", err)
+			}
+			if html != "<p>"+tt.want+"</p>" {
+				t.Errorf("html in %q = %q, want %q", tt.locale, html, "<p>"+tt.want+"</p>")
+			}
+		})
+	}
+}
+
+func TestFormatMoneyUsesCurrencyDecimals(t *testing.T) {
+	s := &NotificationService{}
+
+	body, err := s.renderTemplate("{{money .Amount .Currency}}", map[string]interface{}{"Amount": 1234.25, "Currency": "JPY"}, "de-DE")
+	if err != nil {
+		t.Fatalf("renderTemplate: %v", err)
+	}
+	if body != "1.234 ¥" {
+		t.Errorf("body = %q, want %q", body, "1.234 ¥")
+	}
+
+	if _, err := s.renderTemplate("{{money .Amount .Currency}}", map[string]interface{}{"Amount": 10.0, "Currency": "XYZ1"}, "en-US"); err == nil {
+		t.Error("expected an error for an unknown currency")
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+
+	"fintech/notifications-service/internal/domain"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SendHook runs integrator logic, such as updating a CRM, after a send attempt settles a
+// notification's status. Hook errors are logged and never affect delivery.
+type SendHook interface {
+	// OnSent is called after a notification is delivered and saved as SENT
+	OnSent(ctx context.Context, notification *domain.Notification) error
+	// OnFailed is called after a notification is saved as FAILED with no retries left;
+	// attempts that will be retried don't call it
+	OnFailed(ctx context.Context, notification *domain.Notification, sendErr error) error
+}
+
+// RegisterHook adds a hook to run after sends. Register hooks at startup, before consumers start.
+func (s *NotificationService) RegisterHook(hook SendHook) {
+	s.hooks = append(s.hooks, hook)
+}
+
+// runHooks notifies the registered hooks of a send's outcome
+func (s *NotificationService) runHooks(ctx context.Context, notification *domain.Notification, sendErr error) {
+	for _, hook := range s.hooks {
+		var err error
+		switch {
+		case notification.Status == domain.SentStatus:
+			err = hook.OnSent(ctx, notification)
+		case notification.Status == domain.FailedStatus:
+			err = hook.OnFailed(ctx, notification, sendErr)
+		default:
+			return
+		}
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"notification_id": notification.ID,
+				"status":          notification.Status,
+			}).Error("Send hook failed")
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"fintech/notifications-service/internal/domain"
+)
+
+// recordingHook records the notifications it is called with, failing with err
+type recordingHook struct {
+	sent    []string
+	failed  []string
+	sendErr []error
+	err     error
+}
+
+func (h *recordingHook) OnSent(ctx context.Context, notification *domain.Notification) error {
+	h.sent = append(h.sent, notification.ID)
+	return h.err
+}
+
+func (h *recordingHook) OnFailed(ctx context.Context, notification *domain.Notification, sendErr error) error {
+	h.failed = append(h.failed, notification.ID)
+	h.sendErr = append(h.sendErr, sendErr)
+	return h.err
+}
+
+func TestRunHooks(t *testing.T) {
+	sendErr := errors.New("endpoint disabled")
+	tests := []struct {
+		status     domain.NotificationStatus
+		wantSent   int
+		wantFailed int
+	}{
+		{domain.SentStatus, 1, 0},
+		{domain.FailedStatus, 0, 1},
+		{domain.PendingStatus, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			// A failing hook doesn't keep the next one from running
+			failing, hook := &recordingHook{err: errors.New("CRM unavailable")}, &recordingHook{}
+			s := &NotificationService{}
+			s.RegisterHook(failing)
+			s.RegisterHook(hook)
+
+			notification := &domain.Notification{ID: "notification-1", Status: tt.status}
+			s.runHooks(context.Background(), notification, sendErr)
+
+			for _, h := range []*recordingHook{failing, hook} {
+				if len(h.sent) != tt.wantSent || len(h.failed) != tt.wantFailed {
+					t.Errorf("hook calls: sent %v, failed %v, want %d sent and %d failed", h.sent, h.failed, tt.wantSent, tt.wantFailed)
+				}
+				for _, id := range append(h.sent, h.failed...) {
+					if id != "notification-1" {
+						t.Errorf("hook called with %s, want notification-1", id)
+					}
+				}
+				for _, err := range h.sendErr {
+					if err != sendErr {
+						t.Errorf("OnFailed error = %v, want the send error", err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/otel"
+	"fintech/notifications-service/pkg/respond"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MuteRequest mutes the listed accounts, or every account when Global is set, until ExpiresAt
+type MuteRequest struct {
+	AccountIDs []string  `json:"accountIds,omitempty"`
+	Global     bool      `json:"global,omitempty"`
+	Reason     string    `json:"reason"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	Operator   string    `json:"operator"`
+}
+
+// MuteNotifications handles POST /notifications/mute
+func (s *NotificationService) MuteNotifications(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "MuteNotifications")
+	defer span.End()
+
+	var req MuteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logrus.WithError(err).Error("Failed to decode mute request")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("global", req.Global),
+		otel.Attribute("accounts", len(req.AccountIDs)),
+		otel.Attribute("operator", req.Operator),
+	)
+
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	accountIDs := req.AccountIDs
+	if req.Global {
+		accountIDs = []string{""}
+	}
+
+	mutes := make([]*domain.Mute, 0, len(accountIDs))
+	for _, accountID := range accountIDs {
+		mute, err := domain.NewMute(accountID, req.Reason, req.Operator, req.ExpiresAt)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mutes = append(mutes, mute)
+	}
+
+	for _, mute := range mutes {
+		if err := s.mutes.Save(ctx, mute); err != nil {
+			logrus.WithError(err).Error("Failed to save mute")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"global":     req.Global,
+		"accounts":   req.AccountIDs,
+		"reason":     req.Reason,
+		"expires_at": req.ExpiresAt,
+		"operator":   req.Operator,
+	}).Warn("Notifications muted")
+
+	if err := respond.JSON(ctx, w, http.StatusCreated, mutes); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,75 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// isQueued reports whether a notification is waiting for the send workers
+func isQueued(s *NotificationService, id string) bool {
+	s.queuedMu.Lock()
+	defer s.queuedMu.Unlock()
+	_, ok := s.queued[id]
+	return ok
+}
+
+func muteAccount(t *testing.T, s *NotificationService, accountID string) {
+	t.Helper()
+
+	body := `{"accountIds": ["` + accountID + `"], "reason": "card processor outage", "operator": "ops-1", "expiresAt": "` +
+		time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `"}`
+	rec := httptest.NewRecorder()
+	s.MuteNotifications(rec, httptest.NewRequest(http.MethodPost, "/notifications/mute", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("mute status = %d, want 201: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestHandlePaymentEventForMutedAccount(t *testing.T) {
+	s, db := newTestService(t, nil)
+	event := paymentEvent("PaymentFailed")
+	muteAccount(t, s, event.FromAccountID)
+
+	if err := s.HandlePaymentEvent(context.Background(), event); err != nil {
+		t.Fatalf("HandlePaymentEvent: %v", err)
+	}
+
+	notifications := notificationsForEvent(t, db, event.PaymentID)
+	if len(notifications) == 0 {
+		t.Fatal("no notifications recorded for the muted account")
+	}
+	for channel, n := range notifications {
+		if n.Status != "MUTED" {
+			t.Errorf("%s notification status = %s, want MUTED", channel, n.Status)
+		}
+		if isQueued(s, n.ID) {
+			t.Errorf("%s notification of a muted account was queued for sending", channel)
+		}
+	}
+}
+
+func TestHandlePaymentEventForUnmutedAccount(t *testing.T) {
+	s, db := newTestService(t, nil)
+	muteAccount(t, s, newID("acc"))
+	event := paymentEvent("PaymentFailed")
+
+	if err := s.HandlePaymentEvent(context.Background(), event); err != nil {
+		t.Fatalf("HandlePaymentEvent: %v", err)
+	}
+
+	notifications := notificationsForEvent(t, db, event.PaymentID)
+	if len(notifications) == 0 {
+		t.Fatal("no notifications created")
+	}
+	for channel, n := range notifications {
+		if n.Status != "PENDING" || !isQueued(s, n.ID) {
+			t.Errorf("%s notification status = %s queued=%v, want PENDING and queued", channel, n.Status, isQueued(s, n.ID))
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMuteNotificationsRejectsInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"neither accounts nor global", `{"reason": "outage", "operator": "ops-1", "expiresAt": "2999-01-01T00:00:00Z"}`},
+		{"both accounts and global", `{"accountIds": ["acc-1"], "global": true, "reason": "outage", "operator": "ops-1", "expiresAt": "2999-01-01T00:00:00Z"}`},
+		{"missing reason", `{"accountIds": ["acc-1"], "operator": "ops-1", "expiresAt": "2999-01-01T00:00:00Z"}`},
+		{"missing operator", `{"accountIds": ["acc-1"], "reason": "outage", "expiresAt": "2999-01-01T00:00:00Z"}`},
+		{"expired", `{"accountIds": ["acc-1"], "reason": "outage", "operator": "ops-1", "expiresAt": "2000-01-01T00:00:00Z"}`},
+	}
+
+	// No repository: the request must be rejected before a mute is saved
+	s := &NotificationService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.MuteNotifications(rec, httptest.NewRequest(http.MethodPost, "/notifications/mute", strings.NewReader(tt.body)))
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+			}
+		})
+	}
+}
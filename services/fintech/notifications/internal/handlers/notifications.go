@@ -2,10 +2,13 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	htmltemplate "html/template"
+	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -14,33 +17,83 @@ import (
 	"fintech/notifications-service/internal/infrastructure"
 	"fintech/notifications-service/pkg/aws"
 	"fintech/notifications-service/pkg/database"
+	"fintech/notifications-service/pkg/flags"
 	"fintech/notifications-service/pkg/kafka"
+	"fintech/notifications-service/pkg/metrics"
 	"fintech/notifications-service/pkg/otel"
+	"fintech/notifications-service/pkg/profile"
+	"fintech/notifications-service/pkg/redact"
+	"fintech/notifications-service/pkg/respond"
 
 	"github.com/sirupsen/logrus"
 )
 
+// TemplateMissingError reports that no template is configured for an event type and channel.
+// It signals a configuration gap rather than a transient failure, so it is not worth retrying.
+type TemplateMissingError struct {
+	EventType        string
+	NotificationType domain.NotificationType
+}
+
+func (e *TemplateMissingError) Error() string {
+	return fmt.Sprintf("no template found for event type %s and notification type %s", e.EventType, e.NotificationType)
+}
+
 // NotificationService handles notification business logic
 type NotificationService struct {
 	repo      *infrastructure.NotificationRepository
+	mutes     *infrastructure.MuteRepository
+	templates *infrastructure.TemplateRepository
+	prefs     *infrastructure.PreferenceRepository
 	snsClient *aws.SNSClient
 	sqsClient *aws.SQSClient
+	sesClient *aws.SESClient // Optional; emails go through SNS when nil
 	config    *config.Config
+	inFlight  sync.WaitGroup
+	queue     *sendQueue
+	queuedMu  sync.Mutex
+	queued    map[string]struct{} // IDs queued or being sent, so the retry worker can't double-send
+	ramp      *rampLimiter
+	throttle  *recipientThrottle
+	dashboard *dashboardCache
+	hooks     []SendHook
+	profiles  *profile.Client // Optional; re-resolves recipients of retried sends when set
+	lookupMX  func(ctx context.Context, name string) ([]*net.MX, error)
 }
 
 // NewNotificationService creates a new notification service
-func NewNotificationService(db *database.DB, snsClient *aws.SNSClient, sqsClient *aws.SQSClient, config *config.Config) *NotificationService {
-	return &NotificationService{
+func NewNotificationService(db *database.DB, snsClient *aws.SNSClient, sqsClient *aws.SQSClient, sesClient *aws.SESClient, config *config.Config) *NotificationService {
+	s := &NotificationService{
 		repo:      infrastructure.NewNotificationRepository(db),
+		mutes:     infrastructure.NewMuteRepository(db),
+		templates: infrastructure.NewTemplateRepository(db),
+		prefs:     infrastructure.NewPreferenceRepository(db),
 		snsClient: snsClient,
 		sqsClient: sqsClient,
+		sesClient: sesClient,
 		config:    config,
+		queue:     newSendQueue(),
+		queued:    make(map[string]struct{}),
+		ramp:      newRampLimiter(config.SendRampStartRate, config.SendRateLimit, config.SendRampWindow),
+		throttle:  newRecipientThrottle(config.RecipientThrottleLimit, config.RecipientThrottleWindow),
+		dashboard: &dashboardCache{ttl: config.DashboardCacheTTL},
+		lookupMX:  net.DefaultResolver.LookupMX,
+	}
+	if config.ResolveRecipientAtSend && config.ProfileServiceURL != "" {
+		s.profiles = profile.NewClient(config.ProfileServiceURL, config.ProfileServiceTimeout)
 	}
+
+	for i := 0; i < config.SendWorkers; i++ {
+		go s.sendWorker()
+	}
+
+	return s
 }
 
-// HandlePaymentEvent handles payment events from Kafka
-func (s *NotificationService) HandlePaymentEvent(event *kafka.PaymentInitiatedEvent) error {
-	ctx, span := otel.StartSpan(context.Background(), "HandlePaymentEvent")
+// HandlePaymentEvent handles payment events from Kafka. ctx carries the producer's trace context,
+// so the span continues the upstream payment trace.
+func (s *NotificationService) HandlePaymentEvent(ctx context.Context, event *kafka.PaymentInitiatedEvent) error {
+	ctx, span := otel.StartSpan(ctx, "HandlePaymentEvent")
 	defer span.End()
 
 	otel.AddSpanAttributes(span,
@@ -55,15 +108,48 @@ func (s *NotificationService) HandlePaymentEvent(event *kafka.PaymentInitiatedEv
 		"account_id": event.FromAccountID,
 	}).Info("Processing payment event for notifications")
 
-	// Create notifications for all supported types
-	notificationTypes := []domain.NotificationType{
+	// Muted accounts get their notifications recorded but not sent. A failed lookup fails open
+	// unless the mute_fail_open flag is off, in which case the event fails and can be redelivered.
+	mute, err := s.mutes.FindActive(ctx, event.FromAccountID)
+	if err != nil {
+		logrus.WithError(err).WithField("account_id", event.FromAccountID).Error("Failed to check notification mutes")
+		if !s.config.Flags.Enabled(flags.MuteFailOpen) {
+			return fmt.Errorf("failed to check notification mutes: %w", err)
+		}
+	}
+
+	// Skip channels the account has disabled. A failed lookup keeps every channel, so a
+	// preferences outage never silences payment notifications.
+	prefs, err := s.prefs.Get(ctx, event.FromAccountID)
+	if err != nil {
+		logrus.WithError(err).WithField("account_id", event.FromAccountID).Error("Failed to get notification preferences")
+	}
+
+	// Create notifications for all supported types the account accepts
+	notificationTypes := allowedNotificationTypes([]domain.NotificationType{
 		domain.EmailNotification,
 		domain.SMSNotification,
 		domain.PushNotification,
+	}, prefs)
+	if len(notificationTypes) < 3 {
+		logrus.WithFields(logrus.Fields{
+			"payment_id": event.PaymentID,
+			"account_id": event.FromAccountID,
+			"channels":   notificationTypes,
+		}).Debug("Skipping notification channels disabled by account preferences")
 	}
 
 	for _, notificationType := range notificationTypes {
-		if err := s.createAndSendNotification(ctx, event, notificationType); err != nil {
+		if err := s.createAndSendNotification(ctx, event, notificationType, mute); err != nil {
+			var missing *TemplateMissingError
+			if errors.As(err, &missing) {
+				metrics.TemplateMissing.WithLabelValues(missing.EventType, string(missing.NotificationType)).Inc()
+				logrus.WithError(err).WithFields(logrus.Fields{
+					"payment_id":        event.PaymentID,
+					"notification_type": notificationType,
+				}).Warn("Skipping notification without a template")
+				continue
+			}
 			logrus.WithError(err).WithFields(logrus.Fields{
 				"payment_id":        event.PaymentID,
 				"notification_type": notificationType,
@@ -75,98 +161,224 @@ func (s *NotificationService) HandlePaymentEvent(event *kafka.PaymentInitiatedEv
 	return nil
 }
 
-// createAndSendNotification creates and sends a notification for a specific type
-func (s *NotificationService) createAndSendNotification(ctx context.Context, event *kafka.PaymentInitiatedEvent, notificationType domain.NotificationType) error {
+// createAndSendNotification creates and sends a notification for a specific type. When mute
+// is non-nil the notification is recorded as muted instead of being sent.
+func (s *NotificationService) createAndSendNotification(ctx context.Context, event *kafka.PaymentInitiatedEvent, notificationType domain.NotificationType, mute *domain.Mute) error {
 	// Get template for this event type and notification type
-	template := domain.GetTemplate("PaymentInitiated", notificationType)
+	eventType := event.Type()
+	templateEventType := s.templateEventType(eventType)
+	recipientAttrs := s.getRecipientAttributes(event)
+	template := s.templates.Get(templateEventType, notificationType, recipientAttrs)
 	if template == nil {
-		return fmt.Errorf("no template found for event type PaymentInitiated and notification type %s", notificationType)
+		return &TemplateMissingError{EventType: templateEventType, NotificationType: notificationType}
 	}
 
 	// Prepare template data
-	templateData := struct {
-		PaymentID string
-		Amount    float64
-		Currency  string
-		AccountID string
-	}{
-		PaymentID: event.PaymentID,
-		Amount:    event.Amount,
-		Currency:  event.Currency,
-		AccountID: event.FromAccountID,
+	templateData := map[string]interface{}{
+		"PaymentID": event.PaymentID,
+		"Amount":    event.Amount,
+		"Currency":  event.Currency,
+		"AccountID": event.FromAccountID,
+	}
+	if event.Reason != "" {
+		templateData["Reason"] = event.Reason
 	}
+	template.ApplyDefaults(templateData)
 
 	// Render subject and body using templates
-	subject, err := s.renderTemplate(template.SubjectTemplate, templateData)
+	subject, err := s.renderTemplate(template.SubjectTemplate, templateData, recipientAttrs.Locale)
 	if err != nil {
 		return fmt.Errorf("failed to render subject template: %w", err)
 	}
 
-	body, err := s.renderTemplate(template.BodyTemplate, templateData)
+	body, err := s.renderTemplate(template.BodyTemplate, templateData, recipientAttrs.Locale)
 	if err != nil {
 		return fmt.Errorf("failed to render body template: %w", err)
 	}
 
+	// HTML bodies and attachments only apply to email
+	var htmlBody string
+	var attachments []domain.AttachmentRef
+	if notificationType == domain.EmailNotification {
+		if htmlBody, err = s.renderHTMLTemplate(template.HTMLBodyTemplate, templateData, recipientAttrs.Locale); err != nil {
+			return fmt.Errorf("failed to render HTML body template: %w", err)
+		}
+		if attachments, err = s.renderAttachments(template.Attachments, templateData, recipientAttrs.Locale); err != nil {
+			return fmt.Errorf("failed to render attachments: %w", err)
+		}
+	}
+
 	// Get recipient based on notification type
 	recipient, err := s.getRecipient(event, notificationType)
 	if err != nil {
 		return fmt.Errorf("failed to get recipient: %w", err)
 	}
 
+	// Validate and normalize the recipient; undeliverable ones fail without retrying
+	normalized, invalidErr := s.validateRecipient(ctx, notificationType, recipient)
+	if invalidErr == nil {
+		recipient = normalized
+	}
+
 	// Create notification
 	notification, err := domain.NewNotification(
 		event.PaymentID,
-		"PaymentInitiated",
+		eventType,
 		notificationType,
 		recipient,
 		subject,
 		body,
 		template.Priority,
-		template.MaxRetries,
+		s.effectiveMaxRetries(template.MaxRetries),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create notification: %w", err)
 	}
+	notification.AccountID = event.FromAccountID
+	notification.HTMLBody = htmlBody
+	notification.Attachments = attachments
+	if sla, ok := s.config.DeliverySLAs[notification.Priority]; ok {
+		notification.SetDeliverySLA(sla)
+	}
 
-	// Save notification to database
-	if err := s.repo.Save(ctx, notification); err != nil {
-		return fmt.Errorf("failed to save notification: %w", err)
+	if mute != nil {
+		notification.MarkAsMuted(mute.Reason)
+		if created, err := s.createNotification(ctx, notification); err != nil || !created {
+			return err
+		}
+		logrus.WithFields(logrus.Fields{
+			"notification_id": notification.ID,
+			"mute_id":         mute.ID,
+		}).Info("Notification muted")
+		return nil
 	}
 
-	// Send notification asynchronously
-	go s.sendNotification(notification)
+	if invalidErr != nil {
+		notification.MarkAsFailed(invalidErr.Error())
+		if created, err := s.createNotification(ctx, notification); err != nil || !created {
+			return err
+		}
+		logrus.WithError(redact.Error(invalidErr, recipient)).WithFields(logrus.Fields{
+			"notification_id": notification.ID,
+			"recipient":       redact.Recipient(recipient),
+		}).Warn("Notification failed recipient validation")
+		return nil
+	}
+
+	// Throttle per recipient address across channels. A redelivered event finds its notification
+	// already saved, so the slot it took is given back rather than counted twice.
+	if !s.throttle.Allow(recipient) {
+		notification.MarkAsThrottled(fmt.Sprintf("more than %d notifications in %s", s.config.RecipientThrottleLimit, s.config.RecipientThrottleWindow))
+		if created, err := s.createNotification(ctx, notification); err != nil || !created {
+			return err
+		}
+		metrics.NotificationsThrottled.WithLabelValues(string(notificationType)).Inc()
+		logrus.WithFields(logrus.Fields{
+			"notification_id": notification.ID,
+			"recipient":       redact.Recipient(recipient),
+		}).Warn("Notification throttled")
+		return nil
+	}
+
+	// Save notification to database; a redelivered event finds its notification already saved
+	if created, err := s.createNotification(ctx, notification); err != nil || !created {
+		s.throttle.Release(recipient)
+		return err
+	}
+
+	// Queue notification for asynchronous sending
+	s.enqueue(notification)
 
 	return nil
 }
 
+// createNotification saves a new notification, reporting false without error when the event was
+// already handled for this channel so the duplicate is neither saved nor sent
+func (s *NotificationService) createNotification(ctx context.Context, notification *domain.Notification) (bool, error) {
+	created, err := s.repo.Create(ctx, notification)
+	if err != nil {
+		return false, fmt.Errorf("failed to save notification: %w", err)
+	}
+	if !created {
+		metrics.NotificationsDeduplicated.WithLabelValues(string(notification.Type)).Inc()
+		logrus.WithFields(logrus.Fields{
+			"event_id":          notification.EventID,
+			"event_type":        notification.EventType,
+			"notification_type": notification.Type,
+		}).Info("Skipping duplicate notification for a redelivered event")
+	}
+	return created, nil
+}
+
 // sendNotification sends a notification via SNS/SQS
 func (s *NotificationService) sendNotification(notification *domain.Notification) {
 	ctx, cancel := context.WithTimeout(context.Background(), s.config.NotificationTimeout)
 	defer cancel()
 
-	defer func() {
+	// Hold non-urgent SMS and push during the recipient's quiet hours; this isn't an attempt
+	if until, quiet := s.quietHoursEnd(ctx, notification); quiet {
+		notification.DeferUntil(until)
 		if err := s.repo.Save(ctx, notification); err != nil {
-			logrus.WithError(err).WithField("notification_id", notification.ID).Error("Failed to save notification after send")
+			logrus.WithError(err).WithField("notification_id", notification.ID).Error("Failed to save deferred notification")
+			return
+		}
+		logrus.WithFields(logrus.Fields{
+			"notification_id": notification.ID,
+			"deferred_until":  until,
+		}).Info("Notification deferred until the end of quiet hours")
+		return
+	}
+
+	attempt := &domain.NotificationAttempt{
+		NotificationID: notification.ID,
+		AttemptNumber:  notification.RetryCount + 1,
+		AttemptedAt:    time.Now().UTC(),
+	}
+
+	var sendErr error
+	defer func() {
+		metrics.NotificationsSent.WithLabelValues(string(notification.Type), string(notification.Status)).Inc()
+		saveErr := s.repo.Save(ctx, notification)
+		if saveErr != nil {
+			logrus.WithError(saveErr).WithField("notification_id", notification.ID).Error("Failed to save notification after send")
+		}
+		s.recordAttempt(ctx, attempt)
+		if saveErr == nil {
+			s.runHooks(ctx, notification, sendErr)
 		}
 	}()
 
-	// Publish to SNS topic
-	if err := s.snsClient.PublishNotification(notification); err != nil {
-		logrus.WithError(err).WithField("notification_id", notification.ID).Error("Failed to publish to SNS")
+	// Deliver via SES (email) or the SNS topic
+	start := time.Now()
+	err := s.deliver(ctx, notification)
+	metrics.NotificationSendDuration.WithLabelValues(string(notification.Type)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		sendErr = err
+		logrus.WithError(redact.Error(err, notification.Recipient)).WithFields(logrus.Fields{
+			"notification_id": notification.ID,
+			"recipient":       redact.Recipient(notification.Recipient),
+		}).Error("Failed to deliver notification")
 
 		// Mark for retry if possible
+		attempt.Error = err.Error()
+		attempt.Outcome = domain.AttemptRetrying
 		if notification.CanRetry() {
 			if err := notification.MarkForRetry(s.config.RetryDelay, err.Error()); err != nil {
 				notification.MarkAsFailed("Max retries exceeded: " + err.Error())
+				attempt.Outcome = domain.AttemptFailed
+			} else {
+				metrics.NotificationRetries.WithLabelValues(string(notification.Type)).Inc()
 			}
 		} else {
-			notification.MarkAsFailed("Failed to publish to SNS: " + err.Error())
+			notification.MarkAsFailed("Failed to deliver notification: " + err.Error())
+			attempt.Outcome = domain.AttemptFailed
 		}
 		return
 	}
 
 	// Mark as sent
 	notification.MarkAsSent()
+	attempt.Outcome = domain.AttemptSucceeded
 
 	// Send to appropriate SQS queue for processing
 	queueURL := s.getQueueURL(notification.Type)
@@ -179,13 +391,104 @@ func (s *NotificationService) sendNotification(notification *domain.Notification
 	logrus.WithField("notification_id", notification.ID).Info("Notification sent successfully")
 }
 
-// renderTemplate renders a template with the given data
-func (s *NotificationService) renderTemplate(templateStr string, data interface{}) (string, error) {
+// templateFuncs are the helper functions available to notification templates
+var templateFuncs = template.FuncMap{
+	"default": defaultValue,
+}
+
+// defaultValue returns fallback when value is nil or empty. Combine it with
+// index to tolerate absent keys, e.g. {{default "n/a" (index . "Reason")}}.
+func defaultValue(fallback, value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return fallback
+	case string:
+		if v == "" {
+			return fallback
+		}
+	}
+	return value
+}
+
+// deliver hands a notification to its transport: SES for email when configured, SNS otherwise
+func (s *NotificationService) deliver(ctx context.Context, notification *domain.Notification) error {
+	if notification.Type == domain.EmailNotification && s.sesClient != nil {
+		return s.sesClient.SendEmail(ctx, notification)
+	}
+	return s.snsClient.PublishNotification(notification)
+}
+
+// recordAttempt stores a send attempt when attempt history is enabled
+func (s *NotificationService) recordAttempt(ctx context.Context, attempt *domain.NotificationAttempt) {
+	if !s.config.RecordAttempts {
+		return
+	}
+	if err := s.repo.SaveAttempt(ctx, attempt); err != nil {
+		logrus.WithError(err).WithField("notification_id", attempt.NotificationID).Error("Failed to record notification attempt")
+	}
+}
+
+// enqueue hands a notification to the send workers, highest priority first. A notification
+// already queued or being sent is skipped.
+func (s *NotificationService) enqueue(notification *domain.Notification) {
+	s.queuedMu.Lock()
+	if _, ok := s.queued[notification.ID]; ok {
+		s.queuedMu.Unlock()
+		return
+	}
+	s.queued[notification.ID] = struct{}{}
+	s.queuedMu.Unlock()
+
+	s.inFlight.Add(1)
+	s.queue.Push(notification)
+}
+
+// sendWorker sends queued notifications until the queue is closed
+func (s *NotificationService) sendWorker() {
+	for {
+		notification, ok := s.queue.Pop()
+		if !ok {
+			return
+		}
+		s.sendNotification(notification)
+
+		s.queuedMu.Lock()
+		delete(s.queued, notification.ID)
+		s.queuedMu.Unlock()
+		s.inFlight.Done()
+	}
+}
+
+// Drain waits for queued and in-flight sends to finish or for ctx to expire, then stops the workers
+func (s *NotificationService) Drain(ctx context.Context) error {
+	defer s.queue.Close()
+
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// renderTemplate renders a template with the given data, formatting money and dates for locale.
+// Missing keys are an error so a typo in a template fails loudly rather than rendering "<no value>".
+func (s *NotificationService) renderTemplate(templateStr string, data map[string]interface{}, locale string) (string, error) {
 	if templateStr == "" {
 		return "", nil
 	}
 
-	tmpl, err := template.New("notification").Parse(templateStr)
+	tmpl, err := template.New("notification").
+		Option("missingkey=error").
+		Funcs(templateFuncs).
+		Funcs(localeFuncs(locale)).
+		Parse(templateStr)
 	if err != nil {
 		return "", err
 	}
@@ -198,6 +501,68 @@ func (s *NotificationService) renderTemplate(templateStr string, data interface{
 	return result.String(), nil
 }
 
+// renderHTMLTemplate renders an HTML body with html/template, which escapes event data by context
+// so a value such as a failure reason cannot inject markup. Options and funcs match renderTemplate.
+func (s *NotificationService) renderHTMLTemplate(templateStr string, data map[string]interface{}, locale string) (string, error) {
+	if templateStr == "" {
+		return "", nil
+	}
+
+	tmpl, err := htmltemplate.New("notification").
+		Option("missingkey=error").
+		Funcs(htmltemplate.FuncMap(templateFuncs)).
+		Funcs(htmltemplate.FuncMap(localeFuncs(locale))).
+		Parse(templateStr)
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	if err := tmpl.Execute(&result, data); err != nil {
+		return "", err
+	}
+
+	return result.String(), nil
+}
+
+// renderAttachments renders the filename, URL and S3 key of each template attachment
+func (s *NotificationService) renderAttachments(refs []domain.AttachmentRef, data map[string]interface{}, locale string) ([]domain.AttachmentRef, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	rendered := make([]domain.AttachmentRef, 0, len(refs))
+	for _, ref := range refs {
+		var err error
+		out := ref
+		if out.Filename, err = s.renderTemplate(ref.Filename, data, locale); err != nil {
+			return nil, err
+		}
+		if out.URL, err = s.renderTemplate(ref.URL, data, locale); err != nil {
+			return nil, err
+		}
+		if out.S3Key, err = s.renderTemplate(ref.S3Key, data, locale); err != nil {
+			return nil, err
+		}
+		if err := out.Validate(); err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, out)
+	}
+
+	return rendered, nil
+}
+
+// getRecipientAttributes returns the recipient's tier and locale for template selection
+func (s *NotificationService) getRecipientAttributes(event *kafka.PaymentInitiatedEvent) domain.RecipientAttributes {
+	// In a real implementation these would come from the user profile; for now producers may
+	// pass them on the event
+	return domain.RecipientAttributes{
+		Tier:   strings.ToLower(strings.TrimSpace(event.RecipientTier)),
+		Locale: strings.TrimSpace(event.Locale),
+	}
+}
+
 // getRecipient gets the recipient for a notification type
 func (s *NotificationService) getRecipient(event *kafka.PaymentInitiatedEvent, notificationType domain.NotificationType) (string, error) {
 	// In a real implementation, you would look up user contact information
@@ -206,7 +571,7 @@ func (s *NotificationService) getRecipient(event *kafka.PaymentInitiatedEvent, n
 	case domain.EmailNotification:
 		return fmt.Sprintf("user+%s@fintech.com", event.FromAccountID), nil
 	case domain.SMSNotification:
-		return "+1234567890", nil // Placeholder phone number
+		return "+12025550123", nil // Placeholder phone number
 	case domain.PushNotification:
 		return event.FromAccountID, nil // Device token or user ID
 	default:
@@ -214,6 +579,102 @@ func (s *NotificationService) getRecipient(event *kafka.PaymentInitiatedEvent, n
 	}
 }
 
+// refreshRecipient re-resolves a delayed or retried notification's recipient from the account's
+// current profile, recording the recipient it was created with if the two differ. On lookup
+// failure, or if the profile has no valid contact for the channel, the current recipient is kept.
+func (s *NotificationService) refreshRecipient(ctx context.Context, notification *domain.Notification) {
+	if s.profiles == nil || notification.AccountID == "" {
+		return
+	}
+
+	contact, err := s.profiles.GetContact(ctx, notification.AccountID)
+	if err != nil {
+		logrus.WithError(err).WithField("notification_id", notification.ID).Warn("Failed to re-resolve recipient, keeping the original")
+		return
+	}
+
+	var resolved string
+	switch notification.Type {
+	case domain.EmailNotification:
+		resolved = contact.Email
+	case domain.SMSNotification:
+		resolved = contact.Phone
+	case domain.PushNotification:
+		resolved = contact.DeviceToken
+	}
+	if resolved == "" {
+		return
+	}
+
+	resolved, err = s.validateRecipient(ctx, notification.Type, resolved)
+	if err != nil || resolved == notification.Recipient {
+		return
+	}
+
+	if notification.OriginalRecipient == "" {
+		notification.OriginalRecipient = notification.Recipient
+	}
+	notification.Recipient = resolved
+
+	logrus.WithFields(logrus.Fields{
+		"notification_id": notification.ID,
+		"recipient":       redact.Recipient(resolved),
+	}).Info("Recipient re-resolved from profile")
+}
+
+// templateEventType resolves which event's templates to use, honouring configured overrides
+func (s *NotificationService) templateEventType(eventType string) string {
+	if override, ok := s.config.TemplateOverrides[eventType]; ok && override != "" {
+		return override
+	}
+	return eventType
+}
+
+// effectiveMaxRetries clamps a template's max retries to the global ceiling
+func (s *NotificationService) effectiveMaxRetries(templateMaxRetries int) int {
+	if s.config.GlobalMaxRetries > 0 && templateMaxRetries > s.config.GlobalMaxRetries {
+		return s.config.GlobalMaxRetries
+	}
+	return templateMaxRetries
+}
+
+// validateRecipient checks that a recipient is deliverable for the channel and returns it normalized
+func (s *NotificationService) validateRecipient(ctx context.Context, notificationType domain.NotificationType, recipient string) (string, error) {
+	switch notificationType {
+	case domain.SMSNotification:
+		return domain.NormalizePhoneNumber(recipient, s.config.DefaultPhoneRegion)
+	case domain.EmailNotification:
+		address, err := domain.NormalizeEmailAddress(recipient)
+		if err != nil || !s.config.ValidateEmailMX {
+			return address, err
+		}
+		return address, s.checkMX(ctx, address)
+	default:
+		return recipient, nil
+	}
+}
+
+// checkMX verifies the address's domain accepts mail. Only a definitive "not found"
+// answer invalidates the address; transient DNS errors are logged and tolerated.
+func (s *NotificationService) checkMX(ctx context.Context, address string) error {
+	_, emailDomain := domain.EmailDomain(address)
+
+	records, err := s.lookupMX(ctx, emailDomain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return fmt.Errorf("%w: email domain %q has no MX record", domain.ErrInvalidRecipient, emailDomain)
+		}
+		logrus.WithError(err).WithField("domain", emailDomain).Warn("MX lookup failed, accepting address")
+		return nil
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("%w: email domain %q has no MX record", domain.ErrInvalidRecipient, emailDomain)
+	}
+
+	return nil
+}
+
 // getQueueURL returns the SQS queue URL for a notification type
 func (s *NotificationService) getQueueURL(notificationType domain.NotificationType) string {
 	switch notificationType {
@@ -230,9 +691,7 @@ func (s *NotificationService) getQueueURL(notificationType domain.NotificationTy
 
 // HealthCheck handles GET /health
 func HealthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	respond.JSON(r.Context(), w, http.StatusOK, map[string]interface{}{
 		"status": "healthy",
 		"time":   time.Now().UTC().Format(time.RFC3339),
 	})
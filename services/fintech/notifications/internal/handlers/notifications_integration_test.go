@@ -0,0 +1,309 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"fintech/notifications-service/internal/config"
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/aws"
+	"fintech/notifications-service/pkg/kafka"
+	"fintech/notifications-service/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// paymentEvent returns a payment event of eventType for a fresh payment and account
+func paymentEvent(eventType string) *kafka.PaymentInitiatedEvent {
+	return &kafka.PaymentInitiatedEvent{
+		EventType:     eventType,
+		PaymentID:     newID("pay"),
+		FromAccountID: newID("acc"),
+		ToAccountID:   newID("acc"),
+		Amount:        42.5,
+		Currency:      "EUR",
+	}
+}
+
+func TestHandlePaymentEventUsesEventTemplates(t *testing.T) {
+	s, db := newTestService(t, nil)
+	event := paymentEvent("PaymentCompleted")
+
+	if err := s.HandlePaymentEvent(context.Background(), event); err != nil {
+		t.Fatalf("HandlePaymentEvent: %v", err)
+	}
+
+	email, ok := notificationsForEvent(t, db, event.PaymentID)["EMAIL"]
+	if !ok {
+		t.Fatal("no email notification created")
+	}
+	if email.EventType != "PaymentCompleted" || !strings.HasPrefix(email.Subject, "Payment Completed") {
+		t.Errorf("email = %q (%s), want the PaymentCompleted template", email.Subject, email.EventType)
+	}
+}
+
+func TestHandlePaymentEventTemplateOverride(t *testing.T) {
+	s, db := newTestService(t, map[string]string{"TEMPLATE_OVERRIDES": "RefundIssued:PaymentCompleted"})
+	event := paymentEvent("RefundIssued")
+
+	if err := s.HandlePaymentEvent(context.Background(), event); err != nil {
+		t.Fatalf("HandlePaymentEvent: %v", err)
+	}
+
+	notifications := notificationsForEvent(t, db, event.PaymentID)
+	if len(notifications) != 3 {
+		t.Fatalf("created %d notifications, want one per channel", len(notifications))
+	}
+	email := notifications["EMAIL"]
+	// The notification keeps its own event type; only the template is remapped
+	if email.EventType != "RefundIssued" || !strings.HasPrefix(email.Subject, "Payment Completed") {
+		t.Errorf("email = %q (%s), want the PaymentCompleted template for a RefundIssued event", email.Subject, email.EventType)
+	}
+}
+
+func TestHandlePaymentEventWithoutTemplate(t *testing.T) {
+	s, db := newTestService(t, nil)
+	event := paymentEvent(newID("Unmapped"))
+
+	before := make(map[string]float64)
+	for _, channel := range []string{"EMAIL", "SMS", "PUSH"} {
+		before[channel] = testutil.ToFloat64(metrics.TemplateMissing.WithLabelValues(event.EventType, channel))
+	}
+
+	// A missing template is a configuration gap, not a failure worth redelivering
+	if err := s.HandlePaymentEvent(context.Background(), event); err != nil {
+		t.Fatalf("HandlePaymentEvent: %v", err)
+	}
+
+	for _, channel := range []string{"EMAIL", "SMS", "PUSH"} {
+		got := testutil.ToFloat64(metrics.TemplateMissing.WithLabelValues(event.EventType, channel))
+		if got != before[channel]+1 {
+			t.Errorf("%s template missing counter = %v, want %v", channel, got, before[channel]+1)
+		}
+	}
+	if notifications := notificationsForEvent(t, db, event.PaymentID); len(notifications) != 0 {
+		t.Errorf("created %d notifications without a template, want 0", len(notifications))
+	}
+}
+
+// fakeSNS returns an SNS client whose first failures publishes are rejected and the rest accepted
+func fakeSNS(t *testing.T, failures int32) *aws.SNSClient {
+	t.Helper()
+
+	var publishes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		if atomic.AddInt32(&publishes, 1) <= failures {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>InvalidParameter</Code><Message>endpoint disabled</Message></Error><RequestId>req</RequestId></ErrorResponse>`))
+			return
+		}
+		w.Write([]byte(`<PublishResponse><PublishResult><MessageId>msg</MessageId></PublishResult><ResponseMetadata><RequestId>req</RequestId></ResponseMetadata></PublishResponse>`))
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	awsConfig := config.AWSConfig{Endpoint: server.URL, Region: "us-east-1"}
+	client, err := aws.NewSNSClient(awsConfig.ToAWSConfig(), "arn:aws:sns:us-east-1:000000000000:test")
+	if err != nil {
+		t.Fatalf("NewSNSClient: %v", err)
+	}
+	return client
+}
+
+// newSavedEmail saves a pending email notification allowing maxRetries retries
+func newSavedEmail(t *testing.T, s *NotificationService, maxRetries int) *domain.Notification {
+	t.Helper()
+
+	notification, err := domain.NewNotification(newID("pay"), "PaymentCompleted", domain.EmailNotification, "jane@example.com", "Payment Completed", "Your payment has completed.", 1, maxRetries)
+	if err != nil {
+		t.Fatalf("NewNotification: %v", err)
+	}
+	if _, err := s.repo.Create(context.Background(), notification); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return notification
+}
+
+func TestSendNotificationRecordsEachAttempt(t *testing.T) {
+	// No SQS client: a sent email isn't forwarded to a queue
+	s, _ := newTestService(t, map[string]string{"EMAIL_QUEUE_URL": ""})
+	s.snsClient = fakeSNS(t, 2)
+	notification := newSavedEmail(t, s, 3)
+
+	for i := 0; i < 3; i++ {
+		s.sendNotification(notification)
+	}
+	if notification.Status != domain.SentStatus {
+		t.Fatalf("status = %s, want SENT", notification.Status)
+	}
+
+	attempts, err := s.repo.FindAttempts(context.Background(), notification.ID)
+	if err != nil {
+		t.Fatalf("FindAttempts: %v", err)
+	}
+	want := []domain.AttemptOutcome{domain.AttemptRetrying, domain.AttemptRetrying, domain.AttemptSucceeded}
+	if len(attempts) != len(want) {
+		t.Fatalf("recorded %d attempts, want %d", len(attempts), len(want))
+	}
+	for i, attempt := range attempts {
+		if attempt.AttemptNumber != i+1 || attempt.Outcome != want[i] {
+			t.Errorf("attempt %d = #%d %s, want #%d %s", i, attempt.AttemptNumber, attempt.Outcome, i+1, want[i])
+		}
+		if failed := want[i] != domain.AttemptSucceeded; failed != strings.Contains(attempt.Error, "endpoint disabled") {
+			t.Errorf("attempt %d error = %q", i+1, attempt.Error)
+		}
+	}
+}
+
+func TestSendNotificationRecordsFinalFailure(t *testing.T) {
+	s, _ := newTestService(t, nil)
+	s.snsClient = fakeSNS(t, 2)
+	notification := newSavedEmail(t, s, 1)
+
+	for i := 0; i < 2; i++ {
+		s.sendNotification(notification)
+	}
+	if notification.Status != domain.FailedStatus {
+		t.Fatalf("status = %s, want FAILED", notification.Status)
+	}
+
+	attempts, err := s.repo.FindAttempts(context.Background(), notification.ID)
+	if err != nil {
+		t.Fatalf("FindAttempts: %v", err)
+	}
+	if len(attempts) != 2 || attempts[0].Outcome != domain.AttemptRetrying || attempts[1].Outcome != domain.AttemptFailed {
+		t.Errorf("attempts = %+v, want RETRYING then FAILED", attempts)
+	}
+}
+
+func TestSendNotificationWithoutAttemptHistory(t *testing.T) {
+	s, _ := newTestService(t, map[string]string{"RECORD_ATTEMPTS": "false"})
+	s.snsClient = fakeSNS(t, 1)
+	notification := newSavedEmail(t, s, 3)
+
+	s.sendNotification(notification)
+
+	attempts, err := s.repo.FindAttempts(context.Background(), notification.ID)
+	if err != nil {
+		t.Fatalf("FindAttempts: %v", err)
+	}
+	if len(attempts) != 0 {
+		t.Errorf("recorded %d attempts with history disabled, want 0", len(attempts))
+	}
+}
+
+func TestHandlePaymentEventAttachesToEmailOnly(t *testing.T) {
+	s, db := newTestService(t, nil)
+	event := paymentEvent("PaymentCompleted")
+
+	if err := s.HandlePaymentEvent(context.Background(), event); err != nil {
+		t.Fatalf("HandlePaymentEvent: %v", err)
+	}
+
+	for channel, stored := range notificationsForEvent(t, db, event.PaymentID) {
+		notification, err := s.repo.FindByID(context.Background(), stored.ID)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+
+		if channel != "EMAIL" {
+			if len(notification.Attachments) != 0 {
+				t.Errorf("%s notification has attachments %+v, want none", channel, notification.Attachments)
+			}
+			continue
+		}
+		want := domain.AttachmentRef{
+			Filename:    "receipt-" + event.PaymentID + ".pdf",
+			S3Key:       "receipts/" + event.PaymentID + ".pdf",
+			ContentType: "application/pdf",
+		}
+		if len(notification.Attachments) != 1 || notification.Attachments[0] != want {
+			t.Errorf("email attachments = %+v, want the rendered receipt %+v", notification.Attachments, want)
+		}
+	}
+}
+
+func TestSendNotificationRunsHookOnSent(t *testing.T) {
+	s, _ := newTestService(t, map[string]string{"EMAIL_QUEUE_URL": ""})
+	s.snsClient = fakeSNS(t, 1)
+	hook := &recordingHook{err: errors.New("CRM unavailable")}
+	s.RegisterHook(hook)
+	notification := newSavedEmail(t, s, 3)
+
+	// The retried attempt runs no hook; the hook's error doesn't affect delivery
+	s.sendNotification(notification)
+	if len(hook.sent) != 0 || len(hook.failed) != 0 {
+		t.Errorf("hooks ran for a retried attempt: sent %v, failed %v", hook.sent, hook.failed)
+	}
+	s.sendNotification(notification)
+
+	if notification.Status != domain.SentStatus {
+		t.Fatalf("status = %s, want SENT", notification.Status)
+	}
+	if len(hook.sent) != 1 || hook.sent[0] != notification.ID || len(hook.failed) != 0 {
+		t.Errorf("hook calls: sent %v, failed %v, want OnSent for %s", hook.sent, hook.failed, notification.ID)
+	}
+}
+
+func TestSendNotificationRunsHookOnFailed(t *testing.T) {
+	s, _ := newTestService(t, nil)
+	s.snsClient = fakeSNS(t, 1)
+	hook := &recordingHook{}
+	s.RegisterHook(hook)
+	notification := newSavedEmail(t, s, 0)
+
+	s.sendNotification(notification)
+
+	if notification.Status != domain.FailedStatus {
+		t.Fatalf("status = %s, want FAILED", notification.Status)
+	}
+	if len(hook.failed) != 1 || hook.failed[0] != notification.ID || len(hook.sent) != 0 {
+		t.Errorf("hook calls: sent %v, failed %v, want OnFailed for %s", hook.sent, hook.failed, notification.ID)
+	}
+	if len(hook.sendErr) != 1 || !strings.Contains(hook.sendErr[0].Error(), "endpoint disabled") {
+		t.Errorf("OnFailed error = %v, want the SNS error", hook.sendErr)
+	}
+}
+
+func TestHandlePaymentEventTwiceCreatesOneNotificationPerChannel(t *testing.T) {
+	s, db := newTestService(t, nil)
+	event := paymentEvent("PaymentInitiated")
+
+	// Kafka redelivers the event, e.g. after a rebalance before its offset was committed
+	for i := 0; i < 2; i++ {
+		if err := s.HandlePaymentEvent(context.Background(), event); err != nil {
+			t.Fatalf("HandlePaymentEvent (delivery %d): %v", i+1, err)
+		}
+	}
+
+	notifications := notificationsForEvent(t, db, event.PaymentID)
+	if len(notifications) != 3 {
+		t.Fatalf("got %d notifications, want one per channel: %+v", len(notifications), notifications)
+	}
+	for _, channel := range []string{"EMAIL", "SMS", "PUSH"} {
+		if _, ok := notifications[channel]; !ok {
+			t.Errorf("no %s notification", channel)
+		}
+	}
+
+	// Only the first delivery's notifications were queued for sending
+	s.queuedMu.Lock()
+	defer s.queuedMu.Unlock()
+	if len(s.queued) != len(notifications) {
+		t.Errorf("%d notifications queued, want the %d saved", len(s.queued), len(notifications))
+	}
+	for channel, notification := range notifications {
+		if _, ok := s.queued[notification.ID]; !ok {
+			t.Errorf("saved %s notification was not queued", channel)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fintech/notifications-service/internal/config"
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/internal/infrastructure"
+	"fintech/notifications-service/pkg/kafka"
+	"fintech/notifications-service/pkg/profile"
+)
+
+func paymentFailedData() map[string]interface{} {
+	return map[string]interface{}{
+		"PaymentID": "pay-1",
+		"Amount":    12.5,
+		"Currency":  "EUR",
+		"AccountID": "acc-1",
+	}
+}
+
+func TestRenderTemplateOptionalFieldPresent(t *testing.T) {
+	template := domain.GetTemplate("PaymentFailed", domain.EmailNotification, domain.RecipientAttributes{})
+	if template == nil {
+		t.Fatal("expected a PaymentFailed email template")
+	}
+
+	data := paymentFailedData()
+	data["Reason"] = "Insufficient funds"
+	template.ApplyDefaults(data)
+
+	s := &NotificationService{}
+	body, err := s.renderTemplate(template.BodyTemplate, data, "")
+	if err != nil {
+		t.Fatalf("renderTemplate: %v", err)
+	}
+	if !strings.Contains(body, "Reason: Insufficient funds") {
+		t.Errorf("body = %q, want the event's reason", body)
+	}
+}
+
+func TestRenderTemplateOptionalFieldAbsent(t *testing.T) {
+	template := domain.GetTemplate("PaymentFailed", domain.EmailNotification, domain.RecipientAttributes{})
+	if template == nil {
+		t.Fatal("expected a PaymentFailed email template")
+	}
+
+	data := paymentFailedData()
+	template.ApplyDefaults(data)
+
+	s := &NotificationService{}
+	body, err := s.renderTemplate(template.BodyTemplate, data, "")
+	if err != nil {
+		t.Fatalf("renderTemplate: %v", err)
+	}
+	if !strings.Contains(body, "Reason: Not specified") {
+		t.Errorf("body = %q, want the template default", body)
+	}
+	if strings.Contains(body, "<no value>") {
+		t.Errorf("body = %q, rendered a missing value", body)
+	}
+
+	html, err := s.renderHTMLTemplate(template.HTMLBodyTemplate, data, "")
+	if err != nil {
+		t.Fatalf("renderHTMLTemplate: %v", err)
+	}
+	if !strings.Contains(html, "Reason: Not specified") {
+		t.Errorf("html = %q, want the template default", html)
+	}
+}
+
+func TestRenderTemplateDefaultFunc(t *testing.T) {
+	s := &NotificationService{}
+	tmpl := `Reason: {{default "n/a" (index . "Reason")}}`
+
+	body, err := s.renderTemplate(tmpl, paymentFailedData(), "")
+	if err != nil {
+		t.Fatalf("renderTemplate: %v", err)
+	}
+	if body != "Reason: n/a" {
+		t.Errorf("body = %q, want %q", body, "Reason: n/a")
+	}
+
+	data := paymentFailedData()
+	data["Reason"] = "Card expired"
+	body, err = s.renderTemplate(tmpl, data, "")
+	if err != nil {
+		t.Fatalf("renderTemplate: %v", err)
+	}
+	if body != "Reason: Card expired" {
+		t.Errorf("body = %q, want %q", body, "Reason: Card expired")
+	}
+}
+
+func TestRenderTemplateMissingKeyFails(t *testing.T) {
+	s := &NotificationService{}
+	if _, err := s.renderTemplate("Reason: {{.Reason}}", paymentFailedData(), ""); err == nil {
+		t.Error("expected an error for a field without a default")
+	}
+}
+
+func TestDrainStopsAtShutdownTimeout(t *testing.T) {
+	s := &NotificationService{queue: newSendQueue()}
+	s.inFlight.Add(1)
+	defer s.inFlight.Done()
+
+	timeout := 50 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := s.Drain(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed < timeout {
+		t.Errorf("Drain returned after %v, before the %v timeout", elapsed, timeout)
+	}
+}
+
+func TestDrainWaitsForInFlightSends(t *testing.T) {
+	s := &NotificationService{queue: newSendQueue()}
+	s.inFlight.Add(1)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		s.inFlight.Done()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := s.Drain(ctx); err != nil {
+		t.Errorf("Drain = %v, want nil", err)
+	}
+}
+
+func TestValidateRecipientPhoneUsesDefaultRegion(t *testing.T) {
+	s := &NotificationService{config: &config.Config{DefaultPhoneRegion: "US"}}
+
+	got, err := s.validateRecipient(context.Background(), domain.SMSNotification, "(650) 253-0000")
+	if err != nil {
+		t.Fatalf("validateRecipient: %v", err)
+	}
+	if got != "+16502530000" {
+		t.Errorf("validateRecipient = %q, want %q", got, "+16502530000")
+	}
+
+	if _, err := s.validateRecipient(context.Background(), domain.SMSNotification, "12"); !errors.Is(err, domain.ErrInvalidRecipient) {
+		t.Errorf("validateRecipient(%q) error = %v, want ErrInvalidRecipient", "12", err)
+	}
+}
+
+func TestValidateRecipientEmailMX(t *testing.T) {
+	s := &NotificationService{
+		config: &config.Config{ValidateEmailMX: true},
+		lookupMX: func(ctx context.Context, name string) ([]*net.MX, error) {
+			switch name {
+			case "example.com":
+				return []*net.MX{{Host: "mx.example.com.", Pref: 10}}, nil
+			case "no-mx.example":
+				return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+			default:
+				return nil, &net.DNSError{Err: "i/o timeout", Name: name, IsTimeout: true}
+			}
+		},
+	}
+	ctx := context.Background()
+
+	if _, err := s.validateRecipient(ctx, domain.EmailNotification, "jane@example.com"); err != nil {
+		t.Errorf("domain with MX: %v", err)
+	}
+	if _, err := s.validateRecipient(ctx, domain.EmailNotification, "jane@no-mx.example"); !errors.Is(err, domain.ErrInvalidRecipient) {
+		t.Errorf("domain without MX: error = %v, want ErrInvalidRecipient", err)
+	}
+	// A lookup that fails without a definitive answer accepts the address
+	if _, err := s.validateRecipient(ctx, domain.EmailNotification, "jane@flaky.example"); err != nil {
+		t.Errorf("transient lookup failure: %v", err)
+	}
+	if _, err := s.validateRecipient(ctx, domain.EmailNotification, "jane@"); !errors.Is(err, domain.ErrInvalidRecipient) {
+		t.Errorf("invalid address: error = %v, want ErrInvalidRecipient", err)
+	}
+}
+
+func TestValidateRecipientEmailSkipsMXWhenDisabled(t *testing.T) {
+	s := &NotificationService{
+		config: &config.Config{},
+		lookupMX: func(ctx context.Context, name string) ([]*net.MX, error) {
+			t.Errorf("unexpected MX lookup for %s", name)
+			return nil, nil
+		},
+	}
+
+	if _, err := s.validateRecipient(context.Background(), domain.EmailNotification, "jane@no-mx.example"); err != nil {
+		t.Errorf("validateRecipient: %v", err)
+	}
+}
+
+func TestEffectiveMaxRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		ceiling  int
+		template int
+		want     int
+	}{
+		{"high template value is clamped", 5, 10, 5},
+		{"low template value is kept", 5, 2, 2},
+		{"value at the ceiling is kept", 5, 5, 5},
+		{"zero ceiling disables clamping", 0, 10, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &NotificationService{config: &config.Config{GlobalMaxRetries: tt.ceiling}}
+			if got := s.effectiveMaxRetries(tt.template); got != tt.want {
+				t.Errorf("effectiveMaxRetries(%d) with ceiling %d = %d, want %d", tt.template, tt.ceiling, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateEventTypeDefaultMapping(t *testing.T) {
+	s := &NotificationService{config: &config.Config{}}
+
+	for _, eventType := range []string{"PaymentInitiated", "PaymentCompleted", "PaymentFailed"} {
+		if got := s.templateEventType(eventType); got != eventType {
+			t.Errorf("templateEventType(%q) = %q, want the event's own type", eventType, got)
+		}
+	}
+}
+
+func TestTemplateEventTypeOverride(t *testing.T) {
+	s := &NotificationService{
+		config:    &config.Config{TemplateOverrides: map[string]string{"RefundIssued": "PaymentCompleted"}},
+		templates: infrastructure.NewTemplateRepository(nil),
+	}
+
+	if got := s.templateEventType("RefundIssued"); got != "PaymentCompleted" {
+		t.Errorf("templateEventType(RefundIssued) = %q, want PaymentCompleted", got)
+	}
+	if got := s.templateEventType("PaymentFailed"); got != "PaymentFailed" {
+		t.Errorf("templateEventType(PaymentFailed) = %q, want PaymentFailed", got)
+	}
+
+	template := s.templates.Get(s.templateEventType("RefundIssued"), domain.EmailNotification, domain.RecipientAttributes{})
+	if template == nil || template.EventType != "PaymentCompleted" {
+		t.Errorf("template for RefundIssued = %+v, want the PaymentCompleted email template", template)
+	}
+}
+
+func TestCreateAndSendNotificationWithoutTemplate(t *testing.T) {
+	// No repository: creating a notification would panic
+	s := &NotificationService{
+		config:    &config.Config{},
+		templates: infrastructure.NewTemplateRepository(nil),
+	}
+	event := &kafka.PaymentInitiatedEvent{EventType: "LoanDisbursed", PaymentID: "pay-1", FromAccountID: "acc-1", Amount: 10, Currency: "EUR"}
+
+	err := s.createAndSendNotification(context.Background(), event, domain.SMSNotification, nil)
+
+	var missing *TemplateMissingError
+	if !errors.As(err, &missing) {
+		t.Fatalf("createAndSendNotification error = %v, want TemplateMissingError", err)
+	}
+	if missing.EventType != "LoanDisbursed" || missing.NotificationType != domain.SMSNotification {
+		t.Errorf("missing template = %s/%s, want LoanDisbursed/SMS", missing.EventType, missing.NotificationType)
+	}
+}
+
+// profileService serves contact as the profile of every account, or status if it isn't 200
+func profileService(t *testing.T, status int, contact *profile.Contact) *profile.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		json.NewEncoder(w).Encode(contact)
+	}))
+	t.Cleanup(server.Close)
+	return profile.NewClient(server.URL, time.Second)
+}
+
+// retriedEmail returns an email notification for acc-1 that has already failed once
+func retriedEmail(t *testing.T, recipient string) *domain.Notification {
+	t.Helper()
+
+	notification, err := domain.NewNotification("pay-1", "PaymentCompleted", domain.EmailNotification, recipient, "Payment Completed", "Your payment has completed.", 1, 3)
+	if err != nil {
+		t.Fatalf("NewNotification: %v", err)
+	}
+	notification.AccountID = "acc-1"
+	notification.RetryCount = 1
+	return notification
+}
+
+func TestRefreshRecipientPicksUpUpdatedRecipient(t *testing.T) {
+	contact := &profile.Contact{Email: "jane.new@example.com"}
+	s := &NotificationService{
+		config:   &config.Config{},
+		profiles: profileService(t, http.StatusOK, contact),
+	}
+	notification := retriedEmail(t, "jane@example.com")
+
+	s.refreshRecipient(context.Background(), notification)
+	if notification.Recipient != "jane.new@example.com" || notification.OriginalRecipient != "jane@example.com" {
+		t.Fatalf("recipient = %q (original %q), want the updated address with the original recorded", notification.Recipient, notification.OriginalRecipient)
+	}
+
+	// Later updates keep the recipient the notification was created with
+	contact.Email = "jane.newer@example.com"
+	s.refreshRecipient(context.Background(), notification)
+	if notification.Recipient != "jane.newer@example.com" || notification.OriginalRecipient != "jane@example.com" {
+		t.Errorf("recipient = %q (original %q), want the newest address with the first recorded", notification.Recipient, notification.OriginalRecipient)
+	}
+}
+
+func TestRefreshRecipientKeepsRecipient(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		contact *profile.Contact
+	}{
+		{"unchanged profile", http.StatusOK, &profile.Contact{Email: "jane@example.com"}},
+		{"no contact for the channel", http.StatusOK, &profile.Contact{Phone: "+16502530000"}},
+		{"invalid profile contact", http.StatusOK, &profile.Contact{Email: "jane@"}},
+		{"profile lookup failure", http.StatusInternalServerError, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &NotificationService{
+				config:   &config.Config{},
+				profiles: profileService(t, tt.status, tt.contact),
+			}
+			notification := retriedEmail(t, "jane@example.com")
+
+			s.refreshRecipient(context.Background(), notification)
+			if notification.Recipient != "jane@example.com" || notification.OriginalRecipient != "" {
+				t.Errorf("recipient = %q (original %q), want jane@example.com unchanged", notification.Recipient, notification.OriginalRecipient)
+			}
+		})
+	}
+}
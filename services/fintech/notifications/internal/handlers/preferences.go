@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/otel"
+	"fintech/notifications-service/pkg/respond"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// PreferencesRequest updates an account's channel preferences; omitted channels keep their current setting
+type PreferencesRequest struct {
+	EmailEnabled *bool   `json:"emailEnabled,omitempty"`
+	SMSEnabled   *bool   `json:"smsEnabled,omitempty"`
+	PushEnabled  *bool   `json:"pushEnabled,omitempty"`
+	Timezone     *string `json:"timezone,omitempty"` // IANA name, e.g. "Europe/Berlin"; empty resets to the default
+}
+
+// UpdatePreferences handles PUT /preferences/{accountId}
+func (s *NotificationService) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "UpdatePreferences")
+	defer span.End()
+
+	accountID := mux.Vars(r)["accountId"]
+	otel.AddSpanAttributes(span, otel.Attribute("account_id", accountID))
+
+	var req PreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logrus.WithError(err).Error("Failed to decode preferences request")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	prefs, err := s.prefs.Get(ctx, accountID)
+	if err != nil {
+		logrus.WithError(err).WithField("account_id", accountID).Error("Failed to get notification preferences")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if req.EmailEnabled != nil {
+		prefs.EmailEnabled = *req.EmailEnabled
+	}
+	if req.SMSEnabled != nil {
+		prefs.SMSEnabled = *req.SMSEnabled
+	}
+	if req.PushEnabled != nil {
+		prefs.PushEnabled = *req.PushEnabled
+	}
+	if req.Timezone != nil {
+		prefs.Timezone = *req.Timezone
+	}
+	prefs.UpdatedAt = time.Now().UTC()
+
+	if err := s.prefs.Save(ctx, prefs); err != nil {
+		logrus.WithError(err).WithField("account_id", accountID).Error("Failed to save notification preferences")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"account_id":    accountID,
+		"email_enabled": prefs.EmailEnabled,
+		"sms_enabled":   prefs.SMSEnabled,
+		"push_enabled":  prefs.PushEnabled,
+		"timezone":      prefs.Timezone,
+	}).Info("Notification preferences updated")
+
+	if err := respond.JSON(ctx, w, http.StatusOK, prefs); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// allowedNotificationTypes filters types down to the channels prefs has not disabled; nil prefs allow every type
+func allowedNotificationTypes(types []domain.NotificationType, prefs *domain.NotificationPreferences) []domain.NotificationType {
+	if prefs == nil {
+		return types
+	}
+	allowed := make([]domain.NotificationType, 0, len(types))
+	for _, notificationType := range types {
+		if prefs.Allows(notificationType) {
+			allowed = append(allowed, notificationType)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/otel"
+	"fintech/notifications-service/pkg/respond"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// Page is the standard paginated response envelope
+type Page struct {
+	Items  interface{} `json:"items"`
+	Limit  int         `json:"limit"`
+	Offset int         `json:"offset"`
+	Count  int         `json:"count"`
+}
+
+// ListNotifications handles GET /notifications?status=FAILED&from=&to=&limit=&offset=
+func (s *NotificationService) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "ListNotifications")
+	defer span.End()
+
+	query := r.URL.Query()
+
+	status := domain.NotificationStatus(query.Get("status"))
+	switch status {
+	case domain.PendingStatus, domain.SentStatus, domain.FailedStatus, domain.DeliveredStatus, domain.MutedStatus, domain.ThrottledStatus:
+	default:
+		http.Error(w, "Invalid or missing status", http.StatusBadRequest)
+		return
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-24 * time.Hour)
+	var err error
+	if v := query.Get("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "Invalid from timestamp, expected RFC3339", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "Invalid to timestamp, expected RFC3339", http.StatusBadRequest)
+			return
+		}
+	}
+	if !from.Before(to) {
+		http.Error(w, "from must be before to", http.StatusBadRequest)
+		return
+	}
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("status", string(status)),
+		otel.Attribute("limit", limit),
+		otel.Attribute("offset", offset),
+	)
+
+	notifications, err := s.repo.FindByStatusAndRange(ctx, status, from, to, limit, offset)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to search notifications")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writePage(ctx, w, notifications, len(notifications), limit, offset)
+}
+
+// GetNotification handles GET /notifications/{id}
+func (s *NotificationService) GetNotification(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "GetNotification")
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+	otel.AddSpanAttributes(span, otel.Attribute("notification_id", id))
+
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+		return
+	}
+
+	notification, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotificationNotFound) {
+			http.Error(w, "Notification not found", http.StatusNotFound)
+			return
+		}
+		logrus.WithError(err).WithField("notification_id", id).Error("Failed to load notification")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := respond.JSON(ctx, w, http.StatusOK, notification); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// GetNotificationStats handles GET /notifications/stats, returning the number of notifications in each status
+func (s *NotificationService) GetNotificationStats(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "GetNotificationStats")
+	defer span.End()
+
+	stats, err := s.repo.GetNotificationStats(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get notification stats")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := respond.JSON(ctx, w, http.StatusOK, stats); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// ListAttempts handles GET /notifications/{id}/attempts
+func (s *NotificationService) ListAttempts(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "ListAttempts")
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+	otel.AddSpanAttributes(span, otel.Attribute("notification_id", id))
+
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrNotificationNotFound) {
+			http.Error(w, "Notification not found", http.StatusNotFound)
+			return
+		}
+		logrus.WithError(err).WithField("notification_id", id).Error("Failed to load notification")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	attempts, err := s.repo.FindAttempts(ctx, id)
+	if err != nil {
+		logrus.WithError(err).WithField("notification_id", id).Error("Failed to load notification attempts")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := respond.JSON(ctx, w, http.StatusOK, attempts); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// parsePagination reads limit and offset query parameters, applying defaults and bounds
+func parsePagination(r *http.Request) (int, int, error) {
+	limit, offset := defaultPageSize, 0
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return 0, 0, fmt.Errorf("limit must be a positive integer")
+		}
+		limit = n
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	if v := r.URL.Query().Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+		offset = n
+	}
+
+	return limit, offset, nil
+}
+
+// writePage writes items wrapped in the standard pagination envelope
+func writePage(ctx context.Context, w http.ResponseWriter, items interface{}, count, limit, offset int) {
+	if err := respond.JSON(ctx, w, http.StatusOK, Page{
+		Items:  items,
+		Limit:  limit,
+		Offset: offset,
+		Count:  count,
+	}); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
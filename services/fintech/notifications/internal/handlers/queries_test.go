@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListNotificationsRejectsInvalidFilters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"missing status", ""},
+		{"unknown status", "status=LOST"},
+		{"malformed from", "status=FAILED&from=yesterday"},
+		{"malformed to", "status=FAILED&to=2024-01-01"},
+		{"empty window", "status=FAILED&from=2024-01-02T00:00:00Z&to=2024-01-01T00:00:00Z"},
+		{"negative limit", "status=FAILED&limit=-1"},
+	}
+
+	s := &NotificationService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.ListNotifications(rec, httptest.NewRequest(http.MethodGet, "/notifications?"+tt.query, nil))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
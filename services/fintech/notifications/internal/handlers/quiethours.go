@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"fintech/notifications-service/internal/domain"
+
+	"github.com/sirupsen/logrus"
+)
+
+// quietHoursEnd reports whether notification falls in its account's quiet hours and, if so, when
+// they end. Only SMS and push below the priority threshold are held; email and urgent alerts always
+// go out. The account's timezone comes from its preferences, falling back to the configured default
+// when it has none or the lookup fails.
+func (s *NotificationService) quietHoursEnd(ctx context.Context, notification *domain.Notification) (time.Time, bool) {
+	if !s.config.QuietHoursEnabled ||
+		notification.Type == domain.EmailNotification ||
+		notification.Priority >= s.config.QuietHoursPriorityThreshold {
+		return time.Time{}, false
+	}
+
+	var timezone string
+	if notification.AccountID != "" {
+		prefs, err := s.prefs.Get(ctx, notification.AccountID)
+		if err != nil {
+			logrus.WithError(err).WithField("account_id", notification.AccountID).Warn("Failed to get timezone for quiet hours, using the default")
+		} else {
+			timezone = prefs.Timezone
+		}
+	}
+
+	return s.quietHoursUntil(time.Now(), timezone)
+}
+
+// quietHoursUntil reports whether now falls in the quiet hours of timezone and, if so, when they end
+// in UTC. An empty or unknown timezone uses the configured default.
+func (s *NotificationService) quietHoursUntil(now time.Time, timezone string) (time.Time, bool) {
+	location := s.config.QuietHoursLocation
+	if timezone != "" {
+		if accountLocation, err := time.LoadLocation(timezone); err == nil {
+			location = accountLocation
+		} else {
+			logrus.WithError(err).WithField("timezone", timezone).Warn("Unknown quiet hours timezone, using the default")
+		}
+	}
+
+	quiet := domain.QuietHours{Start: s.config.QuietHoursStart, End: s.config.QuietHoursEnd}
+	end, inside := quiet.Until(now.In(location))
+	if !inside {
+		return time.Time{}, false
+	}
+	return end.UTC(), true
+}
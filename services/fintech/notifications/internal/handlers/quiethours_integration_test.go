@@ -0,0 +1,104 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/aws"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+// stubSNSClient returns an SNS client publishing to a stub endpoint, and a count of its publishes
+func stubSNSClient(t *testing.T) (*aws.SNSClient, *int) {
+	t.Helper()
+
+	var publishes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		publishes++
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<PublishResponse xmlns="http://sns.amazonaws.com/doc/2010-03-31/"><PublishResult><MessageId>message-1</MessageId></PublishResult></PublishResponse>`))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := aws.NewSNSClient(&awssdk.Config{
+		Region:      awssdk.String("us-east-1"),
+		Endpoint:    awssdk.String(server.URL),
+		Credentials: credentials.NewStaticCredentials("test", "test", ""),
+	}, "arn:aws:sns:us-east-1:000000000000:fintech-notifications")
+	if err != nil {
+		t.Fatalf("NewSNSClient: %v", err)
+	}
+	return client, &publishes
+}
+
+func TestSendNotificationDuringQuietHours(t *testing.T) {
+	// Quiet hours cover the current UTC hour and the next one
+	hour := time.Now().UTC().Hour()
+	env := map[string]string{
+		"QUIET_HOURS_ENABLED":          "true",
+		"QUIET_HOURS_START":            strconv.Itoa(hour),
+		"QUIET_HOURS_END":              strconv.Itoa((hour + 2) % 24),
+		"QUIET_HOURS_DEFAULT_TIMEZONE": "UTC",
+		"SMS_QUEUE_URL":                "",
+	}
+	tests := []struct {
+		name     string
+		priority int
+		timezone string
+		wantHeld bool
+	}{
+		{"low priority", 1, "", true},
+		{"high priority", 2, "", false},
+		// Twelve hours ahead of UTC the account is outside the window
+		{"account timezone", 1, "Etc/GMT-12", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newTestService(t, env)
+			sns, publishes := stubSNSClient(t)
+			s.snsClient = sns
+			ctx := context.Background()
+
+			notification, err := domain.NewNotification(newID("pay"), "PaymentFailed", domain.SMSNotification, "+15550100", "", "Your payment has failed.", tt.priority, 3)
+			if err != nil {
+				t.Fatalf("NewNotification: %v", err)
+			}
+			notification.AccountID = newID("acc")
+			if tt.timezone != "" {
+				if err := s.prefs.Save(ctx, &domain.NotificationPreferences{AccountID: notification.AccountID, SMSEnabled: true, Timezone: tt.timezone, UpdatedAt: time.Now().UTC()}); err != nil {
+					t.Fatalf("failed to save preferences: %v", err)
+				}
+			}
+			if _, err := s.repo.Create(ctx, notification); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			t.Cleanup(func() {
+				db.Exec(context.Background(), "DELETE FROM notification_attempts WHERE notification_id = $1", notification.ID)
+				db.Exec(context.Background(), "DELETE FROM notifications WHERE id = $1", notification.ID)
+				db.Exec(context.Background(), "DELETE FROM notification_preferences WHERE account_id = $1", notification.AccountID)
+			})
+
+			s.sendNotification(notification)
+
+			if tt.wantHeld {
+				if notification.Status != domain.PendingStatus || notification.NextRetryAt == nil || !notification.NextRetryAt.After(time.Now()) || *publishes != 0 {
+					t.Errorf("status %s, deferred until %v, %d publishes, want held until quiet hours end", notification.Status, notification.NextRetryAt, *publishes)
+				}
+				return
+			}
+			if notification.Status != domain.SentStatus || *publishes != 1 {
+				t.Errorf("status %s with %d publishes, want sent once", notification.Status, *publishes)
+			}
+		})
+	}
+}
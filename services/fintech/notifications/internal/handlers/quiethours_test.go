@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fintech/notifications-service/internal/config"
+	"fintech/notifications-service/internal/domain"
+)
+
+func quietHoursService() *NotificationService {
+	return &NotificationService{config: &config.Config{
+		QuietHoursEnabled:           true,
+		QuietHoursStart:             22,
+		QuietHoursEnd:               7,
+		QuietHoursPriorityThreshold: 2,
+		QuietHoursLocation:          time.UTC,
+	}}
+}
+
+func TestQuietHoursUntilTimezone(t *testing.T) {
+	afternoon := time.Date(2024, 1, 15, 14, 0, 0, 0, time.UTC)
+	lateEvening := time.Date(2024, 1, 15, 23, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		now        time.Time
+		timezone   string
+		wantInside bool
+		wantEnd    time.Time
+	}{
+		{"default timezone outside", afternoon, "", false, time.Time{}},
+		{"default timezone inside", lateEvening, "", true, time.Date(2024, 1, 16, 7, 0, 0, 0, time.UTC)},
+		// 14:00 UTC is 23:00 in Tokyo, whose 07:00 is 22:00 UTC
+		{"account timezone", afternoon, "Asia/Tokyo", true, time.Date(2024, 1, 15, 22, 0, 0, 0, time.UTC)},
+		{"invalid timezone outside", afternoon, "Mars/Olympus", false, time.Time{}},
+		{"invalid timezone inside", lateEvening, "Mars/Olympus", true, time.Date(2024, 1, 16, 7, 0, 0, 0, time.UTC)},
+	}
+
+	s := quietHoursService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, inside := s.quietHoursUntil(tt.now, tt.timezone)
+			if inside != tt.wantInside || !end.Equal(tt.wantEnd) {
+				t.Errorf("quietHoursUntil(%s, %q) = %s, %v, want %s, %v", tt.now, tt.timezone, end, inside, tt.wantEnd, tt.wantInside)
+			}
+			if inside && end.Location() != time.UTC {
+				t.Errorf("end is in %s, want UTC", end.Location())
+			}
+		})
+	}
+}
+
+func TestQuietHoursEndExemptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		enabled      bool
+		notification *domain.Notification
+	}{
+		{"disabled", false, &domain.Notification{Type: domain.SMSNotification, Priority: 1}},
+		{"email", true, &domain.Notification{Type: domain.EmailNotification, Priority: 1}},
+		{"high priority", true, &domain.Notification{Type: domain.SMSNotification, Priority: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := quietHoursService()
+			s.config.QuietHoursEnabled = tt.enabled
+			// Exempt notifications return before the account's preferences are looked up
+			if _, quiet := s.quietHoursEnd(context.Background(), tt.notification); quiet {
+				t.Error("exempt notification was held for quiet hours")
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rampLimiter paces sends, raising the allowed rate linearly from a low start rate to the
+// steady-state cap over a window so a backlog drained after downtime doesn't hit AWS all at once
+type rampLimiter struct {
+	mu        sync.Mutex
+	startRate float64 // Sends per second when the ramp begins
+	maxRate   float64 // Steady-state sends per second; 0 means unlimited
+	window    time.Duration
+	began     time.Time
+	next      time.Time // Earliest time the next send may go out
+	now       func() time.Time
+}
+
+func newRampLimiter(startRate, maxRate float64, window time.Duration) *rampLimiter {
+	if startRate <= 0 || startRate > maxRate {
+		startRate = maxRate
+	}
+	l := &rampLimiter{startRate: startRate, maxRate: maxRate, window: window, now: time.Now}
+	l.began = l.now()
+	return l
+}
+
+// Restart begins a new ramp from the start rate
+func (l *rampLimiter) Restart() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.began = l.now()
+	l.next = time.Time{}
+}
+
+// Rate returns the sends per second currently allowed
+func (l *rampLimiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.rateAt(l.now())
+}
+
+func (l *rampLimiter) rateAt(t time.Time) float64 {
+	elapsed := t.Sub(l.began)
+	if l.window <= 0 || elapsed >= l.window {
+		return l.maxRate
+	}
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return l.startRate + (l.maxRate-l.startRate)*float64(elapsed)/float64(l.window)
+}
+
+// Wait blocks until the next send is allowed or ctx is done
+func (l *rampLimiter) Wait(ctx context.Context) error {
+	if l.maxRate <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := l.now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(time.Duration(float64(time.Second) / l.rateAt(slot)))
+	l.mu.Unlock()
+
+	delay := slot.Sub(now)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// newTestRamp returns a limiter ramping from 1 to 10 sends per second over 100s, and a pointer to its clock
+func newTestRamp() (*rampLimiter, *time.Time) {
+	now := time.Now()
+	l := newRampLimiter(1, 10, 100*time.Second)
+	l.now = func() time.Time { return now }
+	l.began = now
+	return l, &now
+}
+
+func TestRampLimiterRateIncreases(t *testing.T) {
+	l, now := newTestRamp()
+	began := *now
+
+	tests := []struct {
+		elapsed time.Duration
+		want    float64
+	}{
+		{0, 1},
+		{50 * time.Second, 5.5},
+		{100 * time.Second, 10},
+		{time.Hour, 10},
+	}
+	for _, tt := range tests {
+		*now = began.Add(tt.elapsed)
+		if got := l.Rate(); got != tt.want {
+			t.Errorf("rate after %v = %v, want %v", tt.elapsed, got, tt.want)
+		}
+	}
+
+	l.Restart()
+	if got := l.Rate(); got != 1 {
+		t.Errorf("rate after Restart = %v, want the start rate 1", got)
+	}
+}
+
+func TestRampLimiterSpacesSendsFurtherApartAtFirst(t *testing.T) {
+	l, now := newTestRamp()
+
+	// A cancelled context makes Wait return at once; the clock is moved to each granted slot instead
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var gaps []time.Duration
+	for i := 0; i < 5; i++ {
+		l.Wait(ctx)
+		gaps = append(gaps, l.next.Sub(*now))
+		*now = l.next.Add(25 * time.Second)
+	}
+
+	if gaps[0] != time.Second {
+		t.Errorf("first gap = %v, want 1s at the start rate", gaps[0])
+	}
+	for i := 1; i < len(gaps); i++ {
+		if gaps[i] >= gaps[i-1] {
+			t.Errorf("gaps = %v, want each shorter than the last while ramping", gaps)
+			break
+		}
+	}
+	if last := gaps[len(gaps)-1]; last != 100*time.Millisecond {
+		t.Errorf("gap after the window = %v, want 100ms at the steady-state rate", last)
+	}
+}
+
+func TestRampLimiterUnlimited(t *testing.T) {
+	l := newRampLimiter(1, 0, time.Minute)
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("100 unlimited waits took %v", elapsed)
+	}
+}
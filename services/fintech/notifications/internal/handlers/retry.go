@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RunRetryWorker periodically re-queues pending notifications that are due, paced by the send
+// ramp, until ctx is cancelled. The ramp starts when the worker starts and restarts whenever a
+// backlog appears after an idle sweep, e.g. once AWS or the database comes back. Every fetched
+// notification is sent: the query has already decided it's due by the database clock, and
+// re-checking against the local clock would skip rows whenever the two clocks disagree. Fetched rows
+// are claimed for RetryClaimTTL, so other replicas' workers don't send them too.
+//
+// The worker also owns recovery: a notification saved PENDING but never sent, because the process
+// stopped before its send worker got to it, is picked up once PendingRecoveryAfter has passed. The
+// first sweep runs at startup, so a restarted instance recovers rows orphaned by its predecessor.
+func (s *NotificationService) RunRetryWorker(ctx context.Context) {
+	ticker := time.NewTicker(s.config.RetryPollInterval)
+	defer ticker.Stop()
+
+	idle := false
+	for {
+		due, err := s.repo.ClaimPendingNotifications(ctx, s.config.RetryBatchSize, s.config.PendingRecoveryAfter, s.config.RetryClaimTTL)
+		if err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Error("Failed to claim pending notifications")
+		}
+
+		if len(due) > 0 && idle {
+			s.ramp.Restart()
+		}
+		idle = len(due) == 0
+
+		for _, notification := range due {
+			if err := s.ramp.Wait(ctx); err != nil {
+				return
+			}
+			s.refreshRecipient(ctx, notification)
+			s.enqueue(notification)
+		}
+
+		if len(due) > 0 {
+			logrus.WithFields(logrus.Fields{
+				"count": len(due),
+				"rate":  s.ramp.Rate(),
+			}).Debug("Re-queued pending notifications")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,61 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fintech/notifications-service/internal/domain"
+)
+
+func TestRetryWorkerSendsDueRowsDespiteClockSkew(t *testing.T) {
+	s, db := newTestService(t, map[string]string{"RETRY_POLL_INTERVAL": "50ms", "SEND_RAMP_START_RATE": "1000"})
+	ctx := context.Background()
+
+	// The highest priority puts the row first in the sweep on a shared database
+	notification, err := domain.NewNotification(newID("pay"), "PaymentFailed", domain.EmailNotification, "jane@example.com", "Payment Failed", "Your payment has failed.", 1000, 3)
+	if err != nil {
+		t.Fatalf("NewNotification: %v", err)
+	}
+	if _, err := s.repo.Create(ctx, notification); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Exec(context.Background(), "DELETE FROM notifications WHERE id = $1", notification.ID)
+	})
+
+	// Due by the database clock, but not by an application clock running an hour behind it
+	var nextRetryAt time.Time
+	if err := db.QueryRow(ctx, `
+		UPDATE notifications SET next_retry_at = CURRENT_TIMESTAMP - INTERVAL '1 second'
+		WHERE id = $1
+		RETURNING next_retry_at
+	`, notification.ID).Scan(&nextRetryAt); err != nil {
+		t.Fatalf("failed to schedule retry: %v", err)
+	}
+	notification.NextRetryAt = &nextRetryAt
+	if notification.IsReadyForRetry(time.Now().Add(-time.Hour)) {
+		t.Fatal("expected the skewed application clock to consider the retry not yet due")
+	}
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.RunRetryWorker(workerCtx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !isQueued(s, notification.ID) {
+		if time.Now().After(deadline) {
+			t.Fatal("retry worker never queued the notification the database found due")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/metrics"
+
+	"github.com/sirupsen/logrus"
+)
+
+// slaBatchSize caps the breaches flagged per check
+const slaBatchSize = 100
+
+// slaAlertClient posts breach alerts to the Slack webhook
+var slaAlertClient = &http.Client{Timeout: 5 * time.Second}
+
+// RunSLAWorker periodically flags notifications still unsent past their deliver-by deadline until
+// ctx is cancelled. Each breach is counted and, when a webhook is configured, alerted once.
+func (s *NotificationService) RunSLAWorker(ctx context.Context) {
+	if len(s.config.DeliverySLAs) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.SLACheckInterval)
+	defer ticker.Stop()
+
+	for {
+		s.checkSLAs(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkSLAs flags one batch of SLA breaches
+func (s *NotificationService) checkSLAs(ctx context.Context) {
+	breaches, err := s.repo.FindSLABreaches(ctx, slaBatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			logrus.WithError(err).Error("Failed to find SLA breaches")
+		}
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, notification := range breaches {
+		flagged, err := s.repo.MarkSLABreached(ctx, notification.ID)
+		if err != nil {
+			logrus.WithError(err).WithField("notification_id", notification.ID).Error("Failed to flag SLA breach")
+			continue
+		}
+		if !flagged {
+			continue
+		}
+
+		metrics.SLABreaches.WithLabelValues(string(notification.Type), strconv.Itoa(notification.Priority)).Inc()
+		logrus.WithFields(logrus.Fields{
+			"notification_id": notification.ID,
+			"channel":         notification.Type,
+			"priority":        notification.Priority,
+			"status":          notification.Status,
+			"deliver_by":      notification.DeliverBy,
+		}).Warn("Notification breached its delivery SLA")
+
+		if err := s.alertSLABreach(ctx, notification, now); err != nil {
+			logrus.WithError(err).WithField("notification_id", notification.ID).Warn("Failed to send SLA breach alert")
+		}
+	}
+}
+
+// alertSLABreach posts a breach to the Slack webhook, if one is configured. The recipient and
+// content are left out so alerts never carry PII.
+func (s *NotificationService) alertSLABreach(ctx context.Context, notification *domain.Notification, now time.Time) error {
+	if s.config.SLAAlertWebhookURL == "" {
+		return nil
+	}
+
+	late := "past its deadline"
+	if notification.DeliverBy != nil {
+		late = now.Sub(*notification.DeliverBy).Round(time.Second).String() + " late"
+	}
+	payload, err := json.Marshal(map[string]string{
+		"text": fmt.Sprintf("Notification %s (%s %s, priority %d) breached its delivery SLA: still %s, %s",
+			notification.ID, notification.EventType, notification.Type, notification.Priority, notification.Status, late),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal SLA alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.SLAAlertWebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create SLA alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := slaAlertClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post SLA alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("SLA alert webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/database"
+	"fintech/notifications-service/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// seedSLANotification saves a pending priority 3 email due for delivery by deliverBy
+func seedSLANotification(t *testing.T, s *NotificationService, db *database.DB, deliverBy time.Time) string {
+	t.Helper()
+
+	notification, err := domain.NewNotification(newID("pay"), "PaymentFailed", domain.EmailNotification, "jane@example.com", "Payment Failed", "Your payment has failed.", 3, 3)
+	if err != nil {
+		t.Fatalf("NewNotification: %v", err)
+	}
+	notification.DeliverBy = &deliverBy
+	if _, err := s.repo.Create(context.Background(), notification); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Exec(context.Background(), "DELETE FROM notifications WHERE id = $1", notification.ID)
+	})
+	return notification.ID
+}
+
+// slaBreached reports whether the notification's SLA breach has been flagged
+func slaBreached(t *testing.T, db *database.DB, id string) bool {
+	t.Helper()
+
+	var breached bool
+	if err := db.QueryRow(context.Background(), "SELECT sla_breached_at IS NOT NULL FROM notifications WHERE id = $1", id).Scan(&breached); err != nil {
+		t.Fatalf("failed to read notification %s: %v", id, err)
+	}
+	return breached
+}
+
+func TestHandlePaymentEventSetsDeliverBy(t *testing.T) {
+	s, db := newTestService(t, map[string]string{"DELIVERY_SLAS": "3:5m"})
+
+	// PaymentFailed templates are priority 3 and get the SLA; PaymentInitiated ones have none
+	tests := []struct {
+		eventType string
+		want      time.Duration
+	}{
+		{"PaymentFailed", 5 * time.Minute},
+		{"PaymentInitiated", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.eventType, func(t *testing.T) {
+			event := paymentEvent(tt.eventType)
+			if err := s.HandlePaymentEvent(context.Background(), event); err != nil {
+				t.Fatalf("HandlePaymentEvent: %v", err)
+			}
+
+			rows, err := db.Query(context.Background(), `
+				SELECT type, COALESCE(EXTRACT(EPOCH FROM deliver_by - created_at), 0)
+				FROM notifications
+				WHERE event_id = $1
+			`, event.PaymentID)
+			if err != nil {
+				t.Fatalf("failed to query notifications: %v", err)
+			}
+			defer rows.Close()
+
+			count := 0
+			for rows.Next() {
+				var channel string
+				var seconds float64
+				if err := rows.Scan(&channel, &seconds); err != nil {
+					t.Fatalf("failed to scan notification: %v", err)
+				}
+				count++
+				if got := time.Duration(seconds * float64(time.Second)).Round(time.Second); got != tt.want {
+					t.Errorf("%s deliver_by = created_at + %v, want + %v", channel, got, tt.want)
+				}
+			}
+			if err := rows.Err(); err != nil {
+				t.Fatalf("failed to read notifications: %v", err)
+			}
+			if count == 0 {
+				t.Fatal("no notifications created")
+			}
+		})
+	}
+}
+
+func TestCheckSLAsFlagsOnlyBreaches(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		alerts []string
+	)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		alerts = append(alerts, string(body))
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+
+	s, db := newTestService(t, map[string]string{"DELIVERY_SLAS": "3:5m", "SLA_ALERT_WEBHOOK_URL": webhook.URL})
+
+	// The breached deadline is far enough back to be first in the batch on a shared database
+	breached := seedSLANotification(t, s, db, time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC))
+	withinSLA := seedSLANotification(t, s, db, time.Now().Add(time.Hour))
+
+	before := testutil.ToFloat64(metrics.SLABreaches.WithLabelValues(string(domain.EmailNotification), "3"))
+	s.checkSLAs(context.Background())
+
+	if !slaBreached(t, db, breached) {
+		t.Error("notification past its deadline was not flagged")
+	}
+	if slaBreached(t, db, withinSLA) {
+		t.Error("notification within its SLA was flagged")
+	}
+	if got := testutil.ToFloat64(metrics.SLABreaches.WithLabelValues(string(domain.EmailNotification), "3")); got < before+1 {
+		t.Errorf("SLA breaches = %v, want at least %v", got, before+1)
+	}
+
+	// A flagged breach is alerted once, however often the worker checks
+	s.checkSLAs(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	breachAlerts := 0
+	for _, alert := range alerts {
+		if strings.Contains(alert, breached) {
+			breachAlerts++
+		}
+		if strings.Contains(alert, withinSLA) {
+			t.Errorf("alert sent for the notification within its SLA: %s", alert)
+		}
+	}
+	if breachAlerts != 1 {
+		t.Errorf("%d alerts for the breach, want 1", breachAlerts)
+	}
+}
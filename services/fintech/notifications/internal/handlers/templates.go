@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LoadTemplates seeds the template table with any missing built-in templates and loads the cache.
+// Failures are logged, leaving the built-in templates to serve until a refresh succeeds.
+func (s *NotificationService) LoadTemplates(ctx context.Context) {
+	if err := s.templates.Seed(ctx); err != nil {
+		logrus.WithError(err).Warn("Failed to seed notification templates")
+	}
+	if err := s.templates.Refresh(ctx); err != nil {
+		logrus.WithError(err).Warn("Failed to load notification templates, using built-in templates")
+	}
+}
+
+// RunTemplateRefresher reloads the template cache every TemplateRefreshInterval until ctx is
+// cancelled, so edits to the table apply without a deploy. A failed refresh keeps the last
+// templates loaded.
+func (s *NotificationService) RunTemplateRefresher(ctx context.Context) {
+	if s.config.TemplateRefreshInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.TemplateRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.templates.Refresh(ctx); err != nil && ctx.Err() == nil {
+				logrus.WithError(err).Error("Failed to refresh notification templates")
+			}
+		}
+	}
+}
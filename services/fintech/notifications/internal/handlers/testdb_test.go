@@ -0,0 +1,120 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"fintech/notifications-service/internal/config"
+	"fintech/notifications-service/pkg/database"
+
+	"github.com/google/uuid"
+)
+
+// migrationLock serializes RunMigrations across test packages sharing TEST_DATABASE_URL
+const migrationLock = 742001
+
+// newTestDB connects to TEST_DATABASE_URL and migrates it, skipping the test when it isn't set.
+// Tests share the database, so each one works on its own events and accounts.
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := database.NewConnection(url)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	ctx := context.Background()
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("failed to acquire connection: %v", err)
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLock); err != nil {
+		t.Fatalf("failed to take migration lock: %v", err)
+	}
+	defer conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", migrationLock)
+
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	return db
+}
+
+// newTestService returns a notification service over the test database, configured from the
+// environment plus env. It has no AWS clients and no send workers, so notifications are created
+// and queued but never sent.
+func newTestService(t *testing.T, env map[string]string) (*NotificationService, *database.DB) {
+	t.Helper()
+
+	db := newTestDB(t)
+
+	t.Setenv("DATABASE_URL", os.Getenv("TEST_DATABASE_URL"))
+	t.Setenv("SEND_WORKERS", "0")
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	s := NewNotificationService(db, nil, nil, nil, cfg)
+	t.Cleanup(s.queue.Close)
+	return s, db
+}
+
+// newID returns a fresh payment or account ID, so tests don't see each other's rows
+func newID(prefix string) string {
+	return prefix + "-" + uuid.New().String()
+}
+
+// storedNotification is the part of a notifications row tests check
+type storedNotification struct {
+	ID        string
+	EventType string
+	Type      string
+	Status    string
+	Subject   string
+	Body      string
+}
+
+// notificationsForEvent returns the notifications saved for an event, by channel
+func notificationsForEvent(t *testing.T, db *database.DB, eventID string) map[string]storedNotification {
+	t.Helper()
+
+	rows, err := db.Query(context.Background(), `
+		SELECT id, event_type, type, status, COALESCE(subject, ''), body
+		FROM notifications
+		WHERE event_id = $1
+	`, eventID)
+	if err != nil {
+		t.Fatalf("failed to query notifications: %v", err)
+	}
+	defer rows.Close()
+
+	notifications := make(map[string]storedNotification)
+	for rows.Next() {
+		var n storedNotification
+		if err := rows.Scan(&n.ID, &n.EventType, &n.Type, &n.Status, &n.Subject, &n.Body); err != nil {
+			t.Fatalf("failed to scan notification: %v", err)
+		}
+		if _, exists := notifications[n.Type]; exists {
+			t.Errorf("event %s has more than one %s notification", eventID, n.Type)
+		}
+		notifications[n.Type] = n
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("failed to read notifications: %v", err)
+	}
+	return notifications
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+)
+
+// recipientThrottle is a sliding-window limit on notifications per recipient across all channels
+type recipientThrottle struct {
+	mu        sync.Mutex
+	limit     int // 0 disables the throttle
+	window    time.Duration
+	sends     map[string][]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newRecipientThrottle(limit int, window time.Duration) *recipientThrottle {
+	return &recipientThrottle{
+		limit:  limit,
+		window: window,
+		sends:  make(map[string][]time.Time),
+		now:    time.Now,
+	}
+}
+
+// Allow records a send for key and reports whether it is within the limit. Sends that are
+// refused don't count towards the window.
+func (t *recipientThrottle) Allow(key string) bool {
+	if t.limit <= 0 || t.window <= 0 {
+		return true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	cutoff := now.Add(-t.window)
+
+	// Drop idle recipients now and then so the map doesn't grow without bound
+	if now.Sub(t.lastSweep) >= t.window {
+		for k, times := range t.sends {
+			if len(times) == 0 || !times[len(times)-1].After(cutoff) {
+				delete(t.sends, k)
+			}
+		}
+		t.lastSweep = now
+	}
+
+	recent := t.sends[key]
+	i := 0
+	for i < len(recent) && !recent[i].After(cutoff) {
+		i++
+	}
+	recent = recent[i:]
+
+	if len(recent) >= t.limit {
+		t.sends[key] = recent
+		return false
+	}
+
+	t.sends[key] = append(recent, now)
+	return true
+}
+
+// Release gives back the most recent send recorded for key, for a send that didn't happen after all
+func (t *recipientThrottle) Release(key string) {
+	if t.limit <= 0 || t.window <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if recent := t.sends[key]; len(recent) > 0 {
+		t.sends[key] = recent[:len(recent)-1]
+	}
+}
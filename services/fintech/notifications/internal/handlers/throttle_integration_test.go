@@ -0,0 +1,81 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"fintech/notifications-service/pkg/database"
+)
+
+// handleEventsForAccount handles count payment events from accountID and returns the statuses of
+// the notifications they created, by status
+func handleEventsForAccount(t *testing.T, s *NotificationService, db *database.DB, accountID string, count int) map[string]int {
+	t.Helper()
+
+	statuses := make(map[string]int)
+	for i := 0; i < count; i++ {
+		event := paymentEvent("PaymentCompleted")
+		event.FromAccountID = accountID
+		if err := s.HandlePaymentEvent(context.Background(), event); err != nil {
+			t.Fatalf("HandlePaymentEvent: %v", err)
+		}
+		for _, n := range notificationsForEvent(t, db, event.PaymentID) {
+			statuses[n.Status]++
+		}
+	}
+	return statuses
+}
+
+func TestHandlePaymentEventUnderRecipientThrottle(t *testing.T) {
+	s, db := newTestService(t, map[string]string{"RECIPIENT_THROTTLE_LIMIT": "2", "RECIPIENT_THROTTLE_WINDOW": "10m"})
+
+	// Two events stay within the limit of each channel's recipient address
+	statuses := handleEventsForAccount(t, s, db, newID("acc"), 2)
+	if statuses["PENDING"] != 6 || statuses["THROTTLED"] != 0 {
+		t.Errorf("statuses = %v, want 6 PENDING", statuses)
+	}
+}
+
+func TestHandlePaymentEventOverRecipientThrottle(t *testing.T) {
+	s, db := newTestService(t, map[string]string{"RECIPIENT_THROTTLE_LIMIT": "1", "RECIPIENT_THROTTLE_WINDOW": "10m"})
+	accountID := newID("acc")
+
+	// The second event goes to the same addresses as the first
+	statuses := handleEventsForAccount(t, s, db, accountID, 2)
+	if statuses["PENDING"] != 3 || statuses["THROTTLED"] != 3 {
+		t.Errorf("statuses = %v, want 3 PENDING and 3 THROTTLED", statuses)
+	}
+
+	// Another account shares only the placeholder phone number, so only its SMS is throttled
+	event := paymentEvent("PaymentCompleted")
+	if err := s.HandlePaymentEvent(context.Background(), event); err != nil {
+		t.Fatalf("HandlePaymentEvent: %v", err)
+	}
+	for channel, n := range notificationsForEvent(t, db, event.PaymentID) {
+		want := "PENDING"
+		if channel == "SMS" {
+			want = "THROTTLED"
+		}
+		if n.Status != want {
+			t.Errorf("other account %s notification status = %s, want %s", channel, n.Status, want)
+		}
+	}
+}
+
+func TestHandlePaymentEventRedeliveryKeepsThrottleSlots(t *testing.T) {
+	s, db := newTestService(t, map[string]string{"RECIPIENT_THROTTLE_LIMIT": "2", "RECIPIENT_THROTTLE_WINDOW": "10m"})
+	ctx := context.Background()
+	event := paymentEvent("PaymentCompleted")
+
+	// A redelivered event is deduplicated without using up the recipients' slots
+	for i := 0; i < 2; i++ {
+		if err := s.HandlePaymentEvent(ctx, event); err != nil {
+			t.Fatalf("HandlePaymentEvent: %v", err)
+		}
+	}
+	if statuses := handleEventsForAccount(t, s, db, event.FromAccountID, 1); statuses["PENDING"] != 3 {
+		t.Errorf("statuses after a redelivery = %v, want 3 PENDING", statuses)
+	}
+}
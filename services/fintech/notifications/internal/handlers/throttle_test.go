@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecipientThrottleSlidingWindow(t *testing.T) {
+	throttle := newRecipientThrottle(2, 10*time.Minute)
+	now := time.Now()
+	throttle.now = func() time.Time { return now }
+
+	if !throttle.Allow("acc-1") || !throttle.Allow("acc-1") {
+		t.Fatal("sends within the limit were refused")
+	}
+	if throttle.Allow("acc-1") {
+		t.Error("third send within the window was allowed")
+	}
+	if !throttle.Allow("acc-2") {
+		t.Error("another recipient was throttled")
+	}
+
+	// The refused send doesn't extend the window: once the first two age out, sends resume
+	now = now.Add(10*time.Minute + time.Second)
+	if !throttle.Allow("acc-1") {
+		t.Error("send after the window was refused")
+	}
+}
+
+func TestRecipientThrottleDisabled(t *testing.T) {
+	throttle := newRecipientThrottle(0, 10*time.Minute)
+	for i := 0; i < 100; i++ {
+		if !throttle.Allow("acc-1") {
+			t.Fatalf("send %d refused with the throttle disabled", i+1)
+		}
+	}
+}
+
+func TestRecipientThrottleRelease(t *testing.T) {
+	throttle := newRecipientThrottle(1, 10*time.Minute)
+
+	if !throttle.Allow("user@example.com") {
+		t.Fatal("first send was refused")
+	}
+	throttle.Release("user@example.com")
+	if !throttle.Allow("user@example.com") {
+		t.Error("send after a released slot was refused")
+	}
+	if throttle.Allow("user@example.com") {
+		t.Error("send over the limit was allowed")
+	}
+
+	// Releasing a recipient without sends is a no-op
+	throttle.Release("other@example.com")
+	if !throttle.Allow("other@example.com") {
+		t.Error("send for another recipient was refused")
+	}
+}
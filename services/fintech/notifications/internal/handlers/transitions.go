@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/otel"
+	"fintech/notifications-service/pkg/respond"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// TransitionRequest represents a manual status change requested by an operator
+type TransitionRequest struct {
+	Status   domain.NotificationStatus `json:"status"`
+	Operator string                    `json:"operator"`
+	Reason   string                    `json:"reason,omitempty"`
+}
+
+// TransitionNotification handles POST /notifications/{id}/transition
+func (s *NotificationService) TransitionNotification(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.StartSpan(r.Context(), "TransitionNotification")
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+		return
+	}
+
+	var req TransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logrus.WithError(err).Error("Failed to decode transition request")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	otel.AddSpanAttributes(span,
+		otel.Attribute("notification_id", id),
+		otel.Attribute("status", string(req.Status)),
+		otel.Attribute("operator", req.Operator),
+	)
+
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	notification, err := s.repo.FindByID(ctx, id)
+	if errors.Is(err, domain.ErrNotificationNotFound) {
+		http.Error(w, "Notification not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("notification_id", id).Error("Failed to load notification")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	transition := &domain.StatusTransition{
+		NotificationID: notification.ID,
+		FromStatus:     notification.Status,
+		ToStatus:       req.Status,
+		Operator:       req.Operator,
+		Reason:         req.Reason,
+		CreatedAt:      time.Now().UTC(),
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = "Manually transitioned by " + req.Operator
+	}
+	if err := notification.TransitionTo(req.Status, reason); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	if err := s.repo.SaveTransition(ctx, notification, transition); err != nil {
+		logrus.WithError(err).WithField("notification_id", id).Error("Failed to save notification transition")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"notification_id": notification.ID,
+		"from_status":     transition.FromStatus,
+		"to_status":       transition.ToStatus,
+		"operator":        transition.Operator,
+	}).Warn("Notification manually transitioned")
+
+	// Re-trigger sending for notifications moved back to PENDING
+	if notification.Status == domain.PendingStatus {
+		s.enqueue(notification)
+	}
+
+	if err := respond.JSON(ctx, w, http.StatusOK, notification); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
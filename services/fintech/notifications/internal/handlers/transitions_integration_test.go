@@ -0,0 +1,105 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/database"
+
+	"github.com/gorilla/mux"
+)
+
+func transition(s *NotificationService, id, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/notifications/"+id+"/transition", strings.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	rec := httptest.NewRecorder()
+	s.TransitionNotification(rec, req)
+	return rec
+}
+
+// transitionOperators returns the operators of the notification's recorded transitions, oldest first
+func transitionOperators(t *testing.T, db *database.DB, notificationID string) []string {
+	t.Helper()
+
+	rows, err := db.Query(context.Background(), `
+		SELECT operator FROM notification_transitions WHERE notification_id = $1 ORDER BY created_at
+	`, notificationID)
+	if err != nil {
+		t.Fatalf("failed to query transitions: %v", err)
+	}
+	defer rows.Close()
+
+	var operators []string
+	for rows.Next() {
+		var operator string
+		if err := rows.Scan(&operator); err != nil {
+			t.Fatalf("failed to scan transition: %v", err)
+		}
+		operators = append(operators, operator)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("failed to read transitions: %v", err)
+	}
+	return operators
+}
+
+func TestTransitionNotificationToFailed(t *testing.T) {
+	s, db := newTestService(t, nil)
+	notification := newSavedEmail(t, s, 3)
+
+	rec := transition(s, notification.ID, `{"status": "FAILED", "operator": "ops-1", "reason": "lost in-flight send"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	stored, err := s.repo.FindByID(context.Background(), notification.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if stored.Status != domain.FailedStatus || stored.Error != "lost in-flight send" {
+		t.Errorf("stored notification = %s %q, want FAILED with the reason", stored.Status, stored.Error)
+	}
+	if operators := transitionOperators(t, db, notification.ID); len(operators) != 1 || operators[0] != "ops-1" {
+		t.Errorf("recorded transitions by %v, want one by ops-1", operators)
+	}
+}
+
+func TestTransitionNotificationRejectsIllegalTransition(t *testing.T) {
+	s, db := newTestService(t, nil)
+	notification := newSavedEmail(t, s, 3)
+
+	if rec := transition(s, notification.ID, `{"status": "FAILED", "operator": "ops-1"}`); rec.Code != http.StatusOK {
+		t.Fatalf("PENDING -> FAILED status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	rec := transition(s, notification.ID, `{"status": "SENT", "operator": "ops-2"}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("FAILED -> SENT status = %d, want 409", rec.Code)
+	}
+
+	stored, err := s.repo.FindByID(context.Background(), notification.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if stored.Status != domain.FailedStatus {
+		t.Errorf("stored status = %s after a rejected transition, want FAILED", stored.Status)
+	}
+	if operators := transitionOperators(t, db, notification.ID); len(operators) != 1 {
+		t.Errorf("recorded transitions by %v, want only the allowed one", operators)
+	}
+}
+
+func TestTransitionNotificationNotFound(t *testing.T) {
+	s, _ := newTestService(t, nil)
+
+	rec := transition(s, "00000000-0000-0000-0000-000000000000", `{"status": "FAILED", "operator": "ops-1"}`)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestTransitionNotificationRejectsInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		body string
+		want int
+	}{
+		{"malformed ID", "not-a-uuid", `{"status": "FAILED", "operator": "ops-1"}`, http.StatusBadRequest},
+		{"malformed body", "00000000-0000-0000-0000-000000000000", `{"status":`, http.StatusBadRequest},
+		{"missing operator", "00000000-0000-0000-0000-000000000000", `{"status": "FAILED"}`, http.StatusUnprocessableEntity},
+		{"unsupported status", "00000000-0000-0000-0000-000000000000", `{"status": "MUTED", "operator": "ops-1"}`, http.StatusUnprocessableEntity},
+	}
+
+	// No repository: the request must be rejected before the notification is loaded
+	s := &NotificationService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/notifications/"+tt.id+"/transition", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+			rec := httptest.NewRecorder()
+
+			s.TransitionNotification(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
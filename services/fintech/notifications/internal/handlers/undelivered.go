@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"fintech/notifications-service/pkg/metrics"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RunUndeliveredSweeper periodically flags notifications that were SENT more than UndeliveredAfter
+// ago without a delivery receipt, counting them per channel, until ctx is cancelled. The grace
+// window keeps receipts that are merely slow from being reported.
+func (s *NotificationService) RunUndeliveredSweeper(ctx context.Context) {
+	if s.config.UndeliveredAfter <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.UndeliveredSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		flagged, err := s.repo.FlagUndelivered(ctx, s.config.UndeliveredAfter)
+		if err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Error("Failed to flag undelivered notifications")
+		}
+
+		for channel, count := range flagged {
+			metrics.NotificationsUndelivered.WithLabelValues(string(channel)).Add(float64(count))
+			logrus.WithFields(logrus.Fields{
+				"channel": channel,
+				"count":   count,
+			}).Warn("Sent notifications flagged undelivered")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/middleware"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FieldError describes why one request field is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse is the 422 body listing every invalid field of a request
+type ValidationErrorResponse struct {
+	Code      string       `json:"code"` // Always VALIDATION_FAILED
+	Message   string       `json:"message"`
+	Details   []FieldError `json:"details"`
+	RequestID string       `json:"requestId,omitempty"`
+}
+
+// fieldErrors collects every invalid field of a request, so that a client sees all its problems at once
+type fieldErrors []FieldError
+
+// add records that field is invalid
+func (e *fieldErrors) add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+// required checks that value is not blank
+func (e *fieldErrors) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		e.add(field, "is required")
+	}
+}
+
+// writeValidationErrors rejects a request with 422, listing every invalid field. The request ID is
+// the one the RequestID middleware set on the response, if any.
+func writeValidationErrors(w http.ResponseWriter, errs fieldErrors) {
+	response := ValidationErrorResponse{
+		Code:      "VALIDATION_FAILED",
+		Message:   "Request validation failed",
+		Details:   errs,
+		RequestID: w.Header().Get(middleware.RequestIDHeader),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logrus.WithError(err).Error("Failed to encode validation errors")
+	}
+}
+
+// validate checks the fields of a mute: exactly one of accountIds and global, a reason, an operator
+// and an expiry in the future
+func (r MuteRequest) validate() fieldErrors {
+	var errs fieldErrors
+	if r.Global == (len(r.AccountIDs) > 0) {
+		errs.add("accountIds", "specify either accountIds or global")
+	}
+	if !r.Global {
+		for i, accountID := range r.AccountIDs {
+			errs.required(fmt.Sprintf("accountIds[%d]", i), accountID)
+		}
+	}
+	errs.required("reason", r.Reason)
+	errs.required("operator", r.Operator)
+	if !r.ExpiresAt.After(time.Now()) {
+		errs.add("expiresAt", "must be in the future")
+	}
+	return errs
+}
+
+// validate checks that a preferences update names a known IANA timezone, if any
+func (r PreferencesRequest) validate() fieldErrors {
+	var errs fieldErrors
+	if r.Timezone != nil && *r.Timezone != "" {
+		if _, err := time.LoadLocation(*r.Timezone); err != nil {
+			errs.add("timezone", "must be an IANA timezone name, e.g. Europe/Berlin")
+		}
+	}
+	return errs
+}
+
+// validate checks the fields of a status transition
+func (r TransitionRequest) validate() fieldErrors {
+	var errs fieldErrors
+	errs.required("operator", r.Operator)
+	switch r.Status {
+	case domain.PendingStatus, domain.SentStatus, domain.FailedStatus, domain.DeliveredStatus:
+	default:
+		errs.add("status", fmt.Sprintf("must be one of %s, %s, %s, %s",
+			domain.PendingStatus, domain.SentStatus, domain.FailedStatus, domain.DeliveredStatus))
+	}
+	return errs
+}
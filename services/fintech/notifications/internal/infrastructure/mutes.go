@@ -0,0 +1,83 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/database"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// MuteRepository handles database operations for notification mutes
+type MuteRepository struct {
+	db *database.DB
+}
+
+// NewMuteRepository creates a new mute repository
+func NewMuteRepository(db *database.DB) *MuteRepository {
+	return &MuteRepository{db: db}
+}
+
+// Save stores a new mute
+func (r *MuteRepository) Save(ctx context.Context, mute *domain.Mute) error {
+	if mute.ID == "" {
+		mute.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO notification_mutes (id, account_id, reason, expires_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		mute.ID,
+		nullString(mute.AccountID),
+		mute.Reason,
+		mute.ExpiresAt,
+		mute.CreatedBy,
+		mute.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save mute: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"mute_id":    mute.ID,
+		"account_id": mute.AccountID,
+		"expires_at": mute.ExpiresAt,
+	}).Debug("Mute saved")
+
+	return nil
+}
+
+// FindActive returns the unexpired mute covering an account (an account mute or a global one), or nil
+func (r *MuteRepository) FindActive(ctx context.Context, accountID string) (*domain.Mute, error) {
+	query := `
+		SELECT id, COALESCE(account_id, ''), reason, expires_at, created_by, created_at
+		FROM notification_mutes
+		WHERE (account_id = $1 OR account_id IS NULL) AND expires_at > CURRENT_TIMESTAMP
+		ORDER BY expires_at DESC
+		LIMIT 1
+	`
+
+	var mute domain.Mute
+	err := r.db.QueryRow(ctx, query, accountID).Scan(
+		&mute.ID,
+		&mute.AccountID,
+		&mute.Reason,
+		&mute.ExpiresAt,
+		&mute.CreatedBy,
+		&mute.CreatedAt,
+	)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find active mute: %w", err)
+	}
+
+	return &mute, nil
+}
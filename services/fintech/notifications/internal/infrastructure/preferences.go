@@ -0,0 +1,74 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/database"
+)
+
+// PreferenceRepository handles database operations for per-account channel preferences
+type PreferenceRepository struct {
+	db *database.DB
+}
+
+// NewPreferenceRepository creates a new preference repository
+func NewPreferenceRepository(db *database.DB) *PreferenceRepository {
+	return &PreferenceRepository{db: db}
+}
+
+// Get returns an account's preferences, or the all-enabled defaults when none are stored
+func (r *PreferenceRepository) Get(ctx context.Context, accountID string) (*domain.NotificationPreferences, error) {
+	query := `
+		SELECT account_id, email_enabled, sms_enabled, push_enabled, timezone, updated_at
+		FROM notification_preferences
+		WHERE account_id = $1
+	`
+
+	var prefs domain.NotificationPreferences
+	err := r.db.QueryRow(ctx, query, accountID).Scan(
+		&prefs.AccountID,
+		&prefs.EmailEnabled,
+		&prefs.SMSEnabled,
+		&prefs.PushEnabled,
+		&prefs.Timezone,
+		&prefs.UpdatedAt,
+	)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return domain.DefaultPreferences(accountID), nil
+		}
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	return &prefs, nil
+}
+
+// Save creates or replaces an account's preferences
+func (r *PreferenceRepository) Save(ctx context.Context, prefs *domain.NotificationPreferences) error {
+	query := `
+		INSERT INTO notification_preferences (account_id, email_enabled, sms_enabled, push_enabled, timezone, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (account_id) DO UPDATE SET
+			email_enabled = EXCLUDED.email_enabled,
+			sms_enabled = EXCLUDED.sms_enabled,
+			push_enabled = EXCLUDED.push_enabled,
+			timezone = EXCLUDED.timezone,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		prefs.AccountID,
+		prefs.EmailEnabled,
+		prefs.SMSEnabled,
+		prefs.PushEnabled,
+		prefs.Timezone,
+		prefs.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package infrastructure
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -9,10 +10,13 @@ import (
 	"fintech/notifications-service/pkg/database"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/sirupsen/logrus"
 )
 
-// NotificationRepository handles database operations for notifications
+// NotificationRepository handles database operations for notifications. Listing and stats
+// queries read from the replica when one is configured; everything else uses the primary.
 type NotificationRepository struct {
 	db *database.DB
 }
@@ -22,144 +26,317 @@ func NewNotificationRepository(db *database.DB) *NotificationRepository {
 	return &NotificationRepository{db: db}
 }
 
+// querier is satisfied by both the connection pool and a transaction
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+}
+
 // Save saves a notification to the database
 func (r *NotificationRepository) Save(ctx context.Context, notification *domain.Notification) error {
-	if notification.ID == "" {
-		notification.ID = uuid.New().String()
+	return r.save(ctx, r.db, notification)
+}
+
+// Create inserts a new notification unless one already exists for the same event, event type and
+// channel, which happens when Kafka redelivers an event. It reports whether the row was inserted;
+// on a duplicate the notification is left unsaved and must not be sent.
+func (r *NotificationRepository) Create(ctx context.Context, notification *domain.Notification) (bool, error) {
+	tag, err := r.insert(ctx, r.db, notification, "ON CONFLICT (event_id, event_type, type) DO NOTHING")
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
 	}
 
-	query := `
-		INSERT INTO notifications (id, event_id, event_type, type, recipient, subject, body, status, priority, retry_count, max_retries, next_retry_at, error, created_at, updated_at, sent_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	logrus.WithField("notification_id", notification.ID).Debug("Notification created")
+	return true, nil
+}
+
+// SaveTransition persists a manually transitioned notification together with its audit record
+func (r *NotificationRepository) SaveTransition(ctx context.Context, notification *domain.Notification, transition *domain.StatusTransition) error {
+	if transition.ID == "" {
+		transition.ID = uuid.New().String()
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transition transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := r.save(ctx, tx, notification); err != nil {
+		return err
+	}
+
+	_, err = database.TracedExec(ctx, tx, "NotificationRepository.SaveTransition", "notification_transitions", `
+		INSERT INTO notification_transitions (id, notification_id, from_status, to_status, operator, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, transition.ID, transition.NotificationID, string(transition.FromStatus), string(transition.ToStatus), transition.Operator, nullString(transition.Reason), transition.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save status transition: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit status transition: %w", err)
+	}
+
+	return nil
+}
+
+func (r *NotificationRepository) save(ctx context.Context, q querier, notification *domain.Notification) error {
+	_, err := r.insert(ctx, q, notification, `
 		ON CONFLICT (id)
 		DO UPDATE SET
+			recipient = EXCLUDED.recipient,
+			original_recipient = EXCLUDED.original_recipient,
 			status = EXCLUDED.status,
 			retry_count = EXCLUDED.retry_count,
 			next_retry_at = EXCLUDED.next_retry_at,
 			error = EXCLUDED.error,
 			updated_at = EXCLUDED.updated_at,
-			sent_at = EXCLUDED.sent_at
-	`
+			sent_at = EXCLUDED.sent_at,
+			retry_claimed_until = NULL
+	`)
+	if err != nil {
+		return err
+	}
+
+	logrus.WithField("notification_id", notification.ID).Debug("Notification saved")
+	return nil
+}
+
+// insert writes every column of a notification, resolving conflicts with onConflict
+func (r *NotificationRepository) insert(ctx context.Context, q querier, notification *domain.Notification, onConflict string) (pgconn.CommandTag, error) {
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO notifications (id, event_id, event_type, type, recipient, subject, body, status, priority, retry_count, max_retries, next_retry_at, error, created_at, updated_at, sent_at, attachments, deliver_by, account_id, original_recipient, html_body)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+	` + onConflict
 
 	var sentAt *time.Time
 	if notification.SentAt != nil {
 		sentAt = notification.SentAt
 	}
 
-	_, err := r.db.Exec(ctx, query,
+	var attachments []byte
+	if len(notification.Attachments) > 0 {
+		var err error
+		if attachments, err = json.Marshal(notification.Attachments); err != nil {
+			return pgconn.CommandTag{}, fmt.Errorf("failed to marshal attachments: %w", err)
+		}
+	}
+
+	tag, err := database.TracedExec(ctx, q, "NotificationRepository.save", "notifications", query,
 		notification.ID,
 		notification.EventID,
 		notification.EventType,
 		string(notification.Type),
 		notification.Recipient,
-		notification.Subject,
+		nullString(notification.Subject),
 		notification.Body,
 		string(notification.Status),
 		notification.Priority,
 		notification.RetryCount,
 		notification.MaxRetries,
 		notification.NextRetryAt,
-		notification.Error,
+		nullString(notification.Error),
 		notification.CreatedAt,
 		notification.UpdatedAt,
 		sentAt,
+		attachments,
+		notification.DeliverBy,
+		nullString(notification.AccountID),
+		nullString(notification.OriginalRecipient),
+		nullString(notification.HTMLBody),
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to save notification: %w", err)
+		return pgconn.CommandTag{}, fmt.Errorf("failed to save notification: %w", err)
 	}
 
-	logrus.WithField("notification_id", notification.ID).Debug("Notification saved")
-	return nil
+	return tag, nil
 }
 
 // FindByID finds a notification by ID
 func (r *NotificationRepository) FindByID(ctx context.Context, id string) (*domain.Notification, error) {
 	query := `
-		SELECT id, event_id, event_type, type, recipient, subject, body, status, priority, retry_count, max_retries, next_retry_at, error, created_at, updated_at, sent_at
+		SELECT id, event_id, event_type, type, recipient, subject, body, status, priority, retry_count, max_retries, next_retry_at, error, created_at, updated_at, sent_at, attachments, deliver_by, sla_breached_at, account_id, original_recipient, undelivered_at, html_body
 		FROM notifications
 		WHERE id = $1
 	`
 
-	var notification domain.Notification
-	var sentAt *time.Time
-
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&notification.ID,
-		&notification.EventID,
-		&notification.EventType,
-		&notification.Type,
-		&notification.Recipient,
-		&notification.Subject,
-		&notification.Body,
-		&notification.Status,
-		&notification.Priority,
-		&notification.RetryCount,
-		&notification.MaxRetries,
-		&notification.NextRetryAt,
-		&notification.Error,
-		&notification.CreatedAt,
-		&notification.UpdatedAt,
-		&sentAt,
-	)
-
+	notification, err := scanNotification(database.TracedQueryRow(ctx, r.db, "NotificationRepository.FindByID", "notifications", query, id))
 	if err != nil {
 		if err.Error() == "no rows in result set" {
-			return nil, fmt.Errorf("notification not found: %s", id)
+			return nil, fmt.Errorf("%w: %s", domain.ErrNotificationNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to find notification: %w", err)
 	}
 
-	notification.SentAt = sentAt
-	return &notification, nil
+	return notification, nil
 }
 
-// FindPendingNotifications finds notifications that are ready for processing
-func (r *NotificationRepository) FindPendingNotifications(ctx context.Context, limit int) ([]*domain.Notification, error) {
+// ClaimPendingNotifications claims notifications that are ready for processing, at most limit of
+// them, for claimTTL. Rows are locked with SKIP LOCKED and leased in a single statement, so replicas
+// sweeping at the same time claim disjoint rows, and a row stays claimed until it is saved again or
+// the lease runs out, e.g. because the claiming replica stopped. Whether a retry is due is decided
+// by the database clock (CURRENT_TIMESTAMP), never the application's. Notifications with no retry
+// scheduled are only claimed once unchanged for recoverAfter: until then they are expected to be
+// queued in the process that saved them, and after it they were orphaned by a restart.
+func (r *NotificationRepository) ClaimPendingNotifications(ctx context.Context, limit int, recoverAfter, claimTTL time.Duration) ([]*domain.Notification, error) {
 	query := `
-		SELECT id, event_id, event_type, type, recipient, subject, body, status, priority, retry_count, max_retries, next_retry_at, error, created_at, updated_at, sent_at
-		FROM notifications
-		WHERE status = 'PENDING'
-		AND (next_retry_at IS NULL OR next_retry_at <= CURRENT_TIMESTAMP)
+		WITH claimed AS (
+			UPDATE notifications
+			SET retry_claimed_until = CURRENT_TIMESTAMP + make_interval(secs => $3)
+			WHERE id IN (
+				SELECT id
+				FROM notifications
+				WHERE status = 'PENDING'
+				AND (next_retry_at <= CURRENT_TIMESTAMP
+					OR (next_retry_at IS NULL AND updated_at <= CURRENT_TIMESTAMP - make_interval(secs => $2)))
+				AND (retry_claimed_until IS NULL OR retry_claimed_until <= CURRENT_TIMESTAMP)
+				ORDER BY priority DESC, created_at ASC
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, event_id, event_type, type, recipient, subject, body, status, priority, retry_count, max_retries, next_retry_at, error, created_at, updated_at, sent_at, attachments, deliver_by, sla_breached_at, account_id, original_recipient, undelivered_at, html_body
+		)
+		SELECT id, event_id, event_type, type, recipient, subject, body, status, priority, retry_count, max_retries, next_retry_at, error, created_at, updated_at, sent_at, attachments, deliver_by, sla_breached_at, account_id, original_recipient, undelivered_at, html_body
+		FROM claimed
 		ORDER BY priority DESC, created_at ASC
+	`
+
+	rows, err := database.TracedQuery(ctx, r.db, "NotificationRepository.ClaimPendingNotifications", "notifications", query, limit, recoverAfter.Seconds(), claimTTL.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pending notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*domain.Notification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// FindSLABreaches finds notifications past their deliver-by deadline that are still unsent and
+// haven't been flagged yet, oldest deadline first. Muted and throttled notifications are never
+// sent by design and don't breach.
+func (r *NotificationRepository) FindSLABreaches(ctx context.Context, limit int) ([]*domain.Notification, error) {
+	query := `
+		SELECT id, event_id, event_type, type, recipient, subject, body, status, priority, retry_count, max_retries, next_retry_at, error, created_at, updated_at, sent_at, attachments, deliver_by, sla_breached_at, account_id, original_recipient, undelivered_at, html_body
+		FROM notifications
+		WHERE deliver_by < CURRENT_TIMESTAMP
+		AND sla_breached_at IS NULL
+		AND status IN ('PENDING', 'FAILED')
+		ORDER BY deliver_by ASC
 		LIMIT $1
 	`
 
-	rows, err := r.db.Query(ctx, query, limit)
+	rows, err := database.TracedQuery(ctx, r.db, "NotificationRepository.FindSLABreaches", "notifications", query, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query pending notifications: %w", err)
+		return nil, fmt.Errorf("failed to query SLA breaches: %w", err)
 	}
 	defer rows.Close()
 
 	var notifications []*domain.Notification
 	for rows.Next() {
-		var notification domain.Notification
-		var sentAt *time.Time
-
-		err := rows.Scan(
-			&notification.ID,
-			&notification.EventID,
-			&notification.EventType,
-			&notification.Type,
-			&notification.Recipient,
-			&notification.Subject,
-			&notification.Body,
-			&notification.Status,
-			&notification.Priority,
-			&notification.RetryCount,
-			&notification.MaxRetries,
-			&notification.NextRetryAt,
-			&notification.Error,
-			&notification.CreatedAt,
-			&notification.UpdatedAt,
-			&sentAt,
-		)
+		notification, err := scanNotification(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan notification: %w", err)
 		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// MarkSLABreached flags a notification's SLA breach. It returns false if the breach was already
+// flagged, e.g. by another replica, so each breach is reported once.
+func (r *NotificationRepository) MarkSLABreached(ctx context.Context, id string) (bool, error) {
+	result, err := database.TracedExec(ctx, r.db, "NotificationRepository.MarkSLABreached", "notifications", `
+		UPDATE notifications
+		SET sla_breached_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND sla_breached_at IS NULL
+	`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark SLA breach: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// FlagUndelivered flags notifications SENT more than after ago that still have no delivery
+// receipt, returning how many were newly flagged per channel. Each is flagged once.
+func (r *NotificationRepository) FlagUndelivered(ctx context.Context, after time.Duration) (map[domain.NotificationType]int, error) {
+	rows, err := database.TracedQuery(ctx, r.db, "NotificationRepository.FlagUndelivered", "notifications", `
+		UPDATE notifications
+		SET undelivered_at = CURRENT_TIMESTAMP
+		WHERE status = 'SENT'
+		AND undelivered_at IS NULL
+		AND sent_at < CURRENT_TIMESTAMP - make_interval(secs => $1)
+		RETURNING type
+	`, after.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to flag undelivered notifications: %w", err)
+	}
+	defer rows.Close()
+
+	flagged := make(map[domain.NotificationType]int)
+	for rows.Next() {
+		var notificationType domain.NotificationType
+		if err := rows.Scan(&notificationType); err != nil {
+			return nil, fmt.Errorf("failed to scan undelivered notification: %w", err)
+		}
+		flagged[notificationType]++
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating undelivered notifications: %w", err)
+	}
+
+	return flagged, nil
+}
+
+// FindByStatusAndRange finds notifications with the given status created within [from, to), newest first
+func (r *NotificationRepository) FindByStatusAndRange(ctx context.Context, status domain.NotificationStatus, from, to time.Time, limit, offset int) ([]*domain.Notification, error) {
+	query := `
+		SELECT id, event_id, event_type, type, recipient, subject, body, status, priority, retry_count, max_retries, next_retry_at, error, created_at, updated_at, sent_at, attachments, deliver_by, sla_breached_at, account_id, original_recipient, undelivered_at, html_body
+		FROM notifications
+		WHERE status = $1 AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at DESC, id
+		LIMIT $4 OFFSET $5
+	`
 
-		notification.SentAt = sentAt
-		notifications = append(notifications, &notification)
+	rows, err := database.TracedQuery(ctx, r.db.Reader(), "NotificationRepository.FindByStatusAndRange", "notifications", query, string(status), from, to, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications by status: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []*domain.Notification{}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, notification)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -174,11 +351,12 @@ func (r *NotificationRepository) UpdateStatus(ctx context.Context, id string, st
 	query := `
 		UPDATE notifications
 		SET status = $1, error = $2, updated_at = CURRENT_TIMESTAMP,
-		    sent_at = CASE WHEN $1 = 'SENT' THEN CURRENT_TIMESTAMP ELSE sent_at END
+		    sent_at = CASE WHEN $1 = 'SENT' THEN CURRENT_TIMESTAMP ELSE sent_at END,
+		    retry_claimed_until = NULL
 		WHERE id = $3
 	`
 
-	_, err := r.db.Exec(ctx, query, string(status), errorMsg, id)
+	_, err := database.TracedExec(ctx, r.db, "NotificationRepository.UpdateStatus", "notifications", query, string(status), nullString(errorMsg), id)
 	if err != nil {
 		return fmt.Errorf("failed to update notification status: %w", err)
 	}
@@ -199,7 +377,7 @@ func (r *NotificationRepository) GetNotificationStats(ctx context.Context) (map[
 		GROUP BY status
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := database.TracedQuery(ctx, r.db.Reader(), "NotificationRepository.GetNotificationStats", "notifications", query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get notification stats: %w", err)
 	}
@@ -217,3 +395,199 @@ func (r *NotificationRepository) GetNotificationStats(ctx context.Context) (map[
 
 	return stats, nil
 }
+
+// GetFailureCounts returns how many notifications created since the given time failed, out of the total
+func (r *NotificationRepository) GetFailureCounts(ctx context.Context, since time.Time) (failed, total int, err error) {
+	query := `
+		SELECT COUNT(*) FILTER (WHERE status = 'FAILED'), COUNT(*)
+		FROM notifications
+		WHERE created_at >= $1
+	`
+
+	if err := database.TracedQueryRow(ctx, r.db.Reader(), "NotificationRepository.GetFailureCounts", "notifications", query, since).Scan(&failed, &total); err != nil {
+		return 0, 0, fmt.Errorf("failed to get failure counts: %w", err)
+	}
+
+	return failed, total, nil
+}
+
+// GetOldestPending returns when the oldest pending notification was created, or nil if none are pending
+func (r *NotificationRepository) GetOldestPending(ctx context.Context) (*time.Time, error) {
+	query := `
+		SELECT MIN(created_at)
+		FROM notifications
+		WHERE status = 'PENDING'
+	`
+
+	var oldest *time.Time
+	if err := database.TracedQueryRow(ctx, r.db.Reader(), "NotificationRepository.GetOldestPending", "notifications", query).Scan(&oldest); err != nil {
+		return nil, fmt.Errorf("failed to get oldest pending notification: %w", err)
+	}
+
+	return oldest, nil
+}
+
+// GetChannelVolume returns the number of notifications created since the given time, by channel
+func (r *NotificationRepository) GetChannelVolume(ctx context.Context, since time.Time) (map[string]int, error) {
+	query := `
+		SELECT type, COUNT(*) as count
+		FROM notifications
+		WHERE created_at >= $1
+		GROUP BY type
+	`
+
+	rows, err := database.TracedQuery(ctx, r.db.Reader(), "NotificationRepository.GetChannelVolume", "notifications", query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel volume: %w", err)
+	}
+	defer rows.Close()
+
+	volume := make(map[string]int)
+	for rows.Next() {
+		var channel string
+		var count int
+		if err := rows.Scan(&channel, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan channel volume: %w", err)
+		}
+		volume[channel] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating channel volume: %w", err)
+	}
+
+	return volume, nil
+}
+
+// nullString maps an empty optional field to NULL so "IS NULL" means "not set"
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// scanNotification scans a notifications row selected in the standard column order
+func scanNotification(row pgx.Row) (*domain.Notification, error) {
+	var notification domain.Notification
+	var sentAt *time.Time
+	var subject, errorMsg, accountID, originalRecipient, htmlBody *string
+	var attachments []byte
+
+	err := row.Scan(
+		&notification.ID,
+		&notification.EventID,
+		&notification.EventType,
+		&notification.Type,
+		&notification.Recipient,
+		&subject,
+		&notification.Body,
+		&notification.Status,
+		&notification.Priority,
+		&notification.RetryCount,
+		&notification.MaxRetries,
+		&notification.NextRetryAt,
+		&errorMsg,
+		&notification.CreatedAt,
+		&notification.UpdatedAt,
+		&sentAt,
+		&attachments,
+		&notification.DeliverBy,
+		&notification.BreachedAt,
+		&accountID,
+		&originalRecipient,
+		&notification.UndeliveredAt,
+		&htmlBody,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(attachments) > 0 {
+		if err := json.Unmarshal(attachments, &notification.Attachments); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal attachments: %w", err)
+		}
+	}
+
+	notification.SentAt = sentAt
+	if subject != nil {
+		notification.Subject = *subject
+	}
+	if errorMsg != nil {
+		notification.Error = *errorMsg
+	}
+	if accountID != nil {
+		notification.AccountID = *accountID
+	}
+	if originalRecipient != nil {
+		notification.OriginalRecipient = *originalRecipient
+	}
+	if htmlBody != nil {
+		notification.HTMLBody = *htmlBody
+	}
+	return &notification, nil
+}
+
+// SaveAttempt records a send attempt
+func (r *NotificationRepository) SaveAttempt(ctx context.Context, attempt *domain.NotificationAttempt) error {
+	if attempt.ID == "" {
+		attempt.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO notification_attempts (id, notification_id, attempt_number, outcome, error, attempted_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := database.TracedExec(ctx, r.db, "NotificationRepository.SaveAttempt", "notification_attempts", query,
+		attempt.ID,
+		attempt.NotificationID,
+		attempt.AttemptNumber,
+		string(attempt.Outcome),
+		nullString(attempt.Error),
+		attempt.AttemptedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save notification attempt: %w", err)
+	}
+
+	return nil
+}
+
+// FindAttempts returns a notification's send attempts, oldest first
+func (r *NotificationRepository) FindAttempts(ctx context.Context, notificationID string) ([]*domain.NotificationAttempt, error) {
+	query := `
+		SELECT id, notification_id, attempt_number, outcome, COALESCE(error, ''), attempted_at
+		FROM notification_attempts
+		WHERE notification_id = $1
+		ORDER BY attempted_at, attempt_number
+	`
+
+	rows, err := database.TracedQuery(ctx, r.db.Reader(), "NotificationRepository.FindAttempts", "notification_attempts", query, notificationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := []*domain.NotificationAttempt{}
+	for rows.Next() {
+		var attempt domain.NotificationAttempt
+		if err := rows.Scan(
+			&attempt.ID,
+			&attempt.NotificationID,
+			&attempt.AttemptNumber,
+			&attempt.Outcome,
+			&attempt.Error,
+			&attempt.AttemptedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan notification attempt: %w", err)
+		}
+		attempts = append(attempts, &attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification attempts: %w", err)
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,164 @@
+//go:build integration
+
+package infrastructure
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/database"
+)
+
+func TestFindByStatusAndRange(t *testing.T) {
+	repo := NewNotificationRepository(newTestDB(t))
+	ctx := context.Background()
+
+	// A window of its own, far in the past, so other tests' notifications stay out of it
+	windowStart := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(rand.Int63n(int64(24 * 365 * time.Hour))))
+	windowEnd := windowStart.Add(time.Hour)
+
+	save := func(status domain.NotificationStatus, createdAt time.Time) string {
+		notification := newTestNotification(t)
+		notification.Status = status
+		notification.CreatedAt = createdAt
+		notification.UpdatedAt = createdAt
+		if err := repo.Save(ctx, notification); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		return notification.ID
+	}
+
+	older := save(domain.FailedStatus, windowStart)
+	newer := save(domain.FailedStatus, windowStart.Add(30*time.Minute))
+	save(domain.SentStatus, windowStart.Add(10*time.Minute)) // Other status
+	save(domain.FailedStatus, windowStart.Add(-time.Minute)) // Before the window
+	save(domain.FailedStatus, windowEnd)                     // The window's end is exclusive
+
+	found, err := repo.FindByStatusAndRange(ctx, domain.FailedStatus, windowStart, windowEnd, 10, 0)
+	if err != nil {
+		t.Fatalf("FindByStatusAndRange: %v", err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("found %d notifications, want 2", len(found))
+	}
+	if found[0].ID != newer || found[1].ID != older {
+		t.Errorf("found %s, %s; want newest first: %s, %s", found[0].ID, found[1].ID, newer, older)
+	}
+
+	page, err := repo.FindByStatusAndRange(ctx, domain.FailedStatus, windowStart, windowEnd, 1, 1)
+	if err != nil {
+		t.Fatalf("FindByStatusAndRange: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != older {
+		t.Errorf("second page = %v, want only %s", page, older)
+	}
+}
+
+// storedError returns the notification's error and subject columns, nil when NULL
+func storedError(t *testing.T, db *database.DB, id string) (errorText, subject *string) {
+	t.Helper()
+
+	err := db.QueryRow(context.Background(), "SELECT error, subject FROM notifications WHERE id = $1", id).Scan(&errorText, &subject)
+	if err != nil {
+		t.Fatalf("failed to read notification %s: %v", id, err)
+	}
+	return errorText, subject
+}
+
+func TestEmptyOptionalFieldsStoredAsNull(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewNotificationRepository(db)
+	ctx := context.Background()
+
+	notification := newTestNotification(t)
+	notification.Subject = ""
+	if _, err := repo.Create(ctx, notification); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if errorText, subject := storedError(t, db, notification.ID); errorText != nil || subject != nil {
+		t.Errorf("after Create: error=%v subject=%v, want both NULL", errorText, subject)
+	}
+
+	if err := repo.UpdateStatus(ctx, notification.ID, domain.FailedStatus, "SNS publish failed"); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	if errorText, _ := storedError(t, db, notification.ID); errorText == nil || *errorText != "SNS publish failed" {
+		t.Errorf("after a failure: error=%v, want the error text", errorText)
+	}
+
+	if err := repo.UpdateStatus(ctx, notification.ID, domain.SentStatus, ""); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	if errorText, _ := storedError(t, db, notification.ID); errorText != nil {
+		t.Errorf("after an empty error: error=%q, want NULL", *errorText)
+	}
+
+	notification.Error = ""
+	if err := repo.Save(ctx, notification); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if errorText, subject := storedError(t, db, notification.ID); errorText != nil || subject != nil {
+		t.Errorf("after Save: error=%v subject=%v, want both NULL", errorText, subject)
+	}
+}
+
+func TestFlagUndelivered(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewNotificationRepository(db)
+	ctx := context.Background()
+
+	saveSent := func(sentAgo time.Duration) string {
+		notification := newTestNotification(t)
+		notification.MarkAsSent()
+		sentAt := time.Now().UTC().Add(-sentAgo)
+		notification.SentAt = &sentAt
+		if err := repo.Save(ctx, notification); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		return notification.ID
+	}
+	undelivered := func(id string) bool {
+		var flagged bool
+		if err := db.QueryRow(ctx, "SELECT undelivered_at IS NOT NULL FROM notifications WHERE id = $1", id).Scan(&flagged); err != nil {
+			t.Fatalf("failed to read notification %s: %v", id, err)
+		}
+		return flagged
+	}
+
+	recent := saveSent(time.Minute)
+	old := saveSent(2 * time.Hour)
+
+	flagged, err := repo.FlagUndelivered(ctx, time.Hour)
+	if err != nil {
+		t.Fatalf("FlagUndelivered: %v", err)
+	}
+	// Other tests' old sends may be flagged alongside
+	if flagged[domain.EmailNotification] < 1 {
+		t.Errorf("flagged %v, want at least one email", flagged)
+	}
+	if !undelivered(old) {
+		t.Error("notification sent past the grace window was not flagged")
+	}
+	if undelivered(recent) {
+		t.Error("notification sent within the grace window was flagged")
+	}
+
+	// Each notification is flagged once
+	var flaggedAt time.Time
+	if err := db.QueryRow(ctx, "SELECT undelivered_at FROM notifications WHERE id = $1", old).Scan(&flaggedAt); err != nil {
+		t.Fatalf("failed to read notification %s: %v", old, err)
+	}
+	if _, err := repo.FlagUndelivered(ctx, time.Hour); err != nil {
+		t.Fatalf("FlagUndelivered: %v", err)
+	}
+	var reflaggedAt time.Time
+	if err := db.QueryRow(ctx, "SELECT undelivered_at FROM notifications WHERE id = $1", old).Scan(&reflaggedAt); err != nil {
+		t.Fatalf("failed to read notification %s: %v", old, err)
+	}
+	if !reflaggedAt.Equal(flaggedAt) {
+		t.Errorf("undelivered_at moved from %v to %v on a second sweep", flaggedAt, reflaggedAt)
+	}
+}
@@ -0,0 +1,162 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/database"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TemplateRepository serves notification templates from the notification_templates table through
+// an in-memory cache, falling back per key to the built-in templates. Until the first Refresh, or
+// if the table is unreachable, the built-in templates are served alone.
+type TemplateRepository struct {
+	db        *database.DB
+	mu        sync.RWMutex
+	templates map[domain.TemplateKey]*domain.NotificationTemplate
+}
+
+// NewTemplateRepository creates a new template repository
+func NewTemplateRepository(db *database.DB) *TemplateRepository {
+	return &TemplateRepository{db: db}
+}
+
+// Get returns the most specific template for an event type, notification type and recipient from
+// the cache, or nil if there is none
+func (r *TemplateRepository) Get(eventType string, notificationType domain.NotificationType, recipient domain.RecipientAttributes) *domain.NotificationTemplate {
+	r.mu.RLock()
+	templates := r.templates
+	r.mu.RUnlock()
+
+	return domain.SelectTemplate(func(key domain.TemplateKey) *domain.NotificationTemplate {
+		if template, exists := templates[key]; exists {
+			return template
+		}
+		return domain.BuiltinTemplate(key)
+	}, eventType, notificationType, recipient)
+}
+
+// Seed inserts the built-in templates missing from the table. Rows already present are left alone,
+// so templates edited in the database survive restarts.
+func (r *TemplateRepository) Seed(ctx context.Context) error {
+	query := `
+		INSERT INTO notification_templates (event_type, notification_type, tier, locale, subject_template, body_template, html_body_template, priority, max_retries, defaults, attachments)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (event_type, notification_type, tier, locale) DO NOTHING
+	`
+
+	seeded := 0
+	for key, template := range domain.BuiltinTemplates() {
+		defaults, attachments, err := marshalTemplateExtras(template)
+		if err != nil {
+			return err
+		}
+
+		tag, err := r.db.Exec(ctx, query,
+			key.EventType,
+			string(key.NotificationType),
+			key.Tier,
+			key.Locale,
+			template.SubjectTemplate,
+			template.BodyTemplate,
+			template.HTMLBodyTemplate,
+			template.Priority,
+			template.MaxRetries,
+			defaults,
+			attachments,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to seed template: %w", err)
+		}
+		seeded += int(tag.RowsAffected())
+	}
+
+	if seeded > 0 {
+		logrus.WithField("count", seeded).Info("Seeded notification templates")
+	}
+	return nil
+}
+
+// Refresh reloads every template from the table into the cache
+func (r *TemplateRepository) Refresh(ctx context.Context) error {
+	query := `
+		SELECT event_type, notification_type, tier, locale, subject_template, body_template, html_body_template, priority, max_retries, defaults, attachments
+		FROM notification_templates
+	`
+
+	rows, err := r.db.Reader().Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to query templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make(map[domain.TemplateKey]*domain.NotificationTemplate)
+	for rows.Next() {
+		var key domain.TemplateKey
+		var template domain.NotificationTemplate
+		var defaults, attachments []byte
+
+		if err := rows.Scan(
+			&key.EventType,
+			&key.NotificationType,
+			&key.Tier,
+			&key.Locale,
+			&template.SubjectTemplate,
+			&template.BodyTemplate,
+			&template.HTMLBodyTemplate,
+			&template.Priority,
+			&template.MaxRetries,
+			&defaults,
+			&attachments,
+		); err != nil {
+			return fmt.Errorf("failed to scan template: %w", err)
+		}
+
+		template.EventType, template.NotificationType = key.EventType, key.NotificationType
+		if len(defaults) > 0 {
+			if err := json.Unmarshal(defaults, &template.Defaults); err != nil {
+				return fmt.Errorf("failed to unmarshal template defaults: %w", err)
+			}
+		}
+		if len(attachments) > 0 {
+			if err := json.Unmarshal(attachments, &template.Attachments); err != nil {
+				return fmt.Errorf("failed to unmarshal template attachments: %w", err)
+			}
+		}
+		templates[key] = &template
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating templates: %w", err)
+	}
+
+	r.mu.Lock()
+	r.templates = templates
+	r.mu.Unlock()
+
+	logrus.WithField("count", len(templates)).Debug("Notification templates refreshed")
+	return nil
+}
+
+// marshalTemplateExtras encodes a template's defaults and attachments for their JSONB columns,
+// leaving an absent one NULL
+func marshalTemplateExtras(template *domain.NotificationTemplate) ([]byte, []byte, error) {
+	var defaults, attachments []byte
+	var err error
+	if len(template.Defaults) > 0 {
+		if defaults, err = json.Marshal(template.Defaults); err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal template defaults: %w", err)
+		}
+	}
+	if len(template.Attachments) > 0 {
+		if attachments, err = json.Marshal(template.Attachments); err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal template attachments: %w", err)
+		}
+	}
+	return defaults, attachments, nil
+}
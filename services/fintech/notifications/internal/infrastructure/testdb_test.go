@@ -0,0 +1,67 @@
+//go:build integration
+
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"fintech/notifications-service/internal/domain"
+	"fintech/notifications-service/pkg/database"
+
+	"github.com/google/uuid"
+)
+
+// migrationLock serializes RunMigrations across test packages sharing TEST_DATABASE_URL
+const migrationLock = 742001
+
+// newTestDB connects to TEST_DATABASE_URL and migrates it, skipping the test when it isn't set.
+// Tests share the database, so each one works on its own events and accounts.
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := database.NewConnection(url)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	ctx := context.Background()
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("failed to acquire connection: %v", err)
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLock); err != nil {
+		t.Fatalf("failed to take migration lock: %v", err)
+	}
+	defer conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", migrationLock)
+
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	return db
+}
+
+// newID returns a fresh event or account ID, so tests don't see each other's rows
+func newID(prefix string) string {
+	return prefix + "-" + uuid.New().String()
+}
+
+// newTestNotification returns an unsaved pending email notification for a fresh event
+func newTestNotification(t *testing.T) *domain.Notification {
+	t.Helper()
+
+	notification, err := domain.NewNotification(newID("evt"), "PaymentInitiated", domain.EmailNotification, "jane@example.com", "Subject", "Body", 1, 3)
+	if err != nil {
+		t.Fatalf("NewNotification: %v", err)
+	}
+	return notification
+}
@@ -0,0 +1,2 @@
+-- Support searching notifications by status within a time window
+CREATE INDEX idx_notifications_status_created ON notifications(status, created_at);
@@ -0,0 +1,11 @@
+-- Record every send attempt so intermittent failures can be debugged
+CREATE TABLE notification_attempts (
+    id UUID PRIMARY KEY,
+    notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    attempt_number INTEGER NOT NULL,
+    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('SUCCEEDED', 'RETRYING', 'FAILED')),
+    error TEXT,
+    attempted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notification_attempts_notification_id ON notification_attempts(notification_id, attempted_at);
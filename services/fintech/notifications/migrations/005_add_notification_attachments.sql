@@ -0,0 +1,2 @@
+-- Email attachment references (URLs or S3 keys, not file contents)
+ALTER TABLE notifications ADD COLUMN attachments JSONB;
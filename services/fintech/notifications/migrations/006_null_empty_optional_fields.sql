@@ -0,0 +1,3 @@
+-- Empty optional fields are now stored as NULL; normalize existing rows
+UPDATE notifications SET subject = NULL WHERE subject = '';
+UPDATE notifications SET error = NULL WHERE error = '';
@@ -0,0 +1,16 @@
+-- Notifications suppressed by a mute are recorded as MUTED
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_status_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_status_check
+    CHECK (status IN ('PENDING', 'SENT', 'FAILED', 'DELIVERED', 'MUTED'));
+
+-- Incident mutes; a NULL account_id mutes every account
+CREATE TABLE notification_mutes (
+    id UUID PRIMARY KEY,
+    account_id VARCHAR(255),
+    reason TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notification_mutes_account_expires ON notification_mutes(account_id, expires_at);
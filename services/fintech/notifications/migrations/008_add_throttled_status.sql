@@ -0,0 +1,4 @@
+-- Notifications skipped by the per-recipient throttle are recorded as THROTTLED
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_status_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_status_check
+    CHECK (status IN ('PENDING', 'SENT', 'FAILED', 'DELIVERED', 'MUTED', 'THROTTLED'));
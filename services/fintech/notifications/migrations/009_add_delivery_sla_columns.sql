@@ -0,0 +1,6 @@
+-- Delivery SLA deadline and when a breach of it was flagged
+ALTER TABLE notifications
+    ADD COLUMN deliver_by TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN sla_breached_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_notifications_deliver_by_unflagged ON notifications(deliver_by) WHERE sla_breached_at IS NULL;
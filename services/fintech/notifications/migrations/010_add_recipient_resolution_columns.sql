@@ -0,0 +1,5 @@
+-- Account whose contact details the recipient came from, and the recipient at creation when it
+-- was re-resolved at send time
+ALTER TABLE notifications
+    ADD COLUMN account_id VARCHAR(255),
+    ADD COLUMN original_recipient VARCHAR(255);
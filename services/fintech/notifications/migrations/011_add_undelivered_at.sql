@@ -0,0 +1,4 @@
+-- When a SENT notification was flagged for never getting a delivery receipt
+ALTER TABLE notifications ADD COLUMN undelivered_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_notifications_sent_unflagged ON notifications(sent_at) WHERE status = 'SENT' AND undelivered_at IS NULL;
@@ -0,0 +1,15 @@
+-- Notification templates served through the template cache; an empty tier or locale matches any recipient
+CREATE TABLE notification_templates (
+    event_type VARCHAR(100) NOT NULL,
+    notification_type VARCHAR(20) NOT NULL,
+    tier VARCHAR(50) NOT NULL DEFAULT '',
+    locale VARCHAR(35) NOT NULL DEFAULT '',
+    subject_template TEXT NOT NULL DEFAULT '',
+    body_template TEXT NOT NULL,
+    priority INTEGER NOT NULL,
+    max_retries INTEGER NOT NULL,
+    defaults JSONB,
+    attachments JSONB,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (event_type, notification_type, tier, locale)
+);
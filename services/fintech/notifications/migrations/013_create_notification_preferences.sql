@@ -0,0 +1,8 @@
+-- Per-account channel preferences; accounts without a row get every channel
+CREATE TABLE notification_preferences (
+    account_id VARCHAR(255) PRIMARY KEY,
+    email_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    sms_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    push_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+-- HTML variant of email bodies, sent alongside the text body, and the templates rendering it
+ALTER TABLE notifications ADD COLUMN html_body TEXT;
+ALTER TABLE notification_templates ADD COLUMN html_body_template TEXT NOT NULL DEFAULT '';
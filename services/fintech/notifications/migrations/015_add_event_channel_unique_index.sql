@@ -0,0 +1,3 @@
+-- One notification per event and channel, so a redelivered event cannot create duplicates.
+-- Fails if duplicates already exist; remove them before applying.
+CREATE UNIQUE INDEX idx_notifications_event_channel ON notifications(event_id, event_type, type);
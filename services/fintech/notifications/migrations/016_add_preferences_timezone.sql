@@ -0,0 +1,2 @@
+-- Recipient timezone for quiet hours; empty uses QUIET_HOURS_DEFAULT_TIMEZONE
+ALTER TABLE notification_preferences ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Until when a retry worker has claimed a pending notification, so other replicas skip it
+ALTER TABLE notifications ADD COLUMN retry_claimed_until TIMESTAMP WITH TIME ZONE;
@@ -13,14 +13,61 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// messageAttribute is a transport-neutral message attribute
+type messageAttribute struct {
+	dataType string // "String" or "Number"
+	value    string
+}
+
+// attributesFor returns the message attributes used for filtering and routing a notification,
+// shared by SNS and SQS so both transports always carry the same set
+func attributesFor(notification *domain.Notification) map[string]messageAttribute {
+	return map[string]messageAttribute{
+		"notification_type": {dataType: "String", value: string(notification.Type)},
+		"event_type":        {dataType: "String", value: notification.EventType},
+		"priority":          {dataType: "Number", value: fmt.Sprintf("%d", notification.Priority)},
+	}
+}
+
+// snsAttributes converts neutral attributes to SNS message attributes
+func snsAttributes(attributes map[string]messageAttribute) map[string]*sns.MessageAttributeValue {
+	converted := make(map[string]*sns.MessageAttributeValue, len(attributes))
+	for name, attribute := range attributes {
+		converted[name] = &sns.MessageAttributeValue{
+			DataType:    aws.String(attribute.dataType),
+			StringValue: aws.String(attribute.value),
+		}
+	}
+	return converted
+}
+
+// sqsAttributes converts neutral attributes to SQS message attributes
+func sqsAttributes(attributes map[string]messageAttribute) map[string]*sqs.MessageAttributeValue {
+	converted := make(map[string]*sqs.MessageAttributeValue, len(attributes))
+	for name, attribute := range attributes {
+		converted[name] = &sqs.MessageAttributeValue{
+			DataType:    aws.String(attribute.dataType),
+			StringValue: aws.String(attribute.value),
+		}
+	}
+	return converted
+}
+
 // SNSClient handles SNS operations
 type SNSClient struct {
 	client   *sns.SNS
 	topicARN string
+
+	highPriorityTopicARN  string
+	highPriorityThreshold int
 }
 
-// NewSNSClient creates a new SNS client
-func NewSNSClient(config *aws.Config) (*SNSClient, error) {
+// NewSNSClient creates a new SNS client publishing to the topic with the given ARN
+func NewSNSClient(config *aws.Config, topicARN string) (*SNSClient, error) {
+	if topicARN == "" {
+		return nil, fmt.Errorf("SNS topic ARN is required")
+	}
+
 	sess, err := session.NewSession(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AWS session: %w", err)
@@ -28,15 +75,27 @@ func NewSNSClient(config *aws.Config) (*SNSClient, error) {
 
 	client := sns.New(sess)
 
-	// Extract topic ARN from config (this would be injected)
-	topicARN := config.Endpoint // This should be properly configured
-
 	return &SNSClient{
 		client:   client,
 		topicARN: topicARN,
 	}, nil
 }
 
+// WithHighPriorityTopic routes notifications with priority >= threshold to a dedicated topic
+func (c *SNSClient) WithHighPriorityTopic(topicARN string, threshold int) *SNSClient {
+	c.highPriorityTopicARN = topicARN
+	c.highPriorityThreshold = threshold
+	return c
+}
+
+// topicFor returns the topic a notification should be published to
+func (c *SNSClient) topicFor(notification *domain.Notification) string {
+	if c.highPriorityTopicARN != "" && notification.Priority >= c.highPriorityThreshold {
+		return c.highPriorityTopicARN
+	}
+	return c.topicARN
+}
+
 // PublishNotification publishes a notification to SNS with message attributes for filtering
 func (c *SNSClient) PublishNotification(notification *domain.Notification) error {
 	messageBytes, err := json.Marshal(notification)
@@ -46,26 +105,10 @@ func (c *SNSClient) PublishNotification(notification *domain.Notification) error
 
 	message := string(messageBytes)
 
-	// Create message attributes for filtering
-	messageAttributes := map[string]*sns.MessageAttributeValue{
-		"notification_type": {
-			DataType:    aws.String("String"),
-			StringValue: aws.String(string(notification.Type)),
-		},
-		"event_type": {
-			DataType:    aws.String("String"),
-			StringValue: aws.String(notification.EventType),
-		},
-		"priority": {
-			DataType:    aws.String("Number"),
-			StringValue: aws.String(fmt.Sprintf("%d", notification.Priority)),
-		},
-	}
-
 	input := &sns.PublishInput{
-		TopicArn:          aws.String(c.topicARN),
+		TopicArn:          aws.String(c.topicFor(notification)),
 		Message:           aws.String(message),
-		MessageAttributes: messageAttributes,
+		MessageAttributes: snsAttributes(attributesFor(notification)),
 	}
 
 	_, err = c.client.Publish(input)
@@ -113,16 +156,7 @@ func (c *SQSClient) SendMessage(queueURL string, notification *domain.Notificati
 	input := &sqs.SendMessageInput{
 		QueueUrl:    aws.String(queueURL),
 		MessageBody: aws.String(messageBody),
-		MessageAttributes: map[string]*sqs.MessageAttributeValue{
-			"notification_type": {
-				DataType:    aws.String("String"),
-				StringValue: aws.String(string(notification.Type)),
-			},
-			"event_type": {
-				DataType:    aws.String("String"),
-				StringValue: aws.String(notification.EventType),
-			},
-		},
+		MessageAttributes: sqsAttributes(attributesFor(notification)),
 	}
 
 	_, err = c.client.SendMessage(input)
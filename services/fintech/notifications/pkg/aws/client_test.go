@@ -0,0 +1,128 @@
+package aws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fintech/notifications-service/internal/domain"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+func testNotification() *domain.Notification {
+	return &domain.Notification{
+		ID:        "notification-1",
+		EventType: "PaymentFailed",
+		Type:      domain.SMSNotification,
+		Priority:  3,
+	}
+}
+
+func TestAttributesFor(t *testing.T) {
+	want := map[string]messageAttribute{
+		"notification_type": {dataType: "String", value: "SMS"},
+		"event_type":        {dataType: "String", value: "PaymentFailed"},
+		"priority":          {dataType: "Number", value: "3"},
+	}
+
+	got := attributesFor(testNotification())
+	if len(got) != len(want) {
+		t.Fatalf("attributesFor returned %d attributes, want %d: %+v", len(got), len(want), got)
+	}
+	for name, attribute := range want {
+		if got[name] != attribute {
+			t.Errorf("attribute %s = %+v, want %+v", name, got[name], attribute)
+		}
+	}
+}
+
+func TestSNSAndSQSAttributesMatch(t *testing.T) {
+	attributes := attributesFor(testNotification())
+	snsAttrs := snsAttributes(attributes)
+	sqsAttrs := sqsAttributes(attributes)
+
+	if len(snsAttrs) != len(attributes) || len(sqsAttrs) != len(attributes) {
+		t.Fatalf("got %d SNS and %d SQS attributes, want %d each", len(snsAttrs), len(sqsAttrs), len(attributes))
+	}
+	for name, attribute := range attributes {
+		snsAttr, sqsAttr := snsAttrs[name], sqsAttrs[name]
+		if snsAttr == nil || sqsAttr == nil {
+			t.Errorf("attribute %s missing: SNS %v, SQS %v", name, snsAttr, sqsAttr)
+			continue
+		}
+		if aws.StringValue(snsAttr.DataType) != attribute.dataType || aws.StringValue(snsAttr.StringValue) != attribute.value {
+			t.Errorf("SNS attribute %s = %s %q, want %s %q", name, aws.StringValue(snsAttr.DataType), aws.StringValue(snsAttr.StringValue), attribute.dataType, attribute.value)
+		}
+		if aws.StringValue(sqsAttr.DataType) != attribute.dataType || aws.StringValue(sqsAttr.StringValue) != attribute.value {
+			t.Errorf("SQS attribute %s = %s %q, want %s %q", name, aws.StringValue(sqsAttr.DataType), aws.StringValue(sqsAttr.StringValue), attribute.dataType, attribute.value)
+		}
+	}
+}
+
+// stubSNS answers SNS Publish calls, recording the topic ARN of each
+func stubSNS(t *testing.T) (*aws.Config, *[]string) {
+	t.Helper()
+
+	var topics []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse SNS request: %v", err)
+		}
+		if action := r.PostForm.Get("Action"); action != "Publish" {
+			t.Errorf("SNS action = %q, want Publish", action)
+		}
+		topics = append(topics, r.PostForm.Get("TopicArn"))
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<PublishResponse xmlns="http://sns.amazonaws.com/doc/2010-03-31/"><PublishResult><MessageId>message-1</MessageId></PublishResult></PublishResponse>`))
+	}))
+	t.Cleanup(server.Close)
+
+	config := &aws.Config{
+		Region:      aws.String("us-east-1"),
+		Endpoint:    aws.String(server.URL),
+		Credentials: credentials.NewStaticCredentials("test", "test", ""),
+	}
+	return config, &topics
+}
+
+func TestPublishNotificationUsesConfiguredTopicARN(t *testing.T) {
+	const (
+		topicARN     = "arn:aws:sns:us-east-1:000000000000:payment-notifications"
+		highPriority = "arn:aws:sns:us-east-1:000000000000:payment-notifications-urgent"
+	)
+	config, topics := stubSNS(t)
+
+	client, err := NewSNSClient(config, topicARN)
+	if err != nil {
+		t.Fatalf("NewSNSClient: %v", err)
+	}
+	client.WithHighPriorityTopic(highPriority, 3)
+
+	notification := testNotification()
+	notification.Priority = 1
+	if err := client.PublishNotification(notification); err != nil {
+		t.Fatalf("PublishNotification: %v", err)
+	}
+	notification.Priority = 3
+	if err := client.PublishNotification(notification); err != nil {
+		t.Fatalf("PublishNotification: %v", err)
+	}
+
+	want := []string{topicARN, highPriority}
+	if len(*topics) != len(want) {
+		t.Fatalf("published to %v, want %v", *topics, want)
+	}
+	for i, topic := range want {
+		if (*topics)[i] != topic {
+			t.Errorf("publish %d TopicArn = %q, want %q, not the endpoint %q", i+1, (*topics)[i], topic, aws.StringValue(config.Endpoint))
+		}
+	}
+}
+
+func TestNewSNSClientRequiresTopicARN(t *testing.T) {
+	if _, err := NewSNSClient(&aws.Config{Region: aws.String("us-east-1")}, ""); err == nil {
+		t.Error("expected an error without a topic ARN")
+	}
+}
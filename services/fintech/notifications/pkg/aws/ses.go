@@ -0,0 +1,239 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path"
+	"time"
+
+	"fintech/notifications-service/internal/domain"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/sirupsen/logrus"
+)
+
+// maxRawMessageBytes is the SES limit on the size of a raw message, attachments included
+const maxRawMessageBytes = 10 << 20
+
+// Attachment is a fetched attachment ready to be encoded into an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// SESClient sends email notifications through SES, fetching referenced attachments at send time
+type SESClient struct {
+	client     *ses.SES
+	s3         *s3.S3
+	httpClient *http.Client
+	sender     string
+	bucket     string
+}
+
+// NewSESClient creates a new SES client. S3 attachment keys are resolved against bucket.
+func NewSESClient(config *aws.Config, sender, bucket string, fetchTimeout time.Duration) (*SESClient, error) {
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	return &SESClient{
+		client:     ses.New(sess),
+		s3:         s3.New(sess, aws.NewConfig().WithS3ForcePathStyle(true)),
+		httpClient: &http.Client{Timeout: fetchTimeout},
+		sender:     sender,
+		bucket:     bucket,
+	}, nil
+}
+
+// SendEmail sends an email notification with its attachments
+func (c *SESClient) SendEmail(ctx context.Context, notification *domain.Notification) error {
+	attachments := make([]Attachment, 0, len(notification.Attachments))
+	for _, ref := range notification.Attachments {
+		attachment, err := c.fetchAttachment(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("failed to fetch attachment %s: %w", ref.Filename, err)
+		}
+		attachments = append(attachments, *attachment)
+	}
+
+	raw, err := BuildRawMessage(c.sender, notification, attachments)
+	if err != nil {
+		return err
+	}
+	if len(raw) > maxRawMessageBytes {
+		return fmt.Errorf("email of %d bytes exceeds the SES limit", len(raw))
+	}
+
+	_, err = c.client.SendRawEmailWithContext(ctx, &ses.SendRawEmailInput{
+		Source:       aws.String(c.sender),
+		Destinations: []*string{aws.String(notification.Recipient)},
+		RawMessage:   &ses.RawMessage{Data: raw},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send email via SES: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"notification_id": notification.ID,
+		"attachments":     len(attachments),
+	}).Debug("Sent email via SES")
+
+	return nil
+}
+
+// fetchAttachment downloads a referenced attachment from its URL or S3 key
+func (c *SESClient) fetchAttachment(ctx context.Context, ref domain.AttachmentRef) (*Attachment, error) {
+	if err := ref.Validate(); err != nil {
+		return nil, err
+	}
+
+	var body io.ReadCloser
+	contentType := ref.ContentType
+	if ref.S3Key != "" {
+		output, err := c.s3.GetObjectWithContext(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(c.bucket),
+			Key:    aws.String(ref.S3Key),
+		})
+		if err != nil {
+			return nil, err
+		}
+		body = output.Body
+		if contentType == "" && output.ContentType != nil {
+			contentType = *output.ContentType
+		}
+	} else {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ref.URL, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		body = resp.Body
+		if contentType == "" {
+			contentType = resp.Header.Get("Content-Type")
+		}
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, maxRawMessageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxRawMessageBytes {
+		return nil, fmt.Errorf("attachment exceeds %d bytes", maxRawMessageBytes)
+	}
+
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(ref.Filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &Attachment{Filename: ref.Filename, ContentType: contentType, Data: data}, nil
+}
+
+// BuildRawMessage builds a MIME email for a notification: the body as text/plain (wrapped in a
+// multipart/alternative with the text/html body when there is one), followed by one
+// base64-encoded part per attachment
+func BuildRawMessage(from string, notification *domain.Notification, attachments []Attachment) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", notification.Recipient)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Subject))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", writer.Boundary())
+
+	if notification.HTMLBody == "" {
+		if err := writeTextPart(writer, "text/plain", notification.Body); err != nil {
+			return nil, err
+		}
+	} else {
+		var alternative bytes.Buffer
+		bodies := multipart.NewWriter(&alternative)
+		if err := writeTextPart(bodies, "text/plain", notification.Body); err != nil {
+			return nil, err
+		}
+		if err := writeTextPart(bodies, "text/html", notification.HTMLBody); err != nil {
+			return nil, err
+		}
+		if err := bodies.Close(); err != nil {
+			return nil, fmt.Errorf("failed to finish body part: %w", err)
+		}
+
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": bodies.Boundary()})},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create body part: %w", err)
+		}
+		if _, err := part.Write(alternative.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, attachment := range attachments {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create attachment part: %w", err)
+		}
+		if err := writeBase64(part, attachment.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish email: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeTextPart writes a base64-encoded UTF-8 body part of the given media type
+func writeTextPart(writer *multipart.Writer, mediaType, body string) error {
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mediaType + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create %s part: %w", mediaType, err)
+	}
+	return writeBase64(part, []byte(body))
+}
+
+// writeBase64 writes data base64-encoded in 76-character lines, as MIME requires
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(w, encoded[:76]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := io.WriteString(w, encoded+"\r\n")
+	return err
+}
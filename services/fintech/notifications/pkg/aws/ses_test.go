@@ -0,0 +1,124 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"testing"
+
+	"fintech/notifications-service/internal/domain"
+)
+
+// messagePart is a decoded part of a multipart/mixed message
+type messagePart struct {
+	ContentType string
+	Filename    string
+	Data        []byte
+}
+
+// parseRawMessage decodes the top-level parts of a message built by BuildRawMessage
+func parseRawMessage(t *testing.T, raw []byte) []messagePart {
+	t.Helper()
+
+	message, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("message Content-Type = %q, want multipart/mixed", message.Header.Get("Content-Type"))
+	}
+
+	var parts []messagePart
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		if err != nil {
+			t.Fatalf("failed to read part: %v", err)
+		}
+
+		data, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("failed to read part body: %v", err)
+		}
+		if part.Header.Get("Content-Transfer-Encoding") == "base64" {
+			if data, err = base64.StdEncoding.DecodeString(string(bytes.ReplaceAll(data, []byte("\r\n"), nil))); err != nil {
+				t.Fatalf("failed to decode part: %v", err)
+			}
+		}
+		parts = append(parts, messagePart{ContentType: part.Header.Get("Content-Type"), Filename: part.FileName(), Data: data})
+	}
+}
+
+func TestBuildRawMessageWithAttachments(t *testing.T) {
+	notification := &domain.Notification{Recipient: "jane@example.com", Subject: "Payment Completed", Body: "Your payment has completed."}
+	receipt := bytes.Repeat([]byte("%PDF-1.4 receipt "), 10)
+
+	raw, err := BuildRawMessage("noreply@fintech.com", notification, []Attachment{
+		{Filename: "receipt-pay-1.pdf", ContentType: "application/pdf", Data: receipt},
+	})
+	if err != nil {
+		t.Fatalf("BuildRawMessage: %v", err)
+	}
+
+	parts := parseRawMessage(t, raw)
+	if len(parts) != 2 {
+		t.Fatalf("message has %d parts, want the body and one attachment", len(parts))
+	}
+	if parts[0].ContentType != "text/plain; charset=utf-8" || string(parts[0].Data) != notification.Body {
+		t.Errorf("body part = %s %q, want the text body", parts[0].ContentType, parts[0].Data)
+	}
+	if parts[1].ContentType != "application/pdf" || parts[1].Filename != "receipt-pay-1.pdf" || !bytes.Equal(parts[1].Data, receipt) {
+		t.Errorf("attachment part = %s %q (%d bytes), want the receipt", parts[1].ContentType, parts[1].Filename, len(parts[1].Data))
+	}
+}
+
+func TestBuildRawMessageWithoutAttachments(t *testing.T) {
+	notification := &domain.Notification{Recipient: "jane@example.com", Subject: "Payment Completed", Body: "Your payment has completed."}
+
+	raw, err := BuildRawMessage("noreply@fintech.com", notification, nil)
+	if err != nil {
+		t.Fatalf("BuildRawMessage: %v", err)
+	}
+
+	if parts := parseRawMessage(t, raw); len(parts) != 1 {
+		t.Errorf("message has %d parts, want only the body", len(parts))
+	}
+}
+
+func TestFetchAttachmentFromURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/receipts/pay-1.pdf" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.4"))
+	}))
+	defer server.Close()
+	c := &SESClient{httpClient: server.Client()}
+
+	attachment, err := c.fetchAttachment(context.Background(), domain.AttachmentRef{Filename: "receipt.pdf", URL: server.URL + "/receipts/pay-1.pdf"})
+	if err != nil {
+		t.Fatalf("fetchAttachment: %v", err)
+	}
+	if attachment.Filename != "receipt.pdf" || attachment.ContentType != "application/pdf" || string(attachment.Data) != "%PDF-1.4" {
+		t.Errorf("attachment = %s %s %q", attachment.Filename, attachment.ContentType, attachment.Data)
+	}
+
+	if _, err := c.fetchAttachment(context.Background(), domain.AttachmentRef{Filename: "missing.pdf", URL: server.URL + "/missing.pdf"}); err == nil {
+		t.Error("expected an error for a missing attachment")
+	}
+	if _, err := c.fetchAttachment(context.Background(), domain.AttachmentRef{Filename: "receipt.pdf"}); err == nil {
+		t.Error("expected an error for a reference without a source")
+	}
+}
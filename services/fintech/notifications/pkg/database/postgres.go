@@ -2,7 +2,6 @@ package database
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -10,13 +9,51 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// DB represents database connection
+// DB represents database connection. Writes and reads that need fresh data go through the
+// embedded primary pool; read-only queries that tolerate replication lag go through Reader.
 type DB struct {
 	*pgxpool.Pool
+	replica *pgxpool.Pool // nil when no replica is configured
 }
 
 // NewConnection creates a new PostgreSQL connection pool
 func NewConnection(databaseURL string) (*DB, error) {
+	pool, err := connect(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Info("Successfully connected to PostgreSQL")
+	return &DB{Pool: pool}, nil
+}
+
+// ConnectReplica opens a pool to a read-only replica that Reader routes queries to. An empty URL
+// leaves reads on the primary.
+func (db *DB) ConnectReplica(replicaURL string) error {
+	if replicaURL == "" {
+		return nil
+	}
+
+	pool, err := connect(replicaURL)
+	if err != nil {
+		return fmt.Errorf("replica: %w", err)
+	}
+
+	db.replica = pool
+	logrus.Info("Successfully connected to PostgreSQL replica")
+	return nil
+}
+
+// Reader returns the pool for read-only queries: the replica if one is configured, otherwise the primary
+func (db *DB) Reader() *pgxpool.Pool {
+	if db.replica != nil {
+		return db.replica
+	}
+	return db.Pool
+}
+
+// connect creates and pings a connection pool
+func connect(databaseURL string) (*pgxpool.Pool, error) {
 	config, err := pgxpool.ParseConfig(databaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse database URL: %w", err)
@@ -37,8 +74,7 @@ func NewConnection(databaseURL string) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	logrus.Info("Successfully connected to PostgreSQL")
-	return &DB{pool}, nil
+	return pool, nil
 }
 
 // RunMigrations runs database migrations
@@ -60,7 +96,7 @@ func RunMigrations(db *DB) error {
 			recipient VARCHAR(255) NOT NULL,
 			subject VARCHAR(500),
 			body TEXT NOT NULL,
-			status VARCHAR(20) NOT NULL CHECK (status IN ('PENDING', 'SENT', 'FAILED', 'DELIVERED')),
+			status VARCHAR(20) NOT NULL CHECK (status IN ('PENDING', 'SENT', 'FAILED', 'DELIVERED', 'MUTED', 'THROTTLED')),
 			priority INTEGER NOT NULL DEFAULT 1,
 			retry_count INTEGER NOT NULL DEFAULT 0,
 			max_retries INTEGER NOT NULL DEFAULT 3,
@@ -75,6 +111,167 @@ func RunMigrations(db *DB) error {
 		return fmt.Errorf("failed to create notifications table: %w", err)
 	}
 
+	// Email attachment references (URLs or S3 keys, not file contents)
+	_, err = db.Exec(ctx, `ALTER TABLE notifications ADD COLUMN IF NOT EXISTS attachments JSONB`)
+	if err != nil {
+		return fmt.Errorf("failed to add attachments column: %w", err)
+	}
+
+	// Delivery SLA deadline and when a breach of it was flagged
+	_, err = db.Exec(ctx, `
+		ALTER TABLE notifications
+			ADD COLUMN IF NOT EXISTS deliver_by TIMESTAMP WITH TIME ZONE,
+			ADD COLUMN IF NOT EXISTS sla_breached_at TIMESTAMP WITH TIME ZONE
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add SLA columns: %w", err)
+	}
+
+	// Account whose contact details the recipient came from, and the recipient at creation when
+	// it was re-resolved at send time
+	_, err = db.Exec(ctx, `
+		ALTER TABLE notifications
+			ADD COLUMN IF NOT EXISTS account_id VARCHAR(255),
+			ADD COLUMN IF NOT EXISTS original_recipient VARCHAR(255)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add recipient resolution columns: %w", err)
+	}
+
+	// When a SENT notification was flagged for never getting a delivery receipt
+	_, err = db.Exec(ctx, `ALTER TABLE notifications ADD COLUMN IF NOT EXISTS undelivered_at TIMESTAMP WITH TIME ZONE`)
+	if err != nil {
+		return fmt.Errorf("failed to add undelivered_at column: %w", err)
+	}
+
+	// HTML variant of email bodies, sent alongside the text body
+	_, err = db.Exec(ctx, `ALTER TABLE notifications ADD COLUMN IF NOT EXISTS html_body TEXT`)
+	if err != nil {
+		return fmt.Errorf("failed to add html_body column: %w", err)
+	}
+
+	// Until when a retry worker has claimed a pending notification, so other replicas skip it
+	_, err = db.Exec(ctx, `ALTER TABLE notifications ADD COLUMN IF NOT EXISTS retry_claimed_until TIMESTAMP WITH TIME ZONE`)
+	if err != nil {
+		return fmt.Errorf("failed to add retry_claimed_until column: %w", err)
+	}
+
+	// Allow the MUTED and THROTTLED statuses on tables created before they existed
+	_, err = db.Exec(ctx, `
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM pg_constraint
+				WHERE conname = 'notifications_status_check' AND pg_get_constraintdef(oid) LIKE '%THROTTLED%'
+			) THEN
+				ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_status_check;
+				ALTER TABLE notifications ADD CONSTRAINT notifications_status_check
+					CHECK (status IN ('PENDING', 'SENT', 'FAILED', 'DELIVERED', 'MUTED', 'THROTTLED'));
+			END IF;
+		END $$
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to update notifications status constraint: %w", err)
+	}
+
+	// Create notification mutes table (account_id NULL mutes every account)
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS notification_mutes (
+			id UUID PRIMARY KEY,
+			account_id VARCHAR(255),
+			reason TEXT NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			created_by VARCHAR(255) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create notification_mutes table: %w", err)
+	}
+
+	// Create notification attempts table
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS notification_attempts (
+			id UUID PRIMARY KEY,
+			notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+			attempt_number INTEGER NOT NULL,
+			outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('SUCCEEDED', 'RETRYING', 'FAILED')),
+			error TEXT,
+			attempted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create notification_attempts table: %w", err)
+	}
+
+	// Create manual status transition audit table
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS notification_transitions (
+			id UUID PRIMARY KEY,
+			notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+			from_status VARCHAR(20) NOT NULL,
+			to_status VARCHAR(20) NOT NULL,
+			operator VARCHAR(255) NOT NULL,
+			reason TEXT,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create notification_transitions table: %w", err)
+	}
+
+	// Create notification templates table; an empty tier or locale matches any recipient
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS notification_templates (
+			event_type VARCHAR(100) NOT NULL,
+			notification_type VARCHAR(20) NOT NULL,
+			tier VARCHAR(50) NOT NULL DEFAULT '',
+			locale VARCHAR(35) NOT NULL DEFAULT '',
+			subject_template TEXT NOT NULL DEFAULT '',
+			body_template TEXT NOT NULL,
+			html_body_template TEXT NOT NULL DEFAULT '',
+			priority INTEGER NOT NULL,
+			max_retries INTEGER NOT NULL,
+			defaults JSONB,
+			attachments JSONB,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (event_type, notification_type, tier, locale)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create notification_templates table: %w", err)
+	}
+	_, err = db.Exec(ctx, `ALTER TABLE notification_templates ADD COLUMN IF NOT EXISTS html_body_template TEXT NOT NULL DEFAULT ''`)
+	if err != nil {
+		return fmt.Errorf("failed to add html_body_template column: %w", err)
+	}
+
+	// Create per-account channel preferences table; accounts without a row get every channel
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS notification_preferences (
+			account_id VARCHAR(255) PRIMARY KEY,
+			email_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+			sms_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+			push_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+			timezone VARCHAR(64) NOT NULL DEFAULT '',
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create notification_preferences table: %w", err)
+	}
+	_, err = db.Exec(ctx, `ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT ''`)
+	if err != nil {
+		return fmt.Errorf("failed to add preferences timezone column: %w", err)
+	}
+
+	// One notification per event and channel, so a redelivered event cannot create duplicates.
+	// Creating it fails if duplicates already exist; remove them before upgrading.
+	_, err = db.Exec(ctx, `CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_event_channel ON notifications(event_id, event_type, type)`)
+	if err != nil {
+		return fmt.Errorf("failed to create notification deduplication index: %w", err)
+	}
+
 	// Create indexes
 	indexes := []string{
 		"CREATE INDEX IF NOT EXISTS idx_notifications_event_id ON notifications(event_id)",
@@ -87,6 +284,12 @@ func RunMigrations(db *DB) error {
 		"CREATE INDEX IF NOT EXISTS idx_notifications_created_at ON notifications(created_at)",
 		"CREATE INDEX IF NOT EXISTS idx_notifications_status_priority_created ON notifications(status, priority DESC, created_at ASC)",
 		"CREATE INDEX IF NOT EXISTS idx_notifications_event_type_status ON notifications(event_type, status)",
+		"CREATE INDEX IF NOT EXISTS idx_notifications_status_created ON notifications(status, created_at)",
+		"CREATE INDEX IF NOT EXISTS idx_notifications_sent_unflagged ON notifications(sent_at) WHERE status = 'SENT' AND undelivered_at IS NULL",
+		"CREATE INDEX IF NOT EXISTS idx_notifications_deliver_by_unflagged ON notifications(deliver_by) WHERE sla_breached_at IS NULL",
+		"CREATE INDEX IF NOT EXISTS idx_notification_attempts_notification_id ON notification_attempts(notification_id, attempted_at)",
+		"CREATE INDEX IF NOT EXISTS idx_notification_transitions_notification_id ON notification_transitions(notification_id, created_at)",
+		"CREATE INDEX IF NOT EXISTS idx_notification_mutes_account_expires ON notification_mutes(account_id, expires_at)",
 	}
 
 	for _, indexSQL := range indexes {
@@ -101,6 +304,9 @@ func RunMigrations(db *DB) error {
 
 // Close closes the database connection
 func (db *DB) Close() {
+	if db.replica != nil {
+		db.replica.Close()
+	}
 	if db.Pool != nil {
 		db.Pool.Close()
 		logrus.Info("Database connection closed")
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"fintech/notifications-service/pkg/otel"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Execer runs statements; satisfied by pools and transactions
+type Execer interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+}
+
+// Querier runs queries returning rows; satisfied by pools and transactions
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+// RowQuerier runs queries returning a single row; satisfied by pools and transactions
+type RowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// TracedExec runs a statement in a child span named operation, tagged with table and the number
+// of rows affected
+func TracedExec(ctx context.Context, db Execer, operation, table, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	ctx, span := startQuerySpan(ctx, operation, table)
+	tag, err := db.Exec(ctx, sql, args...)
+	endQuerySpan(span, tag.RowsAffected(), err)
+	return tag, err
+}
+
+// TracedQuery runs a query in a child span named operation, tagged with table. The span ends when
+// the rows are exhausted or closed, tagged with the number of rows read.
+func TracedQuery(ctx context.Context, db Querier, operation, table, sql string, args ...interface{}) (pgx.Rows, error) {
+	ctx, span := startQuerySpan(ctx, operation, table)
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		endQuerySpan(span, 0, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+// TracedQueryRow runs a single-row query in a child span named operation, tagged with table. The
+// span ends when the row is scanned; no rows is not recorded as an error.
+func TracedQueryRow(ctx context.Context, db RowQuerier, operation, table, sql string, args ...interface{}) pgx.Row {
+	ctx, span := startQuerySpan(ctx, operation, table)
+	return &tracedRow{row: db.QueryRow(ctx, sql, args...), span: span}
+}
+
+func startQuerySpan(ctx context.Context, operation, table string) (context.Context, trace.Span) {
+	ctx, span := otel.StartSpan(ctx, operation, trace.WithSpanKind(trace.SpanKindClient))
+	otel.AddSpanAttributes(span,
+		otel.Attribute("db.system", "postgresql"),
+		otel.Attribute("db.sql.table", table),
+	)
+	return ctx, span
+}
+
+func endQuerySpan(span trace.Span, rows int64, err error) {
+	otel.AddSpanAttributes(span, otel.Attribute("db.rows", rows))
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tracedRows counts the rows read and ends the query span once they are done
+type tracedRows struct {
+	pgx.Rows
+	span  trace.Span
+	count int64
+	ended bool
+}
+
+func (r *tracedRows) Next() bool {
+	if r.Rows.Next() {
+		r.count++
+		return true
+	}
+	r.end()
+	return false
+}
+
+func (r *tracedRows) Close() {
+	r.Rows.Close()
+	r.end()
+}
+
+func (r *tracedRows) end() {
+	if !r.ended {
+		r.ended = true
+		endQuerySpan(r.span, r.count, r.Rows.Err())
+	}
+}
+
+// tracedRow ends the query span when the row is scanned
+type tracedRow struct {
+	row  pgx.Row
+	span trace.Span
+}
+
+func (r *tracedRow) Scan(dest ...interface{}) error {
+	err := r.row.Scan(dest...)
+	var rows int64 = 1
+	if err != nil {
+		rows = 0
+	}
+	endQuerySpan(r.span, rows, err)
+	return err
+}
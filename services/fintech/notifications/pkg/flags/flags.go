@@ -0,0 +1,45 @@
+package flags
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Known feature flags
+const (
+	// MuteFailOpen sends notifications anyway when the mute lookup fails, instead of failing the event
+	MuteFailOpen = "mute_fail_open"
+)
+
+var known = map[string]bool{
+	MuteFailOpen: true,
+}
+
+// Set is a parsed set of enabled feature flags
+type Set struct {
+	enabled map[string]bool
+}
+
+// Parse parses a comma-separated list of flag names such as "mute_fail_open,other".
+// Unknown names are logged and ignored.
+func Parse(raw string) *Set {
+	s := &Set{enabled: make(map[string]bool)}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			logrus.WithField("flag", name).Warn("Ignoring unknown feature flag")
+			continue
+		}
+		s.enabled[name] = true
+	}
+	return s
+}
+
+// Enabled reports whether the named flag is enabled. A nil set has every flag disabled.
+func (s *Set) Enabled(name string) bool {
+	return s != nil && s.enabled[name]
+}
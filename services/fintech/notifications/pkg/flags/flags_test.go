@@ -0,0 +1,55 @@
+package flags
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want bool
+	}{
+		{"empty", "", false},
+		{"single flag", "mute_fail_open", true},
+		{"case and spacing", " MUTE_FAIL_OPEN ,", true},
+		{"among unknown flags", "rolling_limits,mute_fail_open,step_up", true},
+		{"only unknown flags", "rolling_limits,step_up", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.raw).Enabled(MuteFailOpen); got != tt.want {
+				t.Errorf("Parse(%q).Enabled(%s) = %v, want %v", tt.raw, MuteFailOpen, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWarnsOnUnknownFlags(t *testing.T) {
+	hook := test.NewGlobal()
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+
+	s := Parse("mute_fail_open,rolling_limits")
+	if s.Enabled("rolling_limits") {
+		t.Error("unknown flag rolling_limits is enabled")
+	}
+
+	entries := hook.AllEntries()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(entries))
+	}
+	if entries[0].Level != logrus.WarnLevel || entries[0].Data["flag"] != "rolling_limits" {
+		t.Errorf("logged %s %v, want a warning for rolling_limits", entries[0].Level, entries[0].Data)
+	}
+}
+
+func TestNilSetDisablesEveryFlag(t *testing.T) {
+	var s *Set
+	if s.Enabled(MuteFailOpen) {
+		t.Errorf("nil set has %s enabled", MuteFailOpen)
+	}
+}
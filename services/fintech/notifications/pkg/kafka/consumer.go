@@ -0,0 +1,372 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"fintech/notifications-service/pkg/redact"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// PaymentInitiatedEvent represents a payment initiation event
+type PaymentInitiatedEvent struct {
+	EventType      string  `json:"eventType,omitempty"`
+	PaymentID      string  `json:"paymentId"`
+	IdempotencyKey string  `json:"idempotencyKey"`
+	FromAccountID  string  `json:"fromAccountId"`
+	ToAccountID    string  `json:"toAccountId"`
+	Amount         float64 `json:"amount"`
+	Currency       string  `json:"currency"`
+	Reason         string  `json:"reason,omitempty"` // Only set on failure events
+	RecipientTier  string  `json:"recipientTier,omitempty"`
+	Locale         string  `json:"locale,omitempty"`
+}
+
+// Type returns the event type, defaulting to PaymentInitiated for producers that omit it
+func (e *PaymentInitiatedEvent) Type() string {
+	if e.EventType == "" {
+		return "PaymentInitiated"
+	}
+	return e.EventType
+}
+
+// ErrInvalidEvent marks an event that decoded but is structurally invalid; such events are
+// dead-lettered instead of handled
+var ErrInvalidEvent = errors.New("invalid event")
+
+// Validate checks the event is structurally sound before it reaches the business handler
+func (e *PaymentInitiatedEvent) Validate() error {
+	switch {
+	case e.PaymentID == "":
+		return fmt.Errorf("%w: paymentId is required", ErrInvalidEvent)
+	case e.FromAccountID == "":
+		return fmt.Errorf("%w: fromAccountId is required", ErrInvalidEvent)
+	case !(e.Amount > 0) || math.IsInf(e.Amount, 0):
+		return fmt.Errorf("%w: amount must be a positive number", ErrInvalidEvent)
+	case e.Currency != "" && len(e.Currency) != 3:
+		return fmt.Errorf("%w: currency must be a 3-letter ISO 4217 code", ErrInvalidEvent)
+	}
+	return nil
+}
+
+// DeliverySemantics controls whether a consumer commits offsets before or after handling
+type DeliverySemantics string
+
+const (
+	// AtLeastOnce commits after a message is handled. A crash mid-handle redelivers the message,
+	// so handlers must tolerate duplicates.
+	AtLeastOnce DeliverySemantics = "at_least_once"
+	// AtMostOnce commits before a message is handled. A crash mid-handle loses the message instead
+	// of redelivering it, which is cheaper but only acceptable for informational events.
+	AtMostOnce DeliverySemantics = "at_most_once"
+)
+
+// Valid reports whether d is a known delivery semantics
+func (d DeliverySemantics) Valid() bool {
+	return d == AtLeastOnce || d == AtMostOnce
+}
+
+// messageReader is the part of *kafka.Reader the consumer and reprocessor use
+type messageReader interface {
+	Config() kafka.ReaderConfig
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, messages ...kafka.Message) error
+	Close() error
+}
+
+// messageWriter is the part of *kafka.Writer the consumer and reprocessor use to dead-letter and park messages
+type messageWriter interface {
+	WriteMessages(ctx context.Context, messages ...kafka.Message) error
+	Close() error
+}
+
+// Consumer handles Kafka message consumption
+type Consumer struct {
+	reader      messageReader
+	concurrency int
+	semantics   DeliverySemantics
+	maxEventAge time.Duration    // 0 disables the age check
+	deadLetter  messageWriter    // nil when no dead letter topic is configured
+	transport   *kafka.Transport // Dead letter writer transport, with the reader's security settings
+	handlers    map[string]EventHandler
+}
+
+// NewConsumer creates a new Kafka consumer, connecting with the given TLS and SASL settings
+func NewConsumer(brokers string, groupID string, topic string, security SecurityConfig) (*Consumer, error) {
+	dialer, err := newDialer(security)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := newTransport(security)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     []string{brokers},
+		GroupID:     groupID,
+		Topic:       topic,
+		Dialer:      dialer,
+		MinBytes:    10e3,             // 10KB
+		MaxBytes:    10e6,             // 10MB
+		StartOffset: kafka.LastOffset, // Start from the end
+	})
+
+	return &Consumer{reader: reader, concurrency: 1, semantics: AtLeastOnce, transport: transport}, nil
+}
+
+// WithConcurrency lets up to n messages be handled at once. Messages from the same
+// partition are still handled one at a time, in order.
+func (c *Consumer) WithConcurrency(n int) *Consumer {
+	if n > 1 {
+		c.concurrency = n
+	}
+	return c
+}
+
+// WithDeliverySemantics sets when offsets are committed relative to handling. Unknown values
+// keep the at-least-once default.
+func (c *Consumer) WithDeliverySemantics(semantics DeliverySemantics) *Consumer {
+	if semantics.Valid() {
+		c.semantics = semantics
+	}
+	return c
+}
+
+// WithMaxEventAge skips events whose Kafka timestamp is older than age instead of handling them.
+// A zero age disables the check.
+func (c *Consumer) WithMaxEventAge(age time.Duration) *Consumer {
+	if age > 0 {
+		c.maxEventAge = age
+	}
+	return c
+}
+
+// WithDeadLetterTopic sends skipped and invalid events to topic rather than dropping them. An empty topic disables it.
+func (c *Consumer) WithDeadLetterTopic(brokers string, topic string) *Consumer {
+	if topic != "" {
+		c.deadLetter = &kafka.Writer{
+			Addr:      kafka.TCP(brokers),
+			Topic:     topic,
+			Balancer:  &kafka.Hash{},
+			Transport: c.transport,
+		}
+	}
+	return c
+}
+
+// Start begins consuming messages and calls the handler for each message, with a context carrying
+// the producer's trace context from the message headers. Enveloped events of a type registered with
+// RegisterHandler go to that handler instead.
+func (c *Consumer) Start(ctx context.Context, handler func(ctx context.Context, event *PaymentInitiatedEvent) error) error {
+	logrus.WithField("topic", c.reader.Config().Topic).Info("Starting Kafka consumer")
+
+	if c.concurrency > 1 {
+		return c.startConcurrently(ctx, handler)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			logrus.Info("Stopping Kafka consumer")
+			return ctx.Err()
+		default:
+			message, err := c.reader.FetchMessage(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					logrus.Info("Stopping Kafka consumer")
+					return ctx.Err()
+				}
+				logrus.WithError(err).Error("Failed to read message from Kafka")
+				continue
+			}
+
+			c.handleAndCommit(message, handler)
+		}
+	}
+}
+
+// startConcurrently fans messages out to a fixed pool of workers keyed by partition, so each
+// partition is handled and committed in order by a single worker while partitions run in parallel.
+// Offsets are committed explicitly, before or after handling depending on the delivery semantics.
+func (c *Consumer) startConcurrently(ctx context.Context, handler func(ctx context.Context, event *PaymentInitiatedEvent) error) error {
+	workers := make([]chan kafka.Message, c.concurrency)
+	var wg sync.WaitGroup
+	for i := range workers {
+		workers[i] = make(chan kafka.Message)
+		wg.Add(1)
+		go func(messages <-chan kafka.Message) {
+			defer wg.Done()
+			for message := range messages {
+				c.handleAndCommit(message, handler)
+			}
+		}(workers[i])
+	}
+
+	// Let in-flight messages finish before returning
+	defer func() {
+		for _, messages := range workers {
+			close(messages)
+		}
+		wg.Wait()
+	}()
+
+	for {
+		message, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				logrus.Info("Stopping Kafka consumer")
+				return ctx.Err()
+			}
+			logrus.WithError(err).Error("Failed to fetch message from Kafka")
+			continue
+		}
+
+		select {
+		case workers[message.Partition%len(workers)] <- message:
+		case <-ctx.Done():
+			logrus.Info("Stopping Kafka consumer")
+			return ctx.Err()
+		}
+	}
+}
+
+// handleAndCommit handles a message and commits its offset, committing first under at-most-once
+func (c *Consumer) handleAndCommit(message kafka.Message, handler func(ctx context.Context, event *PaymentInitiatedEvent) error) {
+	if c.semantics == AtMostOnce {
+		c.commit(message)
+		c.handle(message, handler)
+		return
+	}
+
+	c.handle(message, handler)
+	c.commit(message)
+}
+
+// commit commits a message's offset, even during shutdown so finished work isn't redelivered
+func (c *Consumer) commit(message kafka.Message) {
+	if err := c.reader.CommitMessages(context.Background(), message); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"partition": message.Partition,
+			"offset":    message.Offset,
+		}).Error("Failed to commit Kafka offset")
+	}
+}
+
+// handle decodes and handles a single message, logging rather than returning failures
+func (c *Consumer) handle(message kafka.Message, handler func(ctx context.Context, event *PaymentInitiatedEvent) error) {
+	if c.isStale(message) {
+		logrus.WithFields(logrus.Fields{
+			"partition": message.Partition,
+			"offset":    message.Offset,
+			"timestamp": message.Time,
+		}).Warn("Skipping event older than the maximum event age")
+		c.sendToDeadLetter(message, "event older than maximum age", false)
+		return
+	}
+
+	// Parse and handle the event, continuing the producer's trace. Structurally invalid events are
+	// rejected before they reach the handler.
+	paymentID, err := processEvent(messageContext(message), message.Value, handler, c.handlers)
+	if errors.Is(err, ErrInvalidEvent) {
+		logrus.WithError(err).WithField("message", redact.Body(string(message.Value))).Warn("Rejecting invalid event")
+		c.sendToDeadLetter(message, err.Error(), false)
+		return
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("payment_id", paymentID).Error("Failed to handle payment event")
+		c.sendToDeadLetter(message, err.Error(), true)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"payment_id": paymentID,
+		"partition":  message.Partition,
+		"offset":     message.Offset,
+	}).Debug("Successfully processed payment event")
+}
+
+// processEvent passes an enveloped event of a type in handlers to that handler, and decodes any
+// other event as a payment event for handler, returning its payment ID
+func processEvent(ctx context.Context, value []byte, handler func(ctx context.Context, event *PaymentInitiatedEvent) error, handlers map[string]EventHandler) (string, error) {
+	if envelope, ok := decodeEnvelope(value); ok {
+		if registered, ok := handlers[envelope.Type]; ok {
+			return "", registered(ctx, envelope.Payload)
+		}
+	}
+
+	event, err := decodeEvent(value)
+	if err != nil {
+		return "", err
+	}
+	return event.PaymentID, handler(ctx, event)
+}
+
+// decodeEvent unmarshals and validates a payment event, bare or enveloped; every error wraps
+// ErrInvalidEvent
+func decodeEvent(value []byte) (*PaymentInitiatedEvent, error) {
+	var event PaymentInitiatedEvent
+	if envelope, ok := decodeEnvelope(value); ok {
+		if err := json.Unmarshal(envelope.Payload, &event); err != nil {
+			return nil, fmt.Errorf("%w: failed to unmarshal %s event: %v", ErrInvalidEvent, envelope.Type, err)
+		}
+		event.EventType = envelope.Type
+	} else if err := json.Unmarshal(value, &event); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal payment event: %v", ErrInvalidEvent, err)
+	}
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// isStale reports whether the message timestamp is older than the configured maximum event age
+func (c *Consumer) isStale(message kafka.Message) bool {
+	if c.maxEventAge == 0 || message.Time.IsZero() {
+		return false
+	}
+	return time.Since(message.Time) > c.maxEventAge
+}
+
+// sendToDeadLetter forwards a message that wasn't handled to the dead letter topic, if one is
+// configured. Retryable messages are picked up again by the Reprocessor.
+func (c *Consumer) sendToDeadLetter(message kafka.Message, reason string, retryable bool) {
+	if c.deadLetter == nil {
+		return
+	}
+
+	headers := setHeader(message.Headers, headerReason, reason)
+	headers = setHeader(headers, headerSourceTopic, message.Topic)
+	headers = setHeader(headers, headerRetryable, strconv.FormatBool(retryable))
+
+	err := c.deadLetter.WriteMessages(context.Background(), kafka.Message{
+		Key:     message.Key,
+		Value:   message.Value,
+		Headers: headers,
+	})
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"partition": message.Partition,
+			"offset":    message.Offset,
+		}).Error("Failed to send message to dead letter topic")
+	}
+}
+
+// Close closes the Kafka consumer
+func (c *Consumer) Close() error {
+	logrus.Info("Closing Kafka consumer")
+	if c.deadLetter != nil {
+		if err := c.deadLetter.Close(); err != nil {
+			logrus.WithError(err).Error("Failed to close dead letter writer")
+		}
+	}
+	return c.reader.Close()
+}
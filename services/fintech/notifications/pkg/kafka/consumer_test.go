@@ -0,0 +1,194 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeWriter records the messages written to the dead letter topic
+type fakeWriter struct {
+	mu       sync.Mutex
+	messages []kafka.Message
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, messages ...kafka.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, messages...)
+	return nil
+}
+
+func (w *fakeWriter) Close() error {
+	return nil
+}
+
+func (w *fakeWriter) written() []kafka.Message {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]kafka.Message(nil), w.messages...)
+}
+
+// paymentMessage is a valid payment event message produced age ago
+func paymentMessage(age time.Duration) kafka.Message {
+	return kafka.Message{
+		Topic: "payments",
+		Value: []byte(`{"paymentId": "pay-1", "fromAccountId": "acc-1", "amount": 10, "currency": "EUR"}`),
+		Time:  time.Now().Add(-age),
+	}
+}
+
+func TestHandleMaxEventAge(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxEventAge time.Duration
+		age         time.Duration
+		wantHandled bool
+	}{
+		{"fresh event", time.Hour, time.Minute, true},
+		{"stale event", time.Hour, 2 * time.Hour, false},
+		{"old event with the check disabled", 0, 48 * time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &fakeWriter{}
+			c := &Consumer{maxEventAge: tt.maxEventAge, deadLetter: writer}
+
+			var handled []string
+			c.handle(paymentMessage(tt.age), func(ctx context.Context, event *PaymentInitiatedEvent) error {
+				handled = append(handled, event.PaymentID)
+				return nil
+			})
+
+			if got := len(handled) == 1; got != tt.wantHandled {
+				t.Errorf("handled = %v, want %v", got, tt.wantHandled)
+			}
+			dead := writer.written()
+			if tt.wantHandled {
+				if len(dead) != 0 {
+					t.Errorf("dead-lettered %d messages, want none", len(dead))
+				}
+				return
+			}
+			if len(dead) != 1 || header(dead[0], headerReason) != "event older than maximum age" || header(dead[0], headerRetryable) != "false" {
+				t.Errorf("dead-lettered %+v, want the stale event as non-retryable", dead)
+			}
+		})
+	}
+}
+
+func TestHandleValidatesPaymentEvents(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantHandled bool
+		wantReason  string
+	}{
+		{"valid event", `{"paymentId": "pay-1", "fromAccountId": "acc-1", "amount": 10, "currency": "EUR"}`, true, ""},
+		{"negative amount", `{"paymentId": "pay-1", "fromAccountId": "acc-1", "amount": -5, "currency": "EUR"}`, false, "amount must be a positive number"},
+		{"empty account ID", `{"paymentId": "pay-1", "fromAccountId": "", "amount": 10, "currency": "EUR"}`, false, "fromAccountId is required"},
+		{"malformed currency", `{"paymentId": "pay-1", "fromAccountId": "acc-1", "amount": 10, "currency": "EURO"}`, false, "currency must be a 3-letter ISO 4217 code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &fakeWriter{}
+			c := &Consumer{deadLetter: writer}
+
+			var handled []string
+			c.handle(kafka.Message{Topic: "payments", Value: []byte(tt.value)}, func(ctx context.Context, event *PaymentInitiatedEvent) error {
+				handled = append(handled, event.PaymentID)
+				return nil
+			})
+
+			dead := writer.written()
+			if tt.wantHandled {
+				if len(handled) != 1 {
+					t.Errorf("handled %v, want the event", handled)
+				}
+				if len(dead) != 0 {
+					t.Errorf("dead-lettered %d messages, want none", len(dead))
+				}
+				return
+			}
+			if len(handled) != 0 {
+				t.Errorf("business handler called for %v, want the event rejected first", handled)
+			}
+			if len(dead) != 1 || !strings.Contains(header(dead[0], headerReason), tt.wantReason) || header(dead[0], headerRetryable) != "false" {
+				t.Errorf("dead-lettered %+v, want the event as non-retryable with reason %q", dead, tt.wantReason)
+			}
+		})
+	}
+}
+
+// recordingReader records commits in a log shared with the handler, so tests can see whether
+// offsets were committed before or after handling
+type recordingReader struct {
+	log *[]string
+}
+
+func (r *recordingReader) Config() kafka.ReaderConfig {
+	return kafka.ReaderConfig{Topic: "payments", GroupID: "notifications-service"}
+}
+
+func (r *recordingReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *recordingReader) CommitMessages(ctx context.Context, messages ...kafka.Message) error {
+	*r.log = append(*r.log, "commit")
+	return nil
+}
+
+func (r *recordingReader) Close() error {
+	return nil
+}
+
+func TestHandleAndCommitOrdering(t *testing.T) {
+	tests := []struct {
+		semantics DeliverySemantics
+		want      []string
+	}{
+		{AtLeastOnce, []string{"handle", "commit"}},
+		{AtMostOnce, []string{"commit", "handle"}},
+		{DeliverySemantics("exactly_once"), []string{"handle", "commit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.semantics), func(t *testing.T) {
+			var log []string
+			c := (&Consumer{reader: &recordingReader{log: &log}, semantics: AtLeastOnce}).WithDeliverySemantics(tt.semantics)
+
+			c.handleAndCommit(paymentMessage(time.Minute), func(ctx context.Context, event *PaymentInitiatedEvent) error {
+				log = append(log, "handle")
+				return nil
+			})
+
+			if strings.Join(log, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("order = %v, want %v", log, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleAndCommitAtMostOnceCommitsFailedEvents(t *testing.T) {
+	var log []string
+	c := (&Consumer{reader: &recordingReader{log: &log}}).WithDeliverySemantics(AtMostOnce)
+
+	c.handleAndCommit(paymentMessage(time.Minute), func(ctx context.Context, event *PaymentInitiatedEvent) error {
+		log = append(log, "handle")
+		return errors.New("SNS unavailable")
+	})
+
+	// The offset is already committed, so the failed event is not redelivered
+	if strings.Join(log, ",") != "commit,handle" {
+		t.Errorf("order = %v, want the commit before the failed handle", log)
+	}
+}
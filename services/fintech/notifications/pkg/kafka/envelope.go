@@ -0,0 +1,53 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// EventEnvelope wraps an event with its type so that one topic can carry events of different
+// shapes. Producers that predate it send bare payment events, which are still accepted.
+type EventEnvelope struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// EventHandler handles the payload of an enveloped event of the type it was registered for.
+// Returning an error wrapping ErrInvalidEvent dead-letters the event without further retries.
+type EventHandler func(ctx context.Context, payload json.RawMessage) error
+
+// decodeEnvelope returns the envelope value is wrapped in, or false if value is a bare event
+func decodeEnvelope(value []byte) (*EventEnvelope, bool) {
+	var envelope EventEnvelope
+	if err := json.Unmarshal(value, &envelope); err != nil || envelope.Type == "" || len(envelope.Payload) == 0 {
+		return nil, false
+	}
+	return &envelope, true
+}
+
+// RegisterHandler routes enveloped events of eventType on the consumer's topic to handler instead of
+// the payment handler passed to Start. Payment lifecycle types without a registered handler are
+// decoded from the payload as payment events. Register handlers before calling Start.
+func (c *Consumer) RegisterHandler(eventType string, handler EventHandler) *Consumer {
+	if c.handlers == nil {
+		c.handlers = make(map[string]EventHandler)
+	}
+	c.handlers[eventType] = handler
+	return c
+}
+
+// RegisterHandler routes enveloped events of eventType dead-lettered from topic to handler, as for
+// Consumer.RegisterHandler
+func (r *Reprocessor) RegisterHandler(topic, eventType string, handler EventHandler) {
+	r.eventHandlers(topic)[eventType] = handler
+}
+
+// eventHandlers returns the enveloped event handlers registered for topic, creating the map if needed
+func (r *Reprocessor) eventHandlers(topic string) map[string]EventHandler {
+	handlers, ok := r.handlers[topic]
+	if !ok {
+		handlers = make(map[string]EventHandler)
+		r.handlers[topic] = handlers
+	}
+	return handlers
+}
@@ -0,0 +1,80 @@
+package kafka
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewDialerPlaintextByDefault(t *testing.T) {
+	dialer, err := newDialer(SecurityConfig{})
+	if err != nil {
+		t.Fatalf("newDialer: %v", err)
+	}
+	if dialer.TLS != nil || dialer.SASLMechanism != nil {
+		t.Errorf("dialer TLS = %v, SASL = %v, want plaintext without authentication", dialer.TLS, dialer.SASLMechanism)
+	}
+}
+
+func TestNewDialerWithTLSAndSASL(t *testing.T) {
+	tests := []struct {
+		mechanism string
+		want      string
+	}{
+		{"PLAIN", "PLAIN"},
+		{"scram-sha-256", "SCRAM-SHA-256"},
+		{"SCRAM-SHA-512", "SCRAM-SHA-512"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mechanism, func(t *testing.T) {
+			dialer, err := newDialer(SecurityConfig{TLS: true, SASLMechanism: tt.mechanism, Username: "svc", Password: "secret"})
+			if err != nil {
+				t.Fatalf("newDialer: %v", err)
+			}
+			if dialer.TLS == nil {
+				t.Error("dialer has no TLS config")
+			}
+			if dialer.SASLMechanism == nil || dialer.SASLMechanism.Name() != tt.want {
+				t.Errorf("dialer SASL mechanism = %v, want %s", dialer.SASLMechanism, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDialerErrors(t *testing.T) {
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		security SecurityConfig
+	}{
+		{"unsupported SASL mechanism", SecurityConfig{SASLMechanism: "GSSAPI"}},
+		{"missing CA file", SecurityConfig{TLS: true, CAFile: filepath.Join(t.TempDir(), "missing.pem")}},
+		{"CA file without certificates", SecurityConfig{TLS: true, CAFile: notPEM}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newDialer(tt.security); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestNewConsumerUsesSecurityConfig(t *testing.T) {
+	c, err := NewConsumer("localhost:9092", "group", "payments", SecurityConfig{TLS: true, SASLMechanism: "PLAIN", Username: "svc", Password: "secret"})
+	if err != nil {
+		t.Fatalf("NewConsumer: %v", err)
+	}
+	defer c.Close()
+
+	dialer := c.reader.Config().Dialer
+	if dialer == nil || dialer.TLS == nil || dialer.SASLMechanism == nil {
+		t.Errorf("reader dialer = %+v, want TLS and SASL configured", dialer)
+	}
+}
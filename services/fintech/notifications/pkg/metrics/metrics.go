@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Collectors are registered once with the default registry at package init,
+// so they are served by the existing promhttp /metrics handler.
+var (
+	// TemplateMissing counts notifications skipped because no template matched
+	TemplateMissing = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notification_template_missing_total",
+		Help: "Notifications not created because no template exists for the event type and channel.",
+	}, []string{"event_type", "channel"})
+
+	// NotificationsThrottled counts notifications skipped by the per-recipient throttle
+	NotificationsThrottled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notifications_throttled_total",
+		Help: "Notifications recorded as THROTTLED because the recipient exceeded the cross-channel throttle.",
+	}, []string{"channel"})
+
+	// NotificationsDeduplicated counts notifications skipped because a redelivered event already created them
+	NotificationsDeduplicated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notifications_deduplicated_total",
+		Help: "Notifications not created or sent again because their event was redelivered.",
+	}, []string{"channel"})
+
+	// SLABreaches counts notifications flagged as unsent past their delivery SLA
+	SLABreaches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notification_sla_breaches_total",
+		Help: "Notifications still not sent when their delivery SLA deadline passed.",
+	}, []string{"channel", "priority"})
+
+	// NotificationsUndelivered counts SENT notifications flagged for never getting a delivery receipt
+	NotificationsUndelivered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notifications_undelivered_total",
+		Help: "SENT notifications with no delivery receipt after UNDELIVERED_AFTER.",
+	}, []string{"channel"})
+
+	// NotificationsSent counts send attempts by channel and the status they left the notification in
+	NotificationsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notifications_sent_total",
+		Help: "Notification send attempts, by type and resulting status (SENT, PENDING for a scheduled retry, or FAILED).",
+	}, []string{"type", "status"})
+
+	// NotificationRetries counts failed sends scheduled for another attempt
+	NotificationRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notifications_retries_total",
+		Help: "Failed notification sends scheduled for retry, by type.",
+	}, []string{"type"})
+
+	// NotificationSendDuration observes how long handing a notification to SES or SNS takes
+	NotificationSendDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "notification_send_duration_seconds",
+		Help:    "Duration of notification delivery calls to SES or SNS, by type.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"type"})
+)
@@ -0,0 +1,57 @@
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Contact holds an account's current contact details; empty fields aren't on file
+type Contact struct {
+	Email       string `json:"email"`
+	Phone       string `json:"phone"`
+	DeviceToken string `json:"deviceToken"`
+}
+
+// Client looks up contact details from a profile service that answers
+// GET {baseURL}/accounts/{accountId}/contact with a Contact body
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewClient creates a client for the profile service at baseURL
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// GetContact fetches the current contact details of an account
+func (c *Client) GetContact(ctx context.Context, accountID string) (*Contact, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/accounts/"+url.PathEscape(accountID)+"/contact", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build profile request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch profile contact: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("profile service returned status %d", resp.StatusCode)
+	}
+
+	var contact Contact
+	if err := json.NewDecoder(resp.Body).Decode(&contact); err != nil {
+		return nil, fmt.Errorf("failed to decode profile contact: %w", err)
+	}
+
+	return &contact, nil
+}
@@ -0,0 +1,56 @@
+package redact
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+var logPII atomic.Bool
+
+// SetLogPII controls whether personal data is logged unmasked (called at startup). Only meant for
+// debugging; it is off by default.
+func SetLogPII(enabled bool) {
+	logPII.Store(enabled)
+}
+
+// Recipient masks an email address or phone number for logging, keeping just enough to tell
+// recipients apart: the first letter and domain of an email, the last four digits of a number.
+func Recipient(recipient string) string {
+	if logPII.Load() || recipient == "" {
+		return recipient
+	}
+
+	if local, domain, ok := strings.Cut(recipient, "@"); ok && local != "" {
+		return local[:1] + "***@" + domain
+	}
+	if len(recipient) > 4 {
+		return "***" + recipient[len(recipient)-4:]
+	}
+	return "***"
+}
+
+// Body masks free-form content such as a notification body or raw event payload for logging
+func Body(body string) string {
+	if logPII.Load() || body == "" {
+		return body
+	}
+	return fmt.Sprintf("[redacted %d bytes]", len(body))
+}
+
+// Error returns err with every occurrence of the given recipients masked, for errors that quote
+// the value they rejected
+func Error(err error, recipients ...string) error {
+	if err == nil || logPII.Load() {
+		return err
+	}
+
+	message := err.Error()
+	for _, recipient := range recipients {
+		if recipient != "" {
+			message = strings.ReplaceAll(message, recipient, Recipient(recipient))
+		}
+	}
+	return errors.New(message)
+}
@@ -0,0 +1,57 @@
+package redact
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRecipientMaskedByDefault(t *testing.T) {
+	tests := []struct {
+		recipient string
+		want      string
+	}{
+		{"jane.doe@example.com", "j***@example.com"},
+		{"+16502530000", "***0000"},
+		{"1234", "***"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.recipient, func(t *testing.T) {
+			if got := Recipient(tt.recipient); got != tt.want {
+				t.Errorf("Recipient(%q) = %q, want %q", tt.recipient, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBodyMaskedByDefault(t *testing.T) {
+	if got := Body("Your payment of 42.50 EUR has completed."); got != "[redacted 40 bytes]" {
+		t.Errorf("Body = %q, want the length only", got)
+	}
+}
+
+func TestErrorMasksRecipients(t *testing.T) {
+	err := Error(errors.New("invalid phone number +16502530000"), "+16502530000")
+	if got := err.Error(); got != "invalid phone number ***0000" {
+		t.Errorf("Error = %q, want the recipient masked", got)
+	}
+	if Error(nil, "+16502530000") != nil {
+		t.Error("Error(nil) is not nil")
+	}
+}
+
+func TestLogPIIShowsContent(t *testing.T) {
+	SetLogPII(true)
+	defer SetLogPII(false)
+
+	if got := Recipient("jane.doe@example.com"); got != "jane.doe@example.com" {
+		t.Errorf("Recipient = %q, want it unmasked", got)
+	}
+	if got := Body("Your payment has completed."); got != "Your payment has completed." {
+		t.Errorf("Body = %q, want it unmasked", got)
+	}
+	if got := Error(errors.New("bad recipient jane@example"), "jane@example").Error(); got != "bad recipient jane@example" {
+		t.Errorf("Error = %q, want it unmasked", got)
+	}
+}
@@ -0,0 +1,68 @@
+package respond
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fintech/notifications-service/pkg/middleware"
+)
+
+// serve runs a request through the request ID middleware to a handler responding with payload
+func serve(t *testing.T, payload interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	handler := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := JSON(r.Context(), w, http.StatusCreated, payload); err != nil {
+			t.Errorf("JSON: %v", err)
+		}
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rec
+}
+
+func TestJSONEnvelope(t *testing.T) {
+	SetEnvelope(true)
+	t.Cleanup(func() { SetEnvelope(false) })
+
+	before := time.Now().UTC().Add(-time.Second)
+	rec := serve(t, map[string]string{"status": "ok"})
+
+	if rec.Code != http.StatusCreated || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("response = %d %s, want 201 application/json", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	var body struct {
+		Data map[string]string `json:"data"`
+		Meta Meta              `json:"meta"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Data["status"] != "ok" {
+		t.Errorf("data = %v, want the payload", body.Data)
+	}
+	if id := rec.Header().Get(middleware.RequestIDHeader); id == "" || body.Meta.RequestID != id {
+		t.Errorf("meta request ID = %q, want the middleware's %q", body.Meta.RequestID, id)
+	}
+	if body.Meta.Timestamp.Before(before) || body.Meta.Timestamp.After(time.Now().UTC()) {
+		t.Errorf("meta timestamp = %v, want the server time", body.Meta.Timestamp)
+	}
+}
+
+func TestJSONWithoutEnvelope(t *testing.T) {
+	SetEnvelope(false)
+
+	rec := serve(t, map[string]string{"status": "ok"})
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(body) != 1 || body["status"] != "ok" {
+		t.Errorf("body = %v, want the bare payload", body)
+	}
+}